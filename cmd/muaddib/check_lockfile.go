@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+var (
+	checkType string
+	checkName string
+)
+
+// lockfileTypes maps --type hints to the filename the scanner parses them as
+var lockfileTypes = map[string]string{
+	"npm":          "package-lock.json",
+	"package-lock": "package-lock.json",
+	"shrinkwrap":   "npm-shrinkwrap.json",
+	"package-json": "package.json",
+	"yarn":         "yarn.lock",
+	"pnpm":         "pnpm-lock.yaml",
+	"bun":          "bun.lock",
+}
+
+// newCheckLockfileCmd creates the check-lockfile subcommand
+func newCheckLockfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-lockfile",
+		Short: "Check a single lockfile or package.json read from stdin",
+		Long: `Reads a single package.json or lockfile from stdin and checks its
+dependencies against the IOC database. No GitHub token is required.
+Like scan, it exits with status 2 when findings qualify under --fail-on.
+
+Example:
+  cat package-lock.json | muaddib check-lockfile
+  cat yarn.lock | muaddib check-lockfile --type yarn`,
+		Args: cobra.NoArgs,
+		RunE: runCheckLockfile,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&checkType, "type", "", "Type of the input: npm, shrinkwrap, package-json, yarn, pnpm, or bun")
+	flags.StringVar(&checkName, "name", "", "Filename of the input, used to pick the parser (default: package-lock.json)")
+	flags.StringArrayVar(&vulnCSVs, "vuln-csv", nil, "Path or URL to a vulnerability CSV or OSV JSON file, or - to read it from stdin; replaces the DataDog + Wiz IOC lists (repeatable, sources are merged)")
+	flags.BoolVar(&includeDefaults, "include-defaults", false, "Merge the --vuln-csv sources with the DataDog + Wiz IOC lists instead of replacing them")
+	flags.StringVar(&manifest, "source-manifest", "", "URL of a JSON/YAML manifest listing IOC feed URLs to load and merge")
+	flags.StringArrayVar(&iocChecksums, "ioc-checksum", nil, "Pin the SHA-256 of an IOC source as url=sha256; a mismatch fails the load (repeatable)")
+	addIOCDownloadFlags(flags)
+	addConfigFlag(flags)
+	flags.BoolVar(&skipDev, "skip-dev", false, "Skip devDependencies")
+	flags.BoolVar(&skipOptional, "skip-optional", false, "Skip optionalDependencies")
+	flags.BoolVar(&noColor, "no-color", false, "Disable colored output; also off when NO_COLOR is set or output is not a terminal")
+	addFailFlags(flags)
+
+	return cmd
+}
+
+// resolveLockfileName picks the filename used to select a parser from --name or --type
+func resolveLockfileName(name, fileType string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if fileType == "" {
+		return "package-lock.json", nil
+	}
+	filename, ok := lockfileTypes[strings.ToLower(fileType)]
+	if !ok {
+		return "", fmt.Errorf("unknown --type %q (expected npm, shrinkwrap, package-json, yarn, pnpm, or bun)", fileType)
+	}
+	return filename, nil
+}
+
+// runCheckLockfile scans the file content read from stdin
+func runCheckLockfile(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	rep := reporter.NewTerminalReporter(reporter.WithOutput(out), colorOption(out), reporter.WithVerbose(true))
+
+	filename, err := resolveLockfileName(checkName, checkType)
+	if err != nil {
+		return err
+	}
+	if err := validateIOCSourceFlags(); err != nil {
+		return err
+	}
+	if readsIOCsFromStdin() {
+		return fmt.Errorf("check-lockfile reads the lockfile from stdin, so --vuln-csv cannot be -")
+	}
+	if err := validateIOCDownloadFlags(); err != nil {
+		return err
+	}
+	if err := validateFailFlags(); err != nil {
+		return err
+	}
+
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	db, err := loadVulnDB(rep)
+	if err != nil {
+		return fmt.Errorf("failed to load vulnerability database: %w", err)
+	}
+
+	// ScanFiles skips files it cannot parse, which for the only file would
+	// report it as clean
+	scan := scanner.NewScanner(db, !skipDev, scanner.WithSkipOptional(skipOptional))
+	file := &github.PackageFile{RepoName: "stdin", Path: filename, Content: string(content)}
+	if _, err := scan.ParseFile(file); err != nil {
+		return fmt.Errorf("failed to parse %s from stdin: %w", filename, err)
+	}
+	result := scan.ScanFiles([]*github.PackageFile{file})
+	result.RepoName = "stdin"
+
+	rep.ReportRepoStart(filename)
+	rep.ReportRepoResult(result)
+	return checkFailPolicy(cmd, []*scanner.RepoScanResult{result}, nil)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLockfile_ReportsVulnerablePackageFromStdin(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "iocs.csv")
+	csvData := "package_name,package_versions,sources\ntest-muaddib-vulnerable,1.0.0,\"test\"\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0o600); err != nil {
+		t.Fatalf("failed to write IOC CSV: %v", err)
+	}
+
+	lockfile := `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/test-muaddib-vulnerable": {"version": "1.0.0"},
+			"node_modules/test-muaddib-safe": {"version": "2.0.0"}
+		}
+	}`
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetIn(strings.NewReader(lockfile))
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"check-lockfile", "--vuln-csv", csvPath})
+	rootCmd.SilenceErrors = true
+
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected exit status %d for findings, got %v", exitFindings, err)
+	}
+
+	if !strings.Contains(out.String(), "test-muaddib-vulnerable@1.0.0") {
+		t.Errorf("expected vulnerable package in output, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "test-muaddib-safe") {
+		t.Errorf("expected safe package not to be reported, got:\n%s", out.String())
+	}
+
+	rootCmd = newRootCmd()
+	rootCmd.SetIn(strings.NewReader(lockfile))
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"check-lockfile", "--vuln-csv", csvPath, "--fail-on", "none"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("expected --fail-on none to exit 0, got %v", err)
+	}
+}
+
+func TestCheckLockfile_FailsOnUnparsableInput(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "iocs.csv")
+	if err := os.WriteFile(csvPath, []byte("package_name,package_versions,sources\ntest-muaddib-vulnerable,1.0.0,\"test\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write IOC CSV: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		input string
+		args  []string
+		want  string
+	}{
+		{"malformed JSON", "{not json", nil, "failed to parse package-lock.json"},
+		{"Yarn Berry lockfile", "__metadata:\n  version: 6\n\n\"test-muaddib-vulnerable@npm:^1.0.0\":\n  version: 1.0.0\n", []string{"--type", "yarn"}, "Yarn Berry"},
+		{"unsupported filename", "anything", []string{"--name", "requirements.txt"}, "unsupported package file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd := newRootCmd()
+			rootCmd.SetIn(strings.NewReader(tc.input))
+			rootCmd.SetOut(&out)
+			rootCmd.SetErr(&bytes.Buffer{})
+			rootCmd.SetArgs(append([]string{"check-lockfile", "--vuln-csv", csvPath}, tc.args...))
+
+			err := rootCmd.Execute()
+			if exitCode(err) != exitError || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected exit status %d with %q, got %v", exitError, tc.want, err)
+			}
+			if strings.Contains(out.String(), "No vulnerable packages") {
+				t.Errorf("expected unparsable input not to be reported as clean, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestCheckLockfile_MergesRepeatedVulnCSV(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "iocs.csv")
+	if err := os.WriteFile(csvPath, []byte("package_name,package_versions,sources\ntest-muaddib-internal,1.0.0,\"test\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write IOC CSV: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "package_name,package_versions,sources\ntest-muaddib-public,2.0.0,\"test\"\ntest-muaddib-public,2.0.1,\"test\"\n")
+	}))
+	defer server.Close()
+
+	lockfile := `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/test-muaddib-internal": {"version": "1.0.0"},
+			"node_modules/test-muaddib-public": {"version": "2.0.0"}
+		}
+	}`
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetIn(strings.NewReader(lockfile))
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"check-lockfile", "--vuln-csv", csvPath, "--vuln-csv", server.URL + "/iocs.csv"})
+	rootCmd.SilenceErrors = true
+
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected exit status %d for findings, got %v", exitFindings, err)
+	}
+
+	for _, want := range []string{
+		"test-muaddib-internal@1.0.0",
+		"test-muaddib-public@2.0.0",
+		"Using custom source: " + csvPath + " (1 entries)",
+		"Using custom source: " + server.URL + "/iocs.csv (2 entries)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCheckLockfile_RejectsIOCsFromStdin(t *testing.T) {
+	rootCmd := newRootCmd()
+	rootCmd.SetIn(strings.NewReader(`{"lockfileVersion": 3, "packages": {}}`))
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"check-lockfile", "--vuln-csv", "-"})
+	rootCmd.SilenceUsage, rootCmd.SilenceErrors = true, true
+
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "reads the lockfile from stdin") {
+		t.Errorf("expected --vuln-csv - to be rejected, got %v", err)
+	}
+}
+
+func TestValidateIOCSourceFlags(t *testing.T) {
+	t.Cleanup(func() { vulnCSVs, manifest, includeDefaults = nil, "", false })
+
+	tests := []struct {
+		name            string
+		vulnCSVs        []string
+		manifest        string
+		includeDefaults bool
+		wantErr         bool
+	}{
+		{"defaults", nil, "", false, false},
+		{"custom sources", []string{"a.csv", "https://example.com/b.csv"}, "", false, false},
+		{"custom sources with defaults", []string{"a.csv"}, "", true, false},
+		{"defaults flag alone", nil, "", true, true},
+		{"custom sources with manifest", []string{"a.csv"}, "https://example.com/iocs.yaml", false, true},
+		{"stdin", []string{"-", "a.csv"}, "", false, false},
+		{"stdin twice", []string{"-", "-"}, "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vulnCSVs, manifest, includeDefaults = tt.vulnCSVs, tt.manifest, tt.includeDefaults
+			if err := validateIOCSourceFlags(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestResolveLockfileName(t *testing.T) {
+	tests := []struct {
+		name, fileType, want string
+	}{
+		{"", "", "package-lock.json"},
+		{"", "yarn", "yarn.lock"},
+		{"", "PNPM", "pnpm-lock.yaml"},
+		{"sub/package.json", "yarn", "sub/package.json"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveLockfileName(tt.name, tt.fileType)
+		if err != nil {
+			t.Fatalf("resolveLockfileName(%q, %q) failed: %v", tt.name, tt.fileType, err)
+		}
+		if got != tt.want {
+			t.Errorf("resolveLockfileName(%q, %q) = %q, want %q", tt.name, tt.fileType, got, tt.want)
+		}
+	}
+
+	if _, err := resolveLockfileName("", "cargo"); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// configPath is the config file given with --config
+var configPath string
+
+// defaultConfigFile is looked up in the working directory when --config is not given
+const defaultConfigFile = "muaddib.yaml"
+
+// configPrecedence explains how config values combine, for error messages
+const configPrecedence = "keys are flag names without the leading dashes; command-line flags override the config file, which overrides the built-in defaults"
+
+// addConfigFlag registers --config, shared by every command
+func addConfigFlag(flags *pflag.FlagSet) {
+	flags.StringVar(&configPath, "config", "", "YAML file of flag values keyed by flag name; command-line flags take precedence (default ./muaddib.yaml, then ~/.config/muaddib/config.yaml, if present)")
+}
+
+// defaultConfigPaths returns the config files looked up when --config is not
+// given, in order: ./muaddib.yaml, then ~/.config/muaddib/config.yaml
+func defaultConfigPaths() []string {
+	paths := []string{defaultConfigFile}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "muaddib", "config.yaml"))
+	}
+	return paths
+}
+
+// readConfig reads the config file given with --config, or the first default
+// config file that exists. It returns nil content when there is none.
+func readConfig() (string, []byte, error) {
+	if configPath != "" {
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		return configPath, content, nil
+	}
+
+	for _, path := range defaultConfigPaths() {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		return path, content, nil
+	}
+	return "", nil, nil
+}
+
+// targetFlags choose what a scan covers. A target given on the command line
+// replaces the configured one rather than combining with it.
+var targetFlags = []string{"org", "user", "repo", "path", "manifests-dir"}
+
+// applyConfig sets the flags of cmd that were not given on the command line
+// from the config file. Keys are flag names. A key naming a flag of another
+// command, such as org for check-lockfile, is ignored so one file can serve
+// every command; a key naming no flag at all is an error. Configured values
+// are not marked Changed, so checks for flags given on the command line
+// ignore them.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	path, content, err := readConfig()
+	if err != nil || content == nil {
+		return err
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	flags := cmd.Flags()
+	targetGiven := slices.ContainsFunc(targetFlags, flags.Changed)
+	for key, value := range values {
+		flag := flags.Lookup(key)
+		if flag == nil {
+			if !isKnownFlag(cmd.Root(), key) {
+				return fmt.Errorf("unknown key %q in config file %s (%s)", key, path, configPrecedence)
+			}
+			continue
+		}
+		if flag.Changed || value == nil || (targetGiven && slices.Contains(targetFlags, key)) {
+			continue
+		}
+		if err := setFlagFromConfig(flag, value); err != nil {
+			return fmt.Errorf("invalid value for %q in config file %s: %w", key, path, err)
+		}
+	}
+	return nil
+}
+
+// setFlagFromConfig sets a flag from a config value. A list replaces the
+// default of a repeatable flag; other flags take a single value.
+func setFlagFromConfig(flag *pflag.Flag, value any) error {
+	items, isList := value.([]any)
+	if _, isMap := value.(map[string]any); isMap {
+		return fmt.Errorf("expected a value or a list")
+	}
+
+	sliceValue, repeatable := flag.Value.(pflag.SliceValue)
+	switch {
+	case repeatable:
+		if !isList {
+			items = []any{value}
+		}
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = fmt.Sprint(item)
+		}
+		if err := sliceValue.Replace(values); err != nil {
+			return err
+		}
+	case isList:
+		return fmt.Errorf("expected a single value, got a list")
+	default:
+		if err := flag.Value.Set(fmt.Sprint(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isKnownFlag checks if any command in the tree has a flag with this name
+func isKnownFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if isKnownFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// writeConfig writes a config file into dir and returns its path
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestConfig_FlagsOverrideFileOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "muaddib.yaml", `
+org: test-org
+rate-limit: 0.5
+skip-dev: true
+concurrency: 8
+format: json
+vuln-csv:
+  - ./test-iocs.csv
+  - https://example.com/test-iocs.json
+exclude-paths: [examples/**]
+`)
+
+	executeWithStubRun(t, "scan", "--config", path, "--concurrency", "2")
+
+	if org != "test-org" || rateLimit != 0.5 || !skipDev || format != "json" {
+		t.Errorf("expected values from the config file, got org=%q rate-limit=%v skip-dev=%v format=%q", org, rateLimit, skipDev, format)
+	}
+	if concurrency != 2 {
+		t.Errorf("expected --concurrency to override the config file, got %d", concurrency)
+	}
+	if !slices.Equal(vulnCSVs, []string{"./test-iocs.csv", "https://example.com/test-iocs.json"}) {
+		t.Errorf("expected the listed IOC sources, got %v", vulnCSVs)
+	}
+	if !slices.Equal(excludePaths, []string{"examples/**"}) {
+		t.Errorf("expected the listed exclude paths, got %v", excludePaths)
+	}
+	if skipOptional {
+		t.Error("expected flags missing from the config file to keep their defaults")
+	}
+}
+
+func TestConfig_DefaultLookup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+
+	writeConfig(t, home, ".config/muaddib/config.yaml", "org: test-home-org\n")
+	executeWithStubRun(t, "scan")
+	if org != "test-home-org" {
+		t.Errorf("expected the home config to be read, got org=%q", org)
+	}
+
+	writeConfig(t, ".", "muaddib.yaml", "org: test-local-org\n")
+	executeWithStubRun(t, "scan")
+	if org != "test-local-org" {
+		t.Errorf("expected ./muaddib.yaml to take precedence, got org=%q", org)
+	}
+}
+
+func TestConfig_IgnoresFlagsOfOtherCommands(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "muaddib.yaml", "org: test-org\nskip-dev: true\n")
+
+	if ran := executeWithStubRun(t, "check-lockfile", "--config", path); ran != "check-lockfile" {
+		t.Errorf("expected check-lockfile to run, got %q", ran)
+	}
+	if !skipDev {
+		t.Error("expected skip-dev to be read for check-lockfile")
+	}
+}
+
+func TestConfig_RejectsUnknownKeysAndBadValues(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "organisation: test-org\n", `unknown key "organisation"`},
+		{"list for single value", "org: [test-org, other-org]\n", "expected a single value"},
+		{"invalid number", "concurrency: lots\n", `invalid value for "concurrency"`},
+		{"mapping", "rate-limit: {value: 1}\n", "expected a value or a list"},
+		{"invalid YAML", "org: [test-org\n", "failed to parse config file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), "muaddib.yaml", tc.content)
+
+			rootCmd := newRootCmd()
+			rootCmd.RunE = func(*cobra.Command, []string) error { return nil }
+			rootCmd.SetArgs([]string{"--config", path})
+			rootCmd.SilenceUsage, rootCmd.SilenceErrors = true, true
+			err := rootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+			if tc.name == "unknown key" && !strings.Contains(err.Error(), "command-line flags override the config file") {
+				t.Errorf("expected the precedence to be explained, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConfig_CommandLineTargetReplacesConfiguredTarget(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "muaddib.yaml", "org: test-org\nskip-dev: true\n")
+
+	executeWithStubRun(t, "scan", "--config", path, "--repo", "test-org/test-repo")
+
+	if org != "" || repoName != "test-org/test-repo" {
+		t.Errorf("expected --repo to replace the configured org, got org=%q repo=%q", org, repoName)
+	}
+	if !skipDev {
+		t.Error("expected other configured flags to still apply")
+	}
+	if err := validateTargetFlags(); err != nil {
+		t.Errorf("expected a single target, got %v", err)
+	}
+}
+
+func TestConfig_ValuesAreNotTreatedAsCommandLineFlags(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "muaddib.yaml", "fail-on: vuln\n")
+
+	rootCmd := newRootCmd()
+	var watchErr error
+	rootCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		watchErr = validateWatchFlags(cmd.Flags())
+		return nil
+	}
+	rootCmd.SetArgs([]string{"--config", path, "--path", ".", "--watch", "5m"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if watchErr != nil {
+		t.Errorf("expected a configured --fail-on to be allowed with --watch, got %v", watchErr)
+	}
+	if failOn != "vuln" {
+		t.Errorf("expected the configured --fail-on to be set, got %q", failOn)
+	}
+}
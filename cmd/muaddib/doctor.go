@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// doctorCheck is a single preflight check. run describes the outcome, or
+// returns an error if the check failed.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// doctorGitHub is the part of the GitHub client the doctor checks use
+type doctorGitHub interface {
+	AuthenticatedUser(ctx context.Context) (string, error)
+	RateLimit(ctx context.Context) (*github.RateLimitStatus, error)
+}
+
+// newDoctorCmd creates the doctor subcommand
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the GitHub token, rate limit, and IOC sources before a scan",
+		Long: `Doctor runs the preflight checks for a scan: that a GitHub token is set and
+valid, how much of the API rate limit is left, and that each IOC source can
+be loaded. Each check is listed as passed or failed, and any failure exits
+with status 1.
+
+Example:
+  muaddib doctor
+  muaddib doctor --token-helper --source-manifest https://example.com/iocs.yaml`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&tokenHelper, "token-helper", "", "Read the GitHub token from this command's output, or from \"gh auth token\" if given without a value; falls back to GITHUB_TOKEN")
+	flags.Lookup("token-helper").NoOptDefVal = github.GHTokenHelper
+	flags.StringVar(&tokenFile, "token-file", "", "Read the GitHub token from this file, such as a mounted secret; takes precedence over GITHUB_TOKEN_FILE and GITHUB_TOKEN")
+	flags.StringArrayVar(&vulnCSVs, "vuln-csv", nil, "Path or URL to a vulnerability CSV or OSV JSON file, or - to read it from stdin; replaces the DataDog + Wiz IOC lists (repeatable, sources are merged)")
+	flags.BoolVar(&includeDefaults, "include-defaults", false, "Merge the --vuln-csv sources with the DataDog + Wiz IOC lists instead of replacing them")
+	flags.StringVar(&manifest, "source-manifest", "", "URL of a JSON/YAML manifest listing IOC feed URLs to load and merge")
+	flags.StringArrayVar(&iocChecksums, "ioc-checksum", nil, "Pin the SHA-256 of an IOC source as url=sha256; a mismatch fails the load (repeatable)")
+	addIOCDownloadFlags(flags)
+	addConfigFlag(flags)
+	flags.BoolVar(&noColor, "no-color", false, "Disable colored output; also off when NO_COLOR is set or output is not a terminal")
+
+	return cmd
+}
+
+// runDoctor runs the preflight checks
+func runDoctor(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	rep := reporter.NewTerminalReporter(reporter.WithOutput(out), colorOption(out))
+
+	if err := validateIOCSourceFlags(); err != nil {
+		return err
+	}
+	if err := validateIOCDownloadFlags(); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	client, err := createGitHubClient(ctx, rep)
+	var checks []doctorCheck
+	if err != nil {
+		checks = append(checks, failedCheck("GitHub token", err))
+	} else {
+		checks = append(checks, gitHubChecks(client)...)
+	}
+	checks = append(checks, iocSourceChecks(loadIOCSource)...)
+
+	return runDoctorChecks(ctx, checks, rep)
+}
+
+// runDoctorChecks runs each check in turn, listing it as passed or failed
+func runDoctorChecks(ctx context.Context, checks []doctorCheck, rep reporter.Reporter) error {
+	failed := 0
+	for _, check := range checks {
+		detail, err := check.run(ctx)
+		if err != nil {
+			rep.ReportError("%s: %v", check.name, err)
+			failed++
+			continue
+		}
+		rep.ReportSuccess("%s: %s", check.name, detail)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	rep.ReportInfo("All %d checks passed", len(checks))
+	return nil
+}
+
+// failedCheck is a check that reports err without running
+func failedCheck(name string, err error) doctorCheck {
+	return doctorCheck{name: name, run: func(context.Context) (string, error) { return "", err }}
+}
+
+// gitHubChecks checks that the token is valid and has rate limit left
+func gitHubChecks(client doctorGitHub) []doctorCheck {
+	return []doctorCheck{
+		{name: "GitHub token", run: func(ctx context.Context) (string, error) {
+			login, err := client.AuthenticatedUser(ctx)
+			if err != nil {
+				return "", err
+			}
+			return "authenticated as " + login, nil
+		}},
+		{name: "GitHub rate limit", run: func(ctx context.Context) (string, error) {
+			status, err := client.RateLimit(ctx)
+			if err != nil {
+				return "", err
+			}
+			reset := status.Reset.Local().Format(time.Kitchen)
+			if status.Remaining == 0 {
+				return "", fmt.Errorf("exhausted (%d requests) until %s", status.Limit, reset)
+			}
+			return fmt.Sprintf("%d of %d requests remaining, resets at %s", status.Remaining, status.Limit, reset), nil
+		}},
+	}
+}
+
+// iocSourceChecks checks that each configured IOC source loads, using load
+func iocSourceChecks(load func(source string) (*vuln.VulnDB, error)) []doctorCheck {
+	sources, err := doctorIOCSources()
+	if err != nil {
+		return []doctorCheck{failedCheck("IOC source manifest", err)}
+	}
+
+	checks := make([]doctorCheck, 0, len(sources))
+	for _, source := range sources {
+		checks = append(checks, doctorCheck{name: "IOC source " + source, run: func(context.Context) (string, error) {
+			db, err := load(source)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d entries", db.Stats().TotalEntries), nil
+		}})
+	}
+	return checks
+}
+
+// doctorIOCSources lists the IOC sources a scan with the same flags would load
+func doctorIOCSources() ([]string, error) {
+	switch {
+	case len(vulnCSVs) > 0 && includeDefaults:
+		return append(slices.Clone(vulnCSVs), vuln.DefaultIOCURLs()...), nil
+	case len(vulnCSVs) > 0:
+		return vulnCSVs, nil
+	case manifest != "":
+		urls, _, err := vuln.FetchSourceManifest(manifest, iocLoadOptions()...)
+		return urls, err
+	default:
+		return vuln.DefaultIOCURLs(), nil
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+)
+
+// minRequestsPerRepo is the fewest API requests a scan makes for a repository:
+// a tree listing shared by every file check, and a page of branches. Each
+// package file and workflow found costs one more.
+const minRequestsPerRepo = 2
+
+// runDryRun lists the repositories a scan would cover after the --include-repos,
+// --exclude-repos, --since, fork, and archived filters, with the ref each would
+// be scanned at or the reason it would be skipped. Only the listing requests
+// are made; no files are fetched and no IOC feeds are loaded.
+func runDryRun(ctx context.Context, rep reporter.Reporter) error {
+	ghClient, err := createGitHubClient(ctx, rep)
+	if err != nil {
+		return err
+	}
+	pushedAfter, err := sinceTime(time.Now())
+	if err != nil {
+		return err
+	}
+
+	p := &repoPipeline{
+		rep:       rep,
+		since:     pushedAfter,
+		skipForks: !includeForks && repoName == "",
+		include:   includeRepos,
+		exclude:   excludeRepos,
+	}
+	rep.ReportInfo("🧪 Dry run: listing repositories without scanning them")
+	pages, listErr := streamRepositories(ctx, ghClient, rep)
+	selected := 0
+	for page := range pages {
+		selected += p.listDryRun(page)
+	}
+	if err := listingError(ctx, <-listErr); err != nil {
+		return err
+	}
+
+	if p.listed == 0 {
+		rep.ReportInfo("No repositories found")
+		return nil
+	}
+	listing := ghClient.GetRequestsMade()
+	rep.ReportSuccess("Would scan %d of %d repositories", selected, p.listed)
+	rep.ReportInfo("📊 Estimated API requests: at least %d (%d made listing repositories, then %d per repository plus one per package file and workflow found)",
+		listing+selected*minRequestsPerRepo, listing, minRequestsPerRepo)
+	return nil
+}
+
+// listDryRun reports whether each repository of a page would be scanned, and
+// returns how many would be
+func (p *repoPipeline) listDryRun(page []*github.Repository) int {
+	selected := 0
+	for _, repo := range page {
+		p.listed++
+		if reason := p.skipReason(repo); reason != "" {
+			p.rep.ReportInfo("   ⏭️  %s: %s", repo.FullName, reason)
+			continue
+		}
+		repo.Ref = scanRef
+		p.rep.ReportInfo("   ✓ %s (%s)", repo.FullName, repo.ScanRef())
+		selected++
+	}
+	return selected
+}
+
+// skipReason returns why a scan would skip the repository, or "" if it
+// would be scanned. Whether a --ref exists in each repository is only known
+// once it is scanned.
+func (p *repoPipeline) skipReason(repo *github.Repository) string {
+	switch {
+	case p.excluded(repo):
+		return "excluded by --include-repos or --exclude-repos"
+	case p.notPushedSince(repo):
+		return "not pushed to since " + p.since.Format(time.DateOnly)
+	case repo.Archived:
+		return "archived"
+	case repo.Fork && p.skipForks:
+		return "fork (use --include-forks to scan it)"
+	default:
+		return repo.NotScannableReason()
+	}
+}
+
+// validateDryRunFlags checks that --dry-run is only used with GitHub targets,
+// as a local directory is scanned without listing anything
+func validateDryRunFlags() error {
+	if !dryRun {
+		return nil
+	}
+	if localPath != "" || manifestsDir != "" {
+		return fmt.Errorf("--dry-run lists GitHub repositories and cannot be used with --path or --manifests-dir")
+	}
+	if watchInterval > 0 {
+		return fmt.Errorf("--dry-run and --watch are mutually exclusive")
+	}
+	return nil
+}
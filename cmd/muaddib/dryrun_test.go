@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+)
+
+func TestListDryRun_ReportsSkipReasons(t *testing.T) {
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	repos := []*github.Repository{
+		{FullName: "test-org/web", Name: "web", DefaultBranch: "main", PushedAt: since.AddDate(0, 0, 1)},
+		{FullName: "test-org/web-archive", Name: "web-archive", DefaultBranch: "main"},
+		{FullName: "test-org/stale", Name: "stale", DefaultBranch: "main", PushedAt: since.AddDate(0, 0, -1)},
+		{FullName: "test-org/old", Name: "old", DefaultBranch: "master", Archived: true},
+		{FullName: "test-org/fork", Name: "fork", DefaultBranch: "main", Fork: true},
+		{FullName: "test-org/empty", Name: "empty"},
+		{FullName: "test-org/api", Name: "api", DefaultBranch: "develop"},
+	}
+
+	var out bytes.Buffer
+	p := &repoPipeline{
+		rep:       reporter.NewTerminalReporter(reporter.WithOutput(&out), reporter.WithColor(false)),
+		since:     since,
+		skipForks: true,
+		exclude:   []string{"*-archive"},
+	}
+	if selected := p.listDryRun(repos); selected != 2 || p.listed != len(repos) {
+		t.Fatalf("expected 2 of %d repositories selected, got %d of %d", len(repos), selected, p.listed)
+	}
+
+	for _, want := range []string{
+		"✓ test-org/web (main)",
+		"test-org/web-archive: excluded by --include-repos or --exclude-repos",
+		"test-org/stale: not pushed to since 2025-09-01",
+		"test-org/old: archived",
+		"test-org/fork: fork (use --include-forks to scan it)",
+		"test-org/empty: " + github.ReasonNoDefaultBranch,
+		"✓ test-org/api (develop)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the listing, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestValidateDryRunFlags(t *testing.T) {
+	executeWithStubRun(t, "--org", "test-org", "--dry-run")
+	if err := validateDryRunFlags(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	executeWithStubRun(t, "--path", ".", "--dry-run")
+	if err := validateDryRunFlags(); err == nil || !strings.Contains(err.Error(), "--path") {
+		t.Errorf("expected --dry-run with --path to be rejected, got %v", err)
+	}
+
+	executeWithStubRun(t, "--org", "test-org", "--dry-run", "--watch", "5m")
+	if err := validateDryRunFlags(); err == nil {
+		t.Error("expected --dry-run with --watch to be rejected")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// scanLocalPath scans the package files in the --path directory as a single
+// repository named after the directory
+func scanLocalPath(db *vuln.VulnDB, baseline *scanner.Baseline, rep reporter.Reporter) ([]*scanner.RepoScanResult, error) {
+	rep.ReportInfo("📂 Scanning local directory: %s", localPath)
+	files, err := github.FindLocalPackageFiles(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --path: %w", err)
+	}
+
+	result := newScanner(db).ScanFiles(files)
+	result.RepoName = localRepoName()
+	if verbose && result.FilesExcluded > 0 {
+		rep.ReportProgress(fmt.Sprintf("   ⏭️  Excluded %d package file(s) by --exclude-paths", result.FilesExcluded))
+	}
+	baseline.Apply(result, includeBaseline)
+
+	rep.ReportRepoStart(result.RepoName)
+	rep.ReportRepoResult(result)
+	return []*scanner.RepoScanResult{result}, nil
+}
+
+// scanManifestsDir scans each package file in the --manifests-dir directory
+// as an independent project named after its path in the directory, so
+// findings are attributed to the file they were found in
+func scanManifestsDir(db *vuln.VulnDB, baseline *scanner.Baseline, rep reporter.Reporter) ([]*scanner.RepoScanResult, error) {
+	rep.ReportInfo("📂 Scanning manifests in: %s", manifestsDir)
+	files, err := github.FindLocalPackageFiles(manifestsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --manifests-dir: %w", err)
+	}
+	rep.ReportInfo("📄 Found %d package files", len(files))
+
+	scan := newScanner(db)
+	var results []*scanner.RepoScanResult
+	for _, file := range files {
+		file.RepoName = file.Path
+		result := scan.ScanFiles([]*github.PackageFile{file})
+		if result.FilesExcluded > 0 {
+			if verbose {
+				rep.ReportProgress(fmt.Sprintf("   ⏭️  Excluded %s by --exclude-paths", file.Path))
+			}
+			continue
+		}
+		baseline.Apply(result, includeBaseline)
+
+		rep.ReportRepoStart(result.RepoName)
+		rep.ReportRepoResult(result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// localRepoName names the local scan after the --path directory, as
+// FindLocalPackageFiles does for each file
+func localRepoName() string {
+	if abs, err := filepath.Abs(localPath); err == nil {
+		return filepath.Base(abs)
+	}
+	return localPath
+}
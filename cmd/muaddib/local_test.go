@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/reporter"
+)
+
+// vulnerablePackageJSON declares the package the test IOC lists flag
+const vulnerablePackageJSON = `{"dependencies": {"test-muaddib-vulnerable": "1.0.0"}}`
+
+// writeTestFiles writes each file, keyed by its slash-separated path, under dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestScanPath_ScansLocalDirectoryWithoutToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"iocs.csv":                            "package_name,package_versions,sources\ntest-muaddib-vulnerable,1.0.0,\"test\"\ntest-muaddib-installed,2.0.0,\"test\"\n",
+		"test-monorepo/apps/web/package.json": vulnerablePackageJSON,
+		"test-monorepo/node_modules/test-muaddib-installed/package.json":  `{"dependencies": {"test-muaddib-installed": "2.0.0"}}`,
+		"test-monorepo/apps/web/node_modules/test-muaddib-x/package.json": `{"dependencies": {"test-muaddib-installed": "2.0.0"}}`,
+	})
+	csvPath := filepath.Join(dir, "iocs.csv")
+	root := filepath.Join(dir, "test-monorepo")
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"scan", "--path", root, "--vuln-csv", csvPath})
+
+	// The vulnerable package fails the scan under the default --fail-on any
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected scan --path to fail on its finding, got %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "test-monorepo") || !strings.Contains(output, "test-muaddib-vulnerable@1.0.0") {
+		t.Errorf("expected the vulnerable package in test-monorepo, got:\n%s", output)
+	}
+	if strings.Contains(output, "test-muaddib-installed") {
+		t.Errorf("expected node_modules to be skipped, got:\n%s", output)
+	}
+}
+
+func TestScanPath_ReadsIOCsFromStdin(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	previous := iocStdin
+	t.Cleanup(func() { iocStdin = previous })
+	iocStdin = strings.NewReader("package_name,package_versions,sources\ntest-muaddib-vulnerable,1.0.0,\"test\"\n")
+
+	root := filepath.Join(t.TempDir(), "test-project")
+	writeTestFiles(t, root, map[string]string{"package.json": vulnerablePackageJSON})
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"scan", "--path", root, "--vuln-csv", "-"})
+
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected the IOC read from stdin to fail the scan, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Using custom source: stdin (1 entries)") || !strings.Contains(out.String(), "test-muaddib-vulnerable@1.0.0") {
+		t.Errorf("expected the stdin IOC list to be used, got:\n%s", out.String())
+	}
+}
+
+func TestScanManifestsDir_ReportsEachFileAsAProject(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"iocs.csv":                           "package_name,package_versions,sources\ntest-muaddib-vulnerable,1.0.0,\"test\"\ntest-muaddib-other,2.0.0,\"test\"\n",
+		"exported/billing/package-lock.json": `{"lockfileVersion": 3, "packages": {"node_modules/test-muaddib-vulnerable": {"version": "1.0.0"}}}`,
+		"exported/checkout/yarn.lock":        "test-muaddib-other@^2.0.0:\n  version \"2.0.0\"\n",
+		"exported/search/package-lock.json":  `{"lockfileVersion": 3, "packages": {"node_modules/test-muaddib-safe": {"version": "1.0.0"}}}`,
+	})
+	csvPath := filepath.Join(dir, "iocs.csv")
+	root := filepath.Join(dir, "exported")
+
+	reportPath := filepath.Join(dir, "report.json")
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"scan", "--manifests-dir", root, "--vuln-csv", csvPath, "--output", reportPath})
+
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected scan --manifests-dir to fail on its findings, got %v", err)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	var report reporter.JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+
+	if len(report.Repositories) != 3 {
+		t.Errorf("expected a result for each of the 3 files, got %d", len(report.Repositories))
+	}
+	found := make(map[string]string)
+	for _, f := range report.Findings {
+		found[f.Repository] = f.PackageName
+	}
+	expected := map[string]string{
+		"billing/package-lock.json": "test-muaddib-vulnerable",
+		"checkout/yarn.lock":        "test-muaddib-other",
+	}
+	if len(found) != len(expected) {
+		t.Errorf("expected findings in %v, got %v", expected, found)
+	}
+	for file, pkg := range expected {
+		if found[file] != pkg {
+			t.Errorf("expected %s attributed to %s, got %v", pkg, file, found)
+		}
+	}
+}
+
+func TestScanPath_WritesFormattedReportToOutput(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"iocs.csv":               "package_name,package_versions\ntest-muaddib-vulnerable,1.0.0\n",
+		"test-repo/package.json": vulnerablePackageJSON,
+	})
+	csvPath := filepath.Join(dir, "iocs.csv")
+	root := filepath.Join(dir, "test-repo")
+
+	reportPath := filepath.Join(dir, "reports", "nightly", "findings.csv")
+
+	var out, errOut bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	rootCmd.SetArgs([]string{"scan", "--path", root, "--vuln-csv", csvPath, "--format", "csv", "--output", reportPath})
+
+	if err := rootCmd.Execute(); exitCode(err) != exitFindings {
+		t.Fatalf("expected the scan to fail on its finding, got %v", err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected nothing on stdout when --output is set, got:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "Report written to "+reportPath) {
+		t.Errorf("expected progress on stderr, got:\n%s", errOut.String())
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "owner,repo,file") || !strings.Contains(lines[1], "test-muaddib-vulnerable") {
+		t.Errorf("expected only the CSV header and finding in the report, got:\n%s", data)
+	}
+}
+
+func TestScanPath_WritesSummaryJSON(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"iocs.csv":               "package_name,package_versions\ntest-muaddib-vulnerable,1.0.0\n",
+		"test-repo/package.json": vulnerablePackageJSON,
+	})
+	csvPath := filepath.Join(dir, "iocs.csv")
+	root := filepath.Join(dir, "test-repo")
+
+	summaryPath := filepath.Join(dir, "nightly", "summary.json")
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"scan", "--path", root, "--vuln-csv", csvPath, "--summary-json", summaryPath, "--fail-on", "none"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	var summary struct {
+		Version string `json:"version"`
+		Summary struct {
+			VulnerablePackages int `json:"vulnerable_packages"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if summary.Version != toolVersion() || summary.Summary.VulnerablePackages != 1 {
+		t.Errorf("expected the version and one vulnerable package, got:\n%s", data)
+	}
+}
+
+func TestValidateTargetFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"path alone", []string{"--path", "."}, ""},
+		{"no target", nil, "must be specified"},
+		{"path and org", []string{"--path", ".", "--org", "test-org"}, "mutually exclusive"},
+		{"path and branch inspection", []string{"--path", ".", "--inspect-malicious-branches"}, "cannot be used with --path"},
+		{"repo alone", []string{"--repo", "test-org/test-repo"}, ""},
+		{"repo and user", []string{"--repo", "test-org/test-repo", "--user", "test-user"}, "mutually exclusive"},
+		{"repo without owner", []string{"--repo", "test-repo"}, "--repo"},
+		{"org at a ref", []string{"--org", "test-org", "--ref", "release/1.x"}, ""},
+		{"path at a ref", []string{"--path", ".", "--ref", "v1.2.0"}, "--ref needs GitHub"},
+		{"org with blob cache", []string{"--org", "test-org", "--cache-blobs=test-muaddib-blobs"}, ""},
+		{"path with blob cache", []string{"--path", ".", "--cache-blobs"}, "--cache-blobs needs GitHub"},
+		{"manifests dir alone", []string{"--manifests-dir", "."}, ""},
+		{"manifests dir and path", []string{"--manifests-dir", ".", "--path", "."}, "mutually exclusive"},
+		{"manifests dir and scheduled workflows", []string{"--manifests-dir", ".", "--check-scheduled-workflows"}, "cannot be used with --path or --manifests-dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executeWithStubRun(t, tt.args...)
+			err := validateTargetFlags()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
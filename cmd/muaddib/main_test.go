@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// executeWithStubRun runs the CLI with the given args, replacing every command's
+// RunE with a stub so no scan is performed. It returns the name of the command
+// that would have run.
+func executeWithStubRun(t *testing.T, args ...string) string {
+	t.Helper()
+
+	rootCmd := newRootCmd()
+	var ran string
+	stub := func(cmd *cobra.Command, args []string) error {
+		ran = cmd.Name()
+		return nil
+	}
+	rootCmd.RunE = stub
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.RunE != nil {
+			cmd.RunE = stub
+		}
+	}
+
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("execute %v: %v", args, err)
+	}
+	return ran
+}
+
+func TestRootCommand_ScansByDefault(t *testing.T) {
+	ran := executeWithStubRun(t, "--org", "test-org", "--skip-dev")
+
+	if ran != "muaddib" {
+		t.Errorf("expected root command to run, got %q", ran)
+	}
+	if org != "test-org" || !skipDev {
+		t.Errorf("expected flags to be parsed, got org=%q skipDev=%v", org, skipDev)
+	}
+}
+
+func TestScanSubcommand_EquivalentToRoot(t *testing.T) {
+	ran := executeWithStubRun(t, "scan", "--org", "test-org", "--skip-dev")
+
+	if ran != "scan" {
+		t.Errorf("expected scan command to run, got %q", ran)
+	}
+	if org != "test-org" || !skipDev {
+		t.Errorf("expected flags to be parsed, got org=%q skipDev=%v", org, skipDev)
+	}
+}
+
+func TestScanFlags_ResetBetweenCommands(t *testing.T) {
+	executeWithStubRun(t, "scan", "--org", "test-org")
+	executeWithStubRun(t, "--user", "test-user")
+
+	if org != "" || user != "test-user" {
+		t.Errorf("expected flags to reset to defaults, got org=%q user=%q", org, user)
+	}
+}
+
+func TestRootAndScan_ShareFlags(t *testing.T) {
+	rootCmd := newRootCmd()
+	scanCmd, _, err := rootCmd.Find([]string{"scan"})
+	if err != nil {
+		t.Fatalf("scan subcommand not found: %v", err)
+	}
+
+	rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if scanCmd.Flags().Lookup(f.Name) == nil {
+			t.Errorf("flag --%s is missing from scan", f.Name)
+		}
+	})
+}
+
+func TestNoColor_AppliesToEverySubcommand(t *testing.T) {
+	for _, args := range [][]string{{"--no-color", "--org", "test-org"}, {"check-lockfile", "--no-color"}, {"doctor", "--no-color"}} {
+		executeWithStubRun(t, args...)
+		if !noColor {
+			t.Errorf("expected --no-color to be parsed for %v", args)
+		}
+	}
+
+	executeWithStubRun(t, "doctor")
+	if noColor {
+		t.Error("expected color to be left on by default")
+	}
+}
+
+func TestCheckFailPolicy_Threshold(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			MaliciousBranches: []*scanner.MaliciousBranch{
+				{RepoName: "test-org/a", BranchName: "shai-hulud"},
+			},
+			MaliciousScripts: []*scanner.MaliciousScript{
+				{RepoName: "test-org/a", FilePath: "package.json", ScriptName: "postinstall", Pattern: "node bundle.js"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		threshold int
+		wantCode  int
+	}{
+		{"below threshold", 3, 0},
+		{"at threshold", 2, 0},
+		{"above threshold", 1, exitFindings},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			executeWithStubRun(t, "--org", "test-org", "--fail-on", "any", "--fail-threshold", strconv.Itoa(tc.threshold))
+
+			err := checkFailPolicy(&cobra.Command{}, results, nil)
+
+			if got := exitCode(err); got != tc.wantCode {
+				t.Errorf("expected exit code %d, got %d (err: %v)", tc.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestCheckFailPolicy_CategorySelection(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName:          "test-org/a",
+			MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org/a", BranchName: "shai-hulud"}},
+		},
+	}
+
+	executeWithStubRun(t, "--org", "test-org", "--fail-on", "vuln")
+	if err := checkFailPolicy(&cobra.Command{}, results, nil); err != nil {
+		t.Errorf("expected --fail-on vuln to ignore malicious branches, got %v", err)
+	}
+
+	executeWithStubRun(t, "--org", "test-org", "--fail-on", "malicious")
+	if err := checkFailPolicy(&cobra.Command{}, results, nil); exitCode(err) != exitFindings {
+		t.Errorf("expected --fail-on malicious to fail, got %v", err)
+	}
+}
+
+func TestCheckFailPolicy_DefaultsToAny(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName:          "test-org/a",
+			MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org/a", BranchName: "shai-hulud"}},
+		},
+	}
+
+	executeWithStubRun(t, "--org", "test-org")
+	if err := checkFailPolicy(&cobra.Command{}, results, nil); exitCode(err) != exitFindings {
+		t.Errorf("expected findings to fail the scan by default, got %v", err)
+	}
+
+	executeWithStubRun(t, "--org", "test-org", "--fail-on", "none")
+	if err := checkFailPolicy(&cobra.Command{}, results, nil); err != nil {
+		t.Errorf("expected --fail-on none to pass, got %v", err)
+	}
+}
+
+func TestExitCode_DistinguishesErrorsFromFindings(t *testing.T) {
+	if exitCode(nil) != 0 {
+		t.Error("expected success to exit 0")
+	}
+	if exitCode(errors.New("boom")) != exitError {
+		t.Error("expected operational errors to exit 1")
+	}
+	if exitCode(fmt.Errorf("wrapped: %w", &findingsError{count: 1})) != exitFindings {
+		t.Error("expected findings to exit 2")
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// newTestPipeline creates a pipeline for repositories that need no API calls
+func newTestPipeline(concurrency, listed int) *repoPipeline {
+	return &repoPipeline{
+		scan:        scanner.NewScanner(nil, true),
+		results:     scanner.NewResults(),
+		rep:         reporter.NewTerminalReporter(reporter.WithOutput(io.Discard)),
+		concurrency: concurrency,
+		listed:      listed,
+	}
+}
+
+// unscannableRepos returns repositories without a default branch, which are
+// recorded as not scannable without calling the API
+func unscannableRepos(n int) []*github.Repository {
+	repos := make([]*github.Repository, n)
+	for i := range repos {
+		repos[i] = &github.Repository{FullName: fmt.Sprintf("test-org/repo-%03d", n-i), Owner: "test-org"}
+	}
+	return repos
+}
+
+// newStubGitHubClient creates a client that talks to a stub GitHub API served by handler
+func newStubGitHubClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := github.NewClient("test-token", github.WithRateLimit(1000), github.WithRetryDelay(time.Millisecond))
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse test server URL: %v", err)
+	}
+	c.Inner().BaseURL = baseURL
+	return c
+}
+
+func TestScanRepository_ListsTreeOnceAndOnlyFetchesNpmrc(t *testing.T) {
+	deepInspect, checkNpmrc, checkScheduled = true, true, true
+	t.Cleanup(func() { deepInspect, checkNpmrc, checkScheduled = false, false, false })
+
+	var treeRequests, npmrcRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		treeRequests.Add(1)
+		w.Write([]byte(`{"sha": "root", "tree": [
+			{"path": "package.json", "type": "blob", "sha": "sha-manifest", "size": 30},
+			{"path": ".npmrc", "type": "blob", "sha": "sha-npmrc", "size": 40},
+			{"path": "deploy/id_rsa", "type": "blob", "sha": "sha-key", "size": 400},
+			{"path": ".github/workflows/ci.yml", "type": "blob", "sha": "sha-ci", "size": 10}
+		]}`))
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type": "file", "encoding": "base64", "content": "e30="}`)
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/git/blobs/sha-key", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the private key not to be downloaded")
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/git/blobs/sha-npmrc", func(w http.ResponseWriter, r *http.Request) {
+		npmrcRequests.Add(1)
+		w.Write([]byte("registry=https://evil.example.com/\n"))
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/branches", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	ghClient := newStubGitHubClient(t, mux)
+	repo := &github.Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+	rep := reporter.NewTerminalReporter(reporter.WithOutput(io.Discard))
+
+	result := scanRepository(t.Context(), repo, ghClient, scanner.NewScanner(nil, true), rep)
+
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if len(result.SuspiciousConfigs) != 1 {
+		t.Errorf("expected the .npmrc to be checked, got %d suspicious configs", len(result.SuspiciousConfigs))
+	}
+	if len(result.Advisories) != 1 || result.Advisories[0].FilePath != "deploy/id_rsa" {
+		t.Errorf("expected the private key to be flagged by its presence, got %+v", result.Advisories)
+	}
+	if n := treeRequests.Load(); n != 1 {
+		t.Errorf("expected the tree to be listed once, got %d requests", n)
+	}
+	if n := npmrcRequests.Load(); n != 1 {
+		t.Errorf("expected the .npmrc to be downloaded once for both checks, got %d requests", n)
+	}
+}
+
+func TestScanGitHub_KeepsResultsWhenListingFailsPartWay(t *testing.T) {
+	org = "test-org"
+	t.Cleanup(func() { org = "" })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/orgs/test-org/repos?page=2>; rel="next"`, r.Host))
+			// Repositories without a default branch are recorded without further requests
+			w.Write([]byte(`[{"name": "repo-1", "full_name": "test-org/repo-1", "owner": {"login": "test-org"}},
+				{"name": "repo-2", "full_name": "test-org/repo-2", "owner": {"login": "test-org"}}]`))
+		default:
+			http.Error(w, "listing unavailable", http.StatusNotFound)
+		}
+	})
+	ghClient := newStubGitHubClient(t, mux)
+	var out strings.Builder
+	rep := reporter.NewTerminalReporter(reporter.WithOutput(&out), reporter.WithColor(false))
+
+	repoResults, orgResult, err := scanGitHub(t.Context(), ghClient, vuln.NewVulnDB(), nil, rep)
+
+	if err == nil || !strings.Contains(err.Error(), "failed to list repositories") {
+		t.Errorf("expected the listing error to be returned, got %v", err)
+	}
+	if orgResult == nil || len(repoResults) != 2 {
+		t.Fatalf("expected the results of the first page to be kept, got %d results", len(repoResults))
+	}
+	if !strings.Contains(out.String(), "Listing stopped after 2 repositories") {
+		t.Errorf("expected a warning that the results are incomplete, got:\n%s", out.String())
+	}
+
+	// The summary is still reported, and the scan exits with the error status
+	var stderr strings.Builder
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(&stderr)
+	err = reportResults(t.Context(), cmd, repoResults, orgResult, vuln.NewVulnDB(), nil, err, rep)
+	if exitCode(err) != exitError {
+		t.Errorf("expected exit status %d, got %v", exitError, err)
+	}
+	if !strings.Contains(stderr.String(), "repos=2") || !strings.Contains(stderr.String(), "exit=1") {
+		t.Errorf("expected the exit summary to cover the scanned repositories, got %q", stderr.String())
+	}
+}
+
+func TestRepoPipeline_ScansConcurrently(t *testing.T) {
+	const n = 50
+	p := newTestPipeline(8, n)
+	var out strings.Builder
+	p.rep = reporter.NewTerminalReporter(reporter.WithOutput(&out), reporter.WithColor(false))
+
+	if !p.scanRepositories(context.Background(), unscannableRepos(n)) {
+		t.Fatal("expected the page to be scanned without interruption")
+	}
+
+	repos, _ := p.results.Snapshot()
+	if len(repos) != n || p.scanned != n {
+		t.Fatalf("expected %d repositories scanned, got %d results after %d scans", n, len(repos), p.scanned)
+	}
+	for i, result := range repos {
+		if want := fmt.Sprintf("test-org/repo-%03d", i+1); result.RepoName != want {
+			t.Errorf("expected results sorted by name, got %s at position %d", result.RepoName, i)
+			break
+		}
+	}
+
+	// Concurrent workers still report their positions in order
+	var positions []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if _, rest, ok := strings.Cut(line, "🔍 ["); ok {
+			positions = append(positions, rest[:strings.Index(rest, "]")])
+		}
+	}
+	for i, position := range positions {
+		if want := fmt.Sprintf("%d/%d", i+1, n); position != want {
+			t.Fatalf("expected position %s at line %d, got %s", want, i, position)
+		}
+	}
+	if len(positions) != n {
+		t.Errorf("expected %d scanning lines, got %d", n, len(positions))
+	}
+}
+
+func TestRepoPipeline_StopsWhenCancelled(t *testing.T) {
+	p := newTestPipeline(4, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if p.scanRepositories(ctx, unscannableRepos(10)) {
+		t.Error("expected an interrupted scan to return false")
+	}
+	if p.scanned != 0 {
+		t.Errorf("expected no repositories to be scanned after cancellation, got %d", p.scanned)
+	}
+}
+
+func TestRepoPipeline_SkipsReposNotPushedSince(t *testing.T) {
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	repos := unscannableRepos(3)
+	repos[0].PushedAt = since.AddDate(0, 0, 1)
+	repos[1].PushedAt = since.AddDate(0, 0, -1)
+	// repos[2] has no push time and is kept
+
+	p := newTestPipeline(1, len(repos))
+	p.since = since
+	p.scanRepositories(context.Background(), p.pushedSince(repos))
+
+	results, _ := p.results.Snapshot()
+	if len(results) != 2 || p.stale != 1 {
+		t.Fatalf("expected 2 repositories scanned and 1 skipped, got %d scanned and %d skipped", len(results), p.stale)
+	}
+	for _, result := range results {
+		if result.RepoName == repos[1].FullName {
+			t.Errorf("expected %s to be skipped as stale", result.RepoName)
+		}
+	}
+}
+
+func TestRepoPipeline_SkipsForks(t *testing.T) {
+	for _, skipForks := range []bool{true, false} {
+		repos := unscannableRepos(2)
+		repos[0].Fork = true
+
+		p := newTestPipeline(1, len(repos))
+		p.skipForks = skipForks
+		p.scanRepositories(context.Background(), repos)
+
+		results, _ := p.results.Snapshot()
+		if len(results) != 2 {
+			t.Fatalf("skipForks %v: expected 2 results, got %d", skipForks, len(results))
+		}
+		for _, result := range results {
+			skipped := result.NotScannable == github.ReasonFork
+			if want := skipForks && result.RepoName == repos[0].FullName; skipped != want {
+				t.Errorf("skipForks %v: expected %s skipped as a fork to be %v, got %q",
+					skipForks, result.RepoName, want, result.NotScannable)
+			}
+		}
+	}
+}
+
+func TestRepoPipeline_CoverageCountsSkippedRepos(t *testing.T) {
+	repos := unscannableRepos(4)
+	repos[0].Archived = true
+	repos[1].Fork = true
+	repos[2].DefaultBranch = "main"
+	repos[2].Disabled = true
+
+	p := newTestPipeline(2, len(repos))
+	p.skipForks = true
+	p.scanRepositories(context.Background(), repos)
+
+	results, orgResult := p.results.Snapshot()
+	summary := reporter.NewJSONReport(results, orgResult, 10).Summary
+	if summary.RepositoriesScanned != 4 || summary.RepositoriesSkipped != 4 || summary.RepositoriesCovered != 0 {
+		t.Errorf("expected all 4 repositories counted as skipped, got %+v", summary)
+	}
+
+	reasons := make(map[string]string)
+	for _, result := range results {
+		reasons[result.RepoName] = result.NotScannable
+	}
+	want := map[string]string{
+		repos[0].FullName: github.ReasonArchived,
+		repos[1].FullName: github.ReasonFork,
+		repos[2].FullName: github.ReasonDisabled,
+		repos[3].FullName: github.ReasonNoDefaultBranch,
+	}
+	for name, reason := range want {
+		if reasons[name] != reason {
+			t.Errorf("expected %s skipped as %q, got %q", name, reason, reasons[name])
+		}
+	}
+}
+
+func TestRepoPipeline_FiltersRepos(t *testing.T) {
+	names := []string{"frontend-web", "frontend-archive", "backend-api"}
+	tests := []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{nil, nil, names},
+		{[]string{"frontend-*"}, nil, []string{"frontend-web", "frontend-archive"}},
+		{nil, []string{"*-archive"}, []string{"frontend-web", "backend-api"}},
+		{[]string{"frontend-*"}, []string{"*-archive"}, []string{"frontend-web"}},
+		{[]string{"test-org/backend-*"}, nil, []string{"backend-api"}},
+	}
+
+	for _, tt := range tests {
+		repos := make([]*github.Repository, len(names))
+		for i, name := range names {
+			repos[i] = &github.Repository{Name: name, FullName: "test-org/" + name, Owner: "test-org"}
+		}
+
+		p := newTestPipeline(1, len(repos))
+		p.include, p.exclude = tt.include, tt.exclude
+
+		var got []string
+		for _, repo := range p.selectedRepos(repos) {
+			got = append(got, repo.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("include %v exclude %v: expected %v, got %v", tt.include, tt.exclude, tt.want, got)
+		}
+		if p.filtered != len(names)-len(tt.want) {
+			t.Errorf("include %v exclude %v: expected %d filtered, got %d", tt.include, tt.exclude, len(names)-len(tt.want), p.filtered)
+		}
+	}
+}
+
+func TestValidateRepoPatterns(t *testing.T) {
+	t.Cleanup(func() { includeRepos, excludeRepos = nil, nil })
+
+	includeRepos, excludeRepos = []string{"frontend-*"}, []string{"*-archive"}
+	if err := validateRepoPatterns(); err != nil {
+		t.Errorf("expected valid patterns, got %v", err)
+	}
+
+	excludeRepos = []string{"[unclosed"}
+	if err := validateRepoPatterns(); err == nil || !strings.Contains(err.Error(), "--exclude-repos") {
+		t.Errorf("expected an invalid --exclude-repos pattern to fail, got %v", err)
+	}
+}
+
+func TestSinceTime(t *testing.T) {
+	t.Cleanup(func() { since = "" })
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"90d", now.AddDate(0, 0, -90), false},
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"2025-11-01", time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2025-11-01T08:00:00Z", time.Date(2025, 11, 1, 8, 0, 0, 0, time.UTC), false},
+		{"-5d", time.Time{}, true},
+		{"last week", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		since = tt.value
+		got, err := sinceTime(now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tt.value, tt.wantErr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.want, got)
+		}
+	}
+}
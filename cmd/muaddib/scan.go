@@ -0,0 +1,1577 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/history"
+	"github.com/rslater/muaddib/internal/proxy"
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+var (
+	org          string
+	user         string
+	repoName     string
+	scanRef      string
+	localPath    string
+	manifestsDir string
+	tokenHelper  string
+	tokenFile    string
+	vulnCSVs     []string
+	manifest     string
+	iocChecksums []string
+	iocCampaigns []string
+	campaigns    []string
+	rateLimit    float64
+	concurrency  int
+	skipDev      bool
+	skipOptional bool
+	excludePaths []string
+	testPaths    []string
+	verbose      bool
+	noColor      bool
+	noProgress   bool
+
+	scriptPatterns     []string
+	scriptPatternsFile string
+	listEmpty          bool
+
+	baselinePath    string
+	includeBaseline bool
+	comparePath     string
+	deepInspect     bool
+	detectTyposquat bool
+	dedupe          bool
+	inspectBranches bool
+	checkScheduled  bool
+	checkNpmrc      bool
+	npmrcHosts      []string
+	pushedBy        string
+	since           string
+	includeForks    bool
+	includeRepos    []string
+	excludeRepos    []string
+
+	format          string
+	outputPath      string
+	summaryJSONPath string
+	historyPath     string
+	compress        string
+	includeEvidence bool
+
+	reportSprawl    bool
+	sprawlThreshold int
+
+	fetchStrategy string
+	blobCacheMB   int
+	blobCacheDir  string
+	groupBy       string
+
+	includeDefaults bool
+	iocAfter        string
+	iocBefore       string
+	iocCacheDir     string
+	iocTimeout      time.Duration
+	iocRetries      int
+	proxyAddr       string
+
+	failOn        string
+	failThreshold int
+
+	watchInterval time.Duration
+	dryRun        bool
+)
+
+const scanLongHelp = `Muaddib scans GitHub organization or user repositories, a single repository,
+or a local directory for vulnerable npm packages.
+
+It fetches package.json and package-lock.json files from all repositories,
+extracts all dependencies (including transitive), and checks them against
+a vulnerability database (IOC list).
+
+Environment Variables:
+  GITHUB_TOKEN       Required unless --path, --manifests-dir, --token-helper, or --token-file is used. GitHub Personal Access Token for API access.
+  GITHUB_TOKEN_FILE  File to read the GitHub token from instead of GITHUB_TOKEN, such as a mounted secret.
+
+Example:
+  export GITHUB_TOKEN=ghp_xxxxxxxxxxxx
+  muaddib scan --org mycompany
+  muaddib scan --user johndoe --vuln-csv ./my-iocs.csv
+  muaddib scan --repo mycompany/webapp
+  muaddib scan --org mycompany --token-helper
+  muaddib scan --path ./my-monorepo
+  muaddib scan --manifests-dir ./exported-lockfiles`
+
+// newScanCmd creates the scan subcommand
+func newScanCmd() *cobra.Command {
+	scanCmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Scan GitHub repositories for compromised npm packages",
+		Long:  scanLongHelp,
+		RunE:  run,
+	}
+	addScanFlags(scanCmd.Flags())
+	return scanCmd
+}
+
+// addScanFlags registers the scan flags. They are shared by the scan
+// subcommand and the root command, which scans when no subcommand is given.
+func addScanFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&org, "org", "", "GitHub organization to scan")
+	flags.StringVar(&user, "user", "", "GitHub user to scan")
+	flags.StringVar(&repoName, "repo", "", "Single GitHub repository to scan, as owner/name")
+	flags.StringVar(&scanRef, "ref", "", "Branch, tag, or commit to scan in every repository instead of its default branch; repositories without it are skipped")
+	flags.StringVar(&localPath, "path", "", "Scan package files in a local directory instead of GitHub (no token required; node_modules is skipped)")
+	flags.StringVar(&manifestsDir, "manifests-dir", "", "Scan each package file in a local directory as its own project, for loose collections of exported manifests and lockfiles (no token required)")
+	flags.StringVar(&tokenHelper, "token-helper", "", "Read the GitHub token from this command's output, or from \"gh auth token\" if given without a value; falls back to GITHUB_TOKEN")
+	flags.Lookup("token-helper").NoOptDefVal = github.GHTokenHelper
+	flags.StringVar(&tokenFile, "token-file", "", "Read the GitHub token from this file, such as a mounted secret; takes precedence over GITHUB_TOKEN_FILE and GITHUB_TOKEN")
+	flags.StringArrayVar(&vulnCSVs, "vuln-csv", nil, "Path or URL to a vulnerability CSV or OSV JSON file, or - to read it from stdin; replaces the DataDog + Wiz IOC lists (repeatable, sources are merged)")
+	flags.BoolVar(&includeDefaults, "include-defaults", false, "Merge the --vuln-csv sources with the DataDog + Wiz IOC lists instead of replacing them")
+	flags.StringVar(&manifest, "source-manifest", "", "URL of a JSON/YAML manifest listing IOC feed URLs to load and merge")
+	flags.StringArrayVar(&iocChecksums, "ioc-checksum", nil, "Pin the SHA-256 of an IOC source as url=sha256; a mismatch fails the load (repeatable)")
+	flags.StringArrayVar(&iocCampaigns, "ioc-campaign", nil, "Label the indicators of an IOC source with a campaign as url=campaign; overrides the manifest and default labels (repeatable)")
+	addIOCDownloadFlags(flags)
+	flags.StringVar(&iocCacheDir, "ioc-cache-dir", vuln.DefaultCacheDir(), "Cache each IOC feed here and fall back to the cached copy when a feed cannot be fetched (\"\" disables)")
+	flags.Float64Var(&rateLimit, "rate-limit", 1.0, "API requests per second (lower is safer)")
+	flags.IntVar(&concurrency, "concurrency", 4, "Repositories to scan at once; API requests still share the --rate-limit")
+	flags.BoolVar(&skipDev, "skip-dev", false, "Skip devDependencies")
+	flags.BoolVar(&skipOptional, "skip-optional", false, "Skip optionalDependencies")
+	flags.StringArrayVar(&excludePaths, "exclude-paths", nil, "Skip package files whose path matches this glob; ** matches any number of directories (repeatable)")
+	flags.StringArrayVar(&testPaths, "test-paths", scanner.DefaultTestPaths, "Report vulnerable packages in files matching this glob with low confidence, so they do not fail --fail-on; replaces the defaults (repeatable, \"\" for none)")
+	flags.StringSliceVar(&scriptPatterns, "script-patterns", nil, "Extra comma-separated patterns to flag in lifecycle scripts, added to the built-in worm patterns")
+	flags.StringVar(&scriptPatternsFile, "script-patterns-file", "", "File of extra lifecycle script patterns, one per line (# comments)")
+	flags.BoolVar(&verbose, "verbose", false, "Enable verbose output")
+	flags.BoolVar(&noColor, "no-color", false, "Disable colored output; also off when NO_COLOR is set or output is not a terminal")
+	flags.BoolVar(&noProgress, "no-progress", false, "List each repository as it is scanned instead of showing a progress bar; the bar is also off with --verbose or when output is not a terminal")
+	flags.BoolVar(&listEmpty, "list-empty", false, "List repositories without package files in the summary, flagging JavaScript projects where discovery found nothing")
+	flags.StringVar(&iocAfter, "ioc-after", "", "Only use IOC entries added on or after this date (YYYY-MM-DD); undated entries are kept")
+	flags.StringVar(&iocBefore, "ioc-before", "", "Only use IOC entries added before this date (YYYY-MM-DD); undated entries are kept")
+	flags.StringSliceVar(&campaigns, "campaign", nil, "Only use IOC entries from sources labelled with one of these comma-separated campaigns (e.g. shai-hulud-2.0)")
+	flags.StringVar(&baselinePath, "baseline", "", "Prior JSON report; findings present in it are treated as known")
+	flags.BoolVar(&includeBaseline, "include-baseline", false, "Report and count known baseline findings like new ones")
+	flags.StringVar(&comparePath, "compare", "", "Prior JSON report; show findings that are new, resolved, or unchanged since it")
+	flags.BoolVar(&deepInspect, "deep-inspect", false, "Enable heuristic checks that report advisories for review")
+	flags.BoolVar(&detectTyposquat, "detect-typosquat", false, "Report dependencies named like popular packages, such as crossenv for cross-env, as possible typosquats")
+	flags.BoolVar(&dedupe, "dedupe", false, "Report each vulnerable package version once per repository, listing every file it was found in, instead of once per file")
+	flags.BoolVar(&inspectBranches, "inspect-malicious-branches", false, "Compare malicious branches with the default branch and scan the changed files (extra API calls)")
+	flags.StringVar(&pushedBy, "pushed-by", "", "Only report repositories whose malicious branches have commits by this GitHub login (requires --inspect-malicious-branches)")
+	flags.BoolVar(&includeForks, "include-forks", false, "Scan forked repositories too; forks are skipped by default unless named with --repo")
+	flags.StringSliceVar(&includeRepos, "include-repos", nil, "Only scan repositories whose name or owner/name matches one of these comma-separated globs (e.g. frontend-*)")
+	flags.StringSliceVar(&excludeRepos, "exclude-repos", nil, "Skip repositories whose name or owner/name matches one of these comma-separated globs (e.g. *-archive); takes precedence over --include-repos")
+	flags.StringVar(&since, "since", "", "Only scan repositories pushed to within this long (e.g. 90d, 720h) or since this date (YYYY-MM-DD or RFC 3339); repositories with no push time are scanned")
+	flags.BoolVar(&checkScheduled, "check-scheduled-workflows", false, "Fetch every workflow and flag cron-triggered ones carrying worm payloads or write-all permissions (extra API calls)")
+	flags.BoolVar(&checkNpmrc, "check-npmrc", false, "Fetch every .npmrc and flag registries on unknown hosts, credentials sent to them, and committed tokens (extra API calls)")
+	flags.StringSliceVar(&npmrcHosts, "npmrc-allowed-hosts", nil, "Comma-separated registry hosts, such as a private registry, that --check-npmrc allows alongside the npm, Yarn, and GitHub Packages registries; subdomains are allowed too")
+	flags.BoolVar(&reportSprawl, "report-version-sprawl", false, "Report packages resolving to many distinct versions in lockfiles (informational)")
+	flags.IntVar(&sprawlThreshold, "version-sprawl-threshold", 3, "Report packages with more than this many distinct versions")
+	flags.StringVar(&fetchStrategy, "fetch-strategy", string(github.FetchContents), "How to download package files found in the repo tree: contents (by path) or blobs (by SHA, no 1 MB limit)")
+	flags.IntVar(&blobCacheMB, "blob-cache-size", github.DefaultBlobCacheSize>>20, "Megabytes of fetched files to keep in memory by blob SHA, so identical files are downloaded once per run (0 disables)")
+	flags.StringVar(&blobCacheDir, "cache-blobs", "", "Also keep fetched files in the directory given as --cache-blobs=DIR, or under the user cache directory if given without a value, so later runs do not download them again")
+	flags.Lookup("cache-blobs").NoOptDefVal = github.DefaultBlobCacheDir()
+	flags.StringVar(&groupBy, "group-by", string(reporter.GroupByRepo), "Organise findings by repo (as scanned) or by severity (in the summary, most severe first)")
+	addFailFlags(flags)
+	flags.StringVar(&format, "format", string(reporter.FormatText), "Output format written to stdout: text, csv (one row per finding), json (the --output report), or sarif (for GitHub code scanning); progress goes to stderr for all but text")
+	flags.StringVar(&historyPath, "history-file", "", "Record a summary of each scan in this file and note the change since the last scan of the same org or user")
+	flags.StringVar(&outputPath, "output", "", "Write the --format report to this file instead of stdout, or the JSON report alongside the terminal output for --format text (.gz is compressed)")
+	flags.StringVar(&summaryJSONPath, "summary-json", "", "Also write the summary counts, with the muaddib version and a UTC timestamp, to this JSON file, whatever the --format")
+	flags.StringVar(&compress, "compress", "", "Compress the --output file (gzip); detected from a .gz extension if unset")
+	flags.Lookup("compress").NoOptDefVal = string(reporter.CompressionGzip)
+	flags.BoolVar(&includeEvidence, "include-evidence", false, "Include the raw IOC row that matched each finding in the JSON report")
+	addConfigFlag(flags)
+	flags.DurationVar(&watchInterval, "watch", 0, "Re-scan at this interval (e.g. 15m) until interrupted, writing new and resolved findings to stdout as NDJSON")
+	flags.BoolVar(&dryRun, "dry-run", false, "List the repositories that would be scanned, or why each would be skipped, with an estimate of the API requests, then exit without fetching files or IOC feeds")
+}
+
+// validateFlags checks that exactly one of --org, --user, --repo, or --path is
+// specified and that mutually exclusive options are not combined
+func validateFlags(flags *pflag.FlagSet) error {
+	if err := validateTargetFlags(); err != nil {
+		return err
+	}
+	if err := validateIOCSourceFlags(); err != nil {
+		return err
+	}
+	if err := validateIOCDownloadFlags(); err != nil {
+		return err
+	}
+	if _, err := github.ParseFetchStrategy(fetchStrategy); err != nil {
+		return err
+	}
+	if _, _, err := iocWindow(); err != nil {
+		return err
+	}
+	if _, err := sinceTime(time.Now()); err != nil {
+		return err
+	}
+	if err := validateRepoPatterns(); err != nil {
+		return err
+	}
+	if _, err := vuln.ParseCampaigns(iocCampaigns); err != nil {
+		return err
+	}
+	if err := validateFailFlags(); err != nil {
+		return err
+	}
+	if err := validateInspectionFlags(); err != nil {
+		return err
+	}
+	if err := validateWatchFlags(flags); err != nil {
+		return err
+	}
+	if err := validateDryRunFlags(); err != nil {
+		return err
+	}
+	return validateReportFlags()
+}
+
+// validateTargetFlags checks that exactly one scan target is given, and that
+// a local scan is not combined with options that need the GitHub API
+func validateTargetFlags() error {
+	targets := 0
+	for _, target := range []string{org, user, repoName, localPath, manifestsDir} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets == 0 {
+		return fmt.Errorf("either --org, --user, --repo, --path, or --manifests-dir must be specified")
+	}
+	if targets > 1 {
+		return fmt.Errorf("--org, --user, --repo, --path, and --manifests-dir are mutually exclusive")
+	}
+	if repoName != "" {
+		if _, _, err := github.SplitFullName(repoName); err != nil {
+			return fmt.Errorf("--repo: %w", err)
+		}
+	}
+	return validateGitHubOnlyFlags()
+}
+
+// validateGitHubOnlyFlags rejects flags that need GitHub when scanning a
+// local directory
+func validateGitHubOnlyFlags() error {
+	if localPath == "" && manifestsDir == "" {
+		return nil
+	}
+	if inspectBranches || checkScheduled || checkNpmrc {
+		return fmt.Errorf("--inspect-malicious-branches, --check-scheduled-workflows, and --check-npmrc need GitHub and cannot be used with --path or --manifests-dir")
+	}
+	if scanRef != "" {
+		return fmt.Errorf("--ref needs GitHub and cannot be used with --path or --manifests-dir")
+	}
+	if blobCacheDir != "" {
+		return fmt.Errorf("--cache-blobs needs GitHub and cannot be used with --path or --manifests-dir")
+	}
+	return nil
+}
+
+// validateInspectionFlags checks the options that control what is scanned
+func validateInspectionFlags() error {
+	if reportSprawl && sprawlThreshold < 1 {
+		return fmt.Errorf("--version-sprawl-threshold must be at least 1")
+	}
+	if blobCacheMB < 0 {
+		return fmt.Errorf("--blob-cache-size cannot be negative")
+	}
+	if err := scanner.ValidateExcludePatterns(excludePaths); err != nil {
+		return err
+	}
+	if err := scanner.ValidateTestPathPatterns(testPaths); err != nil {
+		return err
+	}
+	if pushedBy != "" && !inspectBranches {
+		return fmt.Errorf("--pushed-by requires --inspect-malicious-branches")
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	return nil
+}
+
+// validateReportFlags checks the options that control how results are reported
+func validateReportFlags() error {
+	if _, err := reporter.ParseGroupBy(groupBy); err != nil {
+		return err
+	}
+	if _, err := reporter.ParseFormat(format); err != nil {
+		return err
+	}
+	if compress != "" && outputPath == "" {
+		return fmt.Errorf("--compress requires --output")
+	}
+	if includeEvidence && !writesJSONReport() {
+		return fmt.Errorf("--include-evidence requires the JSON report, from --format json or --output")
+	}
+	if _, err := reporter.ResolveCompression(outputPath, compress); err != nil {
+		return err
+	}
+	return nil
+}
+
+// versionSprawlThreshold returns the sprawl threshold, or zero when the report is disabled
+func versionSprawlThreshold() int {
+	if !reportSprawl {
+		return 0
+	}
+	return sprawlThreshold
+}
+
+// setupContext creates a context with cancellation and signal handling
+func setupContext(rep reporter.Reporter) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		rep.ReportInfo("\n⚠️  Interrupt received, shutting down gracefully...")
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// loadVulnDB loads the vulnerability database from the configured source
+func loadVulnDB(rep reporter.Reporter) (*vuln.VulnDB, error) {
+	rep.ReportInfo("📥 Loading vulnerability database...")
+
+	vuln.SetWarningFunc(func(msg string) {
+		rep.ReportWarning("⚠️  %s", msg)
+	})
+
+	if len(iocChecksums) > 0 {
+		rep.ReportInfo("   Verifying %d pinned IOC source checksum(s)", len(iocChecksums))
+	}
+
+	if len(vulnCSVs) > 0 {
+		return loadCustomSources(rep)
+	}
+
+	if manifest != "" {
+		rep.ReportInfo("   Using source manifest: %s", manifest)
+		return vuln.LoadFromSourceManifest(manifest, iocLoadOptions()...)
+	}
+
+	rep.ReportInfo("   Using default sources: DataDog + Wiz IOC lists")
+	return vuln.LoadFromMultipleURLs(vuln.DefaultIOCURLs(), iocLoadOptions()...)
+}
+
+// loadCustomSources loads and merges each --vuln-csv source, and the default
+// sources too if --include-defaults is set. Every custom source must load.
+func loadCustomSources(rep reporter.Reporter) (*vuln.VulnDB, error) {
+	db := vuln.NewVulnDB()
+	for _, source := range vulnCSVs {
+		sourceDB, err := loadIOCSource(source)
+		if err != nil {
+			return nil, err
+		}
+		if source == stdinSource {
+			source = "stdin"
+		}
+		rep.ReportInfo("   Using custom source: %s (%d entries)", source, sourceDB.TotalEntries())
+		db.Merge(sourceDB)
+	}
+
+	if includeDefaults {
+		rep.ReportInfo("   Including default sources: DataDog + Wiz IOC lists")
+		defaults, err := vuln.LoadFromMultipleURLs(vuln.DefaultIOCURLs(), iocLoadOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		db.Merge(defaults)
+	}
+	return db, nil
+}
+
+// stdinSource is the --vuln-csv source read from stdin
+const stdinSource = "-"
+
+// iocStdin is where --vuln-csv - reads the IOC list from
+var iocStdin io.Reader = os.Stdin
+
+// loadIOCSource loads a single IOC source from a URL, a local file, or
+// stdin. The cache is not consulted, so an unreachable source fails.
+func loadIOCSource(source string) (*vuln.VulnDB, error) {
+	if source == stdinSource {
+		return vuln.LoadFromReader("stdin", iocStdin, iocLoadOptions()...)
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return vuln.LoadFromURLWithOptions(source, iocLoadOptions()...)
+	}
+	return vuln.LoadFromFile(source, iocLoadOptions()...)
+}
+
+// stdinSources counts the --vuln-csv sources read from stdin
+func stdinSources() int {
+	n := 0
+	for _, source := range vulnCSVs {
+		if source == stdinSource {
+			n++
+		}
+	}
+	return n
+}
+
+// readsIOCsFromStdin checks if --vuln-csv - reads an IOC list from stdin
+func readsIOCsFromStdin() bool {
+	return stdinSources() > 0
+}
+
+// validateIOCSourceFlags checks that --vuln-csv, --include-defaults, and
+// --source-manifest are combined sensibly
+func validateIOCSourceFlags() error {
+	if len(vulnCSVs) > 0 && manifest != "" {
+		return fmt.Errorf("--vuln-csv and --source-manifest are mutually exclusive")
+	}
+	if includeDefaults && len(vulnCSVs) == 0 {
+		return fmt.Errorf("--include-defaults requires --vuln-csv")
+	}
+	if stdinSources() > 1 {
+		return fmt.Errorf("--vuln-csv - can only be given once, as stdin is read once")
+	}
+	return nil
+}
+
+// addFailFlags registers --fail-on and --fail-threshold, shared by scan and check-lockfile
+func addFailFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&failOn, "fail-on", string(scanner.FailOnAny), "Exit with status 2 when findings are found: none, vuln, malicious, or any (errors exit 1)")
+	flags.IntVar(&failThreshold, "fail-threshold", 0, "Only fail when more than this many qualifying findings are found")
+}
+
+// validateFailFlags checks --fail-on and --fail-threshold
+func validateFailFlags() error {
+	if _, err := scanner.ParseFailOn(failOn); err != nil {
+		return err
+	}
+	if failThreshold < 0 {
+		return fmt.Errorf("--fail-threshold must not be negative")
+	}
+	return nil
+}
+
+// addIOCDownloadFlags registers the download flags shared by scan, check-lockfile, and doctor
+func addIOCDownloadFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&iocTimeout, "ioc-timeout", vuln.DefaultLoadTimeout, "Timeout for each IOC feed download attempt")
+	flags.IntVar(&iocRetries, "ioc-retries", vuln.DefaultLoadRetries, "Retry IOC feed downloads this many times on network errors, 5xx, and 429, with backoff")
+	flags.StringVar(&proxyAddr, "proxy", "", "Proxy URL for GitHub API requests and IOC downloads, such as http://proxy.example.com:3128; overrides HTTPS_PROXY, HTTP_PROXY, and NO_PROXY")
+}
+
+// iocLoadOptions returns the load options set by --ioc-timeout, --ioc-retries,
+// --proxy, --ioc-checksum, --ioc-campaign, and --ioc-cache-dir. The checksums
+// and campaigns are checked by validateIOCDownloadFlags and validateFlags.
+func iocLoadOptions() []vuln.LoadOption {
+	checksums, _ := vuln.ParseChecksums(iocChecksums)
+	campaigns, _ := vuln.ParseCampaigns(iocCampaigns)
+	return []vuln.LoadOption{
+		vuln.WithTimeout(iocTimeout),
+		vuln.WithRetries(iocRetries),
+		vuln.WithProxy(proxyURL()),
+		vuln.WithChecksums(checksums),
+		vuln.WithCampaigns(campaigns),
+		vuln.WithCacheDir(iocCacheDir),
+	}
+}
+
+// proxyURL returns the --proxy URL, or nil to use the proxy from the
+// environment. The flag is checked by validateIOCDownloadFlags.
+func proxyURL() *url.URL {
+	u, _ := proxy.Parse(proxyAddr)
+	return u
+}
+
+// validateIOCDownloadFlags checks the --ioc-timeout, --ioc-retries, --proxy,
+// and --ioc-checksum values
+func validateIOCDownloadFlags() error {
+	if iocTimeout <= 0 {
+		return fmt.Errorf("--ioc-timeout must be positive")
+	}
+	if iocRetries < 0 {
+		return fmt.Errorf("--ioc-retries must not be negative")
+	}
+	if _, err := proxy.Parse(proxyAddr); err != nil {
+		return fmt.Errorf("--proxy: %w", err)
+	}
+	if _, err := vuln.ParseChecksums(iocChecksums); err != nil {
+		return err
+	}
+	return nil
+}
+
+// iocWindow parses the --ioc-after and --ioc-before dates. Unset bounds are zero.
+func iocWindow() (after, before time.Time, err error) {
+	if iocAfter != "" {
+		if after, err = time.Parse(time.DateOnly, iocAfter); err != nil {
+			return after, before, fmt.Errorf("invalid --ioc-after date %q (expected YYYY-MM-DD)", iocAfter)
+		}
+	}
+	if iocBefore != "" {
+		if before, err = time.Parse(time.DateOnly, iocBefore); err != nil {
+			return after, before, fmt.Errorf("invalid --ioc-before date %q (expected YYYY-MM-DD)", iocBefore)
+		}
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return after, before, fmt.Errorf("--ioc-after must be earlier than --ioc-before")
+	}
+	return after, before, nil
+}
+
+// validateRepoPatterns checks that the --include-repos and --exclude-repos
+// globs are valid, so a typo fails the scan instead of matching nothing
+func validateRepoPatterns() error {
+	for _, pattern := range includeRepos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --include-repos pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range excludeRepos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --exclude-repos pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// repoMatches checks if the name or full name of a repository matches any of
+// the globs
+func repoMatches(repo *github.Repository, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, repo.Name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, repo.FullName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sinceTime parses --since relative to now: a number of days such as "90d",
+// a Go duration, or a date. It is zero when --since is unset.
+func sinceTime(now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(since, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a duration such as 90d or 720h, or a YYYY-MM-DD or RFC 3339 date)", since)
+}
+
+// filterVulnDB restricts the database to the --ioc-after/--ioc-before window
+// and the --campaign labels
+func filterVulnDB(db *vuln.VulnDB, rep reporter.Reporter) *vuln.VulnDB {
+	after, before, _ := iocWindow()
+	if !after.IsZero() || !before.IsZero() {
+		filtered := db.FilterByDate(after, before)
+		rep.ReportInfo("   IOC date window kept %d of %d vulnerable versions (undated entries are always kept)", filtered.Size(), db.Size())
+		db = filtered
+	}
+	if len(campaigns) > 0 {
+		filtered := db.FilterByCampaign(campaigns)
+		rep.ReportInfo("   Campaign filter kept %d of %d vulnerable versions (%s)", filtered.Size(), db.Size(), strings.Join(campaigns, ", "))
+		db = filtered
+	}
+	return db
+}
+
+// loadBaseline loads the baseline snapshot if one was configured
+func loadBaseline(rep reporter.Reporter) (*scanner.Baseline, error) {
+	if baselinePath == "" {
+		return nil, nil
+	}
+
+	baseline, err := scanner.LoadBaseline(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	rep.ReportInfo("📌 Loaded baseline with %d known findings from %s", baseline.Size(), baselinePath)
+	return baseline, nil
+}
+
+// loadComparison loads the prior report given to --compare, if any
+func loadComparison() (*reporter.JSONReport, error) {
+	if comparePath == "" {
+		return nil, nil
+	}
+	return reporter.LoadJSONReport(comparePath)
+}
+
+// createGitHubClient creates and configures the GitHub API client. With
+// --token-file the token is read from the file. With --token-helper it comes
+// from the helper, falling back to the environment when the helper fails.
+func createGitHubClient(ctx context.Context, rep reporter.Reporter) (*github.Client, error) {
+	progressCb := func(msg string) {
+		if verbose {
+			rep.ReportProgress(msg)
+		}
+	}
+
+	strategy, err := github.ParseFetchStrategy(fetchStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []github.ClientOption{
+		github.WithRateLimit(rateLimit),
+		github.WithProgressCallback(progressCb),
+		github.WithFetchStrategy(strategy),
+		github.WithProxy(proxyURL()),
+		github.WithBlobCache(newBlobCache()),
+	}
+	if tokenFile != "" {
+		if tokenHelper != "" {
+			return nil, fmt.Errorf("--token-file and --token-helper are mutually exclusive")
+		}
+		token, err := github.TokenFromFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("--token-file: %w", err)
+		}
+		return github.NewClient(token, opts...), nil
+	}
+	if tokenHelper != "" {
+		token, err := github.TokenFromHelper(ctx, tokenHelper)
+		if err == nil {
+			return github.NewClient(token, opts...), nil
+		}
+		rep.ReportWarning("⚠️  %v; falling back to GITHUB_TOKEN", err)
+	}
+	return github.NewClientFromEnv(opts...)
+}
+
+// newBlobCache returns the cache set by --blob-cache-size and --cache-blobs,
+// or nil when both are off
+func newBlobCache() *github.BlobCache {
+	if blobCacheMB == 0 && blobCacheDir == "" {
+		return nil
+	}
+	return github.NewBlobCache(blobCacheMB<<20, blobCacheDir)
+}
+
+// streamRepositories lists repositories for the configured org or user in the
+// background, delivering each page as soon as it is fetched
+func streamRepositories(ctx context.Context, ghClient *github.Client, rep reporter.Reporter) (<-chan []*github.Repository, <-chan error) {
+	if repoName != "" {
+		rep.ReportInfo("📦 Fetching repository: %s", repoName)
+		return github.StreamRepoPages(ctx, func(ctx context.Context, fn github.RepoPageFunc) error {
+			owner, name, err := github.SplitFullName(repoName)
+			if err != nil {
+				return err
+			}
+			repo, err := ghClient.GetRepo(ctx, owner, name)
+			if err != nil {
+				return err
+			}
+			if scanRef != "" {
+				if err := ghClient.CheckRef(ctx, repo, scanRef); err != nil {
+					return fmt.Errorf("--ref: %w", err)
+				}
+			}
+			return fn([]*github.Repository{repo})
+		})
+	}
+	if org != "" {
+		rep.ReportInfo("📦 Fetching repositories for organization: %s", org)
+		return github.StreamRepoPages(ctx, func(ctx context.Context, fn github.RepoPageFunc) error {
+			return ghClient.ListOrgRepoPages(ctx, org, fn)
+		})
+	}
+	rep.ReportInfo("📦 Fetching repositories for user: %s", user)
+	return github.StreamRepoPages(ctx, func(ctx context.Context, fn github.RepoPageFunc) error {
+		return ghClient.ListUserRepoPages(ctx, user, fn)
+	})
+}
+
+// checkMaliciousMigrationRepos checks a page of repos for malicious migration
+// patterns and returns how many were found
+func checkMaliciousMigrationRepos(repos []*github.Repository, baseline *scanner.Baseline, results *scanner.Results, rep reporter.Reporter) int {
+	var orgResult scanner.OrgScanResult
+
+	for _, repo := range repos {
+		if github.IsMaliciousMigrationRepo(repo) {
+			orgResult.MaliciousRepos = append(orgResult.MaliciousRepos, &scanner.MaliciousRepo{
+				RepoName:    repo.FullName,
+				Description: repo.Description,
+			})
+		}
+	}
+
+	baseline.ApplyOrg(&orgResult, includeBaseline)
+	for _, mr := range orgResult.MaliciousRepos {
+		rep.ReportMaliciousRepo(mr.RepoName, mr.Description)
+	}
+
+	results.AddOrgResult(&orgResult)
+	return len(orgResult.MaliciousRepos)
+}
+
+// scanRepository scans a single repository for vulnerabilities and malicious
+// patterns. With --pushed-by, branches are checked first and nil is returned
+// for repositories without a malicious branch pushed by that login.
+func scanRepository(
+	ctx context.Context,
+	repo *github.Repository,
+	ghClient *github.Client,
+	scan *scanner.Scanner,
+	rep reporter.Reporter,
+) *scanner.RepoScanResult {
+	if reason := repo.NotScannableReason(); reason != "" {
+		return notScannableResult(repo, reason)
+	}
+	repo.Ref = scanRef
+
+	var branches []*scanner.MaliciousBranch
+	if pushedBy != "" {
+		branches = checkBranches(ctx, repo, ghClient, scan, rep)
+		if len(scanner.BranchesPushedBy(branches, pushedBy)) == 0 {
+			return nil
+		}
+	}
+
+	// The tree is listed once and shared by the package file, secret file,
+	// .npmrc, and workflow checks
+	tree, err := ghClient.ListTree(ctx, repo)
+	if err != nil {
+		var notScannable *github.NotScannableError
+		if errors.As(err, &notScannable) {
+			// Workflow and branch checks would fail the same way, so save the quota
+			return notScannableResult(repo, notScannable.Reason)
+		}
+		return &scanner.RepoScanResult{RepoName: repo.FullName, Owner: repo.Owner, Error: err}
+	}
+	files, err := ghClient.FindPackageFilesInTree(ctx, tree)
+	if err != nil {
+		return &scanner.RepoScanResult{RepoName: repo.FullName, Owner: repo.Owner, Error: err}
+	}
+
+	result := scan.ScanFiles(files)
+	result.RepoName = repo.FullName
+	result.Owner = repo.Owner
+	result.Language = repo.Language
+	result.Ref = repo.ScanRef()
+	if verbose && result.FilesExcluded > 0 {
+		rep.ReportProgress(fmt.Sprintf("   ⏭️  Excluded %d package file(s) by --exclude-paths", result.FilesExcluded))
+	}
+
+	secretFiles := findSecretFiles(ctx, tree, ghClient)
+	result.Advisories = append(result.Advisories, checkCommittedSecretFiles(secretFiles, scan)...)
+	result.SuspiciousConfigs = checkNpmrcFiles(secretFiles, scan)
+	result.MaliciousWorkflows = checkWorkflows(ctx, tree, ghClient, scan, rep)
+	annotateActionsEnabled(ctx, repo, result.MaliciousWorkflows, ghClient, rep)
+
+	if pushedBy == "" {
+		branches = checkBranches(ctx, repo, ghClient, scan, rep)
+	}
+	result.MaliciousBranches = branches
+
+	return result
+}
+
+// checkBranches finds the repository's malicious branches and, with
+// --inspect-malicious-branches, scans what each one changed
+func checkBranches(
+	ctx context.Context,
+	repo *github.Repository,
+	ghClient *github.Client,
+	scan *scanner.Scanner,
+	rep reporter.Reporter,
+) []*scanner.MaliciousBranch {
+	if verbose {
+		rep.ReportProgress(fmt.Sprintf("🌿 Checking %s for malicious branches...", repo.FullName))
+	}
+	maliciousBranches, err := ghClient.FindMaliciousBranches(ctx, repo)
+	if err != nil {
+		if verbose {
+			rep.ReportProgress(fmt.Sprintf("   ⚠️  Failed to check branches: %v", err))
+		}
+		return nil
+	}
+	if verbose && len(maliciousBranches) == 0 {
+		rep.ReportProgress("   ✓ No malicious branches found")
+	}
+
+	var branches []*scanner.MaliciousBranch
+	for _, branch := range maliciousBranches {
+		branches = append(branches, &scanner.MaliciousBranch{
+			RepoName:   branch.RepoName,
+			BranchName: branch.Name,
+		})
+	}
+
+	if inspectBranches {
+		inspectMaliciousBranches(ctx, repo, branches, ghClient, scan, rep)
+	}
+	return branches
+}
+
+// findSecretFiles lists the committed secret-bearing files, such as .env and
+// .npmrc, for --deep-inspect and --check-npmrc. Only small .npmrc files are
+// downloaded, once for both checks; other secret files are never fetched.
+func findSecretFiles(ctx context.Context, tree *github.Tree, ghClient *github.Client) []*github.TreeFile {
+	if !deepInspect && !checkNpmrc {
+		return nil
+	}
+	return ghClient.FindTreeFiles(ctx, tree, scanner.IsCommittedSecretPath, scanner.IsInspectableNpmrc)
+}
+
+// checkCommittedSecretFiles flags secret-bearing files such as .env when deep
+// inspection is enabled
+func checkCommittedSecretFiles(files []*github.TreeFile, scan *scanner.Scanner) []*scanner.Advisory {
+	if !deepInspect {
+		return nil
+	}
+	return scan.CheckCommittedSecretFiles(files)
+}
+
+// checkNpmrcFiles flags .npmrc lines that may exfiltrate packages or
+// credentials, with --check-npmrc
+func checkNpmrcFiles(files []*github.TreeFile, scan *scanner.Scanner) []*scanner.SuspiciousConfig {
+	if !checkNpmrc {
+		return nil
+	}
+	return scan.CheckNpmrcFiles(files)
+}
+
+// checkWorkflows fetches the repository's workflows and checks them for worm
+// patterns and, with --check-scheduled-workflows, for scheduled persistence
+func checkWorkflows(
+	ctx context.Context,
+	tree *github.Tree,
+	ghClient *github.Client,
+	scan *scanner.Scanner,
+	rep reporter.Reporter,
+) []*scanner.MaliciousWorkflow {
+	fetch := ghClient.FindMaliciousWorkflows
+	if checkScheduled {
+		fetch = ghClient.FindWorkflowFiles
+	}
+
+	workflows, err := fetch(ctx, tree)
+	if err != nil {
+		if verbose {
+			rep.ReportProgress(fmt.Sprintf("   ⚠️  Failed to check workflows: %v", err))
+		}
+		return nil
+	}
+
+	malicious := scan.CheckWorkflows(workflows)
+	if checkScheduled {
+		malicious = append(malicious, scan.CheckPersistenceWorkflows(workflows)...)
+	}
+	return malicious
+}
+
+// notScannableResult records a repository skipped because of its state
+func notScannableResult(repo *github.Repository, reason string) *scanner.RepoScanResult {
+	return &scanner.RepoScanResult{RepoName: repo.FullName, Owner: repo.Owner, NotScannable: reason}
+}
+
+// annotateActionsEnabled records whether Actions can run the malicious workflows.
+// The setting is only fetched when there are workflows to annotate.
+func annotateActionsEnabled(
+	ctx context.Context,
+	repo *github.Repository,
+	workflows []*scanner.MaliciousWorkflow,
+	ghClient *github.Client,
+	rep reporter.Reporter,
+) {
+	if len(workflows) == 0 {
+		return
+	}
+	enabled, err := ghClient.ActionsEnabled(ctx, repo)
+	if err != nil {
+		if verbose {
+			rep.ReportProgress(fmt.Sprintf("   ⚠️  Failed to check whether Actions is enabled: %v", err))
+		}
+		return
+	}
+	scanner.SetActionsEnabled(workflows, enabled)
+}
+
+// inspectMaliciousBranches scans what each malicious branch changed versus the default branch
+func inspectMaliciousBranches(
+	ctx context.Context,
+	repo *github.Repository,
+	branches []*scanner.MaliciousBranch,
+	ghClient *github.Client,
+	scan *scanner.Scanner,
+	rep reporter.Reporter,
+) {
+	for _, mb := range branches {
+		diff, err := ghClient.InspectBranch(ctx, repo, mb.BranchName)
+		if err != nil {
+			rep.ReportWarning("   ⚠️  Failed to inspect branch %s: %v", mb.BranchName, err)
+			continue
+		}
+		scan.InspectBranch(mb, diff)
+	}
+}
+
+// resultHasIssues checks if a scan result contains any issues
+func resultHasIssues(result *scanner.RepoScanResult) bool {
+	return len(result.VulnerablePackages) > 0 ||
+		len(result.MaliciousWorkflows) > 0 ||
+		len(result.MaliciousScripts) > 0 ||
+		len(result.MaliciousBranches) > 0
+}
+
+// repoPipeline scans repositories as pages arrive from a streaming listing
+type repoPipeline struct {
+	ghClient *github.Client
+	scan     *scanner.Scanner
+	baseline *scanner.Baseline
+	results  *scanner.Results
+	rep      reporter.Reporter
+
+	concurrency int       // Repositories scanned at once
+	since       time.Time // Repositories last pushed before this are skipped; zero scans all
+	skipForks   bool      // Forked repositories are skipped
+	include     []string  // Globs a repository must match to be scanned; empty scans all
+	exclude     []string  // Globs of repositories to skip, even if included
+
+	listed    int // Repositories listed so far
+	stale     int // Repositories skipped as not pushed since --since
+	filtered  int // Repositories skipped by --include-repos or --exclude-repos
+	malicious int // Malicious migration repositories found so far
+
+	mu      sync.Mutex // Guards the counters updated by scan workers and orders progress reports
+	started int        // Repositories taken from the listing so far
+	scanned int        // Repositories whose scan has finished
+	matched int        // Repositories with malicious branches pushed by --pushed-by
+}
+
+// run consumes pages until the listing finishes or the context is cancelled.
+// Each page is checked for migration repos and scanned while the next page loads.
+func (p *repoPipeline) run(ctx context.Context, pages <-chan []*github.Repository) {
+	p.rep.ReportInfo("🔍 Checking for malicious migration repositories...")
+	defer p.rep.FinishProgress()
+	for page := range pages {
+		p.listed += len(page)
+		p.malicious += checkMaliciousMigrationRepos(page, p.baseline, p.results, p.rep)
+		if !p.scanRepositories(ctx, p.pushedSince(p.selectedRepos(page))) {
+			return
+		}
+	}
+}
+
+// selectedRepos returns the repositories matching the include globs and not
+// the exclude globs, counting the rest as filtered. A repository matching
+// both is excluded.
+func (p *repoPipeline) selectedRepos(repos []*github.Repository) []*github.Repository {
+	if len(p.include) == 0 && len(p.exclude) == 0 {
+		return repos
+	}
+	selected := make([]*github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if p.excluded(repo) {
+			p.filtered++
+			continue
+		}
+		selected = append(selected, repo)
+	}
+	return selected
+}
+
+// excluded checks if a repository matches an exclude glob, or no include glob
+func (p *repoPipeline) excluded(repo *github.Repository) bool {
+	return repoMatches(repo, p.exclude) || (len(p.include) > 0 && !repoMatches(repo, p.include))
+}
+
+// notPushedSince checks if a repository was last pushed to before p.since.
+// Repositories without a push time are never stale.
+func (p *repoPipeline) notPushedSince(repo *github.Repository) bool {
+	return !p.since.IsZero() && !repo.PushedAt.IsZero() && repo.PushedAt.Before(p.since)
+}
+
+// pushedSince returns the repositories pushed to since p.since, counting the
+// rest as stale. Repositories without a push time are kept.
+func (p *repoPipeline) pushedSince(repos []*github.Repository) []*github.Repository {
+	if p.since.IsZero() {
+		return repos
+	}
+	recent := make([]*github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if p.notPushedSince(repo) {
+			p.stale++
+			continue
+		}
+		recent = append(recent, repo)
+	}
+	return recent
+}
+
+// scanRepositories scans a page of repositories with up to --concurrency
+// workers, adding results to the aggregator. The GitHub client's rate limiter
+// paces the API calls across workers. It returns false if the scan was
+// interrupted.
+func (p *repoPipeline) scanRepositories(ctx context.Context, repos []*github.Repository) bool {
+	jobs := make(chan *github.Repository)
+	var wg sync.WaitGroup
+	for range max(p.concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range jobs {
+				p.scanRepo(ctx, repo)
+			}
+		}()
+	}
+
+	// Stop handing out repositories once interrupted; in-flight scans see
+	// the cancelled context and return promptly
+dispatch:
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- repo:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		p.rep.ReportInfo("Scan interrupted, showing partial results...")
+		return false
+	}
+	return true
+}
+
+// scanRepo scans a single repository and reports its result. It is called
+// concurrently by the workers of scanRepositories.
+func (p *repoPipeline) scanRepo(ctx context.Context, repo *github.Repository) {
+	rep := p.rep
+	p.reportStarted(repo.FullName)
+	defer p.reportScanned()
+
+	// Skipped repositories are still recorded so the summary's coverage
+	// counts them
+	if repo.Archived {
+		rep.ReportProgress("   ⏭️  Skipping archived repository")
+		p.results.AddRepoResult(notScannableResult(repo, github.ReasonArchived))
+		return
+	}
+	if repo.Fork && p.skipForks {
+		rep.ReportProgress("   ⏭️  Skipping forked repository (use --include-forks to scan it)")
+		p.results.AddRepoResult(notScannableResult(repo, github.ReasonFork))
+		return
+	}
+
+	result := scanRepository(ctx, repo, p.ghClient, p.scan, rep)
+	if !p.matchPushedBy(result) {
+		return
+	}
+	p.baseline.Apply(result, includeBaseline)
+	p.results.AddRepoResult(result)
+
+	// Findings grouped by severity are listed together in the summary. The
+	// header and result are written as one block so concurrent scans don't
+	// interleave them.
+	hasFindings := !rep.GroupsBySeverity() &&
+		(resultHasIssues(result) || len(result.Advisories) > 0 ||
+			len(result.SuspiciousPackages) > 0 || len(result.SuspiciousConfigs) > 0 || len(result.VersionSprawl) > 0)
+	if verbose || hasFindings {
+		rep.ReportRepo(repo.FullName, result)
+	}
+}
+
+// total is the number of repositories listed so far that will be scanned
+func (p *repoPipeline) total() int {
+	return p.listed - p.filtered - p.stale
+}
+
+// reportStarted counts a repository taken from the listing and reports it.
+// The count and report share the lock so positions are reported in order.
+func (p *repoPipeline) reportStarted(repoName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started++
+	p.rep.ReportScanning(p.started, p.total(), repoName)
+}
+
+// reportScanned counts a finished repository and advances the progress. The
+// count and report share the lock so progress never goes backwards.
+func (p *repoPipeline) reportScanned() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scanned++
+	p.rep.ReportScanned(p.scanned, p.total())
+}
+
+// matchPushedBy reports whether a result passed the --pushed-by filter,
+// counting the repositories that matched it
+func (p *repoPipeline) matchPushedBy(result *scanner.RepoScanResult) bool {
+	if result == nil {
+		if verbose {
+			p.rep.ReportProgress(fmt.Sprintf("   ⏭️  No malicious branch pushed by %s", pushedBy))
+		}
+		return false
+	}
+	if pushedBy != "" && result.NotScannable == "" {
+		p.mu.Lock()
+		p.matched++
+		p.mu.Unlock()
+		p.rep.ReportInfo("   🎯 Malicious branch pushed by %s", pushedBy)
+	}
+	return true
+}
+
+// reportListing summarises the repositories found by the listing
+func (p *repoPipeline) reportListing() {
+	p.rep.ReportSuccess("Found %d repositories", p.listed)
+	if len(p.include) > 0 || len(p.exclude) > 0 {
+		p.rep.ReportInfo("⏭️  Skipped %d repositories excluded by --include-repos or --exclude-repos", p.filtered)
+	}
+	if !p.since.IsZero() {
+		p.rep.ReportInfo("⏭️  Skipped %d repositories not pushed to since %s", p.stale, p.since.Format(time.DateOnly))
+	}
+	if p.malicious == 0 {
+		p.rep.ReportSuccess("No malicious migration repositories found")
+	}
+	if pushedBy != "" {
+		p.rep.ReportInfo("🎯 %d repositories have malicious branches pushed by %s", p.matched, pushedBy)
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	out := terminalOutput(cmd)
+	rep, err := reporter.NewReporter(format,
+		reporter.WithOutput(out),
+		colorOption(out),
+		reporter.WithVerbose(verbose),
+		reporter.WithListEmpty(listEmpty),
+		reporter.WithGroupBy(reporter.GroupBy(groupBy)),
+		reporter.WithProgressBar(showProgressBar(out)),
+	)
+	if err != nil {
+		return err
+	}
+	rep.PrintBanner()
+
+	if err := validateFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	ctx, cancel := setupContext(rep)
+	defer cancel()
+
+	if dryRun {
+		return runDryRun(ctx, rep)
+	}
+
+	baseline, err := loadBaseline(rep)
+	if err != nil {
+		return fmt.Errorf("failed to load baseline: %w", err)
+	}
+	previous, err := loadComparison()
+	if err != nil {
+		return fmt.Errorf("failed to load --compare report: %w", err)
+	}
+
+	if err := loadScriptPatterns(); err != nil {
+		return err
+	}
+
+	if watchInterval > 0 {
+		return runWatch(ctx, cmd.OutOrStdout(), baseline, rep)
+	}
+
+	db, err := loadIOCs(rep)
+	if err != nil {
+		return err
+	}
+	repoResults, orgResult, err := scanTargets(ctx, db, baseline, rep)
+	if orgResult == nil {
+		return err
+	}
+	return reportResults(ctx, cmd, repoResults, orgResult, db, previous, err, rep)
+}
+
+// loadIOCs loads the vulnerability database and applies the IOC date window
+func loadIOCs(rep reporter.Reporter) (*vuln.VulnDB, error) {
+	db, err := loadVulnDB(rep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
+	}
+	db = filterVulnDB(db, rep)
+	reportIOCStats(db.Stats(), rep)
+	return db, nil
+}
+
+// reportIOCStats summarises the loaded IOC database
+func reportIOCStats(stats vuln.Stats, rep reporter.Reporter) {
+	rep.ReportSuccess("Loaded %d IOC entries (%d unique packages, %d vulnerable versions)",
+		stats.TotalEntries, stats.UniquePackages, stats.VulnerableVersions)
+	if stats.MultiVersionPackages > 0 {
+		rep.ReportInfo("   %d packages have more than one vulnerable version", stats.MultiVersionPackages)
+	}
+	if stats.Scopes > 0 {
+		rep.ReportInfo("   Including %d scope-wide IOC entries (every package in the scope is flagged)", stats.Scopes)
+	}
+	if stats.IntegrityHashes > 0 {
+		rep.ReportInfo("   Including %d known-bad tarball integrity hashes", stats.IntegrityHashes)
+	}
+	if len(stats.Sources) > 1 {
+		for _, source := range stats.Sources {
+			cached := ""
+			if source.Cached {
+				cached = " (cached)"
+			}
+			rep.ReportInfo("   %d entries from %s%s", source.Entries, source.Location, cached)
+		}
+	}
+	if stats.CachedSources > 0 {
+		rep.ReportWarning("⚠️  %d of %d IOC sources were loaded from cache and may be stale", stats.CachedSources, len(stats.Sources))
+	}
+}
+
+// scanTargets scans the --path directory or the --org/--user repositories.
+// The org result is nil when GitHub lists no repositories, so there is
+// nothing to report. When listing fails part way, the results of the
+// repositories already scanned are returned along with the error.
+func scanTargets(
+	ctx context.Context,
+	db *vuln.VulnDB,
+	baseline *scanner.Baseline,
+	rep reporter.Reporter,
+) ([]*scanner.RepoScanResult, *scanner.OrgScanResult, error) {
+	if localPath != "" {
+		repoResults, err := scanLocalPath(db, baseline, rep)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repoResults, &scanner.OrgScanResult{}, nil
+	}
+	if manifestsDir != "" {
+		repoResults, err := scanManifestsDir(db, baseline, rep)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repoResults, &scanner.OrgScanResult{}, nil
+	}
+
+	ghClient, err := createGitHubClient(ctx, rep)
+	if err != nil {
+		return nil, nil, err
+	}
+	rep.ReportInfo("🔗 Connected to GitHub API (rate limit: %.1f req/sec)", rateLimit)
+	return scanGitHub(ctx, ghClient, db, baseline, rep)
+}
+
+// scanGitHub scans the --org, --user, or --repo repositories, each page as
+// soon as it is listed. If listing fails after some repositories were
+// listed, their results are still returned, with a warning, alongside the
+// error so the summary and reports cover what was scanned.
+func scanGitHub(
+	ctx context.Context,
+	ghClient *github.Client,
+	db *vuln.VulnDB,
+	baseline *scanner.Baseline,
+	rep reporter.Reporter,
+) ([]*scanner.RepoScanResult, *scanner.OrgScanResult, error) {
+	pushedAfter, err := sinceTime(time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := scanner.NewResults()
+	pipeline := &repoPipeline{
+		ghClient:    ghClient,
+		scan:        newScanner(db),
+		baseline:    baseline,
+		results:     results,
+		rep:         rep,
+		concurrency: concurrency,
+		since:       pushedAfter,
+		skipForks:   !includeForks && repoName == "", // A repository named with --repo is scanned even if it is a fork
+		include:     includeRepos,
+		exclude:     excludeRepos,
+	}
+
+	// Scan each page of repositories while the next one is fetched
+	pages, listErr := streamRepositories(ctx, ghClient, rep)
+	pipeline.run(ctx, pages)
+	err = listingError(ctx, <-listErr)
+
+	if pipeline.listed == 0 {
+		if err != nil {
+			return nil, nil, err
+		}
+		rep.ReportInfo("No repositories found")
+		return nil, nil, nil
+	}
+	if err != nil {
+		rep.ReportWarning("⚠️  Listing stopped after %d repositories, so the results are incomplete: %v", pipeline.listed, err)
+	}
+	pipeline.reportListing()
+
+	repoResults, orgResult := results.Snapshot()
+	rep.ReportInfo("📊 Total API requests made: %d", ghClient.GetRequestsMade())
+	return repoResults, orgResult, err
+}
+
+// listingError wraps an error from listing repositories. A --repo that
+// cannot be scanned is returned as is; errors after an interrupt are dropped.
+func listingError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	var notScannable *github.NotScannableError
+	if errors.As(err, &notScannable) {
+		return err
+	}
+	return fmt.Errorf("failed to list repositories: %w", err)
+}
+
+// loadScriptPatterns adds the patterns in --script-patterns-file to those
+// given with --script-patterns
+func loadScriptPatterns() error {
+	if scriptPatternsFile == "" {
+		return nil
+	}
+	patterns, err := scanner.ReadScriptPatterns(scriptPatternsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load --script-patterns-file: %w", err)
+	}
+	scriptPatterns = append(scriptPatterns, patterns...)
+	return nil
+}
+
+// newScanner creates the scanner configured by the scan flags
+func newScanner(db *vuln.VulnDB) *scanner.Scanner {
+	return scanner.NewScanner(db, !skipDev,
+		scanner.WithSkipOptional(skipOptional),
+		scanner.WithDeepInspect(deepInspect),
+		scanner.WithNpmrcAllowedHosts(npmrcHosts),
+		scanner.WithTyposquatDetection(detectTyposquat),
+		scanner.WithDedupe(dedupe),
+		scanner.WithVersionSprawl(versionSprawlThreshold()),
+		scanner.WithExcludePaths(excludePaths),
+		scanner.WithTestPaths(testPaths),
+		scanner.WithScriptPatterns(scriptPatterns))
+}
+
+// reportResults prints the summary, records history, writes the requested
+// reports, and applies the --fail-on policy, ending with the exit summary line
+// on stderr. scanErr, such as listing failing part way, is returned in place
+// of the --fail-on result, as the scan is incomplete.
+func reportResults(
+	ctx context.Context,
+	cmd *cobra.Command,
+	repoResults []*scanner.RepoScanResult,
+	orgResult *scanner.OrgScanResult,
+	db *vuln.VulnDB,
+	previous *reporter.JSONReport,
+	scanErr error,
+	rep reporter.Reporter,
+) error {
+	rep.ReportSummary(repoResults, orgResult, db.Size())
+	rep.ReportSources(db.Sources())
+	if ctx.Err() == nil {
+		recordHistory(time.Now(), repoResults, orgResult, rep)
+	}
+	if previous != nil {
+		rep.ReportDiff(reporter.DiffReports(previous, reporter.NewJSONReport(repoResults, orgResult, db.Size())), comparePath)
+	}
+
+	if outputPath == "" {
+		if err := writeFormattedReport(cmd.OutOrStdout(), reporter.Format(format), repoResults, orgResult, db); err != nil {
+			return err
+		}
+	}
+	if err := writeReportFile(repoResults, orgResult, db, rep); err != nil {
+		return err
+	}
+	if err := writeSummaryFile(time.Now(), repoResults, orgResult, db, rep); err != nil {
+		return err
+	}
+
+	err := checkFailPolicy(cmd, repoResults, orgResult)
+	if scanErr != nil {
+		err = scanErr
+	}
+	writeExitSummary(cmd.ErrOrStderr(), repoResults, orgResult, err)
+	return err
+}
+
+// findingsError reports that the scan found more qualifying findings than allowed.
+// main exits with exitFindings rather than the generic error status for it.
+type findingsError struct {
+	count     int
+	threshold int
+}
+
+func (e *findingsError) Error() string {
+	return fmt.Sprintf("%d qualifying finding(s) exceed --fail-threshold %d", e.count, e.threshold)
+}
+
+// checkFailPolicy returns a findingsError when --fail-on and --fail-threshold say the scan should fail
+func checkFailPolicy(cmd *cobra.Command, repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) error {
+	policy := failPolicy()
+	count := policy.Count(repoResults, orgResult)
+	if !policy.Fails(count) {
+		return nil
+	}
+
+	// The scan itself succeeded, so the usage text would only be noise
+	cmd.SilenceUsage = true
+	return &findingsError{count: count, threshold: failThreshold}
+}
+
+// recordHistory prints how this scan compares with the previous one for the
+// same scope and appends it to --history-file. History is best-effort, so
+// failures are reported as warnings.
+func recordHistory(now time.Time, repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, rep reporter.Reporter) {
+	if historyPath == "" {
+		return
+	}
+
+	entries, err := history.Load(historyPath)
+	if err != nil {
+		rep.ReportWarning("⚠️  Failed to read scan history: %v", err)
+		return
+	}
+
+	scope := history.Scope(history.Target{Org: org, User: user, Repo: repoName, LocalPath: localPath, ManifestsDir: manifestsDir})
+	current := history.NewEntry(scope, now, repoResults, orgResult)
+	if previous := history.Latest(entries, current.Scope); previous != nil {
+		rep.ReportInfo("📈 %s", history.Compare(previous, current).Describe(now))
+	}
+
+	if err := history.Append(historyPath, current); err != nil {
+		rep.ReportWarning("⚠️  Failed to record scan history: %v", err)
+	}
+}
+
+// terminalOutput returns where the terminal report goes. Machine-readable
+// formats and the --watch change stream own stdout, so the terminal report
+// moves to stderr.
+func terminalOutput(cmd *cobra.Command) io.Writer {
+	if reporter.Format(format) == reporter.FormatText && watchInterval == 0 {
+		return cmd.OutOrStdout()
+	}
+	return cmd.ErrOrStderr()
+}
+
+// showProgressBar checks if repositories are counted on a progress bar rather
+// than listed as they are scanned: only on an interactive terminal, and not
+// with --verbose, --no-progress, or --watch
+func showProgressBar(out io.Writer) bool {
+	return !verbose && !noProgress && watchInterval == 0 && reporter.IsInteractive(out)
+}
+
+// writeFormattedReport writes the findings in a structured format. The text
+// format is written by the terminal reporter as the scan runs, so nothing is
+// written for it here.
+func writeFormattedReport(w io.Writer, f reporter.Format, repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, db *vuln.VulnDB) error {
+	switch f {
+	case reporter.FormatCSV:
+		return reporter.WriteCSVReport(w, repoResults, orgResult)
+	case reporter.FormatJSON:
+		return reporter.WriteJSONReport(w, repoResults, orgResult, db.Size(),
+			reporter.WithEvidence(includeEvidence), reporter.WithSources(db.Sources()))
+	case reporter.FormatSARIF:
+		return reporter.WriteSARIFReport(w, repoResults, orgResult, toolVersion(), db.Sources())
+	default:
+		return nil
+	}
+}
+
+// reportFileFormat returns the format written to --output: the --format
+// document, or the JSON report when the terminal report is selected
+func reportFileFormat() reporter.Format {
+	if f := reporter.Format(format); f != reporter.FormatText {
+		return f
+	}
+	return reporter.FormatJSON
+}
+
+// writesJSONReport checks if the scan writes the JSON report, to stdout or --output
+func writesJSONReport() bool {
+	return reporter.Format(format) == reporter.FormatJSON || (outputPath != "" && reportFileFormat() == reporter.FormatJSON)
+}
+
+// writeReportFile writes the report to --output instead of stdout, compressing
+// it if requested. The file holds only the report document; progress stays on
+// the terminal.
+func writeReportFile(repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, db *vuln.VulnDB, rep reporter.Reporter) error {
+	if outputPath == "" {
+		return nil
+	}
+
+	compression, err := reporter.ResolveCompression(outputPath, compress)
+	if err != nil {
+		return err
+	}
+
+	out, err := reporter.CreateOutputFile(outputPath, compression)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFormattedReport(out, reportFileFormat(), repoResults, orgResult, db); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	rep.ReportSuccess("Report written to %s", outputPath)
+	return nil
+}
+
+// writeSummaryFile writes the --summary-json file
+func writeSummaryFile(now time.Time, repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, db *vuln.VulnDB, rep reporter.Reporter) error {
+	if summaryJSONPath == "" {
+		return nil
+	}
+
+	out, err := reporter.CreateOutputFile(summaryJSONPath, reporter.CompressionNone)
+	if err != nil {
+		return err
+	}
+
+	summary := reporter.NewSummaryReport(repoResults, orgResult, db.Size(), toolVersion(), now)
+	if err := reporter.WriteSummaryReport(out, summary); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+
+	rep.ReportSuccess("Summary written to %s", summaryJSONPath)
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// exitSummaryCategories lists the finding categories counted in the exit
+// summary line, in output order
+var exitSummaryCategories = []scanner.FindingCategory{
+	scanner.CategoryVulnerablePackage,
+	scanner.CategoryMaliciousWorkflow,
+	scanner.CategoryMaliciousScript,
+	scanner.CategoryMaliciousBranch,
+	scanner.CategoryMaliciousRepo,
+	scanner.CategoryAdvisory,
+}
+
+// failPolicy returns the policy configured by --fail-on, --fail-threshold,
+// and --include-baseline
+func failPolicy() scanner.FailPolicy {
+	return scanner.FailPolicy{
+		FailOn:       scanner.FailOn(failOn),
+		Threshold:    failThreshold,
+		IncludeKnown: includeBaseline,
+	}
+}
+
+// exitSummary formats the single machine-readable line written to stderr
+// when a scan completes, e.g.
+//
+//	muaddib: repos=25 vulnerable_package=2 ... qualifying=2 fail_on=vuln threshold=0 failed=true exit=2
+//
+// err is the result of checkFailPolicy, so failed and exit always agree with
+// the process exit status.
+func exitSummary(repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, err error) string {
+	counts := make(map[scanner.FindingCategory]int)
+	for _, result := range repoResults {
+		for _, f := range result.Findings() {
+			counts[f.Category]++
+		}
+	}
+	if orgResult != nil {
+		for _, f := range orgResult.Findings() {
+			counts[f.Category]++
+		}
+	}
+
+	fields := []string{fmt.Sprintf("repos=%d", len(repoResults))}
+	for _, category := range exitSummaryCategories {
+		key := strings.ReplaceAll(string(category), "-", "_")
+		fields = append(fields, fmt.Sprintf("%s=%d", key, counts[category]))
+	}
+
+	policy := failPolicy()
+	code := exitCode(err)
+	fields = append(fields,
+		fmt.Sprintf("qualifying=%d", policy.Count(repoResults, orgResult)),
+		fmt.Sprintf("fail_on=%s", policy.FailOn),
+		fmt.Sprintf("threshold=%d", policy.Threshold),
+		fmt.Sprintf("failed=%t", code == exitFindings),
+		fmt.Sprintf("exit=%d", code),
+	)
+	return "muaddib: " + strings.Join(fields, " ")
+}
+
+// writeExitSummary writes the exit summary line
+func writeExitSummary(w io.Writer, repoResults []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, err error) {
+	fmt.Fprintln(w, exitSummary(repoResults, orgResult, err))
+}
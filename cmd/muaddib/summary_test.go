@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestExitSummary_AgreesWithExitCode(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				{
+					Package:   &scanner.Package{Name: "test-muaddib-bad", Version: "1.0.0"},
+					VulnEntry: &vuln.VulnEntry{PackageName: "test-muaddib-bad", PackageVersion: "1.0.0"},
+					FilePath:  "package-lock.json",
+					RepoName:  "test-org/a",
+				},
+			},
+			MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org/a", BranchName: "shai-hulud"}},
+		},
+		{RepoName: "test-org/b"},
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{{RepoName: "test-org/b-migration"}},
+	}
+
+	testCases := []struct {
+		name      string
+		failOn    string
+		threshold int
+		want      string
+	}{
+		{"never fails", "none", 0, "qualifying=0 fail_on=none threshold=0 failed=false exit=0"},
+		{"vulnerable packages fail", "vuln", 0, "qualifying=1 fail_on=vuln threshold=0 failed=true exit=2"},
+		{"malicious findings fail", "malicious", 0, "qualifying=2 fail_on=malicious threshold=0 failed=true exit=2"},
+		{"within threshold", "any", 3, "qualifying=3 fail_on=any threshold=3 failed=false exit=0"},
+		{"above threshold", "any", 2, "qualifying=3 fail_on=any threshold=2 failed=true exit=2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			executeWithStubRun(t, "--org", "test-org", "--fail-on", tc.failOn, "--fail-threshold", strconv.Itoa(tc.threshold))
+
+			err := checkFailPolicy(&cobra.Command{}, results, orgResult)
+			line := exitSummary(results, orgResult, err)
+
+			wantLine := "muaddib: repos=2 vulnerable_package=1 malicious_workflow=0 malicious_script=0 " +
+				"malicious_branch=1 malicious_repo=1 advisory=0 " + tc.want
+			if line != wantLine {
+				t.Errorf("expected %q, got %q", wantLine, line)
+			}
+			if failed := strings.Contains(line, "failed=true"); failed != (exitCode(err) == exitFindings) {
+				t.Errorf("line %q disagrees with exit code %d", line, exitCode(err))
+			}
+		})
+	}
+}
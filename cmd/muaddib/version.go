@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Build details set at build time with -ldflags, e.g.
+// -X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)
+var (
+	version   = ""
+	commit    = ""
+	buildDate = ""
+)
+
+// toolVersion returns the build-time version, falling back to the module
+// version recorded by "go install"
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// buildInfo describes the running binary, for bug reports
+type buildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+	GoVersion string
+}
+
+// currentBuildInfo returns the details set with -ldflags. A commit or date
+// left unset falls back to the VCS stamp "go build" records in the binary,
+// and is "unknown" without one.
+func currentBuildInfo() buildInfo {
+	info := buildInfo{Version: toolVersion(), Commit: commit, BuildDate: buildDate, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.applyVCSSettings(bi.Settings)
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// applyVCSSettings fills the commit and date from the vcs.* build settings
+// when they were not set with -ldflags
+func (b *buildInfo) applyVCSSettings(settings []debug.BuildSetting) {
+	var revision, modified, date string
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		case "vcs.time":
+			date = setting.Value
+		}
+	}
+	if b.Commit == "" && revision != "" {
+		b.Commit = revision
+		if modified == "true" {
+			b.Commit += "-dirty"
+		}
+	}
+	if b.BuildDate == "" {
+		b.BuildDate = date
+	}
+}
+
+// String formats the build details as printed by "muaddib version" and --version
+func (b buildInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "muaddib %s\n", b.Version)
+	fmt.Fprintf(&sb, "  commit:     %s\n", b.Commit)
+	fmt.Fprintf(&sb, "  built:      %s\n", b.BuildDate)
+	fmt.Fprintf(&sb, "  go version: %s\n", b.GoVersion)
+	return sb.String()
+}
+
+// newVersionCmd creates the version subcommand. It needs no network access
+// or GitHub token, and skips the config file so a broken one cannot hide the
+// version from a bug report.
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the muaddib version, commit, build date, and Go version",
+		Args:  cobra.NoArgs,
+
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), currentBuildInfo())
+			return err
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestVersion_PrintsBuildInfo(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	version, commit, buildDate = "v1.2.3", "test-muaddib-commit", "2025-12-01T12:00:00Z"
+	t.Cleanup(func() { version, commit, buildDate = "", "", "" })
+
+	want := "muaddib v1.2.3\n" +
+		"  commit:     test-muaddib-commit\n" +
+		"  built:      2025-12-01T12:00:00Z\n" +
+		"  go version: " + runtime.Version() + "\n"
+
+	for _, args := range [][]string{{"version"}, {"--version"}} {
+		var out bytes.Buffer
+		rootCmd := newRootCmd()
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("execute %v: %v", args, err)
+		}
+		if out.String() != want {
+			t.Errorf("%v: expected %q, got %q", args, want, out.String())
+		}
+	}
+}
+
+func TestVersion_IgnoresBrokenConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	writeConfig(t, ".", "muaddib.yaml", "organisation: test-org\n")
+
+	var out bytes.Buffer
+	rootCmd := newRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"version"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("expected the config file to be skipped, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "muaddib ") {
+		t.Errorf("expected the version to be printed, got %q", out.String())
+	}
+}
+
+func TestBuildInfo_FallsBackToVCSSettings(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123abcd"},
+		{Key: "vcs.time", Value: "2025-12-01T12:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	var info buildInfo
+	info.applyVCSSettings(settings)
+	if info.Commit != "0123abcd-dirty" || info.BuildDate != "2025-12-01T12:00:00Z" {
+		t.Errorf("expected the VCS stamp to be used, got %+v", info)
+	}
+
+	info = buildInfo{Commit: "test-muaddib-commit", BuildDate: "2026-01-01"}
+	info.applyVCSSettings(settings)
+	if info.Commit != "test-muaddib-commit" || info.BuildDate != "2026-01-01" {
+		t.Errorf("expected -ldflags values to take precedence, got %+v", info)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rslater/muaddib/internal/reporter"
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// watchIteration runs one scan and returns its findings as a JSON report
+type watchIteration func(ctx context.Context) (*reporter.JSONReport, error)
+
+// runWatch re-scans every --watch interval until interrupted, writing the
+// findings that changed since the previous iteration to w as NDJSON
+func runWatch(ctx context.Context, w io.Writer, baseline *scanner.Baseline, rep reporter.Reporter) error {
+	rep.ReportInfo("👀 Watching for changes every %s (Ctrl+C to stop)", watchInterval)
+
+	return watchLoop(ctx, w, watchInterval, rep, func(ctx context.Context) (*reporter.JSONReport, error) {
+		// IOC feeds are reloaded each time so new entries are picked up
+		db, err := loadIOCs(rep)
+		if err != nil {
+			return nil, err
+		}
+		repoResults, orgResult, err := scanTargets(ctx, db, baseline, rep)
+		if err != nil {
+			return nil, err
+		}
+		rep.ReportSummary(repoResults, orgResult, db.Size())
+		return reporter.NewJSONReport(repoResults, orgResult, db.Size()), nil
+	})
+}
+
+// watchLoop runs scan every interval, diffing each report against the last
+// one that completed. The first iteration reports every finding as new. A
+// failed iteration is reported and retried on the next tick; an interrupted
+// one is discarded, as its results are partial.
+func watchLoop(ctx context.Context, w io.Writer, interval time.Duration, rep reporter.Reporter, scan watchIteration) error {
+	previous := &reporter.JSONReport{}
+	for iteration := 1; ; iteration++ {
+		current, err := scan(ctx)
+		if ctx.Err() != nil {
+			rep.ReportInfo("Watch stopped")
+			return nil
+		}
+
+		if err != nil {
+			rep.ReportWarning("⚠️  Watch iteration %d failed: %v", iteration, err)
+		} else if current != nil {
+			if err := reporter.WriteJSONChanges(w, reporter.DiffReports(previous, current), time.Now(), iteration); err != nil {
+				return err
+			}
+			previous = current
+		}
+
+		select {
+		case <-ctx.Done():
+			rep.ReportInfo("Watch stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// validateWatchFlags checks that --watch is not combined with options that
+// write a single final report, as watch mode streams changes instead. Watch
+// mode only stops when interrupted, so --fail-on and --fail-threshold are
+// rejected rather than ignored.
+func validateWatchFlags(flags *pflag.FlagSet) error {
+	if watchInterval < 0 {
+		return fmt.Errorf("--watch must not be negative")
+	}
+	if watchInterval == 0 {
+		return nil
+	}
+	if reporter.Format(format) != reporter.FormatText || outputPath != "" || comparePath != "" || historyPath != "" {
+		return fmt.Errorf("--watch writes changes to stdout as NDJSON and cannot be used with --format, --output, --compare, or --history-file")
+	}
+	if flags.Changed("fail-on") || flags.Changed("fail-threshold") {
+		return fmt.Errorf("--watch only stops when interrupted, so it never exits with the findings status and cannot be used with --fail-on or --fail-threshold")
+	}
+	if readsIOCsFromStdin() {
+		return fmt.Errorf("--watch reloads the IOC sources each time and cannot read them from stdin with --vuln-csv -")
+	}
+	return nil
+}
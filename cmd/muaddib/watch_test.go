@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rslater/muaddib/internal/reporter"
+)
+
+func TestWatchLoop_EmitsChangesBetweenIterations(t *testing.T) {
+	reports := []*reporter.JSONReport{
+		{Findings: []*reporter.JSONFinding{{ID: "kept", Repository: "test-org/app"}}},
+		{Findings: []*reporter.JSONFinding{{ID: "kept", Repository: "test-org/app"}, {ID: "added", Repository: "test-org/web"}}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iterations := 0
+	scan := func(ctx context.Context) (*reporter.JSONReport, error) {
+		if iterations == len(reports) {
+			cancel()
+			return nil, errors.New("interrupted")
+		}
+		iterations++
+		return reports[iterations-1], nil
+	}
+
+	var out bytes.Buffer
+	rep := reporter.NewTerminalReporter(reporter.WithOutput(io.Discard))
+	if err := watchLoop(ctx, &out, time.Millisecond, rep, scan); err != nil {
+		t.Fatalf("watchLoop failed: %v", err)
+	}
+
+	if iterations != 2 {
+		t.Fatalf("expected 2 iterations, got %d", iterations)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one change per iteration, got:\n%s", out.String())
+	}
+	var delta reporter.JSONChange
+	if err := json.Unmarshal([]byte(lines[1]), &delta); err != nil {
+		t.Fatalf("change is not valid JSON: %v", err)
+	}
+	if delta.Iteration != 2 || delta.Change != reporter.ChangeNew || delta.Finding.ID != "added" {
+		t.Errorf("expected finding added in iteration 2, got %+v", delta)
+	}
+}
+
+// parseRootFlags parses args into the root command's flags without running it
+func parseRootFlags(t *testing.T, args ...string) *pflag.FlagSet {
+	t.Helper()
+
+	rootCmd := newRootCmd()
+	if err := rootCmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse %v: %v", args, err)
+	}
+	return rootCmd.Flags()
+}
+
+func TestValidateWatchFlags(t *testing.T) {
+	if err := validateWatchFlags(parseRootFlags(t, "--path", ".", "--watch", "5m")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := validateWatchFlags(parseRootFlags(t, "--path", ".", "--watch", "5m", "--format", "json")); err == nil {
+		t.Error("expected --watch with --format json to be rejected")
+	}
+
+	if err := validateWatchFlags(parseRootFlags(t, "--path", ".", "--watch", "5m", "--vuln-csv", "-")); err == nil {
+		t.Error("expected --watch with --vuln-csv - to be rejected")
+	}
+
+	for _, failFlag := range []string{"--fail-on=vuln", "--fail-on=any", "--fail-threshold=3"} {
+		err := validateWatchFlags(parseRootFlags(t, "--path", ".", "--watch", "5m", failFlag))
+		if err == nil || !strings.Contains(err.Error(), "--fail-on or --fail-threshold") {
+			t.Errorf("expected --watch with %s to be rejected, got %v", failFlag, err)
+		}
+	}
+
+	if err := validateWatchFlags(parseRootFlags(t, "--path", ".", "--fail-on=vuln")); err != nil {
+		t.Errorf("expected --fail-on to be allowed without --watch, got %v", err)
+	}
+}
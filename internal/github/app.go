@@ -0,0 +1,188 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+const (
+	// appJWTLifetime is how long an app JWT is valid; GitHub allows at most 10 minutes
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT so a server clock slightly behind ours accepts it
+	appJWTClockSkew = time.Minute
+	// installationTokenMargin is how long before expiry an installation token is replaced
+	installationTokenMargin = 5 * time.Minute
+)
+
+// WithAppAuth authenticates as a GitHub App installation instead of with a
+// token. An installation token is created from the app's private key on the
+// first request and replaced shortly before it expires, so long scans keep
+// working past the token's one hour lifetime.
+func WithAppAuth(appID, installationID int64, privateKeyPEM []byte) ClientOption {
+	return func(c *Client) {
+		c.app = &appTransport{
+			appID:          appID,
+			installationID: installationID,
+			privateKeyPEM:  privateKeyPEM,
+		}
+	}
+}
+
+// appTransport authenticates requests with a GitHub App installation token
+type appTransport struct {
+	appID          int64
+	installationID int64
+	privateKeyPEM  []byte
+	client         *github.Client    // Resolves the token endpoint against the API base URL
+	base           http.RoundTripper // Sends requests through the client's proxy
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// RoundTrip adds the installation token to the request
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationToken returns the current installation token, creating a new
+// one if there is none or it is about to expire
+func (t *appTransport) installationToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Until(t.expires) > installationTokenMargin {
+		return t.token, nil
+	}
+
+	key, err := parseAppPrivateKey(t.privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	jwt, err := appJWT(t.appID, key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	req, err := t.client.NewRequest(http.MethodPost, fmt.Sprintf("app/installations/%d/access_tokens", t.installationID), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create installation token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to create installation token for installation %d: %s", t.installationID, resp.Status)
+	}
+
+	var token github.InstallationToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	t.token, t.expires = token.GetToken(), token.GetExpiresAt().Time
+	return t.token, nil
+}
+
+// appJWT creates the RS256-signed JWT that authenticates as the app itself
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseAppPrivateKey parses a GitHub App private key in PKCS #1 or PKCS #8 PEM form
+func parseAppPrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// appAuthFromEnv returns the app auth option configured by GITHUB_APP_ID,
+// GITHUB_APP_INSTALLATION_ID, and GITHUB_APP_PRIVATE_KEY, or nil if none are
+// set. The private key may be given as PEM or as the path to a PEM file.
+func appAuthFromEnv() (ClientOption, error) {
+	appIDVar, installationIDVar, keyVar := os.Getenv("GITHUB_APP_ID"), os.Getenv("GITHUB_APP_INSTALLATION_ID"), os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	if appIDVar == "" && installationIDVar == "" && keyVar == "" {
+		return nil, nil
+	}
+	if appIDVar == "" || installationIDVar == "" || keyVar == "" {
+		return nil, fmt.Errorf("GitHub App auth requires GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, and GITHUB_APP_PRIVATE_KEY to all be set")
+	}
+
+	appID, err := strconv.ParseInt(appIDVar, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_ID %q", appIDVar)
+	}
+	installationID, err := strconv.ParseInt(installationIDVar, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_INSTALLATION_ID %q", installationIDVar)
+	}
+
+	privateKeyPEM := []byte(keyVar)
+	if !strings.Contains(keyVar, "-----BEGIN") {
+		if privateKeyPEM, err = os.ReadFile(keyVar); err != nil {
+			return nil, fmt.Errorf("failed to read GITHUB_APP_PRIVATE_KEY: %w", err)
+		}
+	}
+	if _, err := parseAppPrivateKey(privateKeyPEM); err != nil {
+		return nil, err
+	}
+
+	return WithAppAuth(appID, installationID, privateKeyPEM), nil
+}
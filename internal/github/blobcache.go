@@ -0,0 +1,155 @@
+package github
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultBlobCacheSize is the memory, in bytes, the blob cache may hold
+const DefaultBlobCacheSize = 64 << 20
+
+// BlobCache holds package file contents keyed by git blob SHA, so a file seen
+// again in the same run, such as a lockfile shared by several repositories, is
+// not downloaded twice. Files that may hold secrets are never cached. The least recently used blobs are evicted once the cache
+// holds more than its size limit. With a directory, blobs are also written to
+// disk and survive across runs. It is safe for concurrent use.
+type BlobCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	order    *list.List // Most recently used first
+	entries  map[string]*list.Element
+	dir      string
+}
+
+// blobCacheEntry is a cached blob, held in the cache's LRU list
+type blobCacheEntry struct {
+	sha     string
+	content string
+}
+
+// NewBlobCache creates a cache holding at most maxBytes of blobs in memory.
+// A non-empty dir also persists blobs there across runs.
+func NewBlobCache(maxBytes int, dir string) *BlobCache {
+	return &BlobCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		dir:      dir,
+	}
+}
+
+// DefaultBlobCacheDir returns the blob cache directory under the user's cache
+// directory, or "" if the platform has none
+func DefaultBlobCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "muaddib", "blobs")
+}
+
+// Get returns the content of a blob, checking memory and then the cache
+// directory. A blob on disk whose content does not hash to its SHA is ignored.
+func (b *BlobCache) Get(sha string) (string, bool) {
+	b.mu.Lock()
+	if elem, ok := b.entries[sha]; ok {
+		b.order.MoveToFront(elem)
+		b.mu.Unlock()
+		return elem.Value.(*blobCacheEntry).content, true
+	}
+	b.mu.Unlock()
+
+	path, ok := b.path(sha)
+	if !ok {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || gitBlobSHA(sha, content) != sha {
+		return "", false
+	}
+	b.remember(sha, string(content))
+	return string(content), true
+}
+
+// Put stores a blob in memory and, with a cache directory, on disk. Failing
+// to write the blob to disk is returned, but it is still cached in memory.
+func (b *BlobCache) Put(sha, content string) error {
+	b.remember(sha, content)
+
+	path, ok := b.path(sha)
+	if !ok {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check blob cache: %w", err)
+	}
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create blob cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write blob cache: %w", err)
+	}
+	return nil
+}
+
+// remember adds a blob to the front of the LRU list, evicting the least
+// recently used blobs to stay within the size limit. Blobs larger than the
+// limit are not held in memory.
+func (b *BlobCache) remember(sha, content string) {
+	if len(content) > b.maxBytes {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elem, ok := b.entries[sha]; ok {
+		b.order.MoveToFront(elem)
+		return
+	}
+	b.entries[sha] = b.order.PushFront(&blobCacheEntry{sha: sha, content: content})
+	b.size += len(content)
+	for b.size > b.maxBytes {
+		oldest := b.order.Back()
+		entry := b.order.Remove(oldest).(*blobCacheEntry)
+		delete(b.entries, entry.sha)
+		b.size -= len(entry.content)
+	}
+}
+
+// path returns the file a blob is persisted in. Only full hex SHA-1 or
+// SHA-256 object names are persisted, so a SHA can never escape the directory.
+func (b *BlobCache) path(sha string) (string, bool) {
+	if b.dir == "" || (len(sha) != sha1.Size*2 && len(sha) != sha256.Size*2) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(sha); err != nil {
+		return "", false
+	}
+	return filepath.Join(b.dir, sha), true
+}
+
+// gitBlobSHA returns the git object name of a blob with this content, using
+// the hash of the same length as sha
+func gitBlobSHA(sha string, content []byte) string {
+	var h hash.Hash
+	if len(sha) == sha256.Size*2 {
+		h = sha256.New()
+	} else {
+		h = sha1.New()
+	}
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlobCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewBlobCache(10, "")
+	cache.Put("sha-a", "aaaa")
+	cache.Put("sha-b", "bbbb")
+	cache.Get("sha-a")
+	cache.Put("sha-c", "cccc")
+
+	if _, ok := cache.Get("sha-b"); ok {
+		t.Error("expected the least recently used blob to be evicted")
+	}
+	for _, sha := range []string{"sha-a", "sha-c"} {
+		if _, ok := cache.Get(sha); !ok {
+			t.Errorf("expected %s to be kept", sha)
+		}
+	}
+
+	cache.Put("sha-large", "larger than the cache")
+	if _, ok := cache.Get("sha-large"); ok {
+		t.Error("expected a blob over the size limit not to be held in memory")
+	}
+}
+
+func TestBlobCache_PersistsAcrossRuns(t *testing.T) {
+	dir := t.TempDir()
+	// git hash-object of `{"lockfileVersion": 3}`
+	const sha = "9394dfa161abca5d08963742795622fa5d26a817"
+	content := `{"lockfileVersion": 3}`
+	if got := gitBlobSHA(sha, []byte(content)); got != sha {
+		t.Fatalf("expected git object name %s, got %s", sha, got)
+	}
+
+	if err := NewBlobCache(0, dir).Put(sha, content); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got, ok := NewBlobCache(DefaultBlobCacheSize, dir).Get(sha); !ok || got != content {
+		t.Errorf("expected the blob to be read back from disk, got %q, %v", got, ok)
+	}
+
+	// A blob whose content does not match its SHA is ignored
+	if err := os.WriteFile(filepath.Join(dir, sha), []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("failed to overwrite blob: %v", err)
+	}
+	if _, ok := NewBlobCache(DefaultBlobCacheSize, dir).Get(sha); ok {
+		t.Error("expected a tampered blob to be ignored")
+	}
+
+	// Names that are not object IDs are never written to disk
+	NewBlobCache(DefaultBlobCacheSize, dir).Put("../sha-escape", content)
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "sha-escape")); err == nil {
+		t.Error("expected a non-hex SHA not to be persisted")
+	}
+}
+
+func TestFindPackageFiles_ReusesCachedBlobs(t *testing.T) {
+	mux := http.NewServeMux()
+	stubRepoTree(mux)
+	blobs := map[string]string{
+		"sha-manifest": stubRepoFiles["package.json"],
+		"sha-lock":     stubRepoFiles["packages/app/package-lock.json"],
+	}
+	for sha, content := range blobs {
+		mux.HandleFunc("/repos/test-org/test-repo/git/blobs/"+sha, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(content))
+		})
+	}
+	c := newTestClient(t, mux, WithFetchStrategy(FetchBlobs), WithBlobCache(NewBlobCache(DefaultBlobCacheSize, "")))
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	for range 2 {
+		files, err := c.FindPackageFiles(t.Context(), repo)
+		if err != nil {
+			t.Fatalf("FindPackageFiles failed: %v", err)
+		}
+		assertStubPackageFiles(t, files)
+	}
+	if c.GetRequestsMade() != 4 {
+		t.Errorf("expected 2 tree + 2 blob requests, got %d", c.GetRequestsMade())
+	}
+}
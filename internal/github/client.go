@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/google/go-github/v67/github"
 	"golang.org/x/time/rate"
+
+	"github.com/rslater/muaddib/internal/proxy"
 )
 
 // ProgressCallback is called to report progress
@@ -24,6 +27,11 @@ type Client struct {
 	onProgress   ProgressCallback
 	mu           sync.Mutex
 	requestsMade int
+
+	fetchStrategy FetchStrategy
+	blobCache     *BlobCache    // Contents of fetched files by blob SHA, if set
+	proxyURL      *url.URL      // Overrides the proxy from the environment, if set
+	app           *appTransport // GitHub App auth, set by WithAppAuth
 }
 
 // ClientOption configures the Client
@@ -50,32 +58,111 @@ func WithProgressCallback(cb ProgressCallback) ClientOption {
 	}
 }
 
+// WithProxy sends API requests through the proxy at proxyURL instead of the
+// one named by HTTPS_PROXY. A nil URL keeps the environment's proxy.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(c *Client) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// FetchStrategy selects how package file contents are downloaded once the
+// repository tree has been listed
+type FetchStrategy string
+
+const (
+	// FetchContents fetches each package file by path via the contents API
+	FetchContents FetchStrategy = "contents"
+	// FetchBlobs fetches each package file by SHA via the Git blobs API
+	FetchBlobs FetchStrategy = "blobs"
+)
+
+// ParseFetchStrategy validates a fetch strategy name
+func ParseFetchStrategy(name string) (FetchStrategy, error) {
+	switch FetchStrategy(name) {
+	case FetchContents, FetchBlobs:
+		return FetchStrategy(name), nil
+	default:
+		return "", fmt.Errorf("unknown fetch strategy %q (expected contents or blobs)", name)
+	}
+}
+
+// WithFetchStrategy sets how package file contents are downloaded
+func WithFetchStrategy(strategy FetchStrategy) ClientOption {
+	return func(c *Client) {
+		c.fetchStrategy = strategy
+	}
+}
+
+// WithBlobCache reuses file contents already fetched, by blob SHA, instead of
+// downloading them again. A nil cache fetches every file.
+func WithBlobCache(cache *BlobCache) ClientOption {
+	return func(c *Client) {
+		c.blobCache = cache
+	}
+}
+
 // NewClient creates a new GitHub client with the given token
 func NewClient(token string, opts ...ClientOption) *Client {
-	httpClient := &http.Client{}
-	ghClient := github.NewClient(httpClient).WithAuthToken(token)
-
 	c := &Client{
-		client:     ghClient,
-		limiter:    rate.NewLimiter(rate.Limit(1.0), 1), // Default: 1 request per second
-		maxRetries: 5,
-		retryDelay: 5 * time.Second,
+		limiter:       rate.NewLimiter(rate.Limit(1.0), 1), // Default: 1 request per second
+		maxRetries:    5,
+		retryDelay:    5 * time.Second,
+		fetchStrategy: FetchContents,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	transport := proxy.Transport(c.proxyURL)
+	if c.app != nil {
+		c.app.base = transport
+		c.client = github.NewClient(&http.Client{Transport: c.app})
+		c.app.client = c.client
+	} else {
+		c.client = github.NewClient(&http.Client{Transport: transport}).WithAuthToken(token)
+	}
+
 	return c
 }
 
-// NewClientFromEnv creates a new GitHub client using GITHUB_TOKEN environment variable
+// NewClientFromEnv creates a new GitHub client from the environment. GitHub
+// App auth is used when GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, and
+// GITHUB_APP_PRIVATE_KEY are set, falling back to the token in the file named
+// by GITHUB_TOKEN_FILE, then the GITHUB_TOKEN environment variable.
 func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	appAuth, err := appAuthFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if appAuth != nil {
+		return NewClient("", append([]ClientOption{appAuth}, opts...)...), nil
+	}
+
+	token, err := tokenFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(token, opts...), nil
+}
+
+// tokenFromEnv returns the token in the file named by GITHUB_TOKEN_FILE, which
+// takes precedence, or else GITHUB_TOKEN
+func tokenFromEnv() (string, error) {
+	if path := os.Getenv("GITHUB_TOKEN_FILE"); path != "" {
+		token, err := TokenFromFile(path)
+		if err != nil {
+			return "", fmt.Errorf("GITHUB_TOKEN_FILE: %w", err)
+		}
+		return token, nil
+	}
+
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
-		return nil, fmt.Errorf("GITHUB_TOKEN environment variable is not set")
+		return "", fmt.Errorf("GITHUB_TOKEN environment variable is not set")
 	}
-	return NewClient(token, opts...), nil
+	return token, nil
 }
 
 // progress reports progress if a callback is set
@@ -85,7 +172,7 @@ func (c *Client) progress(format string, args ...interface{}) {
 	}
 }
 
-// wait waits for rate limiter and handles retries
+// wait waits for the rate limiter
 func (c *Client) wait(ctx context.Context) error {
 	return c.limiter.Wait(ctx)
 }
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient creates a client that talks to a stub GitHub API served by handler
+func newTestClient(t *testing.T, handler http.Handler, opts ...ClientOption) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]ClientOption{WithRateLimit(1000), WithRetryDelay(time.Millisecond)}, opts...)
+	c := NewClient("test-token", opts...)
+
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse test server URL: %v", err)
+	}
+	c.client.BaseURL = baseURL
+
+	return c
+}
+
+func TestNewClient_UsesProxy(t *testing.T) {
+	var proxied string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`[]`))
+	}))
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+
+	c := NewClient("test-token", WithProxy(proxyURL), WithRateLimit(1000))
+	c.client.BaseURL, _ = url.Parse("http://api.github.test/")
+
+	if _, err := c.ListOrgRepos(t.Context(), "test-org"); err != nil {
+		t.Fatalf("ListOrgRepos failed: %v", err)
+	}
+	if !strings.HasPrefix(proxied, "http://api.github.test/orgs/test-org/repos") {
+		t.Errorf("expected the proxy to receive the API request, got %q", proxied)
+	}
+}
+
+func TestClient_CountsRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "repo-a", "full_name": "test-org/repo-a"}]`))
+	})
+	c := newTestClient(t, mux)
+
+	if _, err := c.ListOrgRepos(t.Context(), "test-org"); err != nil {
+		t.Fatalf("ListOrgRepos failed: %v", err)
+	}
+
+	if c.GetRequestsMade() != 1 {
+		t.Errorf("expected 1 request, got %d", c.GetRequestsMade())
+	}
+}
@@ -27,65 +27,197 @@ type WorkflowFile struct {
 // isPackageFile checks if a filename is a package manifest file
 func isPackageFile(filename string) bool {
 	switch filename {
-	case "package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml":
+	case "package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock", "bun.lockb":
 		return true
 	default:
 		return false
 	}
 }
 
-// findPackageFilePaths extracts package file paths from a git tree
-func findPackageFilePaths(tree *github.Tree) []string {
-	var paths []string
-	for _, entry := range tree.Entries {
+// findPackageFileEntries extracts package file blobs from a git tree,
+// skipping packages committed under node_modules
+func findPackageFileEntries(tree *Tree) []*github.TreeEntry {
+	var entries []*github.TreeEntry
+	for _, entry := range tree.entries {
 		if entry.Type == nil || *entry.Type != "blob" || entry.Path == nil {
 			continue
 		}
-		if isPackageFile(path.Base(*entry.Path)) {
-			paths = append(paths, *entry.Path)
+		if isPackageFile(path.Base(*entry.Path)) && !inNodeModules(*entry.Path) {
+			entries = append(entries, entry)
 		}
 	}
-	return paths
+	return entries
 }
 
-// FindPackageFiles finds all package.json and package-lock.json files in a repository
-func (c *Client) FindPackageFiles(ctx context.Context, repo *Repository) ([]*PackageFile, error) {
+// inNodeModules checks if a repository path is inside a node_modules directory
+func inNodeModules(filePath string) bool {
+	return strings.Contains("/"+filePath+"/", "/node_modules/")
+}
+
+// Tree is the recursive listing of a repository's scanned ref. It is listed
+// once per repository and shared by every check that looks for files in it.
+type Tree struct {
+	repo      *Repository
+	entries   []*github.TreeEntry
+	truncated bool // GitHub returned only part of the listing
+}
+
+// ListTree lists the tree of the repository's ScanRef. It returns a
+// *NotScannableError for repositories that are disabled, empty, or have no
+// default branch or ref.
+func (c *Client) ListTree(ctx context.Context, repo *Repository) (*Tree, error) {
 	if err := c.wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait: %w", err)
 	}
 
-	c.progress("🔍 Scanning %s for package files...", repo.FullName)
-
-	tree, resp, err := c.client.Git.GetTree(ctx, repo.Owner, repo.Name, repo.DefaultBranch, true)
+	tree, resp, err := withRetry(ctx, c, func() (*github.Tree, *github.Response, error) {
+		return c.client.Git.GetTree(ctx, repo.Owner, repo.Name, repo.ScanRef(), true)
+	})
 	if err != nil {
-		if resp != nil && (resp.StatusCode == 409 || resp.StatusCode == 404) {
-			c.progress("⚠️  Skipping %s (empty or no default branch)", repo.FullName)
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get tree for %s: %w", repo.FullName, err)
+		return nil, classifyTreeError(repo, resp, err)
 	}
 	c.handleRateLimit(resp)
 
-	packageFilePaths := findPackageFilePaths(tree)
-	if len(packageFilePaths) == 0 {
+	return &Tree{repo: repo, entries: tree.Entries, truncated: tree.GetTruncated()}, nil
+}
+
+// FindPackageFiles finds all package.json and package-lock.json files in a repository.
+// Files are read from the repository's ScanRef. It returns a *NotScannableError
+// for repositories that are disabled, empty, or have no default branch or ref.
+func (c *Client) FindPackageFiles(ctx context.Context, repo *Repository) ([]*PackageFile, error) {
+	tree, err := c.ListTree(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return c.FindPackageFilesInTree(ctx, tree)
+}
+
+// FindPackageFilesInTree fetches the package files in a listed tree. A
+// truncated tree is walked directory by directory to find them all.
+func (c *Client) FindPackageFilesInTree(ctx context.Context, tree *Tree) ([]*PackageFile, error) {
+	repo := tree.repo
+	c.progress("🔍 Scanning %s for package files...", repo.FullName)
+
+	entries := findPackageFileEntries(tree)
+	if tree.truncated {
+		c.progress("⚠️  Tree of %s is too large to list at once; walking its directories", repo.FullName)
+		var err error
+		if entries, err = c.walkPackageFileEntries(ctx, repo, ""); err != nil {
+			return nil, err
+		}
+	}
+	if len(entries) == 0 {
 		c.progress("📭 No package files found in %s", repo.FullName)
 		return nil, nil
 	}
 
-	c.progress("📦 Found %d package file(s) in %s", len(packageFilePaths), repo.FullName)
+	c.progress("📦 Found %d package file(s) in %s", len(entries), repo.FullName)
 
-	return c.fetchPackageFileContents(ctx, repo, packageFilePaths)
+	return c.fetchPackageFiles(ctx, repo, entries)
 }
 
-// fetchPackageFileContents fetches content for multiple package files
-func (c *Client) fetchPackageFileContents(ctx context.Context, repo *Repository, paths []string) ([]*PackageFile, error) {
+// walkPackageFileEntries lists the package files under dir with the contents
+// API, one request per directory. It is the fallback for trees too large for
+// a recursive tree request to return in full. node_modules is skipped.
+func (c *Client) walkPackageFileEntries(ctx context.Context, repo *Repository, dir string) ([]*github.TreeEntry, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	listing, resp, err := withRetry(ctx, c, func() ([]*github.RepositoryContent, *github.Response, error) {
+		_, listing, resp, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, dir, &github.RepositoryContentGetOptions{
+			Ref: repo.ScanRef(),
+		})
+		return listing, resp, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s/%s: %w", repo.FullName, dir, err)
+	}
+	c.handleRateLimit(resp)
+
+	var entries []*github.TreeEntry
+	for _, item := range listing {
+		switch {
+		case item.GetType() == "dir" && item.GetName() != "node_modules":
+			nested, err := c.walkPackageFileEntries(ctx, repo, item.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, nested...)
+		case item.GetType() == "file" && isPackageFile(item.GetName()):
+			entries = append(entries, &github.TreeEntry{Path: item.Path, SHA: item.SHA, Type: github.String("blob")})
+		}
+	}
+	return entries, nil
+}
+
+// fetchPackageFiles downloads the package files found in the tree with the
+// client's fetch strategy, reusing blobs already in the blob cache
+func (c *Client) fetchPackageFiles(ctx context.Context, repo *Repository, entries []*github.TreeEntry) ([]*PackageFile, error) {
+	var files []*PackageFile
+	for _, entry := range entries {
+		content, ok := c.cachedBlob(entry.GetSHA())
+		if ok {
+			c.progress("♻️  Reusing cached %s/%s", repo.FullName, entry.GetPath())
+		} else {
+			if err := c.wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit wait: %w", err)
+			}
+
+			var err error
+			if content, err = c.fetchPackageFile(ctx, repo, entry); err != nil {
+				c.progress("⚠️  Failed to fetch %s/%s: %v", repo.FullName, entry.GetPath(), err)
+				continue
+			}
+			c.cacheBlob(entry.GetSHA(), content)
+		}
+
+		files = append(files, &PackageFile{
+			Path:     entry.GetPath(),
+			Content:  content,
+			RepoName: repo.FullName,
+		})
+	}
+	return files, nil
+}
+
+// fetchPackageFile downloads a package file by path via the contents API, or
+// by blob SHA with FetchBlobs, avoiding the path lookup and its 1 MB size limit
+func (c *Client) fetchPackageFile(ctx context.Context, repo *Repository, entry *github.TreeEntry) (string, error) {
+	if c.fetchStrategy == FetchBlobs {
+		return c.getBlobContent(ctx, repo, entry.GetSHA())
+	}
+	return c.getFileContent(ctx, repo, repo.ScanRef(), entry.GetPath())
+}
+
+// cachedBlob returns the content of a blob from the blob cache, if enabled
+func (c *Client) cachedBlob(sha string) (string, bool) {
+	if c.blobCache == nil || sha == "" {
+		return "", false
+	}
+	return c.blobCache.Get(sha)
+}
+
+// cacheBlob stores a fetched blob in the blob cache, if enabled. Failing to
+// persist it is reported but does not fail the fetch.
+func (c *Client) cacheBlob(sha, content string) {
+	if c.blobCache == nil || sha == "" {
+		return
+	}
+	if err := c.blobCache.Put(sha, content); err != nil {
+		c.progress("⚠️  %v", err)
+	}
+}
+
+// fetchPackageFileContents fetches content for multiple package files at the given ref
+func (c *Client) fetchPackageFileContents(ctx context.Context, repo *Repository, ref string, paths []string) ([]*PackageFile, error) {
 	var files []*PackageFile
 	for _, filePath := range paths {
 		if err := c.wait(ctx); err != nil {
 			return nil, fmt.Errorf("rate limit wait: %w", err)
 		}
 
-		content, err := c.getFileContent(ctx, repo, filePath)
+		content, err := c.getFileContent(ctx, repo, ref, filePath)
 		if err != nil {
 			c.progress("⚠️  Failed to fetch %s/%s: %v", repo.FullName, filePath, err)
 			continue
@@ -100,67 +232,171 @@ func (c *Client) fetchPackageFileContents(ctx context.Context, repo *Repository,
 	return files, nil
 }
 
-// FindMaliciousWorkflows finds the discussion.yaml workflow file if it exists
-func (c *Client) FindMaliciousWorkflows(ctx context.Context, repo *Repository) ([]*WorkflowFile, error) {
+// TreeFile is a file found in a repository tree. Content is only fetched for
+// files selected by the fetch function given to FindTreeFiles.
+type TreeFile struct {
+	Path     string
+	Size     int
+	Content  string
+	Fetched  bool // Whether Content was downloaded
+	RepoName string
+}
+
+// FindTreeFiles selects files from a listed tree with match. Only the files
+// selected by fetch are downloaded; the rest are listed by path and size, so
+// presence checks cost no more than the tree listing.
+func (c *Client) FindTreeFiles(ctx context.Context, tree *Tree, match func(filePath string) bool, fetch func(file *TreeFile) bool) []*TreeFile {
+	var files []*TreeFile
+	for _, entry := range tree.entries {
+		if entry.GetType() != "blob" || !match(entry.GetPath()) {
+			continue
+		}
+		file := &TreeFile{Path: entry.GetPath(), Size: entry.GetSize(), RepoName: tree.repo.FullName}
+		if fetch(file) {
+			c.fetchTreeFileContent(ctx, tree.repo, entry.GetSHA(), file)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// fetchTreeFileContent downloads a tree file by blob SHA. Failures leave the
+// file unfetched rather than failing the lookup. Tree files may hold secrets,
+// so they are never put in the blob cache.
+func (c *Client) fetchTreeFileContent(ctx context.Context, repo *Repository, sha string, file *TreeFile) {
 	if err := c.wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait: %w", err)
+		return
 	}
 
-	// Get the tree recursively
-	tree, resp, err := c.client.Git.GetTree(ctx, repo.Owner, repo.Name, repo.DefaultBranch, true)
+	content, err := c.getBlobContent(ctx, repo, sha)
 	if err != nil {
-		// Check if it's a 409 conflict (empty repo) or 404 (no default branch)
-		if resp != nil && (resp.StatusCode == 409 || resp.StatusCode == 404) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get tree for %s: %w", repo.FullName, err)
+		c.progress("⚠️  Failed to fetch %s/%s: %v", repo.FullName, file.Path, err)
+		return
+	}
+
+	file.Content = content
+	file.Fetched = true
+}
+
+// getBlobContent fetches the raw content of a blob by SHA
+func (c *Client) getBlobContent(ctx context.Context, repo *Repository, sha string) (string, error) {
+	content, resp, err := withRetry(ctx, c, func() ([]byte, *github.Response, error) {
+		return c.client.Git.GetBlobRaw(ctx, repo.Owner, repo.Name, sha)
+	})
+	if err != nil {
+		return "", err
 	}
 	c.handleRateLimit(resp)
+	return string(content), nil
+}
+
+// MaliciousWorkflowPath is the workflow file the worm adds to repositories
+const MaliciousWorkflowPath = ".github/workflows/discussion.yaml"
+
+// FindMaliciousWorkflows finds the discussion.yaml workflow file in a listed tree, if it exists
+func (c *Client) FindMaliciousWorkflows(ctx context.Context, tree *Tree) ([]*WorkflowFile, error) {
+	return c.findWorkflows(ctx, tree, func(filePath string) bool {
+		return filePath == MaliciousWorkflowPath
+	})
+}
+
+// FindWorkflowFiles fetches every GitHub Actions workflow in a listed tree.
+// It costs one request per workflow.
+func (c *Client) FindWorkflowFiles(ctx context.Context, tree *Tree) ([]*WorkflowFile, error) {
+	return c.findWorkflows(ctx, tree, isWorkflowFile)
+}
 
-	// Look for .github/workflows/discussion.yaml
-	const targetPath = ".github/workflows/discussion.yaml"
-	var found bool
-	for _, entry := range tree.Entries {
-		if entry.Type == nil || *entry.Type != "blob" {
+// findWorkflows fetches the workflow files in a listed tree selected by match
+func (c *Client) findWorkflows(ctx context.Context, tree *Tree, match func(filePath string) bool) ([]*WorkflowFile, error) {
+	repo := tree.repo
+	var workflows []*WorkflowFile
+	for _, entry := range tree.entries {
+		if entry.Type == nil || *entry.Type != "blob" || entry.Path == nil || !match(*entry.Path) {
 			continue
 		}
-		if entry.Path == nil {
-			continue
+
+		if err := c.wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait: %w", err)
 		}
-		if *entry.Path == targetPath {
-			found = true
-			break
+
+		content, err := c.getFileContent(ctx, repo, repo.ScanRef(), *entry.Path)
+		if err != nil {
+			c.progress("⚠️  Failed to fetch %s/%s: %v", repo.FullName, *entry.Path, err)
+			continue
 		}
+
+		workflows = append(workflows, &WorkflowFile{
+			Path:     *entry.Path,
+			Content:  content,
+			RepoName: repo.FullName,
+		})
 	}
 
-	if !found {
-		return nil, nil
+	return workflows, nil
+}
+
+// BranchDiff holds the files a branch changed relative to the default branch
+type BranchDiff struct {
+	Branch        string
+	ChangedFiles  []string
+	Authors       []string // Logins that authored or committed the branch's commits
+	PackageFiles  []*PackageFile
+	WorkflowFiles []*WorkflowFile
+}
+
+// isWorkflowFile checks if a path is a GitHub Actions workflow file
+func isWorkflowFile(filePath string) bool {
+	ext := path.Ext(filePath)
+	return path.Dir(filePath) == ".github/workflows" && (ext == ".yml" || ext == ".yaml")
+}
+
+// InspectBranch compares a branch against the default branch and fetches the
+// changed package files and workflows as they exist on the branch
+func (c *Client) InspectBranch(ctx context.Context, repo *Repository, branch string) (*BranchDiff, error) {
+	comparison, err := c.compareWithDefaultBranch(ctx, repo, branch)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch content
-	if err := c.wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait: %w", err)
+	diff := &BranchDiff{Branch: branch, ChangedFiles: comparison.changed, Authors: comparison.authors}
+
+	var packagePaths []string
+	for _, filePath := range comparison.changed {
+		switch {
+		case isPackageFile(path.Base(filePath)):
+			packagePaths = append(packagePaths, filePath)
+		case isWorkflowFile(filePath):
+			if err := c.wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit wait: %w", err)
+			}
+			content, err := c.getFileContent(ctx, repo, branch, filePath)
+			if err != nil {
+				c.progress("⚠️  Failed to fetch %s/%s@%s: %v", repo.FullName, filePath, branch, err)
+				continue
+			}
+			diff.WorkflowFiles = append(diff.WorkflowFiles, &WorkflowFile{
+				Path:     filePath,
+				Content:  content,
+				RepoName: repo.FullName,
+			})
+		}
 	}
 
-	content, err := c.getFileContent(ctx, repo, targetPath)
+	diff.PackageFiles, err = c.fetchPackageFileContents(ctx, repo, branch, packagePaths)
 	if err != nil {
-		c.progress("⚠️  Failed to fetch %s/%s: %v", repo.FullName, targetPath, err)
-		return nil, nil
+		return nil, err
 	}
 
-	return []*WorkflowFile{
-		{
-			Path:     targetPath,
-			Content:  content,
-			RepoName: repo.FullName,
-		},
-	}, nil
+	return diff, nil
 }
 
-// getFileContent fetches the content of a file from the repository
-func (c *Client) getFileContent(ctx context.Context, repo *Repository, filePath string) (string, error) {
-	fileContent, _, resp, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, filePath, &github.RepositoryContentGetOptions{
-		Ref: repo.DefaultBranch,
+// getFileContent fetches the content of a file from the repository at the given ref
+func (c *Client) getFileContent(ctx context.Context, repo *Repository, ref, filePath string) (string, error) {
+	fileContent, resp, err := withRetry(ctx, c, func() (*github.RepositoryContent, *github.Response, error) {
+		fileContent, _, resp, err := c.client.Repositories.GetContents(ctx, repo.Owner, repo.Name, filePath, &github.RepositoryContentGetOptions{
+			Ref: ref,
+		})
+		return fileContent, resp, err
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to get content: %w", err)
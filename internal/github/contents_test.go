@@ -0,0 +1,311 @@
+package github
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// stubRepoTree serves a recursive tree with two package files and a non-package file
+func stubRepoTree(mux *http.ServeMux) {
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") == "" {
+			http.Error(w, "expected recursive listing", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{
+			"sha": "root",
+			"truncated": false,
+			"tree": [
+				{"path": "package.json", "type": "blob", "sha": "sha-manifest"},
+				{"path": "README.md", "type": "blob", "sha": "sha-readme"},
+				{"path": "packages/app", "type": "tree", "sha": "sha-dir"},
+				{"path": "packages/app/package-lock.json", "type": "blob", "sha": "sha-lock"}
+			]
+		}`))
+	})
+}
+
+var stubRepoFiles = map[string]string{
+	"package.json":                   `{"name": "test-muaddib-root"}`,
+	"packages/app/package-lock.json": `{"lockfileVersion": 3}`,
+}
+
+func TestFindPackageFiles_ContentsStrategy(t *testing.T) {
+	mux := http.NewServeMux()
+	stubRepoTree(mux)
+	for filePath, content := range stubRepoFiles {
+		encoded := base64.StdEncoding.EncodeToString([]byte(content))
+		mux.HandleFunc("/repos/test-org/test-repo/contents/"+filePath, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, encoded)
+		})
+	}
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	files, err := c.FindPackageFiles(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("FindPackageFiles failed: %v", err)
+	}
+
+	assertStubPackageFiles(t, files)
+	if c.GetRequestsMade() != 3 {
+		t.Errorf("expected 1 tree + 2 contents requests, got %d", c.GetRequestsMade())
+	}
+}
+
+func TestFindPackageFiles_ReadsRef(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/v1.2.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha": "root", "truncated": false, "tree": [{"path": "package.json", "type": "blob", "sha": "sha-manifest"}]}`))
+	})
+	encoded := base64.StdEncoding.EncodeToString([]byte(stubRepoFiles["package.json"]))
+	mux.HandleFunc("/repos/test-org/test-repo/contents/package.json", func(w http.ResponseWriter, r *http.Request) {
+		if ref := r.URL.Query().Get("ref"); ref != "v1.2.0" {
+			t.Errorf("expected the content to be read at v1.2.0, got ref %q", ref)
+		}
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, encoded)
+	})
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main", Ref: "v1.2.0"}
+
+	files, err := c.FindPackageFiles(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("FindPackageFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Content != stubRepoFiles["package.json"] {
+		t.Errorf("expected package.json at v1.2.0, got %+v", files)
+	}
+}
+
+func TestFindPackageFiles_BlobsStrategy(t *testing.T) {
+	mux := http.NewServeMux()
+	stubRepoTree(mux)
+	blobs := map[string]string{
+		"sha-manifest": stubRepoFiles["package.json"],
+		"sha-lock":     stubRepoFiles["packages/app/package-lock.json"],
+	}
+	for sha, content := range blobs {
+		mux.HandleFunc("/repos/test-org/test-repo/git/blobs/"+sha, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(content))
+		})
+	}
+	mux.HandleFunc("/repos/test-org/test-repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected contents request for %s", r.URL.Path)
+		http.NotFound(w, r)
+	})
+	c := newTestClient(t, mux, WithFetchStrategy(FetchBlobs))
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	files, err := c.FindPackageFiles(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("FindPackageFiles failed: %v", err)
+	}
+
+	assertStubPackageFiles(t, files)
+	if c.GetRequestsMade() != 3 {
+		t.Errorf("expected 1 tree + 2 blob requests, got %d", c.GetRequestsMade())
+	}
+}
+
+func TestFindPackageFiles_WalksContentsWhenTreeIsTruncated(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha": "root", "truncated": true, "tree": [{"path": "package.json", "type": "blob", "sha": "sha-manifest"}]}`))
+	})
+	listings := map[string]string{
+		"": `[
+			{"type": "file", "name": "package.json", "path": "package.json", "sha": "sha-manifest"},
+			{"type": "file", "name": "README.md", "path": "README.md", "sha": "sha-readme"},
+			{"type": "dir", "name": "node_modules", "path": "node_modules", "sha": "sha-vendored"},
+			{"type": "dir", "name": "packages", "path": "packages", "sha": "sha-packages"}
+		]`,
+		"packages":     `[{"type": "dir", "name": "app", "path": "packages/app", "sha": "sha-dir"}]`,
+		"packages/app": `[{"type": "file", "name": "package-lock.json", "path": "packages/app/package-lock.json", "sha": "sha-lock"}]`,
+	}
+	for dir, listing := range listings {
+		mux.HandleFunc("/repos/test-org/test-repo/contents/"+dir, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repos/test-org/test-repo/contents/"+dir {
+				t.Errorf("unexpected contents request for %s", r.URL.Path)
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(listing))
+		})
+	}
+	blobs := map[string]string{
+		"sha-manifest": stubRepoFiles["package.json"],
+		"sha-lock":     stubRepoFiles["packages/app/package-lock.json"],
+	}
+	for sha, content := range blobs {
+		mux.HandleFunc("/repos/test-org/test-repo/git/blobs/"+sha, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(content))
+		})
+	}
+	c := newTestClient(t, mux, WithFetchStrategy(FetchBlobs))
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	files, err := c.FindPackageFiles(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("FindPackageFiles failed: %v", err)
+	}
+
+	assertStubPackageFiles(t, files)
+	if c.GetRequestsMade() != 6 {
+		t.Errorf("expected 1 tree + 3 directory + 2 blob requests, got %d", c.GetRequestsMade())
+	}
+}
+
+// assertStubPackageFiles checks that exactly the stub package files were fetched
+func assertStubPackageFiles(t *testing.T, files []*PackageFile) {
+	t.Helper()
+
+	if len(files) != len(stubRepoFiles) {
+		t.Fatalf("expected %d package files, got %d", len(stubRepoFiles), len(files))
+	}
+	for _, f := range files {
+		if f.Content != stubRepoFiles[f.Path] {
+			t.Errorf("unexpected content for %s: %q", f.Path, f.Content)
+		}
+		if f.RepoName != "test-org/test-repo" {
+			t.Errorf("expected repo name test-org/test-repo, got %q", f.RepoName)
+		}
+	}
+}
+
+func TestParseFetchStrategy(t *testing.T) {
+	for _, name := range []string{"contents", "blobs"} {
+		if _, err := ParseFetchStrategy(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	if _, err := ParseFetchStrategy("graphql"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestFindPackageFileEntries_AllManifestNames(t *testing.T) {
+	blob := "blob"
+	paths := []string{
+		"package.json",
+		"package-lock.json",
+		"apps/web/npm-shrinkwrap.json",
+		"apps/web/yarn.lock",
+		"apps/api/pnpm-lock.yaml",
+		"apps/api/bun.lockb",
+		"README.md",
+		"yarn.lock.bak",
+		"node_modules/test-muaddib-vendored/package.json",
+		"apps/web/node_modules/test-muaddib-vendored/package-lock.json",
+	}
+	tree := &Tree{}
+	for i := range paths {
+		tree.entries = append(tree.entries, &github.TreeEntry{Path: &paths[i], Type: &blob})
+	}
+
+	entries := findPackageFileEntries(tree)
+
+	if len(entries) != 6 {
+		t.Fatalf("expected 6 package files, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.GetPath() != paths[i] {
+			t.Errorf("entry %d: expected %s, got %s", i, paths[i], entry.GetPath())
+		}
+	}
+}
+
+func TestFindWorkflowFiles_FetchesEveryWorkflow(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"sha": "root",
+			"tree": [
+				{"path": ".github/workflows/discussion.yaml", "type": "blob", "sha": "sha-a"},
+				{"path": ".github/workflows/nightly.yml", "type": "blob", "sha": "sha-b"},
+				{"path": ".github/dependabot.yml", "type": "blob", "sha": "sha-c"}
+			]
+		}`))
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		encoded := base64.StdEncoding.EncodeToString([]byte("on: push"))
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, encoded)
+	})
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+	c := newTestClient(t, mux)
+	tree, err := c.ListTree(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("ListTree failed: %v", err)
+	}
+
+	all, err := c.FindWorkflowFiles(t.Context(), tree)
+	if err != nil {
+		t.Fatalf("FindWorkflowFiles failed: %v", err)
+	}
+	if len(all) != 2 || all[0].Path != MaliciousWorkflowPath || all[1].Path != ".github/workflows/nightly.yml" {
+		t.Errorf("expected both workflows, got %v", workflowPaths(all))
+	}
+
+	worm, err := c.FindMaliciousWorkflows(t.Context(), tree)
+	if err != nil {
+		t.Fatalf("FindMaliciousWorkflows failed: %v", err)
+	}
+	if len(worm) != 1 || worm[0].Path != MaliciousWorkflowPath {
+		t.Errorf("expected only %s, got %v", MaliciousWorkflowPath, workflowPaths(worm))
+	}
+}
+
+func workflowPaths(workflows []*WorkflowFile) []string {
+	var paths []string
+	for _, wf := range workflows {
+		paths = append(paths, wf.Path)
+	}
+	return paths
+}
+
+func TestFindTreeFiles_OnlyDownloadsSelectedFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"sha": "root",
+			"tree": [
+				{"path": ".npmrc", "type": "blob", "sha": "sha-small", "size": 40},
+				{"path": ".env", "type": "blob", "sha": "sha-large", "size": 50000},
+				{"path": "README.md", "type": "blob", "sha": "sha-readme", "size": 10}
+			]
+		}`))
+	})
+	mux.HandleFunc("/repos/test-org/test-repo/git/blobs/sha-small", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("registry=https://registry.npmjs.org/\n"))
+	})
+	cache := NewBlobCache(DefaultBlobCacheSize, "")
+	c := newTestClient(t, mux, WithBlobCache(cache))
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	tree, err := c.ListTree(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("ListTree failed: %v", err)
+	}
+	files := c.FindTreeFiles(t.Context(), tree,
+		func(p string) bool { return p != "README.md" },
+		func(f *TreeFile) bool { return f.Size <= 1024 })
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 matching files, got %d", len(files))
+	}
+	if !files[0].Fetched || files[0].Content == "" {
+		t.Errorf("expected the small file to be downloaded, got %+v", files[0])
+	}
+	if files[1].Fetched || files[1].Size != 50000 {
+		t.Errorf("expected the large file to be listed without downloading, got %+v", files[1])
+	}
+	if c.GetRequestsMade() != 2 {
+		t.Errorf("expected 1 tree + 1 blob request, got %d", c.GetRequestsMade())
+	}
+	if _, ok := cache.Get("sha-small"); ok {
+		t.Error("expected files that may hold secrets not to be cached")
+	}
+}
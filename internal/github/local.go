@@ -0,0 +1,62 @@
+package github
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FindLocalPackageFiles walks a local checkout for package files. Installed
+// packages under node_modules and the .git directory are skipped. Each file's
+// RepoName is the name of the root directory and its Path is relative to the
+// root, with forward slashes as in a repository tree.
+func FindLocalPackageFiles(root string) ([]*PackageFile, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	repoName := filepath.Base(abs)
+	var files []*PackageFile
+	err = filepath.WalkDir(abs, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if filePath != abs && (d.Name() == "node_modules" || d.Name() == ".git") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isPackageFile(d.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(abs, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, &PackageFile{
+			Path:     filepath.ToSlash(rel),
+			Content:  string(content),
+			RepoName: repoName,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return files, nil
+}
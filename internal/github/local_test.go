@@ -0,0 +1,67 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestFindLocalPackageFiles(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "test-monorepo")
+	for _, file := range []string{
+		"package.json",
+		"package-lock.json",
+		"apps/web/package.json",
+		"apps/web/yarn.lock",
+		"packages/ui/pnpm-lock.yaml",
+		"packages/ui/README.md",
+		"node_modules/test-muaddib-dep/package.json",
+		"apps/web/node_modules/test-muaddib-dep/package.json",
+		".git/package.json",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	files, err := FindLocalPackageFiles(root)
+	if err != nil {
+		t.Fatalf("FindLocalPackageFiles failed: %v", err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		if file.RepoName != "test-monorepo" {
+			t.Errorf("expected repo name test-monorepo, got %s", file.RepoName)
+		}
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+
+	want := []string{"apps/web/package.json", "apps/web/yarn.lock", "package-lock.json", "package.json", "packages/ui/pnpm-lock.yaml"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, paths)
+			break
+		}
+	}
+}
+
+func TestFindLocalPackageFiles_NotADirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := FindLocalPackageFiles(path); err == nil {
+		t.Error("expected an error for a file path")
+	}
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RateLimitStatus is the core API rate limit of the authenticated token
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// AuthenticatedUser returns the login the token authenticates as, checking
+// that the token is valid with a single cheap request. Installation tokens
+// have no user, so with app auth the app and installation are named instead,
+// once an installation token has been created.
+func (c *Client) AuthenticatedUser(ctx context.Context) (string, error) {
+	if err := c.wait(ctx); err != nil {
+		return "", fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	if app, ok := c.client.Client().Transport.(*appTransport); ok {
+		if _, err := app.installationToken(ctx); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("GitHub App %d (installation %d)", app.appID, app.installationID), nil
+	}
+
+	user, resp, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the authenticated user: %w", err)
+	}
+	c.handleRateLimit(resp)
+
+	return user.GetLogin(), nil
+}
+
+// RateLimit returns the token's core API rate limit. Checking it does not
+// count against the limit.
+func (c *Client) RateLimit(ctx context.Context) (*RateLimitStatus, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	limits, _, err := c.client.RateLimit.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the rate limit: %w", err)
+	}
+	core := limits.GetCore()
+	if core == nil {
+		return nil, fmt.Errorf("rate limit response has no core limit")
+	}
+
+	return &RateLimitStatus{Limit: core.Limit, Remaining: core.Remaining, Reset: core.Reset.Time}, nil
+}
@@ -0,0 +1,45 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_AuthenticatedUserAndRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "test-user"}`)
+	})
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources": {"core": {"limit": 5000, "remaining": 4321, "reset": 1700000000}}}`)
+	})
+	client := newTestClient(t, mux)
+
+	login, err := client.AuthenticatedUser(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if login != "test-user" {
+		t.Errorf("expected login test-user, got %q", login)
+	}
+
+	status, err := client.RateLimit(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Limit != 5000 || status.Remaining != 4321 || status.Reset.Unix() != 1700000000 {
+		t.Errorf("unexpected rate limit status: %+v", status)
+	}
+}
+
+func TestClient_AuthenticatedUser_BadCredentials(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "Bad credentials"}`)
+	}))
+
+	if _, err := client.AuthenticatedUser(t.Context()); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+}
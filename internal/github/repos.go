@@ -3,7 +3,9 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v67/github"
 )
@@ -16,7 +18,40 @@ type Repository struct {
 	Description   string
 	Private       bool
 	Archived      bool
+	Disabled      bool
+	Fork          bool
 	DefaultBranch string
+	Ref           string    // Branch, tag, or commit to scan instead of DefaultBranch, if set
+	Language      string    // Primary language detected by GitHub, if any
+	PushedAt      time.Time // When the repository was last pushed to; zero if GitHub did not say
+}
+
+// ScanRef returns the ref files are read from: Ref if set, else the default branch
+func (r *Repository) ScanRef() string {
+	if r.Ref != "" {
+		return r.Ref
+	}
+	return r.DefaultBranch
+}
+
+// CheckRef checks that a branch, tag, or commit exists in the repository. A
+// missing ref is reported as a *NotScannableError.
+func (c *Client) CheckRef(ctx context.Context, repo *Repository, ref string) error {
+	if err := c.wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	_, resp, err := withRetry(ctx, c, func() (string, *github.Response, error) {
+		return c.client.Repositories.GetCommitSHA1(ctx, repo.Owner, repo.Name, ref, "")
+	})
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return &NotScannableError{RepoName: repo.FullName, Reason: RefNotFoundReason(ref)}
+		}
+		return fmt.Errorf("failed to resolve %s in %s: %w", ref, repo.FullName, err)
+	}
+	c.handleRateLimit(resp)
+	return nil
 }
 
 // Branch represents a GitHub branch
@@ -40,10 +75,26 @@ func IsMaliciousMigrationRepo(repo *Repository) bool {
 		repo.Description == MaliciousRepoDescription
 }
 
+// RepoPageFunc receives each page of repositories as soon as it is fetched.
+// Returning an error stops the listing.
+type RepoPageFunc func(repos []*Repository) error
+
 // ListOrgRepos lists all repositories for an organization with pagination
 func (c *Client) ListOrgRepos(ctx context.Context, org string) ([]*Repository, error) {
 	var allRepos []*Repository
+	err := c.ListOrgRepoPages(ctx, org, func(repos []*Repository) error {
+		allRepos = append(allRepos, repos...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allRepos, nil
+}
 
+// ListOrgRepoPages lists an organization's repositories, handing each page to
+// fn before the next page is requested
+func (c *Client) ListOrgRepoPages(ctx context.Context, org string, fn RepoPageFunc) error {
 	opts := &github.RepositoryListByOrgOptions{
 		Type: "all",
 		ListOptions: github.ListOptions{
@@ -51,40 +102,81 @@ func (c *Client) ListOrgRepos(ctx context.Context, org string) ([]*Repository, e
 		},
 	}
 
-	page := 1
+	page, total := 1, 0
 	for {
 		if err := c.wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limit wait: %w", err)
+			return fmt.Errorf("rate limit wait: %w", err)
 		}
 
 		c.progress("📦 Fetching repositories for org '%s' (page %d)...", org, page)
 
-		repos, resp, err := c.client.Repositories.ListByOrg(ctx, org, opts)
+		repos, resp, err := withRetry(ctx, c, func() ([]*github.Repository, *github.Response, error) {
+			return c.client.Repositories.ListByOrg(ctx, org, opts)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list org repos: %w", err)
+			return fmt.Errorf("failed to list org repos: %w", err)
 		}
 		c.handleRateLimit(resp)
 
-		for _, repo := range repos {
-			allRepos = append(allRepos, convertRepo(repo))
-		}
+		total += len(repos)
+		c.progress("📦 Fetched %d repositories so far...", total)
 
-		c.progress("📦 Fetched %d repositories so far...", len(allRepos))
+		if err := fn(convertRepos(repos)); err != nil {
+			return err
+		}
 
 		if resp.NextPage == 0 {
-			break
+			return nil
 		}
 		opts.Page = resp.NextPage
 		page++
 	}
+}
+
+// GetRepo fetches the metadata of a single repository
+func (c *Client) GetRepo(ctx context.Context, owner, name string) (*Repository, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
 
-	return allRepos, nil
+	c.progress("📦 Fetching repository %s/%s...", owner, name)
+
+	repo, resp, err := withRetry(ctx, c, func() (*github.Repository, *github.Response, error) {
+		return c.client.Repositories.Get(ctx, owner, name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", owner, name, err)
+	}
+	c.handleRateLimit(resp)
+
+	return convertRepo(repo), nil
+}
+
+// SplitFullName splits an "owner/name" repository name
+func SplitFullName(fullName string) (owner, name string, err error) {
+	owner, name, found := strings.Cut(fullName, "/")
+	if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid repository %q (expected owner/name)", fullName)
+	}
+	return owner, name, nil
 }
 
 // ListUserRepos lists all repositories for a user with pagination
 func (c *Client) ListUserRepos(ctx context.Context, user string) ([]*Repository, error) {
 	var allRepos []*Repository
+	err := c.ListUserRepoPages(ctx, user, func(repos []*Repository) error {
+		allRepos = append(allRepos, repos...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allRepos, nil
+}
 
+// ListUserRepoPages lists a user's repositories, handing each page to fn
+// before the next page is requested
+func (c *Client) ListUserRepoPages(ctx context.Context, user string, fn RepoPageFunc) error {
 	opts := &github.RepositoryListByUserOptions{
 		Type: "owner", // Only repos owned by the user, not org repos they have access to
 		ListOptions: github.ListOptions{
@@ -92,34 +184,69 @@ func (c *Client) ListUserRepos(ctx context.Context, user string) ([]*Repository,
 		},
 	}
 
-	page := 1
+	page, total := 1, 0
 	for {
 		if err := c.wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limit wait: %w", err)
+			return fmt.Errorf("rate limit wait: %w", err)
 		}
 
 		c.progress("📦 Fetching repositories for user '%s' (page %d)...", user, page)
 
-		repos, resp, err := c.client.Repositories.ListByUser(ctx, user, opts)
+		repos, resp, err := withRetry(ctx, c, func() ([]*github.Repository, *github.Response, error) {
+			return c.client.Repositories.ListByUser(ctx, user, opts)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list user repos: %w", err)
+			return fmt.Errorf("failed to list user repos: %w", err)
 		}
 		c.handleRateLimit(resp)
 
-		for _, repo := range repos {
-			allRepos = append(allRepos, convertRepo(repo))
-		}
+		total += len(repos)
+		c.progress("📦 Fetched %d repositories so far...", total)
 
-		c.progress("📦 Fetched %d repositories so far...", len(allRepos))
+		if err := fn(convertRepos(repos)); err != nil {
+			return err
+		}
 
 		if resp.NextPage == 0 {
-			break
+			return nil
 		}
 		opts.Page = resp.NextPage
 		page++
 	}
+}
 
-	return allRepos, nil
+// StreamRepoPages runs a paged listing in the background and sends each page on
+// the returned channel as soon as it is fetched, so callers can scan while later
+// pages load. The channel holds one page, letting the listing run a page ahead.
+// The error channel receives the listing result after the page channel closes.
+func StreamRepoPages(ctx context.Context, list func(context.Context, RepoPageFunc) error) (<-chan []*Repository, <-chan error) {
+	pages := make(chan []*Repository, 1)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		err := list(ctx, func(repos []*Repository) error {
+			select {
+			case pages <- repos:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(pages)
+		errc <- err
+	}()
+
+	return pages, errc
+}
+
+// convertRepos converts a page of GitHub repositories to our Repository type
+func convertRepos(repos []*github.Repository) []*Repository {
+	out := make([]*Repository, 0, len(repos))
+	for _, repo := range repos {
+		out = append(out, convertRepo(repo))
+	}
+	return out
 }
 
 func convertRepo(repo *github.Repository) *Repository {
@@ -128,6 +255,10 @@ func convertRepo(repo *github.Repository) *Repository {
 		Name:     repo.GetName(),
 		Private:  repo.GetPrivate(),
 		Archived: repo.GetArchived(),
+		Disabled: repo.GetDisabled(),
+		Fork:     repo.GetFork(),
+		Language: repo.GetLanguage(),
+		PushedAt: repo.GetPushedAt().Time,
 	}
 
 	if repo.Owner != nil {
@@ -162,7 +293,9 @@ func (c *Client) ListRepoBranches(ctx context.Context, owner, repo string) ([]*B
 			return nil, fmt.Errorf("rate limit wait: %w", err)
 		}
 
-		branches, resp, err := c.client.Repositories.ListBranches(ctx, owner, repo, opts)
+		branches, resp, err := withRetry(ctx, c, func() ([]*github.Branch, *github.Response, error) {
+			return c.client.Repositories.ListBranches(ctx, owner, repo, opts)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list branches: %w", err)
 		}
@@ -200,3 +333,85 @@ func (c *Client) FindMaliciousBranches(ctx context.Context, repo *Repository) ([
 
 	return malicious, nil
 }
+
+// CompareWithDefaultBranch lists the files changed on a branch relative to the
+// repository's default branch. Removed files are omitted.
+func (c *Client) CompareWithDefaultBranch(ctx context.Context, repo *Repository, branch string) ([]string, error) {
+	comparison, err := c.compareWithDefaultBranch(ctx, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	return comparison.changed, nil
+}
+
+// branchComparison is what a branch changed relative to the default branch
+type branchComparison struct {
+	changed []string
+	authors []string // Logins of the authors and committers of the branch's commits
+}
+
+// compareWithDefaultBranch pages through the comparison of a branch with the
+// default branch, collecting the changed files and who made the commits
+func (c *Client) compareWithDefaultBranch(ctx context.Context, repo *Repository, branch string) (*branchComparison, error) {
+	result := &branchComparison{}
+	seen := make(map[string]bool)
+	addAuthor := func(login string) {
+		if login != "" && !seen[login] {
+			seen[login] = true
+			result.authors = append(result.authors, login)
+		}
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		if err := c.wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait: %w", err)
+		}
+
+		c.progress("🔀 Comparing %s against %s in %s...", branch, repo.DefaultBranch, repo.FullName)
+
+		comparison, resp, err := withRetry(ctx, c, func() (*github.CommitsComparison, *github.Response, error) {
+			return c.client.Repositories.CompareCommits(ctx, repo.Owner, repo.Name, repo.DefaultBranch, branch, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s with %s: %w", branch, repo.DefaultBranch, err)
+		}
+		c.handleRateLimit(resp)
+
+		for _, file := range comparison.Files {
+			if file.GetStatus() == "removed" {
+				continue
+			}
+			result.changed = append(result.changed, file.GetFilename())
+		}
+		for _, commit := range comparison.Commits {
+			addAuthor(commit.GetAuthor().GetLogin())
+			addAuthor(commit.GetCommitter().GetLogin())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// ActionsEnabled reports whether GitHub Actions is enabled for a repository.
+// Reading the setting requires admin access to the repository.
+func (c *Client) ActionsEnabled(ctx context.Context, repo *Repository) (bool, error) {
+	if err := c.wait(ctx); err != nil {
+		return false, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	permissions, resp, err := withRetry(ctx, c, func() (*github.ActionsPermissionsRepository, *github.Response, error) {
+		return c.client.Repositories.GetActionsPermissions(ctx, repo.Owner, repo.Name)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get actions permissions: %w", err)
+	}
+	c.handleRateLimit(resp)
+
+	return permissions.GetEnabled(), nil
+}
@@ -0,0 +1,321 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckRef(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/commits/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/test-org/test-repo/commits/v1.2.0":
+			w.Write([]byte("0123456789abcdef0123456789abcdef01234567"))
+		case "/repos/test-org/test-repo/commits/no-such-branch":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "No commit found for SHA: no-such-branch"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	if err := c.CheckRef(t.Context(), repo, "v1.2.0"); err != nil {
+		t.Errorf("expected v1.2.0 to exist, got %v", err)
+	}
+
+	err := c.CheckRef(t.Context(), repo, "no-such-branch")
+	var notScannable *NotScannableError
+	if !errors.As(err, &notScannable) || notScannable.Reason != "ref no-such-branch not found" {
+		t.Errorf("expected the missing ref to make the repository not scannable, got %v", err)
+	}
+
+	if err := c.CheckRef(t.Context(), repo, "broken"); err == nil || errors.As(err, &notScannable) {
+		t.Errorf("expected a real error for a server failure, got %v", err)
+	}
+}
+
+func TestCompareWithDefaultBranch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/compare/main...shai-hulud", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"files": [
+				{"filename": "package.json", "status": "modified"},
+				{"filename": "bundle.js", "status": "added"},
+				{"filename": "old.js", "status": "removed"}
+			]
+		}`))
+	})
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	changed, err := c.CompareWithDefaultBranch(t.Context(), repo, "shai-hulud")
+	if err != nil {
+		t.Fatalf("CompareWithDefaultBranch failed: %v", err)
+	}
+
+	if len(changed) != 2 || changed[0] != "package.json" || changed[1] != "bundle.js" {
+		t.Errorf("expected [package.json bundle.js], got %v", changed)
+	}
+}
+
+func TestInspectBranch_RecordsCommitAuthors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/compare/main...shai-hulud", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"commits": [
+				{"sha": "a1", "author": {"login": "test-muaddib-compromised"}, "committer": {"login": "web-flow"}},
+				{"sha": "b2", "author": {"login": "test-muaddib-compromised"}, "committer": {"login": "test-muaddib-compromised"}},
+				{"sha": "c3", "author": null, "committer": null}
+			],
+			"files": [{"filename": "README.md", "status": "modified"}]
+		}`))
+	})
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	diff, err := c.InspectBranch(t.Context(), repo, "shai-hulud")
+	if err != nil {
+		t.Fatalf("InspectBranch failed: %v", err)
+	}
+
+	if len(diff.Authors) != 2 || diff.Authors[0] != "test-muaddib-compromised" || diff.Authors[1] != "web-flow" {
+		t.Errorf("expected [test-muaddib-compromised web-flow], got %v", diff.Authors)
+	}
+}
+
+func TestInspectBranch_FetchesChangedFilesAtBranch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/compare/main...shai-hulud", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"files": [
+				{"filename": "package.json", "status": "modified"},
+				{"filename": ".github/workflows/discussion.yaml", "status": "added"},
+				{"filename": "bundle.js", "status": "added"}
+			]
+		}`))
+	})
+	contentHandler := func(content string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if ref := r.URL.Query().Get("ref"); ref != "shai-hulud" {
+				t.Errorf("expected ref shai-hulud, got %q", ref)
+			}
+			encoded := base64.StdEncoding.EncodeToString([]byte(content))
+			fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, encoded)
+		}
+	}
+	mux.HandleFunc("/repos/test-org/test-repo/contents/package.json", contentHandler(`{"scripts": {"postinstall": "node bundle.js"}}`))
+	mux.HandleFunc("/repos/test-org/test-repo/contents/.github/workflows/discussion.yaml", contentHandler("on: discussion"))
+
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+	diff, err := c.InspectBranch(t.Context(), repo, "shai-hulud")
+	if err != nil {
+		t.Fatalf("InspectBranch failed: %v", err)
+	}
+
+	if len(diff.ChangedFiles) != 3 {
+		t.Errorf("expected 3 changed files, got %d", len(diff.ChangedFiles))
+	}
+	if len(diff.PackageFiles) != 1 || diff.PackageFiles[0].Content != `{"scripts": {"postinstall": "node bundle.js"}}` {
+		t.Errorf("unexpected package files: %+v", diff.PackageFiles)
+	}
+	if len(diff.WorkflowFiles) != 1 || diff.WorkflowFiles[0].Content != "on: discussion" {
+		t.Errorf("unexpected workflow files: %+v", diff.WorkflowFiles)
+	}
+}
+
+func TestActionsEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"enabled", `{"enabled": true, "allowed_actions": "all"}`, true},
+		{"disabled", `{"enabled": false}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/test-org/test-repo/actions/permissions", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			})
+			c := newTestClient(t, mux)
+			repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo"}
+
+			enabled, err := c.ActionsEnabled(t.Context(), repo)
+			if err != nil {
+				t.Fatalf("ActionsEnabled failed: %v", err)
+			}
+			if enabled != tt.want {
+				t.Errorf("expected enabled=%v, got %v", tt.want, enabled)
+			}
+		})
+	}
+}
+
+// stubPagedOrgRepos serves the org listing as pages of two repositories each.
+// Before serving a page, it waits on the matching gate if one is given.
+func stubPagedOrgRepos(mux *http.ServeMux, pages int, gates map[int]chan struct{}) {
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if gate, ok := gates[page]; ok {
+			<-gate
+		}
+		if page < pages {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		fmt.Fprintf(w, `[{"name": "repo-%[1]d-a", "full_name": "test-org/repo-%[1]d-a"}, {"name": "repo-%[1]d-b", "full_name": "test-org/repo-%[1]d-b"}]`, page)
+	})
+}
+
+func TestStreamRepoPages_DeliversPagesBeforeListingCompletes(t *testing.T) {
+	// The third page is only served once the first page has been consumed, so
+	// collecting every page before handing any over would deadlock
+	firstConsumed := make(chan struct{})
+	mux := http.NewServeMux()
+	stubPagedOrgRepos(mux, 3, map[int]chan struct{}{3: firstConsumed})
+	c := newTestClient(t, mux)
+
+	pages, errc := StreamRepoPages(t.Context(), func(ctx context.Context, fn RepoPageFunc) error {
+		return c.ListOrgRepoPages(ctx, "test-org", fn)
+	})
+
+	var names []string
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case page, ok := <-pages:
+			if !ok {
+				done = true
+				break
+			}
+			if len(names) == 0 {
+				close(firstConsumed)
+			}
+			for _, repo := range page {
+				names = append(names, repo.FullName)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for streamed pages")
+		}
+	}
+
+	if err := <-errc; err != nil {
+		t.Fatalf("listing failed: %v", err)
+	}
+	want := []string{
+		"test-org/repo-1-a", "test-org/repo-1-b",
+		"test-org/repo-2-a", "test-org/repo-2-b",
+		"test-org/repo-3-a", "test-org/repo-3-b",
+	}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
+func TestStreamRepoPages_ReportsListingErrorAfterPartialPages(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		w.Write([]byte(`[{"name": "repo-a", "full_name": "test-org/repo-a"}]`))
+	})
+	c := newTestClient(t, mux, WithMaxRetries(0))
+
+	pages, errc := StreamRepoPages(t.Context(), func(ctx context.Context, fn RepoPageFunc) error {
+		return c.ListOrgRepoPages(ctx, "test-org", fn)
+	})
+
+	received := 0
+	for page := range pages {
+		received += len(page)
+	}
+
+	if received != 1 {
+		t.Errorf("expected the first page before the error, got %d repos", received)
+	}
+	if err := <-errc; err == nil {
+		t.Error("expected the listing error to be reported")
+	}
+}
+
+func TestListOrgRepos_CollectsAllPages(t *testing.T) {
+	mux := http.NewServeMux()
+	stubPagedOrgRepos(mux, 3, nil)
+	c := newTestClient(t, mux)
+
+	repos, err := c.ListOrgRepos(t.Context(), "test-org")
+	if err != nil {
+		t.Fatalf("ListOrgRepos failed: %v", err)
+	}
+	if len(repos) != 6 {
+		t.Errorf("expected 6 repos across 3 pages, got %d", len(repos))
+	}
+}
+
+func TestGetRepo_PopulatesMetadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"name": "test-repo",
+			"full_name": "test-org/test-repo",
+			"owner": {"login": "test-org"},
+			"description": "A test repository",
+			"default_branch": "develop",
+			"archived": true,
+			"fork": true,
+			"pushed_at": "2025-11-24T12:00:00Z"
+		}`))
+	})
+	c := newTestClient(t, mux)
+
+	repo, err := c.GetRepo(t.Context(), "test-org", "test-repo")
+	if err != nil {
+		t.Fatalf("GetRepo failed: %v", err)
+	}
+	if repo.FullName != "test-org/test-repo" || repo.Owner != "test-org" || repo.DefaultBranch != "develop" {
+		t.Errorf("unexpected repository identity: %+v", repo)
+	}
+	if !repo.Archived || !repo.Fork || repo.Description != "A test repository" {
+		t.Errorf("expected archived and fork flags and description, got %+v", repo)
+	}
+	if want := time.Date(2025, 11, 24, 12, 0, 0, 0, time.UTC); !repo.PushedAt.Equal(want) {
+		t.Errorf("expected pushed at %v, got %v", want, repo.PushedAt)
+	}
+}
+
+func TestGetRepo_NotFound(t *testing.T) {
+	c := newTestClient(t, http.NewServeMux())
+
+	if _, err := c.GetRepo(t.Context(), "test-org", "missing"); err == nil {
+		t.Error("expected an error for a missing repository")
+	}
+}
+
+func TestSplitFullName(t *testing.T) {
+	owner, name, err := SplitFullName("test-org/test-repo")
+	if err != nil || owner != "test-org" || name != "test-repo" {
+		t.Errorf("expected test-org and test-repo, got %q, %q, %v", owner, name, err)
+	}
+	for _, invalid := range []string{"test-repo", "/test-repo", "test-org/", "test-org/a/b"} {
+		if _, _, err := SplitFullName(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// WithRetryDelay sets the delay before the first retry of a failed request.
+// Each further retry waits twice as long as the one before.
+func WithRetryDelay(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryDelay = d
+	}
+}
+
+// secondaryRateLimitWait is how long to wait after a secondary rate limit
+// response that has no Retry-After header, as GitHub recommends
+const secondaryRateLimitWait = time.Minute
+
+// withRetry makes an API request, retrying server errors and network failures
+// up to the client's maxRetries times with exponential backoff from its
+// retryDelay. Secondary rate limit responses are retried after the
+// Retry-After duration instead. Other errors, such as 404s and permission
+// failures, are returned at once. The caller waits for the rate limiter before the first
+// attempt; withRetry waits again before each retry.
+func withRetry[T any](ctx context.Context, c *Client, request func() (T, *github.Response, error)) (T, *github.Response, error) {
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		result, resp, err := request()
+		if err == nil || attempt >= c.maxRetries || !isRetryable(ctx, resp, err) {
+			return result, resp, err
+		}
+
+		// The final response is counted by the caller; count the failed ones here
+		c.handleRateLimit(resp)
+		wait := delay
+		if retryAfter, ok := secondaryRateLimit(err); ok {
+			wait = retryAfter
+			c.progress("⏳ Secondary rate limit hit, waiting %v before retrying (%d/%d)...", wait, attempt+1, c.maxRetries)
+		} else {
+			c.progress("⚠️  Request failed (%v), retrying in %v (%d/%d)...", err, wait, attempt+1, c.maxRetries)
+			delay *= 2
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return result, resp, err
+		}
+		if err := c.wait(ctx); err != nil {
+			return result, resp, fmt.Errorf("rate limit wait: %w", err)
+		}
+	}
+}
+
+// secondaryRateLimit reports whether err is a secondary (abuse) rate limit
+// response, and how long GitHub asked us to wait before trying again
+func secondaryRateLimit(err error) (time.Duration, bool) {
+	var abuse *github.AbuseRateLimitError
+	if !errors.As(err, &abuse) {
+		return 0, false
+	}
+	if abuse.RetryAfter == nil {
+		return secondaryRateLimitWait, true
+	}
+	return abuse.GetRetryAfter(), true
+}
+
+// isRetryable checks if a failed request may succeed when retried: a 5xx
+// response, a secondary rate limit, or a network error with no response at all
+func isRetryable(ctx context.Context, resp *github.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if _, ok := secondaryRateLimit(err); ok {
+		return true
+	}
+	if resp == nil || resp.Response == nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// sleep waits for d, returning early if the context is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingHandler serves status for the first failures requests to the org
+// repos endpoint, then a single repository
+func failingHandler(status int, failures int32, calls *atomic.Int32) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`[{"name": "repo-a", "full_name": "test-org/repo-a"}]`))
+	})
+	return mux
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, failingHandler(http.StatusBadGateway, 2, &calls), WithMaxRetries(3))
+
+	repos, err := c.ListOrgRepos(t.Context(), "test-org")
+	if err != nil {
+		t.Fatalf("expected the request to succeed after retries, got %v", err)
+	}
+	if len(repos) != 1 || calls.Load() != 3 {
+		t.Errorf("expected 1 repo after 3 attempts, got %d repos after %d", len(repos), calls.Load())
+	}
+	if c.GetRequestsMade() != 3 {
+		t.Errorf("expected every attempt to be counted, got %d", c.GetRequestsMade())
+	}
+}
+
+func TestClient_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, failingHandler(http.StatusInternalServerError, 10, &calls), WithMaxRetries(2))
+
+	if _, err := c.ListOrgRepos(t.Context(), "test-org"); err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 1 attempt and 2 retries, got %d attempts", calls.Load())
+	}
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, failingHandler(http.StatusNotFound, 10, &calls), WithMaxRetries(3))
+
+	if _, err := c.ListOrgRepos(t.Context(), "test-org"); err == nil {
+		t.Fatal("expected a 404 to fail")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a 404 not to be retried, got %d attempts", calls.Load())
+	}
+}
+
+func TestClient_RetryBackoffRespectsCancellation(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, failingHandler(http.StatusServiceUnavailable, 10, &calls),
+		WithMaxRetries(3), WithRetryDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.ListOrgRepos(ctx, "test-org")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the backoff to end with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cancellation to interrupt the backoff, took %v", elapsed)
+	}
+}
+
+func TestClient_WaitsOutSecondaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+			return
+		}
+		w.Write([]byte(`[{"name": "repo-a", "full_name": "test-org/repo-a"}]`))
+	})
+	c := newTestClient(t, mux, WithMaxRetries(3), WithRetryDelay(time.Hour))
+
+	start := time.Now()
+	repos, err := c.ListOrgRepos(t.Context(), "test-org")
+	if err != nil {
+		t.Fatalf("expected the scan to resume after the secondary rate limit, got %v", err)
+	}
+	if len(repos) != 1 || calls.Load() != 2 {
+		t.Errorf("expected 1 repo after 2 attempts, got %d repos after %d", len(repos), calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > time.Minute {
+		t.Errorf("expected to wait for Retry-After rather than the backoff delay, took %v", elapsed)
+	}
+}
+
+func TestClient_SecondaryRateLimitWaitRespectsCancellation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/test-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+	})
+	c := newTestClient(t, mux, WithMaxRetries(3))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.ListOrgRepos(ctx, "test-org"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
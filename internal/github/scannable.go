@@ -0,0 +1,89 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// Reasons a repository cannot be scanned
+const (
+	ReasonDisabled        = "repository is disabled"
+	ReasonEmpty           = "repository is empty"
+	ReasonNoDefaultBranch = "no default branch"
+	ReasonArchived        = "repository is archived"
+	ReasonFork            = "repository is a fork"
+)
+
+// RefNotFoundReason is why a repository without the ref given to scan is skipped
+func RefNotFoundReason(ref string) string {
+	return fmt.Sprintf("ref %s not found", ref)
+}
+
+// NotScannableError reports a repository that cannot be scanned because of its
+// state, such as being disabled or having no commits. It is not a scan failure.
+type NotScannableError struct {
+	RepoName string
+	Reason   string
+}
+
+func (e *NotScannableError) Error() string {
+	return fmt.Sprintf("%s is not scannable: %s", e.RepoName, e.Reason)
+}
+
+// NotScannableReason returns why the repository metadata rules out a scan,
+// or "" if the repository can be scanned
+func (r *Repository) NotScannableReason() string {
+	switch {
+	case r.Disabled:
+		return ReasonDisabled
+	case r.DefaultBranch == "":
+		return ReasonNoDefaultBranch
+	default:
+		return ""
+	}
+}
+
+// classifyTreeError maps a failed tree fetch to a NotScannableError when the
+// response reflects the repository's state rather than a real failure
+func classifyTreeError(repo *Repository, resp *github.Response, err error) error {
+	reason := treeErrorReason(resp, err)
+	if reason == ReasonNoDefaultBranch && repo.Ref != "" {
+		reason = RefNotFoundReason(repo.Ref)
+	}
+	if reason != "" {
+		return &NotScannableError{RepoName: repo.FullName, Reason: reason}
+	}
+	return fmt.Errorf("failed to get tree for %s: %w", repo.FullName, err)
+}
+
+// treeErrorReason returns the not-scannable reason for a failed tree fetch, if any
+func treeErrorReason(resp *github.Response, err error) string {
+	if resp == nil {
+		return ""
+	}
+	switch resp.StatusCode {
+	case http.StatusConflict:
+		return ReasonEmpty
+	case http.StatusNotFound:
+		return ReasonNoDefaultBranch
+	case http.StatusForbidden:
+		if isDisabledMessage(err) {
+			return ReasonDisabled
+		}
+	}
+	return ""
+}
+
+// isDisabledMessage checks if the API error says the repository is disabled or blocked
+func isDisabledMessage(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) {
+		return false
+	}
+	msg := strings.ToLower(ghErr.Message)
+	return strings.Contains(msg, "disabled") || strings.Contains(msg, "access blocked")
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNotScannableReason_FromMetadata(t *testing.T) {
+	testCases := []struct {
+		name string
+		repo *Repository
+		want string
+	}{
+		{"scannable", &Repository{DefaultBranch: "main"}, ""},
+		{"disabled", &Repository{DefaultBranch: "main", Disabled: true}, ReasonDisabled},
+		{"no default branch", &Repository{}, ReasonNoDefaultBranch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.repo.NotScannableReason(); got != tc.want {
+				t.Errorf("expected reason %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFindPackageFiles_ClassifiesNotScannable(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		body       string
+		wantReason string // "" means a real error is expected
+	}{
+		{"empty repository", http.StatusConflict, `{"message": "Git Repository is empty."}`, ReasonEmpty},
+		{"missing default branch", http.StatusNotFound, `{"message": "Not Found"}`, ReasonNoDefaultBranch},
+		{"disabled repository", http.StatusForbidden, `{"message": "Repository access blocked"}`, ReasonDisabled},
+		{"forbidden for other reasons", http.StatusForbidden, `{"message": "Resource not accessible by integration"}`, ""},
+		{"server error", http.StatusInternalServerError, `{"message": "Server Error"}`, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/test-org/test-repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+			c := newTestClient(t, mux)
+			repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main"}
+
+			_, err := c.FindPackageFiles(t.Context(), repo)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var notScannable *NotScannableError
+			isNotScannable := errors.As(err, &notScannable)
+			if tc.wantReason == "" {
+				if isNotScannable {
+					t.Errorf("expected a real error, got not scannable: %v", err)
+				}
+				return
+			}
+			if !isNotScannable {
+				t.Fatalf("expected NotScannableError, got %v", err)
+			}
+			if notScannable.Reason != tc.wantReason {
+				t.Errorf("expected reason %q, got %q", tc.wantReason, notScannable.Reason)
+			}
+		})
+	}
+}
+
+func TestFindPackageFiles_MissingRefIsNotScannable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-org/test-repo/git/trees/v1.2.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	c := newTestClient(t, mux)
+	repo := &Repository{Owner: "test-org", Name: "test-repo", FullName: "test-org/test-repo", DefaultBranch: "main", Ref: "v1.2.0"}
+
+	_, err := c.FindPackageFiles(t.Context(), repo)
+
+	var notScannable *NotScannableError
+	if !errors.As(err, &notScannable) {
+		t.Fatalf("expected NotScannableError, got %v", err)
+	}
+	if notScannable.Reason != "ref v1.2.0 not found" {
+		t.Errorf("expected the missing ref as the reason, got %q", notScannable.Reason)
+	}
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// GHTokenHelper is the --token-helper value that runs "gh auth token"
+const GHTokenHelper = "gh"
+
+// tokenPattern matches the GitHub token formats: classic 40-character hex
+// tokens and the prefixed personal, OAuth, user-to-server, server-to-server,
+// refresh, and fine-grained tokens
+var tokenPattern = regexp.MustCompile(`^([0-9a-f]{40}|gh[pousr]_[A-Za-z0-9]{36,251}|github_pat_[A-Za-z0-9_]{22,242})$`)
+
+// execCommand builds the helper command; replaced in tests
+var execCommand = exec.CommandContext
+
+// ValidateToken checks that a token has the format of a GitHub token
+func ValidateToken(token string) error {
+	if !tokenPattern.MatchString(token) {
+		return fmt.Errorf("token does not look like a GitHub token")
+	}
+	return nil
+}
+
+// TokenFromHelper runs a credential helper and returns the token it prints.
+// The helper "gh" runs "gh auth token" from the GitHub CLI; anything else is
+// split on whitespace and run as a command.
+func TokenFromHelper(ctx context.Context, helper string) (string, error) {
+	args := strings.Fields(helper)
+	if len(args) == 0 {
+		return "", fmt.Errorf("token helper is empty")
+	}
+	if helper == GHTokenHelper {
+		args = []string{"gh", "auth", "token"}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := execCommand(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("token helper %q is not installed", args[0])
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("token helper %q failed: %w: %s", helper, err, msg)
+		}
+		return "", fmt.Errorf("token helper %q failed: %w", helper, err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("token helper %q printed no token", helper)
+	}
+	if err := ValidateToken(token); err != nil {
+		return "", fmt.Errorf("token helper %q: %w", helper, err)
+	}
+	return token, nil
+}
+
+// TokenFromFile reads a token from a file, such as a mounted secret, trimming
+// surrounding whitespace and newlines
+func TokenFromFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
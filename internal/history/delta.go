@@ -0,0 +1,65 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Delta is the change in findings between two scans of the same scope
+type Delta struct {
+	Since              time.Time // When the previous scan ran
+	VulnerablePackages int
+	MaliciousFindings  int
+}
+
+// Compare computes the change from a previous entry to the current one
+func Compare(previous, current *Entry) Delta {
+	return Delta{
+		Since:              previous.Time,
+		VulnerablePackages: current.VulnerablePackages - previous.VulnerablePackages,
+		MaliciousFindings:  current.MaliciousFindings - previous.MaliciousFindings,
+	}
+}
+
+// Describe renders the delta as a one-line note, such as
+// "+2 vulnerable packages since last scan 3 days ago"
+func (d Delta) Describe(now time.Time) string {
+	var changes []string
+	if d.VulnerablePackages != 0 {
+		changes = append(changes, fmt.Sprintf("%+d vulnerable %s", d.VulnerablePackages, plural(d.VulnerablePackages, "package", "packages")))
+	}
+	if d.MaliciousFindings != 0 {
+		changes = append(changes, fmt.Sprintf("%+d malicious %s", d.MaliciousFindings, plural(d.MaliciousFindings, "finding", "findings")))
+	}
+
+	since := "since last scan " + age(now.Sub(d.Since))
+	if len(changes) == 0 {
+		return "No change " + since
+	}
+	return strings.Join(changes, ", ") + " " + since
+}
+
+// age renders a duration in the coarsest whole unit
+func age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n := int(d / time.Minute)
+		return fmt.Sprintf("%d %s ago", n, plural(n, "minute", "minutes"))
+	case d < 24*time.Hour:
+		n := int(d / time.Hour)
+		return fmt.Sprintf("%d %s ago", n, plural(n, "hour", "hours"))
+	default:
+		n := int(d / (24 * time.Hour))
+		return fmt.Sprintf("%d %s ago", n, plural(n, "day", "days"))
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 || n == -1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,135 @@
+// Package history keeps a local log of scan summaries so each run can be
+// compared with the previous one for the same org or user.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// Entry summarises one scan. Entries are stored one JSON object per line.
+type Entry struct {
+	Scope              string    `json:"scope"` // e.g. "org:mycompany" or "user:octocat"
+	Time               time.Time `json:"time"`
+	Repositories       int       `json:"repositories"`
+	VulnerablePackages int       `json:"vulnerable_packages"`
+	MaliciousFindings  int       `json:"malicious_findings"` // Workflows, scripts, branches, and migration repos
+}
+
+// Target is what a scan covered. The first of Org, Repo, LocalPath, and
+// ManifestsDir that is set names it, else User does.
+type Target struct {
+	Org          string
+	User         string
+	Repo         string
+	LocalPath    string
+	ManifestsDir string
+}
+
+// Scope identifies the scanned org, user, repository, or local directory in
+// history entries
+func Scope(target Target) string {
+	switch {
+	case target.Org != "":
+		return "org:" + target.Org
+	case target.Repo != "":
+		return "repo:" + target.Repo
+	case target.LocalPath != "":
+		return "path:" + target.LocalPath
+	case target.ManifestsDir != "":
+		return "manifests:" + target.ManifestsDir
+	default:
+		return "user:" + target.User
+	}
+}
+
+// NewEntry summarises the scan results for the scope at the given time
+func NewEntry(scope string, at time.Time, results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) *Entry {
+	entry := &Entry{Scope: scope, Time: at.UTC(), Repositories: len(results)}
+
+	var findings []*scanner.Finding
+	for _, result := range results {
+		findings = append(findings, result.Findings()...)
+	}
+	if orgResult != nil {
+		findings = append(findings, orgResult.Findings()...)
+	}
+
+	for _, f := range findings {
+		switch f.Category {
+		case scanner.CategoryVulnerablePackage:
+			entry.VulnerablePackages++
+		case scanner.CategoryAdvisory:
+			// Advisories are heuristics and are not tracked over time
+		default:
+			entry.MaliciousFindings++
+		}
+	}
+	return entry
+}
+
+// Load reads all entries from a history file. A missing file has no entries.
+func Load(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	lines := bufio.NewScanner(f)
+	for lineNum := 1; lines.Scan(); lineNum++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse history file line %d: %w", lineNum, err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+// Append adds an entry to the end of a history file, creating it if needed
+func Append(path string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return f.Close()
+}
+
+// Latest returns the most recent entry for the scope, or nil if there is none
+func Latest(entries []*Entry, scope string) *Entry {
+	var latest *Entry
+	for _, entry := range entries {
+		if entry.Scope == scope && (latest == nil || !entry.Time.Before(latest.Time)) {
+			latest = entry
+		}
+	}
+	return latest
+}
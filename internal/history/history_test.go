@@ -0,0 +1,95 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+var testNow = time.Date(2025, 11, 28, 12, 0, 0, 0, time.UTC)
+
+func TestCompare_DeltaFromTwoEntries(t *testing.T) {
+	previous := &Entry{Scope: "org:test-org", Time: testNow.Add(-72 * time.Hour), VulnerablePackages: 3, MaliciousFindings: 2}
+	current := &Entry{Scope: "org:test-org", Time: testNow, VulnerablePackages: 5, MaliciousFindings: 1}
+
+	delta := Compare(previous, current)
+
+	if delta.VulnerablePackages != 2 || delta.MaliciousFindings != -1 {
+		t.Errorf("expected +2 vulnerable and -1 malicious, got %+v", delta)
+	}
+	want := "+2 vulnerable packages, -1 malicious finding since last scan 3 days ago"
+	if got := delta.Describe(testNow); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDescribe_NoChange(t *testing.T) {
+	delta := Delta{Since: testNow.Add(-5 * time.Hour)}
+
+	if got, want := delta.Describe(testNow), "No change since last scan 5 hours ago"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAppendAndLoad_LatestForScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	entries, err := Load(path)
+	if err != nil || entries != nil {
+		t.Fatalf("expected a missing file to have no entries, got %v, %v", entries, err)
+	}
+
+	for _, entry := range []*Entry{
+		{Scope: "org:test-org", Time: testNow.Add(-48 * time.Hour), VulnerablePackages: 1},
+		{Scope: "org:test-org", Time: testNow.Add(-24 * time.Hour), VulnerablePackages: 2},
+		{Scope: "user:test-user", Time: testNow.Add(-1 * time.Hour), VulnerablePackages: 9},
+	} {
+		if err := Append(path, entry); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	latest := Latest(entries, "org:test-org")
+	if latest == nil || latest.VulnerablePackages != 2 {
+		t.Errorf("expected the most recent org entry, got %+v", latest)
+	}
+	if Latest(entries, "org:other") != nil {
+		t.Error("expected no entry for an unscanned scope")
+	}
+}
+
+func TestNewEntry_CountsByCategory(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				{Package: &scanner.Package{Name: "test-muaddib-bad", Version: "1.0.0"}, VulnEntry: &vuln.VulnEntry{PackageName: "test-muaddib-bad", PackageVersion: "1.0.0"}},
+			},
+			MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org/a", BranchName: "shai-hulud"}},
+			Advisories:        []*scanner.Advisory{{RepoName: "test-org/a", Kind: "test"}},
+		},
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{{RepoName: "test-org/migration"}},
+	}
+
+	entry := NewEntry(Scope(Target{Org: "test-org"}), testNow, results, orgResult)
+
+	if entry.Scope != "org:test-org" || entry.Repositories != 1 {
+		t.Errorf("unexpected scope or repository count: %+v", entry)
+	}
+	if entry.VulnerablePackages != 1 || entry.MaliciousFindings != 2 {
+		t.Errorf("expected 1 vulnerable and 2 malicious (advisories excluded), got %+v", entry)
+	}
+}
@@ -0,0 +1,54 @@
+// Package proxy builds the HTTP transport shared by the GitHub client and the
+// IOC downloads, so both reach the internet through the same proxy.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Parse validates a proxy URL given with --proxy. An empty string means the
+// proxy is taken from the environment.
+func Parse(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q (expected http://, https://, or socks5://host:port)", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q (missing host)", u.Redacted())
+	}
+	return u, nil
+}
+
+// Transport returns an HTTP transport that sends requests through proxyURL,
+// or, when it is nil, through the proxy named by HTTPS_PROXY, HTTP_PROXY, and
+// NO_PROXY. HTTPS requests are tunnelled with CONNECT, so certificates are
+// still verified against the destination rather than the proxy.
+func Transport(proxyURL *url.URL) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if proxyURL != nil {
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+	t.OnProxyConnectResponse = checkConnectResponse
+	return t
+}
+
+// checkConnectResponse turns a refused CONNECT into an error naming the proxy,
+// where the transport would otherwise report only the status text
+func checkConnectResponse(_ context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+	if connectRes.StatusCode == http.StatusOK {
+		return nil
+	}
+	return fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxyURL.Redacted(), connectReq.Host, connectRes.Status)
+}
@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// tunnelProxy serves CONNECT by relaying bytes to the requested host, as a
+// corporate proxy does, counting the tunnels it opens
+func tunnelProxy(t *testing.T, tunnels *int) *url.URL {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "expected CONNECT", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		*tunnels++
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	return u
+}
+
+func TestTransport_UsesProxyFromEnvironment(t *testing.T) {
+	// http.ProxyFromEnvironment reads the environment once per process, so
+	// this is the only test in the package that consults it
+	t.Setenv("HTTPS_PROXY", "http://proxy.test:3128")
+	t.Setenv("NO_PROXY", "internal.test")
+
+	transport := Transport(nil)
+	for target, want := range map[string]string{
+		"https://api.github.com/orgs/test-org/repos": "http://proxy.test:3128",
+		"https://internal.test/iocs.csv":             "",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		got, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) failed: %v", target, err)
+		}
+		if (got == nil && want != "") || (got != nil && got.String() != want) {
+			t.Errorf("Proxy(%s) = %v, want %q", target, got, want)
+		}
+	}
+}
+
+func TestTransport_TunnelsAndVerifiesTLS(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package_name,package_versions\n"))
+	}))
+	backend.Config.ErrorLog = log.New(io.Discard, "", 0) // The rejected handshake is expected
+	backend.StartTLS()
+	defer backend.Close()
+	var tunnels int
+	proxyURL := tunnelProxy(t, &tunnels)
+
+	// The proxy does not terminate TLS, so the backend's certificate is still checked
+	untrusted := &http.Client{Transport: Transport(proxyURL)}
+	if _, err := untrusted.Get(backend.URL); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected an untrusted certificate error through the proxy, got %v", err)
+	}
+
+	transport := Transport(proxyURL)
+	transport.TLSClientConfig = &tls.Config{RootCAs: backend.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	resp, err := (&http.Client{Transport: transport}).Get(backend.URL)
+	if err != nil {
+		t.Fatalf("request through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if tunnels != 2 {
+		t.Errorf("expected both requests to be tunnelled, got %d tunnels", tunnels)
+	}
+}
+
+func TestTransport_ReportsRefusedConnect(t *testing.T) {
+	refusing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked by policy", http.StatusForbidden)
+	}))
+	defer refusing.Close()
+	proxyURL, _ := url.Parse(refusing.URL)
+	proxyURL.User = url.UserPassword("test-user", "test-secret")
+
+	_, err := (&http.Client{Transport: Transport(proxyURL)}).Get("https://api.github.com/")
+	if err == nil || !strings.Contains(err.Error(), "refused CONNECT to api.github.com:443: 403 Forbidden") {
+		t.Fatalf("expected a refused CONNECT error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "test-secret") {
+		t.Errorf("expected the proxy password to be redacted, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{"", "", ""},
+		{"http://proxy.test:3128", "http://proxy.test:3128", ""},
+		{"socks5://proxy.test:1080", "socks5://proxy.test:1080", ""},
+		{"proxy.test:3128", "", "expected http://"},
+		{"ftp://proxy.test", "", "expected http://"},
+		{"http://", "", "missing host"},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.raw)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q): expected error containing %q, got %v", tt.raw, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.raw, err)
+			continue
+		}
+		if (got == nil && tt.want != "") || (got != nil && got.String() != tt.want) {
+			t.Errorf("Parse(%q) = %v, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
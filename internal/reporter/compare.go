@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReportDiff categorises findings by how they changed since a previous report
+type ReportDiff struct {
+	New       []*JSONFinding // In the current scan only
+	Resolved  []*JSONFinding // In the previous report only
+	Unchanged []*JSONFinding // In both
+}
+
+// LoadJSONReport loads a JSON report written by --output
+func LoadJSONReport(path string) (*JSONReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer f.Close()
+
+	return ParseJSONReport(f)
+}
+
+// ParseJSONReport parses a JSON report
+func ParseJSONReport(r io.Reader) (*JSONReport, error) {
+	var report JSONReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// DiffReports compares the findings of two reports by finding ID
+func DiffReports(previous, current *JSONReport) *ReportDiff {
+	before := make(map[string]bool, len(previous.Findings))
+	for _, f := range previous.Findings {
+		before[f.ID] = true
+	}
+	after := make(map[string]bool, len(current.Findings))
+	for _, f := range current.Findings {
+		after[f.ID] = true
+	}
+
+	diff := &ReportDiff{}
+	for _, f := range current.Findings {
+		if before[f.ID] {
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			diff.New = append(diff.New, f)
+		}
+	}
+	for _, f := range previous.Findings {
+		if !after[f.ID] {
+			diff.Resolved = append(diff.Resolved, f)
+		}
+	}
+	return diff
+}
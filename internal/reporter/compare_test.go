@@ -0,0 +1,61 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	previous := &JSONReport{Findings: []*JSONFinding{
+		{ID: "kept", Category: "vulnerable-package", Repository: "test-org/app", PackageName: "test-muaddib-kept", Version: "1.0.0"},
+		{ID: "fixed", Category: "malicious-branch", Repository: "test-org/app", Detail: "shai-hulud"},
+	}}
+	current := &JSONReport{Findings: []*JSONFinding{
+		{ID: "kept", Category: "vulnerable-package", Repository: "test-org/app", PackageName: "test-muaddib-kept", Version: "1.0.0"},
+		{ID: "added", Category: "vulnerable-package", Repository: "test-org/web", PackageName: "test-muaddib-added", Version: "2.0.0"},
+	}}
+
+	diff := DiffReports(previous, current)
+
+	if len(diff.New) != 1 || diff.New[0].ID != "added" {
+		t.Errorf("expected [added] new, got %+v", diff.New)
+	}
+	if len(diff.Resolved) != 1 || diff.Resolved[0].ID != "fixed" {
+		t.Errorf("expected [fixed] resolved, got %+v", diff.Resolved)
+	}
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].ID != "kept" {
+		t.Errorf("expected [kept] unchanged, got %+v", diff.Unchanged)
+	}
+}
+
+func TestReportDiff_ColorOnlyOnTerminal(t *testing.T) {
+	diff := &ReportDiff{
+		New:       []*JSONFinding{{ID: "a", Severity: "high", Category: "vulnerable-package", Repository: "test-org/web", PackageName: "test-muaddib-added", Version: "2.0.0", FilePath: "package.json"}},
+		Resolved:  []*JSONFinding{{ID: "b", Severity: "high", Category: "malicious-branch", Repository: "test-org/app", Detail: "shai-hulud"}},
+		Unchanged: []*JSONFinding{{ID: "c", Severity: "low", Category: "advisory", Repository: "test-org/app", Detail: "CommittedSecretFile: .env"}},
+	}
+
+	var plain bytes.Buffer
+	NewTerminalReporter(WithOutput(&plain), WithColor(false)).ReportDiff(diff, "previous.json")
+	output := plain.String()
+	for _, want := range []string{
+		"1 new, 1 resolved, 1 unchanged",
+		"+ [high] vulnerable-package test-org/web: test-muaddib-added@2.0.0 (package.json)",
+		"- [high] malicious-branch test-org/app: shai-hulud",
+		"  [low] advisory test-org/app: CommittedSecretFile: .env",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in diff, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("expected no color codes without a terminal, got %q", output)
+	}
+
+	var colored bytes.Buffer
+	NewTerminalReporter(WithOutput(&colored), WithColor(true)).ReportDiff(diff, "previous.json")
+	if !strings.Contains(colored.String(), "\x1b[31") || !strings.Contains(colored.String(), "\x1b[32") {
+		t.Errorf("expected red and green color codes on a terminal, got %q", colored.String())
+	}
+}
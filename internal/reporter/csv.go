@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// csvHeader lists the columns of the CSV findings export. Package columns
+// are blank for workflow, script, branch, and repository findings, whose
+// pattern, script command, or branch name is in the detail column instead.
+var csvHeader = []string{
+	"owner", "repo", "file", "category", "package", "version",
+	"ioc_version", "dev", "source", "severity", "finding_id", "detail",
+}
+
+// WriteCSVReport writes one row per finding with a header row. Repositories
+// come first in scan order, followed by malicious migration repositories.
+func WriteCSVReport(w io.Writer, results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	owners := make(map[string]string, len(results))
+	var findings []*scanner.Finding
+	for _, result := range results {
+		owners[result.RepoName] = result.Owner
+		findings = append(findings, result.Findings()...)
+	}
+	if orgResult != nil {
+		findings = append(findings, orgResult.Findings()...)
+	}
+
+	for _, f := range findings {
+		if err := cw.Write(csvRow(f, ownerOf(owners[f.RepoName], f.RepoName))); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV report: %w", err)
+	}
+	return nil
+}
+
+// csvRow converts a finding to a row in csvHeader order
+func csvRow(f *scanner.Finding, owner string) []string {
+	return []string{
+		owner,
+		f.RepoName,
+		f.FilePath,
+		string(f.Category),
+		f.PackageName,
+		f.Version,
+		f.IOCVersion,
+		strconv.FormatBool(f.IsDev),
+		f.Source,
+		string(f.Severity),
+		f.ID,
+		f.Detail,
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+func TestWriteCSVReport_MixedFindings(t *testing.T) {
+	vp := vulnerablePackage("test-org/a", "packages/web,app/package.json", "test-muaddib-bad", "1.0.0")
+	vp.Package.IsDev = true
+	vp.Package.Source = "devDependencies"
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName:           "test-org/a",
+			Owner:              "test-org",
+			VulnerablePackages: []*scanner.VulnerablePackage{vp},
+			MaliciousScripts: []*scanner.MaliciousScript{
+				{RepoName: "test-org/a", FilePath: "package.json", ScriptName: "postinstall", Command: `node -e "require('x'), run()"`, Pattern: "node -e"},
+			},
+			MaliciousWorkflows: []*scanner.MaliciousWorkflow{
+				{RepoName: "test-org/a", FilePath: ".github/workflows/discussion.yaml", Pattern: "discussion.yaml", Kind: scanner.WorkflowKindWormPattern},
+			},
+			MaliciousBranches: []*scanner.MaliciousBranch{
+				{RepoName: "test-org/a", BranchName: "shai-hulud"},
+			},
+		},
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{
+			{RepoName: "test-org/migration", Description: "Shai-Hulud Migration"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSVReport(&buf, results, orgResult); err != nil {
+		t.Fatalf("WriteCSVReport failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, buf.String())
+	}
+
+	want := [][]string{
+		csvHeader,
+		{"test-org", "test-org/a", "packages/web,app/package.json", "vulnerable-package", "test-muaddib-bad", "1.0.0", "1.0.0", "true", "devDependencies", "high", vp.ID(), vp.MatchedBy},
+		{"test-org", "test-org/a", ".github/workflows/discussion.yaml", "malicious-workflow", "", "", "", "false", "", "high", results[0].MaliciousWorkflows[0].ID(), "WormPattern: discussion.yaml"},
+		{"test-org", "test-org/a", "package.json", "malicious-script", "", "", "", "false", "", "critical", results[0].MaliciousScripts[0].ID(), `postinstall: node -e "require('x'), run()"`},
+		{"test-org", "test-org/a", "", "malicious-branch", "", "", "", "false", "", "high", results[0].MaliciousBranches[0].ID(), "shai-hulud"},
+		{"test-org", "test-org/migration", "", "malicious-repo", "", "", "", "false", "", "critical", orgResult.MaliciousRepos[0].ID(), "Shai-Hulud Migration"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d:\n%s", len(want), len(rows), buf.String())
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d:\nexpected %q\ngot      %q", i, want[i], rows[i])
+		}
+	}
+	if !strings.Contains(buf.String(), `"packages/web,app/package.json"`) {
+		t.Errorf("expected field with a comma to be quoted, got:\n%s", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"text", "csv", "json", "sarif"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected unknown format to be rejected")
+	}
+}
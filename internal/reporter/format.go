@@ -0,0 +1,27 @@
+package reporter
+
+import "fmt"
+
+// Format selects what the scan writes to stdout
+type Format string
+
+const (
+	// FormatText is the human-readable terminal report
+	FormatText Format = "text"
+	// FormatCSV is one row per finding, for spreadsheets
+	FormatCSV Format = "csv"
+	// FormatJSON is the JSON report also written by --output, for dashboards
+	FormatJSON Format = "json"
+	// FormatSARIF is a SARIF 2.1.0 log, for GitHub code scanning
+	FormatSARIF Format = "sarif"
+)
+
+// ParseFormat validates an output format name
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatText, FormatCSV, FormatJSON, FormatSARIF:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected text, csv, json, or sarif)", name)
+	}
+}
@@ -0,0 +1,208 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// JSONReport is the machine-readable scan report. Its findings list is also
+// the input format for --baseline.
+type JSONReport struct {
+	Repositories   []*JSONRepository `json:"repositories"`
+	MaliciousRepos []*JSONFinding    `json:"malicious_repos"`
+	Findings       []*JSONFinding    `json:"findings"`
+	Sources        []*JSONSource     `json:"sources"`
+	Summary        JSONSummary       `json:"summary"`
+}
+
+// JSONRepository holds the per-repository scan outcome
+type JSONRepository struct {
+	Name                string   `json:"name"`
+	Owner               string   `json:"owner"`
+	FilesScanned        int      `json:"files_scanned"`
+	TotalPackages       int      `json:"total_packages"`
+	Findings            int      `json:"findings"`
+	NoPackageFiles      bool     `json:"no_package_files,omitempty"` // Scanned, but nothing to check
+	NotScannable        string   `json:"not_scannable,omitempty"`
+	Error               string   `json:"error,omitempty"`
+	UnreadableLockfiles []string `json:"unreadable_lockfiles,omitempty"` // Binary lockfiles that were not decoded
+}
+
+// JSONFinding is a single flattened finding
+type JSONFinding struct {
+	ID          string   `json:"id"`
+	Category    string   `json:"category"`
+	Repository  string   `json:"repository"`
+	FilePath    string   `json:"file_path,omitempty"`
+	FilePaths   []string `json:"file_paths,omitempty"`
+	PackageName string   `json:"package_name,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	IOCVersion  string   `json:"ioc_version,omitempty"`
+	IsDev       bool     `json:"dev,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Detail      string   `json:"detail,omitempty"`
+	Known       bool     `json:"known,omitempty"`
+	Confidence  string   `json:"confidence,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Evidence    string   `json:"evidence,omitempty"`
+	Workspaces  []string `json:"workspaces,omitempty"`
+	Line        int      `json:"line,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Campaign    string   `json:"campaign,omitempty"`
+	AdvisoryID  string   `json:"advisory_id,omitempty"`
+	IOCSources  []string `json:"ioc_sources,omitempty"`
+}
+
+// JSONOption configures the JSON report
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	evidence bool
+	sources  []vuln.Source
+}
+
+// WithEvidence includes the raw upstream IOC row that matched each finding
+func WithEvidence(enabled bool) JSONOption {
+	return func(o *jsonOptions) {
+		o.evidence = enabled
+	}
+}
+
+// JSONSummary holds the aggregate counts shown in the terminal summary
+type JSONSummary struct {
+	RepositoriesScanned int     `json:"repositories_scanned"`
+	RepositoriesCovered int     `json:"repositories_covered"`
+	RepositoriesSkipped int     `json:"repositories_skipped"`
+	CoveragePercent     float64 `json:"coverage_percent"`
+	PackagesChecked     int     `json:"packages_checked"`
+	FilesExcluded       int     `json:"files_excluded"`
+	IOCEntries          int     `json:"ioc_entries"`
+	VulnerablePackages  int     `json:"vulnerable_packages"`
+	DistinctVulnerable  int     `json:"distinct_vulnerable_packages"`
+	MaliciousWorkflows  int     `json:"malicious_workflows"`
+	MaliciousScripts    int     `json:"malicious_scripts"`
+	MaliciousBranches   int     `json:"malicious_branches"`
+	MaliciousRepos      int     `json:"malicious_repos"`
+	AffectedRepos       int     `json:"affected_repositories"`
+	Advisories          int     `json:"advisories"`
+	KnownFindings       int     `json:"known_findings"`
+	NotScannable        int     `json:"not_scannable"`
+	NoPackageFiles      int     `json:"no_package_files"`
+	Errors              int     `json:"errors"`
+}
+
+// NewJSONReport builds a JSON report from the scan results
+func NewJSONReport(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int, opts ...JSONOption) *JSONReport {
+	var o jsonOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	report := &JSONReport{
+		Repositories:   []*JSONRepository{},
+		MaliciousRepos: []*JSONFinding{},
+		Findings:       []*JSONFinding{},
+		Sources:        toJSONSources(o.sources),
+	}
+
+	for _, result := range results {
+		repo := &JSONRepository{
+			Name:                result.RepoName,
+			Owner:               ownerOf(result.Owner, result.RepoName),
+			FilesScanned:        result.FilesScanned,
+			TotalPackages:       result.TotalPackages,
+			NotScannable:        result.NotScannable,
+			NoPackageFiles:      result.NoPackageFiles(),
+			UnreadableLockfiles: result.BinaryLockfiles,
+		}
+		if result.Error != nil {
+			repo.Error = result.Error.Error()
+		}
+		findings := result.Findings()
+		repo.Findings = len(findings)
+		report.Repositories = append(report.Repositories, repo)
+		report.Findings = append(report.Findings, toJSONFindings(findings, o)...)
+	}
+
+	if orgResult != nil {
+		repoFindings := toJSONFindings(orgResult.Findings(), o)
+		report.MaliciousRepos = append(report.MaliciousRepos, repoFindings...)
+		report.Findings = append(report.Findings, repoFindings...)
+	}
+
+	report.Summary = newJSONSummary(calculateSummaryStats(results, orgResult), vulnDBSize)
+	return report
+}
+
+// toJSONFindings converts flattened scanner findings to their JSON form
+func toJSONFindings(findings []*scanner.Finding, o jsonOptions) []*JSONFinding {
+	out := make([]*JSONFinding, 0, len(findings))
+	for _, f := range findings {
+		jf := &JSONFinding{
+			ID:          f.ID,
+			Category:    string(f.Category),
+			Repository:  f.RepoName,
+			FilePath:    f.FilePath,
+			FilePaths:   f.FilePaths,
+			PackageName: f.PackageName,
+			Version:     f.Version,
+			IOCVersion:  f.IOCVersion,
+			IsDev:       f.IsDev,
+			Source:      f.Source,
+			Detail:      f.Detail,
+			Known:       f.Known,
+			Confidence:  string(f.Confidence),
+			Severity:    string(f.Severity),
+			Workspaces:  f.Workspaces,
+			Line:        f.Line,
+			URL:         f.URL,
+			Campaign:    f.Campaign,
+			AdvisoryID:  f.AdvisoryID,
+			IOCSources:  f.IOCSources,
+		}
+		if o.evidence {
+			jf.Evidence = f.Evidence
+		}
+		out = append(out, jf)
+	}
+	return out
+}
+
+// newJSONSummary converts the summary statistics to their JSON form
+func newJSONSummary(stats summaryStats, vulnDBSize int) JSONSummary {
+	return JSONSummary{
+		RepositoriesScanned: stats.totalRepos,
+		RepositoriesCovered: stats.covered,
+		RepositoriesSkipped: stats.skipped(),
+		CoveragePercent:     stats.coveragePercent(),
+		PackagesChecked:     stats.totalPackages,
+		FilesExcluded:       stats.filesExcluded,
+		IOCEntries:          vulnDBSize,
+		VulnerablePackages:  stats.totalVulnerable,
+		DistinctVulnerable:  stats.distinctVulnerable,
+		MaliciousWorkflows:  stats.totalMaliciousWorkflows,
+		MaliciousScripts:    stats.totalMaliciousScripts,
+		MaliciousBranches:   stats.totalMaliciousBranches,
+		MaliciousRepos:      stats.totalMaliciousRepos,
+		AffectedRepos:       stats.reposWithVulns + stats.totalMaliciousRepos,
+		Advisories:          stats.totalAdvisories,
+		KnownFindings:       stats.knownFindings,
+		NotScannable:        stats.notScannableCount(),
+		NoPackageFiles:      stats.noPackageFiles,
+		Errors:              stats.errorCount,
+	}
+}
+
+// WriteJSONReport writes the scan results as an indented JSON report
+func WriteJSONReport(w io.Writer, results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int, opts ...JSONOption) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(NewJSONReport(results, orgResult, vulnDBSize, opts...)); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestWriteJSONReport_IsBaselineCompatible(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "package.json", "test-muaddib-bad", "1.0.0"),
+			},
+			MaliciousBranches: []*scanner.MaliciousBranch{
+				{RepoName: "test-org/a", BranchName: "shai-hulud"},
+			},
+		},
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{
+			{RepoName: "test-org/migration", Description: "Shai-Hulud Migration"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONReport(&buf, results, orgResult, 5); err != nil {
+		t.Fatalf("WriteJSONReport failed: %v", err)
+	}
+
+	baseline, err := scanner.ParseBaseline(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("report is not a valid baseline: %v", err)
+	}
+	if baseline.Size() != 3 {
+		t.Errorf("expected 3 findings in baseline, got %d", baseline.Size())
+	}
+	for _, id := range []string{
+		results[0].VulnerablePackages[0].ID(),
+		results[0].MaliciousBranches[0].ID(),
+		orgResult.MaliciousRepos[0].ID(),
+	} {
+		if !baseline.Contains(id) {
+			t.Errorf("expected baseline to contain %s", id)
+		}
+	}
+}
+
+func TestWriteJSONReport_IncludesEvidence(t *testing.T) {
+	const row = `test-muaddib-bad,"1.0.0, 1.0.1",test-feed`
+	db, err := vuln.ParseCSVForTest(strings.NewReader("package_name,package_version,source\n" + row))
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	result := scanner.NewScanner(db, true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/a", Path: "package.json", Content: `{"dependencies": {"test-muaddib-bad": "1.0.1"}}`},
+	})
+
+	testCases := []struct {
+		name string
+		opts []JSONOption
+		want string
+	}{
+		{"omitted by default", nil, ""},
+		{"included with evidence", []JSONOption{WithEvidence(true)}, row},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSONReport(&buf, []*scanner.RepoScanResult{result}, nil, db.Size(), tc.opts...); err != nil {
+				t.Fatalf("WriteJSONReport failed: %v", err)
+			}
+
+			var report JSONReport
+			if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			if len(report.Findings) != 1 {
+				t.Fatalf("expected 1 finding, got %d", len(report.Findings))
+			}
+			if got := report.Findings[0].Evidence; got != tc.want {
+				t.Errorf("expected evidence %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteJSONReport_IncludesCampaign(t *testing.T) {
+	db := vuln.NewVulnDB()
+	db.Add(&vuln.VulnEntry{PackageName: "test-muaddib-bad", PackageVersion: "1.0.0", Campaign: "test-campaign"})
+	result := scanner.NewScanner(db, true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/a", Path: "package.json", Content: `{"dependencies": {"test-muaddib-bad": "1.0.0"}}`},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteJSONReport(&buf, []*scanner.RepoScanResult{result}, nil, db.Size()); err != nil {
+		t.Fatalf("WriteJSONReport failed: %v", err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Campaign != "test-campaign" {
+		t.Errorf("expected one finding tagged test-campaign, got %+v", report.Findings)
+	}
+}
+
+func TestNewJSONReport_MarksReposWithoutPackageFiles(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{RepoName: "test-org/clean", FilesScanned: 2, TotalPackages: 10},
+		{RepoName: "test-org/docs"},
+		{RepoName: "test-org/go-service", Language: "Go"},
+		{RepoName: "test-org/archived", NotScannable: github.ReasonEmpty},
+	}
+
+	report := NewJSONReport(results, nil, 5)
+
+	for i, want := range []bool{false, true, true, false} {
+		if got := report.Repositories[i].NoPackageFiles; got != want {
+			t.Errorf("%s: expected no_package_files %v, got %v", report.Repositories[i].Name, want, got)
+		}
+	}
+	if report.Summary.NoPackageFiles != 2 {
+		t.Errorf("expected 2 repositories without package files in the summary, got %d", report.Summary.NoPackageFiles)
+	}
+}
@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compression identifies how a report file is compressed
+type Compression string
+
+const (
+	// CompressionNone writes the report as-is
+	CompressionNone Compression = ""
+	// CompressionGzip writes the report gzip-compressed
+	CompressionGzip Compression = "gzip"
+)
+
+// ResolveCompression picks the compression for an output file. An explicit
+// flag value wins; otherwise it is detected from the file extension.
+func ResolveCompression(path, flag string) (Compression, error) {
+	switch strings.ToLower(flag) {
+	case "":
+		if strings.HasSuffix(strings.ToLower(path), ".gz") {
+			return CompressionGzip, nil
+		}
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	default:
+		return CompressionNone, fmt.Errorf("unsupported compression %q (supported: gzip)", flag)
+	}
+}
+
+// outputFile closes the compressor before the underlying file
+type outputFile struct {
+	io.Writer
+	closers []io.Closer
+}
+
+// Close flushes and closes the compressor and file in order
+func (o *outputFile) Close() error {
+	var firstErr error
+	for _, c := range o.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CreateOutputFile creates (or truncates) a report file and any missing parent
+// directories, wrapping it in the requested compression. The caller must Close
+// the result to flush it.
+func CreateOutputFile(path string, compression Compression) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if compression != CompressionGzip {
+		return f, nil
+	}
+
+	gz := gzip.NewWriter(f)
+	return &outputFile{Writer: gz, closers: []io.Closer{gz, f}}, nil
+}
@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+func TestResolveCompression(t *testing.T) {
+	tests := []struct {
+		path string
+		flag string
+		want Compression
+	}{
+		{"report.json", "", CompressionNone},
+		{"report.json.gz", "", CompressionGzip},
+		{"REPORT.JSON.GZ", "", CompressionGzip},
+		{"report.json", "gzip", CompressionGzip},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveCompression(tt.path, tt.flag)
+		if err != nil {
+			t.Fatalf("ResolveCompression(%q, %q) failed: %v", tt.path, tt.flag, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveCompression(%q, %q) = %q, want %q", tt.path, tt.flag, got, tt.want)
+		}
+	}
+
+	if _, err := ResolveCompression("report.json", "brotli"); err == nil {
+		t.Error("expected error for unsupported compression")
+	}
+}
+
+func TestCreateOutputFile_GzipContainsJSONReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json.gz")
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName:     "test-org/a",
+			FilesScanned: 1,
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "package-lock.json", "test-muaddib-bad", "1.0.0"),
+			},
+		},
+	}
+
+	compression, err := ResolveCompression(path, "")
+	if err != nil {
+		t.Fatalf("ResolveCompression failed: %v", err)
+	}
+	out, err := CreateOutputFile(path, compression)
+	if err != nil {
+		t.Fatalf("CreateOutputFile failed: %v", err)
+	}
+	if err := WriteJSONReport(out, results, nil, 10); err != nil {
+		t.Fatalf("WriteJSONReport failed: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open output: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("output is not valid gzip: %v", err)
+	}
+
+	var report JSONReport
+	if err := json.NewDecoder(gz).Decode(&report); err != nil {
+		t.Fatalf("gzip content is not a JSON report: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].ID != results[0].VulnerablePackages[0].ID() {
+		t.Errorf("unexpected findings: %+v", report.Findings)
+	}
+	if report.Summary.VulnerablePackages != 1 || report.Summary.IOCEntries != 10 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+}
+
+func TestCreateOutputFile_CreatesDirectoriesAndTruncates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "nightly", "report.csv")
+
+	for _, content := range []string{"a much longer stale report\n", "new\n"} {
+		out, err := CreateOutputFile(path, CompressionNone)
+		if err != nil {
+			t.Fatalf("CreateOutputFile failed: %v", err)
+		}
+		if _, err := out.Write([]byte(content)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := out.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("expected the file to be truncated, got %q", data)
+	}
+}
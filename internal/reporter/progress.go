@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// clearLine returns the cursor to the start of the line and erases it
+const clearLine = "\r\033[K"
+
+// progressBar shows how many repositories have been scanned on the last line
+// of the terminal. Other output is written above it and the bar redrawn.
+type progressBar struct {
+	now     func() time.Time
+	start   time.Time
+	current int
+	total   int
+	shown   bool // The bar is on the last line and must be cleared before writing
+	done    bool // The scan finished and the bar is no longer drawn
+}
+
+// WithProgressBar replaces the per-repository "Scanning" lines with a progress
+// bar showing the count, percentage, and estimated time remaining. It needs a
+// terminal that can redraw a line; see IsInteractive.
+func WithProgressBar(enabled bool) ReporterOption {
+	return func(r *TerminalReporter) {
+		if enabled {
+			r.bar = &progressBar{now: time.Now}
+		}
+	}
+}
+
+// ReportScanning reports that the nth of total repositories is being scanned.
+// A line is printed unless the progress bar is enabled, in which case the bar
+// only takes up the new total; it advances as scans finish (see ReportScanned).
+func (r *TerminalReporter) ReportScanning(n, total int, repoName string) {
+	if r.bar == nil {
+		r.ReportInfo("🔍 [%d/%d] Scanning %s...", n, total, repoName)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bar.clear(r.out)
+	r.bar.update(r.bar.current, total)
+	r.bar.draw(r.out)
+}
+
+// ReportScanned advances the progress bar to n of total repositories scanned.
+// Without the bar the repository was already listed by ReportScanning.
+func (r *TerminalReporter) ReportScanned(n, total int) {
+	if r.bar == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bar.clear(r.out)
+	r.bar.update(n, total)
+	r.bar.draw(r.out)
+}
+
+// FinishProgress removes the progress bar once scanning is done, so the
+// summary is written below the last finding
+func (r *TerminalReporter) FinishProgress() {
+	if r.bar == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bar.clear(r.out)
+	r.bar.done = true
+}
+
+// progressShown checks if the progress bar is tracking a scan
+func (r *TerminalReporter) progressShown() bool {
+	if r.bar == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bar.total > 0 && !r.bar.done
+}
+
+// update records how many repositories have been scanned, starting the clock
+// on the first call
+func (b *progressBar) update(current, total int) {
+	if b.start.IsZero() {
+		b.start = b.now()
+	}
+	b.current, b.total = current, max(total, current)
+}
+
+// clear erases the bar if it is shown
+func (b *progressBar) clear(w io.Writer) {
+	if b == nil || !b.shown {
+		return
+	}
+	io.WriteString(w, clearLine)
+	b.shown = false
+}
+
+// draw writes the bar on the current line, leaving the cursor after it
+func (b *progressBar) draw(w io.Writer) {
+	if b == nil || b.done || b.total == 0 {
+		return
+	}
+	io.WriteString(w, b.render())
+	b.shown = true
+}
+
+// render formats the bar, e.g. "🔍 ███░░░ 12/340 (3%) ETA 4m10s"
+func (b *progressBar) render() string {
+	filled := progressBarWidth * b.current / b.total
+	line := fmt.Sprintf("🔍 %s%s %d/%d (%d%%)",
+		strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled),
+		b.current, b.total, 100*b.current/b.total)
+	if eta, ok := b.eta(); ok {
+		line += " ETA " + eta.String()
+	}
+	return line
+}
+
+// eta estimates the time left from the average time per repository so far.
+// There is no estimate until a repository has been scanned.
+func (b *progressBar) eta() (time.Duration, bool) {
+	if b.current < 1 {
+		return 0, false
+	}
+	perRepo := b.now().Sub(b.start) / time.Duration(b.current)
+	return (perRepo * time.Duration(b.total-b.current)).Round(time.Second), true
+}
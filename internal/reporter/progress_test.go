@@ -0,0 +1,72 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportScanning_DrawsProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	r := NewTerminalReporter(WithOutput(&out), WithColor(false), WithProgressBar(true))
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	r.bar.now = func() time.Time { return now }
+
+	// Starting a scan does not advance the bar
+	r.ReportScanning(1, 10, "test-org/repo-1")
+	want := "🔍 " + strings.Repeat("░", 30) + " 0/10 (0%)"
+	if got := out.String(); got != want {
+		t.Errorf("expected %q without an ETA, got %q", want, got)
+	}
+
+	// Four repositories scanned in 20s leaves six at 5s each
+	out.Reset()
+	now = now.Add(20 * time.Second)
+	r.ReportScanned(4, 10)
+	want = clearLine + "🔍 " + strings.Repeat("█", 12) + strings.Repeat("░", 18) + " 4/10 (40%) ETA 30s"
+	if got := out.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// A later start keeps the count of finished scans
+	out.Reset()
+	r.ReportScanning(6, 10, "test-org/repo-6")
+	if got := out.String(); !strings.Contains(got, " 4/10 (40%)") {
+		t.Errorf("expected the bar to stay at 4/10, got %q", got)
+	}
+
+	// Findings are written above the bar, which is then redrawn
+	out.Reset()
+	r.ReportInfo("test-org/repo-3 has findings")
+	if got := out.String(); !strings.HasPrefix(got, clearLine+"test-org/repo-3 has findings\n🔍 ") {
+		t.Errorf("expected the bar to be cleared and redrawn around the message, got %q", got)
+	}
+
+	// Per-repository progress lines are left to --verbose
+	out.Reset()
+	r.ReportProgress("   ⏭️  Skipping archived repository")
+	if out.Len() != 0 {
+		t.Errorf("expected progress messages to be hidden behind the bar, got %q", out.String())
+	}
+
+	out.Reset()
+	r.FinishProgress()
+	r.ReportInfo("Found 10 repositories")
+	if got := out.String(); got != clearLine+"Found 10 repositories\n" {
+		t.Errorf("expected the bar to be removed once finished, got %q", got)
+	}
+}
+
+func TestReportScanning_ListsReposWithoutProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	r := NewTerminalReporter(WithOutput(&out), WithColor(false))
+
+	r.ReportScanning(3, 10, "test-org/repo-3")
+	r.ReportProgress("   ⏭️  Skipping archived repository")
+	r.ReportScanned(3, 10)
+
+	if got := out.String(); got != "🔍 [3/10] Scanning test-org/repo-3...\n   ⏭️  Skipping archived repository\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
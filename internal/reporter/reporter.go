@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// Reporter reports the progress and results of a scan as it runs. The
+// structured formats are written once the scan finishes (see WriteJSONReport,
+// WriteCSVReport, and WriteSARIFReport); a Reporter covers what is shown
+// while it runs.
+type Reporter interface {
+	PrintBanner()
+	ReportInfo(format string, args ...interface{})
+	ReportSuccess(format string, args ...interface{})
+	ReportWarning(format string, args ...interface{})
+	ReportError(format string, args ...interface{})
+	ReportProgress(message string)
+
+	// Repositories, as they are scanned
+	ReportScanning(n, total int, repoName string)
+	ReportScanned(n, total int)
+	FinishProgress()
+	ReportRepoStart(repoName string)
+	ReportRepoResult(result *scanner.RepoScanResult)
+	ReportRepo(repoName string, result *scanner.RepoScanResult)
+	ReportMaliciousRepo(repoName, description string)
+
+	// The scan as a whole, once it finishes
+	ReportSources(sources []vuln.Source)
+	ReportSummary(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int)
+	ReportDiff(diff *ReportDiff, previousPath string)
+	GroupsBySeverity() bool
+}
+
+var _ Reporter = (*TerminalReporter)(nil)
+
+// NewReporter creates the reporter for an output format. Every format is
+// reported on the terminal as the scan runs; the banner is left off for
+// machine-readable formats, which are parsed from stdout.
+func NewReporter(format string, opts ...ReporterOption) (Reporter, error) {
+	f, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	r := NewTerminalReporter(opts...)
+	r.banner = f == FormatText
+	return r, nil
+}
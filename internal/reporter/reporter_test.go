@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewReporter_LeavesBannerOffMachineReadableFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		banner bool
+	}{
+		{"text", true},
+		{"csv", false},
+		{"json", false},
+		{"sarif", false},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		rep, err := NewReporter(tt.format, WithOutput(&buf), WithColor(false))
+		if err != nil {
+			t.Fatalf("NewReporter(%q) failed: %v", tt.format, err)
+		}
+		rep.PrintBanner()
+		rep.ReportInfo("test-org has 3 repositories")
+
+		if got := strings.Contains(buf.String(), "Shai-Hulud NPM Worm Scanner"); got != tt.banner {
+			t.Errorf("%s: expected banner %v, got output:\n%s", tt.format, tt.banner, buf.String())
+		}
+		if !strings.Contains(buf.String(), "test-org has 3 repositories") {
+			t.Errorf("%s: expected progress messages to be reported, got:\n%s", tt.format, buf.String())
+		}
+	}
+}
+
+func TestNewReporter_RejectsUnknownFormat(t *testing.T) {
+	if _, err := NewReporter("xml"); err == nil || !strings.Contains(err.Error(), `unknown format "xml"`) {
+		t.Errorf("expected an unknown format error, got %v", err)
+	}
+}
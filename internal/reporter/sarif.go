@@ -0,0 +1,248 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// SARIF 2.1.0 identifiers, as expected by GitHub code scanning
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "muaddib"
+	toolURI      = "https://github.com/RichardSlater/muaddib"
+)
+
+// sarifRules lists a rule per finding category, in driver metadata order
+var sarifRules = []struct {
+	category    scanner.FindingCategory
+	name        string
+	description string
+}{
+	{scanner.CategoryVulnerablePackage, "VulnerablePackage", "Dependency matches a known-compromised npm package version"},
+	{scanner.CategoryMaliciousWorkflow, "MaliciousWorkflow", "GitHub Actions workflow matches a Shai-Hulud worm pattern"},
+	{scanner.CategoryMaliciousScript, "MaliciousScript", "npm lifecycle script matches a Shai-Hulud worm pattern"},
+	{scanner.CategoryMaliciousBranch, "MaliciousBranch", "Branch created by the Shai-Hulud worm"},
+	{scanner.CategoryMaliciousRepo, "MaliciousRepo", "Migration repository created by the Shai-Hulud worm"},
+	{scanner.CategoryAdvisory, "Advisory", "Heuristic finding that warrants review"},
+}
+
+// SARIFLog is the root of a SARIF 2.1.0 document
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun holds the results of a single scan
+type SARIFRun struct {
+	Tool       SARIFTool           `json:"tool"`
+	Results    []*SARIFResult      `json:"results"`
+	Properties *SARIFRunProperties `json:"properties,omitempty"`
+}
+
+// SARIFRunProperties holds muaddib-specific metadata about a run
+type SARIFRunProperties struct {
+	Sources []*JSONSource `json:"sources"`
+}
+
+// SARIFTool describes the tool that produced the results
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool's name, version, and rules
+type SARIFDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a kind of finding
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFMessage is a plain-text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a single finding
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+// SARIFLocation is where a finding was made. Findings without a file, such as
+// malicious branches and repositories, use a logical location instead.
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation is a file within the repository
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           SARIFRegion           `json:"region"`
+}
+
+// SARIFArtifactLocation is the path of a file relative to the repository root
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SARIFRegion is the part of a file a finding applies to. Findings without
+// a known line are made per file, so the region is the start of the file.
+type SARIFRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SARIFLogicalLocation names a repository or branch
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// NewSARIFLog builds a SARIF log from the scan results. toolVersion is
+// recorded in the driver metadata and the IOC sources in the run properties.
+func NewSARIFLog(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, toolVersion string, sources []vuln.Source) *SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:           toolName,
+			Version:        toolVersion,
+			InformationURI: toolURI,
+		}},
+		Results:    []*SARIFResult{},
+		Properties: &SARIFRunProperties{Sources: toJSONSources(sources)},
+	}
+	for _, rule := range sarifRules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &SARIFRule{
+			ID:               string(rule.category),
+			Name:             rule.name,
+			ShortDescription: SARIFMessage{Text: rule.description},
+		})
+	}
+
+	var findings []*scanner.Finding
+	for _, result := range results {
+		findings = append(findings, result.Findings()...)
+	}
+	if orgResult != nil {
+		findings = append(findings, orgResult.Findings()...)
+	}
+	for _, f := range findings {
+		run.Results = append(run.Results, toSARIFResult(f))
+	}
+
+	return &SARIFLog{Schema: sarifSchema, Version: sarifVersion, Runs: []SARIFRun{run}}
+}
+
+// toSARIFResult converts a finding to a SARIF result
+func toSARIFResult(f *scanner.Finding) *SARIFResult {
+	result := &SARIFResult{
+		RuleID:              string(f.Category),
+		Level:               sarifLevel(f.Severity),
+		Message:             SARIFMessage{Text: sarifMessageText(f)},
+		PartialFingerprints: map[string]string{"muaddibFindingId/v1": f.ID},
+		Properties: map[string]string{
+			"repository": f.RepoName,
+			"severity":   string(f.Severity),
+		},
+	}
+	if f.URL != "" {
+		result.Properties["url"] = f.URL
+	}
+	if f.Campaign != "" {
+		result.Properties["campaign"] = f.Campaign
+	}
+	if f.AdvisoryID != "" {
+		result.Properties["advisory"] = f.AdvisoryID
+	}
+
+	if f.FilePath != "" {
+		result.Locations = []SARIFLocation{{PhysicalLocation: &SARIFPhysicalLocation{
+			ArtifactLocation: SARIFArtifactLocation{URI: f.FilePath, URIBaseID: "%SRCROOT%"},
+			Region:           SARIFRegion{StartLine: max(f.Line, 1)},
+		}}}
+		return result
+	}
+
+	// Branches and migration repositories have no file to point at
+	name := f.RepoName
+	if f.Category == scanner.CategoryMaliciousBranch {
+		name = f.Detail
+	}
+	result.Locations = []SARIFLocation{{LogicalLocations: []SARIFLogicalLocation{{
+		Name:               name,
+		FullyQualifiedName: f.RepoName,
+		Kind:               "resource",
+	}}}}
+	return result
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity scanner.Severity) string {
+	switch severity {
+	case scanner.SeverityCritical, scanner.SeverityHigh:
+		return "error"
+	case scanner.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifMessageText describes a finding in a single sentence
+func sarifMessageText(f *scanner.Finding) string {
+	switch f.Category {
+	case scanner.CategoryVulnerablePackage:
+		text := fmt.Sprintf("%s@%s in %s matches a compromised package", f.PackageName, f.Version, f.RepoName)
+		if f.IOCVersion != "" && f.IOCVersion != f.Version {
+			text += " (IOC version " + f.IOCVersion + ")"
+		}
+		if f.Detail != "" {
+			text += " [" + f.Detail + "]"
+		}
+		return text
+	case scanner.CategoryMaliciousBranch:
+		return fmt.Sprintf("Malicious branch %s in %s", f.Detail, f.RepoName)
+	case scanner.CategoryMaliciousRepo:
+		return fmt.Sprintf("Malicious migration repository %s: %s", f.RepoName, f.Detail)
+	default:
+		return fmt.Sprintf("%s in %s: %s", sarifRuleName(f.Category), f.RepoName, f.Detail)
+	}
+}
+
+// sarifRuleName returns the rule name for a finding category
+func sarifRuleName(category scanner.FindingCategory) string {
+	for _, rule := range sarifRules {
+		if rule.category == category {
+			return rule.name
+		}
+	}
+	return string(category)
+}
+
+// WriteSARIFReport writes the scan results as a SARIF 2.1.0 log
+func WriteSARIFReport(w io.Writer, results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, toolVersion string, sources []vuln.Source) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(NewSARIFLog(results, orgResult, toolVersion, sources)); err != nil {
+		return fmt.Errorf("failed to encode SARIF report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+func TestWriteSARIFReport(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "packages/web/package-lock.json", "test-muaddib-bad", "1.0.0"),
+			},
+			MaliciousWorkflows: []*scanner.MaliciousWorkflow{
+				{RepoName: "test-org/a", FilePath: ".github/workflows/discussion.yaml", Pattern: "discussion.body"},
+			},
+			MaliciousScripts: []*scanner.MaliciousScript{
+				{RepoName: "test-org/a", FilePath: "package.json", ScriptName: "postinstall", Command: "node bundle.js"},
+			},
+		},
+	}
+
+	log := writeSARIF(t, results)
+	driver := log.Runs[0].Tool.Driver
+	if driver.Name != "muaddib" || driver.Version != "v1.2.3" {
+		t.Errorf("expected driver muaddib v1.2.3, got %s %s", driver.Name, driver.Version)
+	}
+
+	byRule := make(map[string]*SARIFResult)
+	for _, result := range log.Runs[0].Results {
+		byRule[result.RuleID] = result
+	}
+	if len(byRule) != 3 {
+		t.Fatalf("expected results for 3 rules, got %d", len(byRule))
+	}
+
+	tests := []struct {
+		ruleID string
+		uri    string
+		level  string
+	}{
+		{ruleID: "vulnerable-package", uri: "packages/web/package-lock.json", level: "error"},
+		{ruleID: "malicious-workflow", uri: ".github/workflows/discussion.yaml", level: "error"},
+		{ruleID: "malicious-script", uri: "package.json", level: "error"},
+	}
+	for _, tt := range tests {
+		result := byRule[tt.ruleID]
+		if result == nil {
+			t.Errorf("missing %s result", tt.ruleID)
+			continue
+		}
+		if result.Level != tt.level {
+			t.Errorf("%s: expected level %s, got %s", tt.ruleID, tt.level, result.Level)
+		}
+		loc := result.Locations[0].PhysicalLocation
+		if loc == nil || loc.ArtifactLocation.URI != tt.uri {
+			t.Errorf("%s: expected physical location %s, got %+v", tt.ruleID, tt.uri, result.Locations)
+		}
+	}
+}
+
+func TestWriteSARIFReport_BranchUsesLogicalLocation(t *testing.T) {
+	log := writeSARIF(t, []*scanner.RepoScanResult{{
+		RepoName:          "test-org/a",
+		MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org/a", BranchName: "shai-hulud"}},
+	}})
+
+	results := log.Runs[0].Results
+	if len(results) != 1 || results[0].RuleID != "malicious-branch" {
+		t.Fatalf("expected a single malicious-branch result, got %+v", results)
+	}
+	location := results[0].Locations[0]
+	if location.PhysicalLocation != nil {
+		t.Errorf("expected no physical location for a branch, got %+v", location.PhysicalLocation)
+	}
+	logical := location.LogicalLocations
+	if len(logical) != 1 || logical[0].Name != "shai-hulud" || logical[0].FullyQualifiedName != "test-org/a" {
+		t.Errorf("expected logical location shai-hulud in test-org/a, got %+v", logical)
+	}
+}
+
+func TestToSARIFResult_LinksToGitHub(t *testing.T) {
+	result := toSARIFResult(&scanner.Finding{
+		Category: scanner.CategoryVulnerablePackage,
+		RepoName: "test-org/a",
+		FilePath: "package-lock.json",
+		Line:     12,
+		URL:      "https://github.com/test-org/a/blob/main/package-lock.json#L12",
+	})
+
+	if result.Properties["url"] != "https://github.com/test-org/a/blob/main/package-lock.json#L12" {
+		t.Errorf("expected the GitHub URL in the properties, got %v", result.Properties)
+	}
+	if line := result.Locations[0].PhysicalLocation.Region.StartLine; line != 12 {
+		t.Errorf("expected start line 12, got %d", line)
+	}
+}
+
+// writeSARIF writes the results as SARIF and parses the log back
+func writeSARIF(t *testing.T, results []*scanner.RepoScanResult) *SARIFLog {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := WriteSARIFReport(&buf, results, &scanner.OrgScanResult{}, "v1.2.3", nil); err != nil {
+		t.Fatalf("WriteSARIFReport failed: %v", err)
+	}
+
+	var log SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected a single SARIF 2.1.0 run, got version %q with %d runs", log.Version, len(log.Runs))
+	}
+	return &log
+}
+
+func TestSARIFLevel(t *testing.T) {
+	tests := map[scanner.Severity]string{
+		scanner.SeverityCritical: "error",
+		scanner.SeverityHigh:     "error",
+		scanner.SeverityMedium:   "warning",
+		scanner.SeverityLow:      "note",
+	}
+	for severity, want := range tests {
+		if got := sarifLevel(severity); got != want {
+			t.Errorf("sarifLevel(%s) = %s, want %s", severity, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// GroupBy selects how the terminal reporter organises findings
+type GroupBy string
+
+const (
+	// GroupByRepo reports findings under each repository as it is scanned
+	GroupByRepo GroupBy = "repo"
+	// GroupBySeverity lists findings across all repositories, most severe first
+	GroupBySeverity GroupBy = "severity"
+)
+
+// ParseGroupBy validates a grouping name
+func ParseGroupBy(name string) (GroupBy, error) {
+	switch GroupBy(name) {
+	case GroupByRepo, GroupBySeverity:
+		return GroupBy(name), nil
+	default:
+		return "", fmt.Errorf("unknown grouping %q (expected repo or severity)", name)
+	}
+}
+
+// WithGroupBy sets how findings are organised in the output
+func WithGroupBy(g GroupBy) ReporterOption {
+	return func(r *TerminalReporter) {
+		r.groupBy = g
+	}
+}
+
+// GroupsBySeverity reports whether per-repository results are deferred to the
+// severity-ordered listing in the summary
+func (r *TerminalReporter) GroupsBySeverity() bool {
+	return r.groupBy == GroupBySeverity
+}
+
+// severityOrder ranks severities from most to least urgent
+var severityOrder = []scanner.Severity{
+	scanner.SeverityCritical,
+	scanner.SeverityHigh,
+	scanner.SeverityMedium,
+	scanner.SeverityLow,
+}
+
+// findingsBySeverity flattens all findings and groups them by severity, each
+// group ordered by repository and file
+func findingsBySeverity(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) map[scanner.Severity][]*scanner.Finding {
+	var all []*scanner.Finding
+	if orgResult != nil {
+		all = append(all, orgResult.Findings()...)
+	}
+	for _, result := range results {
+		all = append(all, result.Findings()...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].RepoName != all[j].RepoName {
+			return all[i].RepoName < all[j].RepoName
+		}
+		return all[i].FilePath < all[j].FilePath
+	})
+
+	groups := make(map[scanner.Severity][]*scanner.Finding)
+	for _, f := range all {
+		groups[f.Severity] = append(groups[f.Severity], f)
+	}
+	return groups
+}
+
+// findingSubject describes what a finding is about in a single line
+func findingSubject(f *scanner.Finding) string {
+	if f.Category == scanner.CategoryVulnerablePackage {
+		return f.PackageName + "@" + f.Version
+	}
+	return f.Detail
+}
+
+// reportFindingsBySeverity lists every finding across repositories, most severe first
+func (r *TerminalReporter) reportFindingsBySeverity(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) {
+	groups := findingsBySeverity(results, orgResult)
+
+	for _, severity := range severityOrder {
+		findings := groups[severity]
+		if len(findings) == 0 {
+			continue
+		}
+		c := r.errorColor
+		if severity == scanner.SeverityMedium || severity == scanner.SeverityLow {
+			c = r.warnColor
+		}
+		c.Fprintf(r.out, "%s (%d):\n", severity, len(findings))
+		for _, f := range findings {
+			location := f.RepoName
+			if f.FilePath != "" {
+				location += "/" + f.FilePath
+			}
+			c.Fprintf(r.out, "  • [%s] %s%s\n", f.Category, findingSubject(f), r.knownMarker(f.Known))
+			r.dimColor.Fprintf(r.out, "    %s\n", r.hyperlink(f.URL, location))
+		}
+		fmt.Fprintln(r.out)
+	}
+}
@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+func TestReportSummary_GroupBySeverityOrdersMostSevereFirst(t *testing.T) {
+	lowConfidence := vulnerablePackage("test-org/a", "package.json", "test-muaddib-peer", "1.0.0")
+	lowConfidence.Confidence = scanner.ConfidenceLow
+	highConfidence := vulnerablePackage("test-org/b", "package-lock.json", "test-muaddib-bad", "1.0.0")
+	highConfidence.Confidence = scanner.ConfidenceHigh
+
+	results := []*scanner.RepoScanResult{
+		{RepoName: "test-org/a", VulnerablePackages: []*scanner.VulnerablePackage{lowConfidence}},
+		{
+			RepoName:           "test-org/b",
+			VulnerablePackages: []*scanner.VulnerablePackage{highConfidence},
+			MaliciousScripts: []*scanner.MaliciousScript{
+				{RepoName: "test-org/b", FilePath: "package.json", ScriptName: "postinstall", Command: "node bundle.js"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf), WithGroupBy(GroupBySeverity)).ReportSummary(results, nil, 0)
+	out := buf.String()
+
+	critical := strings.Index(out, "critical (1):")
+	high := strings.Index(out, "high (1):")
+	low := strings.Index(out, "low (1):")
+	if critical < 0 || high < 0 || low < 0 {
+		t.Fatalf("expected critical, high and low groups, got:\n%s", out)
+	}
+	if !(critical < high && high < low) {
+		t.Errorf("expected groups ordered critical, high, low, got:\n%s", out)
+	}
+	if i := strings.Index(out, "test-muaddib-bad@1.0.0"); i < high || i > low {
+		t.Errorf("expected high-confidence package in the high group, got:\n%s", out)
+	}
+	if strings.Contains(out, "Affected repositories:\n") {
+		t.Error("expected the per-repo affected listing to be replaced by the severity listing")
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for _, name := range []string{"repo", "severity"} {
+		if _, err := ParseGroupBy(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	if _, err := ParseGroupBy("owner"); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
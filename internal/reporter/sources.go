@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// JSONSource is an IOC feed the scan's detections were based on
+type JSONSource struct {
+	Location  string `json:"location"`
+	FetchedAt string `json:"fetched_at"`
+	SHA256    string `json:"sha256"`
+	Entries   int    `json:"entries"`
+	Cached    bool   `json:"cached,omitempty"`
+	Campaign  string `json:"campaign,omitempty"`
+}
+
+// WithSources records the IOC feeds the vulnerability database was loaded
+// from in the report
+func WithSources(sources []vuln.Source) JSONOption {
+	return func(o *jsonOptions) {
+		o.sources = sources
+	}
+}
+
+// toJSONSources converts IOC sources to their report form
+func toJSONSources(sources []vuln.Source) []*JSONSource {
+	out := make([]*JSONSource, 0, len(sources))
+	for _, source := range sources {
+		out = append(out, &JSONSource{
+			Location:  source.Location,
+			FetchedAt: source.FetchedAt.UTC().Format(time.RFC3339),
+			SHA256:    source.SHA256,
+			Entries:   source.Entries,
+			Cached:    source.Cached,
+			Campaign:  source.Campaign,
+		})
+	}
+	return out
+}
+
+// ReportSources lists the IOC feeds the scan's detections were based on
+func (r *TerminalReporter) ReportSources(sources []vuln.Source) {
+	r.atomically(func(b *TerminalReporter) { b.reportSources(sources) })
+}
+
+// reportSources writes the output of ReportSources without locking
+func (r *TerminalReporter) reportSources(sources []vuln.Source) {
+	if len(sources) == 0 {
+		return
+	}
+
+	fmt.Fprintln(r.out)
+	r.infoColor.Fprintf(r.out, "📚 IOC sources:\n")
+	for _, source := range sources {
+		fetched := "fetched"
+		if source.Cached {
+			fetched = "cached copy fetched"
+		}
+		campaign := ""
+		if source.Campaign != "" {
+			campaign = "campaign " + source.Campaign + ", "
+		}
+		r.dimColor.Fprintf(r.out, "  • %s (%s%d entries, %s %s)\n",
+			source.Location, campaign, source.Entries, fetched, source.FetchedAt.UTC().Format(time.RFC3339))
+	}
+}
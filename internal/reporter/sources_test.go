@@ -0,0 +1,64 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+var testSources = []vuln.Source{
+	{Location: "https://example.com/iocs.csv", FetchedAt: time.Date(2025, 9, 16, 12, 0, 0, 0, time.UTC), SHA256: "abc123", Entries: 42},
+	{Location: "local-iocs.csv", FetchedAt: time.Date(2025, 9, 16, 12, 0, 1, 0, time.UTC), SHA256: "def456", Entries: 3},
+}
+
+func TestWriteJSONReport_RecordsSources(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONReport(&buf, nil, nil, 45, WithSources(testSources)); err != nil {
+		t.Fatalf("WriteJSONReport failed: %v", err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(report.Sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(report.Sources))
+	}
+	want := JSONSource{Location: "https://example.com/iocs.csv", FetchedAt: "2025-09-16T12:00:00Z", SHA256: "abc123", Entries: 42}
+	if *report.Sources[0] != want {
+		t.Errorf("expected %+v, got %+v", want, *report.Sources[0])
+	}
+}
+
+func TestNewSARIFLog_RecordsSources(t *testing.T) {
+	log := NewSARIFLog(nil, &scanner.OrgScanResult{}, "v1.2.3", testSources)
+
+	properties := log.Runs[0].Properties
+	if properties == nil || len(properties.Sources) != 2 {
+		t.Fatalf("expected 2 sources in the run properties, got %+v", properties)
+	}
+	if got := properties.Sources[1]; got.Location != "local-iocs.csv" || got.Entries != 3 {
+		t.Errorf("expected local-iocs.csv with 3 entries, got %+v", got)
+	}
+}
+
+func TestReportSources(t *testing.T) {
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf)).ReportSources(testSources)
+
+	out := buf.String()
+	for _, want := range []string{
+		"IOC sources",
+		"https://example.com/iocs.csv (42 entries, fetched 2025-09-16T12:00:00Z)",
+		"local-iocs.csv (3 entries, fetched 2025-09-16T12:00:01Z)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+// SummaryReport holds the headline counts of a scan, without findings, so it
+// stays small whatever the size of the scan. The version and time let nightly
+// results be trended.
+type SummaryReport struct {
+	Version     string      `json:"version"`
+	GeneratedAt time.Time   `json:"generated_at"` // UTC
+	Summary     JSONSummary `json:"summary"`
+}
+
+// NewSummaryReport builds the summary report from the same counts as ReportSummary
+func NewSummaryReport(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int, version string, now time.Time) *SummaryReport {
+	return &SummaryReport{
+		Version:     version,
+		GeneratedAt: now.UTC(),
+		Summary:     newJSONSummary(calculateSummaryStats(results, orgResult), vulnDBSize),
+	}
+}
+
+// WriteSummaryReport writes the summary report as indented JSON
+func WriteSummaryReport(w io.Writer, report *SummaryReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode summary report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rslater/muaddib/internal/scanner"
+)
+
+func TestWriteSummaryReport(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName:      "test-org/a",
+			FilesScanned:  1,
+			TotalPackages: 4,
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "package.json", "test-muaddib-bad", "1.0.0"),
+			},
+		},
+		{RepoName: "test-org/b"},
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{{RepoName: "test-org/migration", Description: "Shai-Hulud Migration"}},
+	}
+	now := time.Date(2025, 12, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	var buf bytes.Buffer
+	if err := WriteSummaryReport(&buf, NewSummaryReport(results, orgResult, 5, "v1.2.3", now)); err != nil {
+		t.Fatalf("WriteSummaryReport failed: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if got["version"] != "v1.2.3" || got["generated_at"] != "2025-12-01T12:00:00Z" {
+		t.Errorf("expected the version and a UTC timestamp, got %v and %v", got["version"], got["generated_at"])
+	}
+	if _, ok := got["findings"]; ok {
+		t.Error("expected the summary to leave out the findings")
+	}
+	summary, _ := got["summary"].(map[string]any)
+	for key, want := range map[string]float64{
+		"repositories_scanned": 2,
+		"packages_checked":     4,
+		"vulnerable_packages":  1,
+		"malicious_repos":      1,
+		"no_package_files":     1,
+		"ioc_entries":          5,
+	} {
+		if summary[key] != want {
+			t.Errorf("expected %s to be %v, got %v", key, want, summary[key])
+		}
+	}
+}
@@ -1,19 +1,30 @@
 package reporter
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/fatih/color"
 	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
 )
 
 // TerminalReporter outputs scan results to the terminal with colors and emoji
 type TerminalReporter struct {
 	out          io.Writer
+	mu           *sync.Mutex  // Serialises writes to out; shared by block copies
+	bar          *progressBar // Redrawn below other output; nil when disabled
 	verbose      bool
+	listEmpty    bool
+	groupBy      GroupBy
+	banner       bool  // PrintBanner writes the banner; off for machine-readable formats
+	color        *bool // Set by WithColor; nil detects color support from out
+	hyperlinks   bool  // Link findings to GitHub; enabled with color
 	headerColor  *color.Color
 	errorColor   *color.Color
 	warnColor    *color.Color
@@ -22,6 +33,21 @@ type TerminalReporter struct {
 	dimColor     *color.Color
 }
 
+// atomically renders output into a buffer and writes it under the output lock,
+// so each block stays contiguous when repositories are reported concurrently
+func (r *TerminalReporter) atomically(render func(b *TerminalReporter)) {
+	var buf bytes.Buffer
+	block := *r
+	block.out = &buf
+	render(&block)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bar.clear(r.out)
+	r.out.Write(buf.Bytes())
+	r.bar.draw(r.out)
+}
+
 // ReporterOption configures the TerminalReporter
 type ReporterOption func(*TerminalReporter)
 
@@ -39,10 +65,29 @@ func WithVerbose(v bool) ReporterOption {
 	}
 }
 
+// WithListEmpty lists repositories without package files in the summary.
+// Verbose output always lists them.
+func WithListEmpty(enabled bool) ReporterOption {
+	return func(r *TerminalReporter) {
+		r.listEmpty = enabled
+	}
+}
+
+// WithColor forces colored output on or off. By default color is used when
+// the output is a terminal and NO_COLOR is not set; see IsTerminal.
+func WithColor(enabled bool) ReporterOption {
+	return func(r *TerminalReporter) {
+		r.color = &enabled
+	}
+}
+
 // NewTerminalReporter creates a new terminal reporter
 func NewTerminalReporter(opts ...ReporterOption) *TerminalReporter {
 	r := &TerminalReporter{
 		out:          os.Stdout,
+		mu:           &sync.Mutex{},
+		groupBy:      GroupByRepo,
+		banner:       true,
 		headerColor:  color.New(color.FgMagenta, color.Bold),
 		errorColor:   color.New(color.FgRed, color.Bold),
 		warnColor:    color.New(color.FgYellow),
@@ -54,28 +99,138 @@ func NewTerminalReporter(opts ...ReporterOption) *TerminalReporter {
 	for _, opt := range opts {
 		opt(r)
 	}
+	if r.color != nil {
+		r.setColor(*r.color)
+	} else {
+		r.setColor(IsTerminal(r.out))
+	}
 
 	return r
 }
 
-// ReportProgress reports a progress message
+// setColor turns color and hyperlinks on or off for every output style.
+// Emoji and text are written either way; only the escape sequences change.
+func (r *TerminalReporter) setColor(enabled bool) {
+	r.hyperlinks = enabled
+	for _, c := range []*color.Color{r.headerColor, r.errorColor, r.warnColor, r.successColor, r.infoColor, r.dimColor} {
+		if enabled {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+}
+
+// IsTerminal checks if w is a terminal that should receive colored output.
+// NO_COLOR, TERM=dumb, and non-terminal writers such as files and pipes get
+// plain text.
+func IsTerminal(w io.Writer) bool {
+	return os.Getenv("NO_COLOR") == "" && IsInteractive(w)
+}
+
+// IsInteractive checks if w is a terminal that can redraw a line, as the
+// progress bar does. TERM=dumb and non-terminal writers are not.
+func IsInteractive(w io.Writer) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReportDiff reports the findings that changed since a previous report: new
+// findings in red with a "+", resolved in green with a "-", unchanged dimmed
+func (r *TerminalReporter) ReportDiff(diff *ReportDiff, previousPath string) {
+	r.atomically(func(b *TerminalReporter) { b.reportDiff(diff, previousPath) })
+}
+
+// reportDiff writes the output of ReportDiff without locking
+func (r *TerminalReporter) reportDiff(diff *ReportDiff, previousPath string) {
+	fmt.Fprintln(r.out)
+	r.headerColor.Fprintf(r.out, "🔀 Changes since %s: %d new, %d resolved, %d unchanged\n",
+		previousPath, len(diff.New), len(diff.Resolved), len(diff.Unchanged))
+
+	for _, f := range diff.New {
+		r.errorColor.Fprintf(r.out, "+ %s\n", describeJSONFinding(f))
+	}
+	for _, f := range diff.Resolved {
+		r.successColor.Fprintf(r.out, "- %s\n", describeJSONFinding(f))
+	}
+	for _, f := range diff.Unchanged {
+		r.dimColor.Fprintf(r.out, "  %s\n", describeJSONFinding(f))
+	}
+}
+
+// describeJSONFinding formats a finding as a single diff line
+func describeJSONFinding(f *JSONFinding) string {
+	subject := f.Detail
+	if f.PackageName != "" {
+		subject = f.PackageName + "@" + f.Version
+	}
+
+	line := fmt.Sprintf("[%s] %s %s: %s", f.Severity, f.Category, f.Repository, subject)
+	if f.FilePath != "" {
+		line += " (" + f.FilePath + ")"
+	}
+	return line
+}
+
+// ReportProgress reports a progress message. While the progress bar tracks a
+// scan it stands in for these messages; verbose output has no bar.
 func (r *TerminalReporter) ReportProgress(message string) {
+	if r.progressShown() {
+		return
+	}
+	r.atomically(func(b *TerminalReporter) { b.reportProgress(message) })
+}
+
+// reportProgress writes the output of ReportProgress without locking
+func (r *TerminalReporter) reportProgress(message string) {
 	r.dimColor.Fprintf(r.out, "%s\n", message)
 }
 
 // ReportRepoStart reports the start of scanning a repository
 func (r *TerminalReporter) ReportRepoStart(repoName string) {
+	r.atomically(func(b *TerminalReporter) { b.reportRepoStart(repoName) })
+}
+
+// reportRepoStart writes the output of ReportRepoStart without locking
+func (r *TerminalReporter) reportRepoStart(repoName string) {
 	r.headerColor.Fprintf(r.out, "\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	r.headerColor.Fprintf(r.out, "📁 Repository: %s\n", repoName)
 	r.headerColor.Fprintf(r.out, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 }
 
+// ReportRepo reports a repository header and its results as a single block
+func (r *TerminalReporter) ReportRepo(repoName string, result *scanner.RepoScanResult) {
+	r.atomically(func(b *TerminalReporter) {
+		b.reportRepoStart(repoName)
+		b.reportRepoResult(result)
+	})
+}
+
 // ReportRepoResult reports the results for a single repository
 func (r *TerminalReporter) ReportRepoResult(result *scanner.RepoScanResult) {
+	r.atomically(func(b *TerminalReporter) { b.reportRepoResult(result) })
+}
+
+// reportRepoResult writes the output of ReportRepoResult without locking
+func (r *TerminalReporter) reportRepoResult(result *scanner.RepoScanResult) {
 	if result.Error != nil {
 		r.errorColor.Fprintf(r.out, "❌ Error scanning repository: %v\n", result.Error)
 		return
 	}
+	if result.NotScannable != "" {
+		r.dimColor.Fprintf(r.out, "⏭️  Not scannable: %s\n", result.NotScannable)
+		return
+	}
 
 	// If no files scanned and no malicious branches, nothing to report
 	if result.FilesScanned == 0 && len(result.MaliciousBranches) == 0 {
@@ -86,9 +241,20 @@ func (r *TerminalReporter) ReportRepoResult(result *scanner.RepoScanResult) {
 		r.infoColor.Fprintf(r.out, "📦 Scanned %d files, found %d unique packages\n",
 			result.FilesScanned, result.TotalPackages)
 	}
+	if len(result.WorkspaceMembers) > 0 {
+		r.dimColor.Fprintf(r.out, "🗂️  Including %d workspace member(s)\n", len(result.WorkspaceMembers))
+	}
+	for _, lockfile := range result.BinaryLockfiles {
+		r.warnColor.Fprintf(r.out, "⚠️  Cannot read the binary %s, so the packages it pins were not checked; commit a text bun.lock instead (bun install --save-text-lockfile)\n", lockfile)
+	}
+	r.reportUnverifiableDeps(result.UnverifiableDeps)
 
-	if !r.resultHasIssues(result) {
+	if !resultHasIssues(result) {
 		r.successColor.Fprintf(r.out, "✅ No vulnerable packages or malicious patterns detected\n")
+		r.reportAdvisories(result.Advisories, result.Ref)
+		r.reportSuspiciousPackages(result.SuspiciousPackages, result.Ref)
+		r.reportSuspiciousConfigs(result.SuspiciousConfigs, result.Ref)
+		r.reportVersionSprawl(result.VersionSprawl)
 		return
 	}
 
@@ -96,14 +262,30 @@ func (r *TerminalReporter) ReportRepoResult(result *scanner.RepoScanResult) {
 		len(result.MaliciousScripts) + len(result.MaliciousBranches)
 	r.errorColor.Fprintf(r.out, "🔴 Found %d issue(s):\n\n", vulnCount)
 
-	r.reportMaliciousBranches(result.MaliciousBranches)
-	r.reportMaliciousWorkflows(result.MaliciousWorkflows)
-	r.reportMaliciousScripts(result.MaliciousScripts)
-	r.reportVulnerablePackages(result.VulnerablePackages)
+	r.reportMaliciousBranches(result.MaliciousBranches, result.Ref)
+	r.reportMaliciousWorkflows(result.MaliciousWorkflows, result.Ref)
+	r.reportMaliciousScripts(result.MaliciousScripts, result.Ref)
+	r.reportVulnerablePackages(result.VulnerablePackages, result.Ref)
+	r.reportAdvisories(result.Advisories, result.Ref)
+	r.reportSuspiciousPackages(result.SuspiciousPackages, result.Ref)
+	r.reportSuspiciousConfigs(result.SuspiciousConfigs, result.Ref)
+	r.reportVersionSprawl(result.VersionSprawl)
+}
+
+// reportUnverifiableDeps lists, in verbose mode, the git and URL dependencies
+// that could not be checked against the IOC list
+func (r *TerminalReporter) reportUnverifiableDeps(deps []*scanner.UnverifiableDependency) {
+	if !r.verbose {
+		return
+	}
+	for _, dep := range deps {
+		r.dimColor.Fprintf(r.out, "❔ Unverifiable %s dependency %s (%s) in %s is not checked against the IOC list\n",
+			dep.SpecType, dep.Name, dep.Spec, dep.FilePath)
+	}
 }
 
 // resultHasIssues checks if a result contains any issues
-func (r *TerminalReporter) resultHasIssues(result *scanner.RepoScanResult) bool {
+func resultHasIssues(result *scanner.RepoScanResult) bool {
 	return len(result.VulnerablePackages) > 0 ||
 		len(result.MaliciousWorkflows) > 0 ||
 		len(result.MaliciousScripts) > 0 ||
@@ -111,46 +293,162 @@ func (r *TerminalReporter) resultHasIssues(result *scanner.RepoScanResult) bool
 }
 
 // reportMaliciousBranches outputs malicious branch detections
-func (r *TerminalReporter) reportMaliciousBranches(branches []*scanner.MaliciousBranch) {
+func (r *TerminalReporter) reportMaliciousBranches(branches []*scanner.MaliciousBranch, ref string) {
 	if len(branches) == 0 {
 		return
 	}
 	r.errorColor.Fprintf(r.out, "  🌿 Malicious Branch Detected:\n")
 	for _, mb := range branches {
-		r.errorColor.Fprintf(r.out, "     🔴 Branch: %s\n", mb.BranchName)
+		branch := mb.BranchName
+		if ref != "" {
+			branch = r.hyperlink(scanner.BranchURL(mb.RepoName, mb.BranchName), branch)
+		}
+		r.errorColor.Fprintf(r.out, "     🔴 Branch: %s%s\n", branch, r.knownMarker(mb.Known))
+		r.reportBranchInspection(mb)
 	}
 	fmt.Fprintln(r.out)
 }
 
+// maxChangedFiles is how many files a malicious branch changed are listed
+// outside verbose mode
+const maxChangedFiles = 10
+
+// reportBranchInspection outputs what a malicious branch changed, if it was
+// inspected. Only the first few changed files are listed unless verbose.
+func (r *TerminalReporter) reportBranchInspection(mb *scanner.MaliciousBranch) {
+	if mb.Inspection == nil {
+		return
+	}
+
+	r.dimColor.Fprintf(r.out, "        Changed files: %d\n", len(mb.ChangedFiles))
+	listed := mb.ChangedFiles
+	if !r.verbose && len(listed) > maxChangedFiles {
+		listed = listed[:maxChangedFiles]
+	}
+	for _, filePath := range listed {
+		r.dimColor.Fprintf(r.out, "          • %s\n", filePath)
+	}
+	if hidden := len(mb.ChangedFiles) - len(listed); hidden > 0 {
+		r.dimColor.Fprintf(r.out, "          … and %d more (use --verbose to list all)\n", hidden)
+	}
+	for _, filePath := range mb.PayloadFiles {
+		r.errorColor.Fprintf(r.out, "        ⚠️  Payload file: %s\n", filePath)
+	}
+	for _, ms := range mb.Inspection.MaliciousScripts {
+		r.errorColor.Fprintf(r.out, "        ⚠️  Script in %s: %s → %s\n", ms.FilePath, ms.ScriptName, ms.Command)
+	}
+	for _, mw := range mb.Inspection.MaliciousWorkflows {
+		r.errorColor.Fprintf(r.out, "        ⚠️  Workflow: %s\n", mw.FilePath)
+	}
+	for _, vp := range mb.Inspection.VulnerablePackages {
+		r.errorColor.Fprintf(r.out, "        ⚠️  Vulnerable package in %s: %s@%s\n", vp.FilePath, vp.Package.Name, vp.Package.Version)
+	}
+}
+
 // reportMaliciousWorkflows outputs malicious workflow detections
-func (r *TerminalReporter) reportMaliciousWorkflows(workflows []*scanner.MaliciousWorkflow) {
+func (r *TerminalReporter) reportMaliciousWorkflows(workflows []*scanner.MaliciousWorkflow, ref string) {
 	if len(workflows) == 0 {
 		return
 	}
 	r.errorColor.Fprintf(r.out, "  🐛 Malicious Workflow Detected:\n")
 	for _, mw := range workflows {
-		r.errorColor.Fprintf(r.out, "     🔴 %s\n", mw.FilePath)
+		r.errorColor.Fprintf(r.out, "     🔴 %s%s\n", r.fileLink(mw.RepoName, ref, mw.FilePath), r.knownMarker(mw.Known))
 		r.dimColor.Fprintf(r.out, "        Pattern: %s\n", mw.Pattern)
+		switch mw.Kind {
+		case scanner.WorkflowKindPersistence:
+			r.dimColor.Fprintf(r.out, "        Kind: %s (scheduled: %s)\n", mw.Kind, strings.Join(mw.Schedules, ", "))
+		case scanner.WorkflowKindRemoteCodeExecution:
+			r.dimColor.Fprintf(r.out, "        Kind: %s (low confidence, also used by legitimate setup steps)\n", mw.Kind)
+		}
+		r.reportActionsEnabled(mw)
 	}
 	fmt.Fprintln(r.out)
 }
 
+// reportActionsEnabled outputs whether Actions can run a malicious workflow, if known
+func (r *TerminalReporter) reportActionsEnabled(mw *scanner.MaliciousWorkflow) {
+	if mw.ActionsEnabled == nil {
+		return
+	}
+	if *mw.ActionsEnabled {
+		r.errorColor.Fprintf(r.out, "        Actions enabled: yes (severity: %s)\n", mw.Severity())
+		return
+	}
+	r.dimColor.Fprintf(r.out, "        Actions enabled: no (severity: %s)\n", mw.Severity())
+}
+
 // reportMaliciousScripts outputs malicious script detections
-func (r *TerminalReporter) reportMaliciousScripts(scripts []*scanner.MaliciousScript) {
+func (r *TerminalReporter) reportMaliciousScripts(scripts []*scanner.MaliciousScript, ref string) {
 	if len(scripts) == 0 {
 		return
 	}
 	r.errorColor.Fprintf(r.out, "  💉 Malicious Script Detected:\n")
 	for _, ms := range scripts {
-		r.errorColor.Fprintf(r.out, "     🔴 %s\n", ms.FilePath)
+		r.errorColor.Fprintf(r.out, "     🔴 %s%s\n", r.fileLink(ms.RepoName, ref, ms.FilePath), r.knownMarker(ms.Known))
 		r.dimColor.Fprintf(r.out, "        Script: %s → %s\n", ms.ScriptName, ms.Command)
 		r.dimColor.Fprintf(r.out, "        Pattern: %s\n", ms.Pattern)
+		if ms.Kind == scanner.ScriptKindRemoteCodeExecution {
+			r.dimColor.Fprintf(r.out, "        Kind: %s (downloads and executes remote code)\n", ms.Kind)
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
+// reportAdvisories outputs heuristic advisories that warrant review
+func (r *TerminalReporter) reportAdvisories(advisories []*scanner.Advisory, ref string) {
+	if len(advisories) == 0 {
+		return
+	}
+	r.warnColor.Fprintf(r.out, "  💡 Advisories:\n")
+	for _, a := range advisories {
+		r.warnColor.Fprintf(r.out, "     🟡 %s: %s%s\n", a.Kind, r.fileLink(a.RepoName, ref, a.FilePath), r.knownMarker(a.Known))
+		r.dimColor.Fprintf(r.out, "        %s\n", a.Detail)
+	}
+	fmt.Fprintln(r.out)
+}
+
+// reportSuspiciousPackages outputs dependencies named like popular packages
+func (r *TerminalReporter) reportSuspiciousPackages(packages []*scanner.SuspiciousPackage, ref string) {
+	if len(packages) == 0 {
+		return
+	}
+	r.warnColor.Fprintf(r.out, "  🪤 Possible typosquats:\n")
+	for _, sp := range packages {
+		r.warnColor.Fprintf(r.out, "     🟡 %s@%s resembles %q%s\n", sp.Package.Name, sp.Package.Version, sp.LookalikeOf, r.knownMarker(sp.Known))
+		r.dimColor.Fprintf(r.out, "        %s\n", r.fileLink(sp.RepoName, ref, sp.FilePath))
+	}
+	fmt.Fprintln(r.out)
+}
+
+// reportSuspiciousConfigs outputs .npmrc lines pointing at unknown registries
+// or committing credentials
+func (r *TerminalReporter) reportSuspiciousConfigs(configs []*scanner.SuspiciousConfig, ref string) {
+	if len(configs) == 0 {
+		return
+	}
+	r.warnColor.Fprintf(r.out, "  🔑 Suspicious npm config:\n")
+	for _, sc := range configs {
+		r.warnColor.Fprintf(r.out, "     🟡 %s:%d %s%s\n", r.fileLink(sc.RepoName, ref, sc.FilePath), sc.Line, sc.Reason, r.knownMarker(sc.Known))
+		r.dimColor.Fprintf(r.out, "        %s\n", sc.Content)
+	}
+	fmt.Fprintln(r.out)
+}
+
+// reportVersionSprawl outputs packages resolving to many distinct versions
+func (r *TerminalReporter) reportVersionSprawl(sprawl []*scanner.VersionSprawl) {
+	if len(sprawl) == 0 {
+		return
+	}
+	r.infoColor.Fprintf(r.out, "  📚 Version sprawl (informational):\n")
+	for _, vs := range sprawl {
+		r.infoColor.Fprintf(r.out, "     • %s: %d versions\n", vs.PackageName, len(vs.Versions))
+		r.dimColor.Fprintf(r.out, "        %s\n", strings.Join(vs.Versions, ", "))
 	}
 	fmt.Fprintln(r.out)
 }
 
 // reportVulnerablePackages outputs vulnerable package detections grouped by file
-func (r *TerminalReporter) reportVulnerablePackages(packages []*scanner.VulnerablePackage) {
+func (r *TerminalReporter) reportVulnerablePackages(packages []*scanner.VulnerablePackage, ref string) {
 	if len(packages) == 0 {
 		return
 	}
@@ -162,7 +460,7 @@ func (r *TerminalReporter) reportVulnerablePackages(packages []*scanner.Vulnerab
 	}
 
 	for filePath, vulns := range byFile {
-		r.warnColor.Fprintf(r.out, "  📄 %s:\n", filePath)
+		r.warnColor.Fprintf(r.out, "  📄 %s:\n", r.fileLink(vulns[0].RepoName, ref, filePath))
 		for _, vp := range vulns {
 			r.reportSingleVulnerablePackage(vp)
 		}
@@ -177,23 +475,101 @@ func (r *TerminalReporter) reportSingleVulnerablePackage(vp *scanner.VulnerableP
 		devMarker = r.dimColor.Sprint(" (dev)")
 	}
 	sourceMarker := ""
-	if vp.Package.Source == "transitive" {
-		sourceMarker = r.dimColor.Sprint(" [transitive]")
+	if vp.Package.Source == "transitive" || vp.Package.Source == "override" {
+		sourceMarker = r.dimColor.Sprintf(" [%s]", vp.Package.Source)
+	}
+
+	confidenceMarker := ""
+	if vp.Confidence == scanner.ConfidenceLow || vp.Confidence == scanner.ConfidenceMedium {
+		confidenceMarker = r.dimColor.Sprintf(" [%s confidence: %s]", vp.Confidence, vp.ConfidenceNote)
 	}
 
-	r.errorColor.Fprintf(r.out, "     🔴 %s@%s%s%s\n",
+	r.errorColor.Fprintf(r.out, "     🔴 %s@%s%s%s%s%s\n",
 		vp.Package.Name,
 		vp.Package.Version,
 		devMarker,
-		sourceMarker)
+		sourceMarker,
+		confidenceMarker,
+		r.knownMarker(vp.Known))
+	if len(vp.FilePaths) > 1 {
+		r.dimColor.Fprintf(r.out, "        📂 Found in: %s\n", strings.Join(vp.FilePaths, ", "))
+	}
+	r.reportWorkspaces(vp.Workspaces)
+	if vp.VulnEntry.Campaign != "" {
+		r.dimColor.Fprintf(r.out, "        🏷️  Campaign: %s\n", vp.VulnEntry.Campaign)
+	}
+	if vp.VulnEntry.AdvisoryID != "" {
+		r.dimColor.Fprintf(r.out, "        📋 Advisory: %s\n", vp.VulnEntry.AdvisoryID)
+	}
 
-	if vp.VulnEntry.PackageVersion != "" && vp.VulnEntry.PackageVersion != vp.Package.Version {
+	switch {
+	case vp.MatchedBy == scanner.KnownMaliciousIntegrity:
+		r.errorColor.Fprintf(r.out, "        🧬 %s: tarball integrity matches IOC %s\n", vp.MatchedBy, iocLabel(vp.VulnEntry))
+	case vp.VulnEntry.PackageVersion != "" && vp.VulnEntry.PackageVersion != vp.Package.Version:
 		r.dimColor.Fprintf(r.out, "        ⚠️  IOC version: %s\n", vp.VulnEntry.PackageVersion)
 	}
+	if len(vp.VulnEntry.Sources) > 0 {
+		r.dimColor.Fprintf(r.out, "        📚 IOC sources: %s\n", strings.Join(vp.VulnEntry.Sources, ", "))
+	}
+}
+
+// reportWorkspaces outputs the workspace members that depend on a vulnerable package
+func (r *TerminalReporter) reportWorkspaces(members []scanner.WorkspaceMember) {
+	if len(members) == 0 {
+		return
+	}
+	labels := make([]string, 0, len(members))
+	for _, member := range members {
+		label := member.Path
+		if member.Name != "" {
+			label += " (" + member.Name + ")"
+		}
+		labels = append(labels, label)
+	}
+	r.dimColor.Fprintf(r.out, "        🗂️  Workspaces: %s\n", strings.Join(labels, ", "))
+}
+
+// iocLabel names the IOC entry a package matched, with its version if it has one
+func iocLabel(entry *vuln.VulnEntry) string {
+	if entry.PackageVersion == "" {
+		return entry.PackageName
+	}
+	return entry.PackageName + "@" + entry.PackageVersion
+}
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink to url when hyperlinks
+// are enabled. Terminals without hyperlink support show the text alone.
+func (r *TerminalReporter) hyperlink(url, text string) string {
+	if !r.hyperlinks || url == "" {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// fileLink links a file path to the file on GitHub at ref. Files scanned from
+// a local directory have no ref and are shown as they are.
+func (r *TerminalReporter) fileLink(repoName, ref, filePath string) string {
+	if ref == "" || filePath == "" {
+		return filePath
+	}
+	return r.hyperlink(scanner.FileURL(repoName, ref, filePath, 0), filePath)
+}
+
+// knownMarker returns the marker shown next to findings present in the baseline
+func (r *TerminalReporter) knownMarker(known bool) string {
+	if !known {
+		return ""
+	}
+	return r.dimColor.Sprint(" (known)")
 }
 
 // ReportMaliciousRepo reports a detected malicious migration repository
 func (r *TerminalReporter) ReportMaliciousRepo(repoName, description string) {
+	r.atomically(func(b *TerminalReporter) { b.reportMaliciousRepo(repoName, description) })
+}
+
+// reportMaliciousRepo writes the output of ReportMaliciousRepo without locking
+func (r *TerminalReporter) reportMaliciousRepo(repoName, description string) {
 	r.errorColor.Fprintf(r.out, "🚨 MALICIOUS MIGRATION REPO DETECTED: %s\n", repoName)
 	r.dimColor.Fprintf(r.out, "   Description: %s\n", description)
 	r.dimColor.Fprintf(r.out, "   This repo was likely created by the Shai-Hulud worm and may contain exposed secrets!\n\n")
@@ -204,40 +580,146 @@ type summaryStats struct {
 	totalRepos              int
 	totalPackages           int
 	totalVulnerable         int
+	distinctVulnerable      int
 	totalMaliciousWorkflows int
 	totalMaliciousScripts   int
 	totalMaliciousBranches  int
 	totalMaliciousRepos     int
 	reposWithVulns          int
 	errorCount              int
+	notScannable            map[string]int // Skipped repositories by reason
+	knownFindings           int
+	totalAdvisories         int
+	filesExcluded           int
+	covered                 int // Scanned repositories with at least one package file scanned
+	noPackageFiles          int // Scanned repositories that yielded no package files
+	discoveryMissed         int // Of those, repositories whose primary language is JavaScript
 }
 
 // calculateSummaryStats aggregates statistics from scan results
-func (r *TerminalReporter) calculateSummaryStats(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) summaryStats {
+func calculateSummaryStats(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) summaryStats {
 	stats := summaryStats{totalRepos: len(results)}
 
 	if orgResult != nil {
 		stats.totalMaliciousRepos = len(orgResult.MaliciousRepos)
+		stats.knownFindings = orgResult.KnownFindings
 	}
 
+	distinct := make(map[string]bool)
 	for _, result := range results {
+		stats.knownFindings += result.KnownFindings
 		if result.Error != nil {
 			stats.errorCount++
 			continue
 		}
+		if result.NotScannable != "" {
+			if stats.notScannable == nil {
+				stats.notScannable = make(map[string]int)
+			}
+			stats.notScannable[result.NotScannable]++
+			continue
+		}
 		stats.totalPackages += result.TotalPackages
-		if r.resultHasIssues(result) {
+		stats.totalAdvisories += len(result.Advisories) + len(result.SuspiciousPackages) + len(result.SuspiciousConfigs)
+		stats.filesExcluded += result.FilesExcluded
+		if result.FilesScanned > 0 {
+			stats.covered++
+		}
+		if result.NoPackageFiles() {
+			stats.noPackageFiles++
+			if result.DiscoveryMissed() {
+				stats.discoveryMissed++
+			}
+		}
+		if resultHasIssues(result) {
 			stats.totalVulnerable += len(result.VulnerablePackages)
+			for _, vp := range result.VulnerablePackages {
+				distinct[vp.Package.Name+"@"+vp.Package.Version] = true
+			}
 			stats.totalMaliciousWorkflows += len(result.MaliciousWorkflows)
 			stats.totalMaliciousScripts += len(result.MaliciousScripts)
 			stats.totalMaliciousBranches += len(result.MaliciousBranches)
 			stats.reposWithVulns++
 		}
 	}
+	stats.distinctVulnerable = len(distinct)
 
 	return stats
 }
 
+// coveragePercent returns the percentage of repositories that had at least
+// one package file scanned, telling a clean scan of every repository apart
+// from one where nothing was scanned
+func (s summaryStats) coveragePercent() float64 {
+	if s.totalRepos == 0 {
+		return 0
+	}
+	return 100 * float64(s.covered) / float64(s.totalRepos)
+}
+
+// skipped returns the number of repositories that could not be scanned,
+// whether not scannable (archived, disabled, empty) or failed with an error
+func (s summaryStats) skipped() int {
+	return s.notScannableCount() + s.errorCount
+}
+
+// notScannableCount returns the number of repositories skipped as not scannable
+func (s summaryStats) notScannableCount() int {
+	total := 0
+	for _, n := range s.notScannable {
+		total += n
+	}
+	return total
+}
+
+// reportNotScannable outputs the skipped repositories by reason, separately from errors
+func (r *TerminalReporter) reportNotScannable(byReason map[string]int) {
+	if len(byReason) == 0 {
+		return
+	}
+
+	reasons := make([]string, 0, len(byReason))
+	total := 0
+	for reason, n := range byReason {
+		reasons = append(reasons, reason)
+		total += n
+	}
+	sort.Strings(reasons)
+
+	r.dimColor.Fprintf(r.out, "⏭️  Repositories not scannable: %d\n", total)
+	for _, reason := range reasons {
+		r.dimColor.Fprintf(r.out, "   • %s: %d\n", reason, byReason[reason])
+	}
+}
+
+// reportNoPackageFiles counts the repositories that yielded no package files
+// and, when listing is enabled, names them. JavaScript projects are listed
+// apart because discovery probably missed their manifests.
+func (r *TerminalReporter) reportNoPackageFiles(results []*scanner.RepoScanResult, stats summaryStats) {
+	if stats.noPackageFiles == 0 {
+		return
+	}
+
+	r.dimColor.Fprintf(r.out, "📭 Repositories without package files: %d\n", stats.noPackageFiles)
+	if stats.discoveryMissed > 0 {
+		r.warnColor.Fprintf(r.out, "   ⚠️  JavaScript projects among them: %d (discovery may have missed their manifests)\n", stats.discoveryMissed)
+	}
+	if !r.verbose && !r.listEmpty {
+		return
+	}
+
+	for _, result := range results {
+		if result.DiscoveryMissed() {
+			r.warnColor.Fprintf(r.out, "   • %s (%s, discovery found nothing)\n", result.RepoName, result.Language)
+		}
+	}
+	for _, result := range results {
+		if result.NoPackageFiles() && !result.DiscoveryMissed() {
+			r.dimColor.Fprintf(r.out, "   • %s (no JS project)\n", result.RepoName)
+		}
+	}
+}
+
 // hasAnyIssues checks if any issues were found in the summary stats
 func (s summaryStats) hasAnyIssues() bool {
 	return s.totalVulnerable > 0 || s.totalMaliciousWorkflows > 0 ||
@@ -254,6 +736,7 @@ func (r *TerminalReporter) reportSummaryIssues(stats summaryStats) {
 	}
 	if stats.totalVulnerable > 0 {
 		r.errorColor.Fprintf(r.out, "🔴 Vulnerable packages found: %d\n", stats.totalVulnerable)
+		r.errorColor.Fprintf(r.out, "   Distinct vulnerable packages: %d\n", stats.distinctVulnerable)
 	}
 	if stats.totalMaliciousWorkflows > 0 {
 		r.errorColor.Fprintf(r.out, "🐛 Malicious workflows found: %d\n", stats.totalMaliciousWorkflows)
@@ -268,7 +751,7 @@ func (r *TerminalReporter) reportSummaryIssues(stats summaryStats) {
 func (r *TerminalReporter) reportAffectedRepos(results []*scanner.RepoScanResult) {
 	r.warnColor.Fprintf(r.out, "Affected repositories:\n")
 	for _, result := range results {
-		if !r.resultHasIssues(result) {
+		if !resultHasIssues(result) {
 			continue
 		}
 		parts := r.buildIssueParts(result)
@@ -295,18 +778,99 @@ func (r *TerminalReporter) buildIssueParts(result *scanner.RepoScanResult) []str
 	return parts
 }
 
+// ownerStats holds per-owner aggregates for multi-owner scans
+type ownerStats struct {
+	owner              string
+	repos              int
+	vulnerable         int
+	maliciousWorkflows int
+	maliciousScripts   int
+	maliciousBranches  int
+	maliciousRepos     int
+}
+
+// ownerOf returns the owner of a result, falling back to the owner in the full repo name
+func ownerOf(owner, repoName string) string {
+	if owner != "" {
+		return owner
+	}
+	if i := strings.Index(repoName, "/"); i > 0 {
+		return repoName[:i]
+	}
+	return repoName
+}
+
+// calculateOwnerStats aggregates results per owner, sorted by owner name
+func calculateOwnerStats(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult) []*ownerStats {
+	byOwner := make(map[string]*ownerStats)
+	get := func(owner string) *ownerStats {
+		if byOwner[owner] == nil {
+			byOwner[owner] = &ownerStats{owner: owner}
+		}
+		return byOwner[owner]
+	}
+
+	for _, result := range results {
+		s := get(ownerOf(result.Owner, result.RepoName))
+		s.repos++
+		s.vulnerable += len(result.VulnerablePackages)
+		s.maliciousWorkflows += len(result.MaliciousWorkflows)
+		s.maliciousScripts += len(result.MaliciousScripts)
+		s.maliciousBranches += len(result.MaliciousBranches)
+	}
+	if orgResult != nil {
+		for _, mr := range orgResult.MaliciousRepos {
+			get(ownerOf("", mr.RepoName)).maliciousRepos++
+		}
+	}
+
+	owners := make([]*ownerStats, 0, len(byOwner))
+	for _, s := range byOwner {
+		owners = append(owners, s)
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].owner < owners[j].owner })
+	return owners
+}
+
+// reportOwnerBreakdown outputs a per-owner table of repositories and findings
+func (r *TerminalReporter) reportOwnerBreakdown(owners []*ownerStats) {
+	r.warnColor.Fprintf(r.out, "Findings by owner:\n")
+	r.dimColor.Fprintf(r.out, "  %-24s %6s %10s %9s %8s %8s %9s\n",
+		"Owner", "Repos", "Vulnerable", "Workflows", "Scripts", "Branches", "Migration")
+	for _, s := range owners {
+		line := fmt.Sprintf("  %-24s %6d %10d %9d %8d %8d %9d\n",
+			s.owner, s.repos, s.vulnerable, s.maliciousWorkflows, s.maliciousScripts, s.maliciousBranches, s.maliciousRepos)
+		if s.vulnerable+s.maliciousWorkflows+s.maliciousScripts+s.maliciousBranches+s.maliciousRepos > 0 {
+			r.errorColor.Fprint(r.out, line)
+		} else {
+			r.infoColor.Fprint(r.out, line)
+		}
+	}
+	fmt.Fprintln(r.out)
+}
+
 // ReportSummary reports the overall scan summary
 func (r *TerminalReporter) ReportSummary(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int) {
+	r.atomically(func(b *TerminalReporter) { b.reportSummary(results, orgResult, vulnDBSize) })
+}
+
+// reportSummary writes the output of ReportSummary without locking
+func (r *TerminalReporter) reportSummary(results []*scanner.RepoScanResult, orgResult *scanner.OrgScanResult, vulnDBSize int) {
 	fmt.Fprintln(r.out)
 	r.headerColor.Fprintf(r.out, "══════════════════════════════════════════════════════════════\n")
 	r.headerColor.Fprintf(r.out, "                        SCAN SUMMARY\n")
 	r.headerColor.Fprintf(r.out, "══════════════════════════════════════════════════════════════\n\n")
 
-	stats := r.calculateSummaryStats(results, orgResult)
+	stats := calculateSummaryStats(results, orgResult)
 
 	r.infoColor.Fprintf(r.out, "📊 Repositories scanned:     %d\n", stats.totalRepos)
+	r.infoColor.Fprintf(r.out, "🧭 Coverage:                 %d of %d repositories had package files scanned (%.1f%%), %d skipped\n",
+		stats.covered, stats.totalRepos, stats.coveragePercent(), stats.skipped())
 	r.infoColor.Fprintf(r.out, "📦 Total packages checked:   %d\n", stats.totalPackages)
 	r.infoColor.Fprintf(r.out, "🔍 IOC database entries:     %d\n", vulnDBSize)
+	if stats.filesExcluded > 0 {
+		r.dimColor.Fprintf(r.out, "⏭️  Package files excluded:   %d\n", stats.filesExcluded)
+	}
 	fmt.Fprintln(r.out)
 
 	if stats.hasAnyIssues() {
@@ -315,6 +879,17 @@ func (r *TerminalReporter) ReportSummary(results []*scanner.RepoScanResult, orgR
 		r.successColor.Fprintf(r.out, "✅ No vulnerable packages or malicious patterns detected!\n")
 	}
 
+	if stats.totalAdvisories > 0 {
+		r.warnColor.Fprintf(r.out, "💡 Advisories for review:   %d\n", stats.totalAdvisories)
+	}
+
+	if stats.knownFindings > 0 {
+		r.dimColor.Fprintf(r.out, "📌 Known findings (baseline): %d\n", stats.knownFindings)
+	}
+
+	r.reportNotScannable(stats.notScannable)
+	r.reportNoPackageFiles(results, stats)
+
 	if stats.errorCount > 0 {
 		r.warnColor.Fprintf(r.out, "⚠️  Repositories with errors: %d\n", stats.errorCount)
 	}
@@ -329,35 +904,70 @@ func (r *TerminalReporter) ReportSummary(results []*scanner.RepoScanResult, orgR
 		fmt.Fprintln(r.out)
 	}
 
-	if stats.reposWithVulns > 0 {
+	if r.GroupsBySeverity() {
+		r.reportFindingsBySeverity(results, orgResult)
+	} else if stats.reposWithVulns > 0 {
 		r.reportAffectedRepos(results)
 	}
 
+	if owners := calculateOwnerStats(results, orgResult); len(owners) > 1 {
+		r.reportOwnerBreakdown(owners)
+	}
+
 	r.headerColor.Fprintf(r.out, "══════════════════════════════════════════════════════════════\n")
 }
 
 // ReportError reports an error
 func (r *TerminalReporter) ReportError(format string, args ...interface{}) {
+	r.atomically(func(b *TerminalReporter) { b.reportError(format, args...) })
+}
+
+// reportError writes the output of ReportError without locking
+func (r *TerminalReporter) reportError(format string, args ...interface{}) {
 	r.errorColor.Fprintf(r.out, "❌ "+format+"\n", args...)
 }
 
 // ReportWarning reports a warning message
 func (r *TerminalReporter) ReportWarning(format string, args ...interface{}) {
+	r.atomically(func(b *TerminalReporter) { b.reportWarning(format, args...) })
+}
+
+// reportWarning writes the output of ReportWarning without locking
+func (r *TerminalReporter) reportWarning(format string, args ...interface{}) {
 	r.warnColor.Fprintf(r.out, format+"\n", args...)
 }
 
 // ReportInfo reports an informational message
 func (r *TerminalReporter) ReportInfo(format string, args ...interface{}) {
+	r.atomically(func(b *TerminalReporter) { b.reportInfo(format, args...) })
+}
+
+// reportInfo writes the output of ReportInfo without locking
+func (r *TerminalReporter) reportInfo(format string, args ...interface{}) {
 	r.infoColor.Fprintf(r.out, format+"\n", args...)
 }
 
 // ReportSuccess reports a success message
 func (r *TerminalReporter) ReportSuccess(format string, args ...interface{}) {
+	r.atomically(func(b *TerminalReporter) { b.reportSuccess(format, args...) })
+}
+
+// reportSuccess writes the output of ReportSuccess without locking
+func (r *TerminalReporter) reportSuccess(format string, args ...interface{}) {
 	r.successColor.Fprintf(r.out, "✅ "+format+"\n", args...)
 }
 
-// PrintBanner prints the application banner
+// PrintBanner prints the application banner, unless it is turned off for a
+// machine-readable format by NewReporter
 func (r *TerminalReporter) PrintBanner() {
+	if !r.banner {
+		return
+	}
+	r.atomically(func(b *TerminalReporter) { b.printBanner() })
+}
+
+// printBanner writes the output of PrintBanner without locking
+func (r *TerminalReporter) printBanner() {
 	banner := `
   __  __                 _  _     _  _  _
  |  \/  | _  _   __ _  __| |( ) __| |(_)| |__
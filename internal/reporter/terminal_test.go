@@ -0,0 +1,506 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/scanner"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// vulnerablePackage creates a vulnerable package finding for tests
+func vulnerablePackage(repo, file, name, version string) *scanner.VulnerablePackage {
+	return &scanner.VulnerablePackage{
+		Package:   &scanner.Package{Name: name, Version: version},
+		VulnEntry: &vuln.VulnEntry{PackageName: name, PackageVersion: version},
+		FilePath:  file,
+		RepoName:  repo,
+	}
+}
+
+func TestCalculateSummaryStats_DistinctVulnerablePackages(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "package.json", "test-muaddib-bad", "1.0.0"),
+				vulnerablePackage("test-org/a", "package-lock.json", "test-muaddib-bad", "1.0.0"),
+			},
+		},
+		{
+			RepoName: "test-org/b",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/b", "package-lock.json", "test-muaddib-bad", "1.0.0"),
+				vulnerablePackage("test-org/b", "package-lock.json", "test-muaddib-bad", "1.0.1"),
+				vulnerablePackage("test-org/b", "package-lock.json", "@test-muaddib/other", "2.0.0"),
+			},
+		},
+	}
+
+	stats := calculateSummaryStats(results, nil)
+
+	if stats.totalVulnerable != 5 {
+		t.Errorf("expected 5 vulnerable instances, got %d", stats.totalVulnerable)
+	}
+	if stats.distinctVulnerable != 3 {
+		t.Errorf("expected 3 distinct vulnerable packages, got %d", stats.distinctVulnerable)
+	}
+}
+
+func TestReportSummary_ShowsDistinctCount(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf))
+
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org/a",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org/a", "package.json", "test-muaddib-bad", "1.0.0"),
+				vulnerablePackage("test-org/a", "package-lock.json", "test-muaddib-bad", "1.0.0"),
+			},
+		},
+	}
+
+	rep.ReportSummary(results, &scanner.OrgScanResult{}, 10)
+
+	if !strings.Contains(buf.String(), "Distinct vulnerable packages: 1") {
+		t.Errorf("expected distinct count in summary, got:\n%s", buf.String())
+	}
+}
+
+func TestReportRepoResult_AnnotatesActionsEnabled(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		want    string
+	}{
+		{true, "Actions enabled: yes (severity: critical)"},
+		{false, "Actions enabled: no (severity: medium)"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		rep := NewTerminalReporter(WithOutput(&buf))
+		mw := &scanner.MaliciousWorkflow{RepoName: "test-org/a", FilePath: ".github/workflows/discussion.yaml", Pattern: "discussion"}
+		scanner.SetActionsEnabled([]*scanner.MaliciousWorkflow{mw}, tt.enabled)
+
+		rep.ReportRepoResult(&scanner.RepoScanResult{
+			RepoName:           "test-org/a",
+			FilesScanned:       1,
+			MaliciousWorkflows: []*scanner.MaliciousWorkflow{mw},
+		})
+
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("expected %q in output, got:\n%s", tt.want, buf.String())
+		}
+	}
+}
+
+func TestReportRepoResult_ListsIOCSources(t *testing.T) {
+	vp := vulnerablePackage("test-org/a", "package-lock.json", "test-muaddib-vulnerable", "1.0.0")
+	vp.VulnEntry.Sources = []string{"DataDog", "Wiz"}
+
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf))
+	rep.ReportRepoResult(&scanner.RepoScanResult{
+		RepoName:           "test-org/a",
+		FilesScanned:       1,
+		VulnerablePackages: []*scanner.VulnerablePackage{vp},
+	})
+
+	if !strings.Contains(buf.String(), "IOC sources: DataDog, Wiz") {
+		t.Errorf("expected the IOC sources in output, got:\n%s", buf.String())
+	}
+}
+
+func TestReportRepoResult_WarnsAboutBinaryLockfiles(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf), WithColor(false))
+	rep.ReportRepoResult(&scanner.RepoScanResult{
+		RepoName:        "test-org/a",
+		FilesScanned:    2,
+		BinaryLockfiles: []string{"apps/web/bun.lockb"},
+	})
+
+	if !strings.Contains(buf.String(), "Cannot read the binary apps/web/bun.lockb") || !strings.Contains(buf.String(), "commit a text bun.lock") {
+		t.Errorf("expected a warning to commit a text lockfile, got:\n%s", buf.String())
+	}
+}
+
+func TestReportRepoResult_ListsUnverifiableDepsWhenVerbose(t *testing.T) {
+	result := &scanner.RepoScanResult{
+		RepoName:     "test-org/a",
+		FilesScanned: 1,
+		UnverifiableDeps: []*scanner.UnverifiableDependency{
+			{Name: "test-muaddib-git", Spec: "github:test-org/test-repo#v1.0.0", SpecType: scanner.SpecGit, FilePath: "package.json"},
+		},
+	}
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf), WithColor(false)).ReportRepoResult(result)
+	if strings.Contains(buf.String(), "Unverifiable") {
+		t.Errorf("expected unverifiable dependencies to be left to verbose mode, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	NewTerminalReporter(WithOutput(&buf), WithColor(false), WithVerbose(true)).ReportRepoResult(result)
+	want := "Unverifiable git dependency test-muaddib-git (github:test-org/test-repo#v1.0.0) in package.json"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestReportRepoResult_CapsBranchChangedFilesUnlessVerbose(t *testing.T) {
+	changed := make([]string, maxChangedFiles+5)
+	for i := range changed {
+		changed[i] = fmt.Sprintf("src/file-%02d.js", i)
+	}
+	result := &scanner.RepoScanResult{
+		RepoName: "test-org/a",
+		MaliciousBranches: []*scanner.MaliciousBranch{{
+			RepoName:     "test-org/a",
+			BranchName:   "shai-hulud",
+			ChangedFiles: changed,
+			Inspection:   &scanner.RepoScanResult{},
+		}},
+	}
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf), WithColor(false)).ReportRepoResult(result)
+	out := buf.String()
+	if !strings.Contains(out, changed[maxChangedFiles-1]) || strings.Contains(out, changed[maxChangedFiles]) {
+		t.Errorf("expected only the first %d changed files, got:\n%s", maxChangedFiles, out)
+	}
+	if !strings.Contains(out, "… and 5 more") {
+		t.Errorf("expected the hidden files to be counted, got:\n%s", out)
+	}
+
+	buf.Reset()
+	NewTerminalReporter(WithOutput(&buf), WithColor(false), WithVerbose(true)).ReportRepoResult(result)
+	out = buf.String()
+	if !strings.Contains(out, changed[len(changed)-1]) || strings.Contains(out, "more") {
+		t.Errorf("expected every changed file in verbose mode, got:\n%s", out)
+	}
+}
+
+func TestReportRepoResult_ListsSuspiciousConfigs(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf), WithColor(false))
+	rep.ReportRepoResult(&scanner.RepoScanResult{
+		RepoName:     "test-org/a",
+		FilesScanned: 1,
+		SuspiciousConfigs: []*scanner.SuspiciousConfig{{
+			RepoName: "test-org/a",
+			FilePath: "packages/web/.npmrc",
+			Line:     3,
+			Content:  "registry=https://evil.example.com/",
+			Reason:   "registry points at unknown host evil.example.com",
+		}},
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, "packages/web/.npmrc:3 registry points at unknown host evil.example.com") || !strings.Contains(out, "registry=https://evil.example.com/") {
+		t.Errorf("expected the offending line to be listed, got:\n%s", out)
+	}
+}
+
+func TestReportRepoResult_ListsFilesOfDedupedPackage(t *testing.T) {
+	vp := vulnerablePackage("test-org/a", "a/package.json", "test-muaddib-vulnerable", "1.0.0")
+	vp.FilePaths = []string{"a/package.json", "package-lock.json"}
+
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf), WithColor(false))
+	rep.ReportRepoResult(&scanner.RepoScanResult{
+		RepoName:           "test-org/a",
+		FilesScanned:       2,
+		VulnerablePackages: []*scanner.VulnerablePackage{vp},
+	})
+
+	out := buf.String()
+	if strings.Count(out, "test-muaddib-vulnerable@1.0.0") != 1 {
+		t.Errorf("expected the package once, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Found in: a/package.json, package-lock.json") {
+		t.Errorf("expected every file to be listed, got:\n%s", out)
+	}
+}
+
+func TestReportRepoResult_HyperlinksFilesWithColor(t *testing.T) {
+	result := &scanner.RepoScanResult{
+		RepoName:     "test-org/a",
+		Ref:          "main",
+		FilesScanned: 1,
+		MaliciousWorkflows: []*scanner.MaliciousWorkflow{
+			{RepoName: "test-org/a", FilePath: ".github/workflows/discussion.yaml", Pattern: "discussion"},
+		},
+	}
+	link := "\x1b]8;;https://github.com/test-org/a/blob/main/.github/workflows/discussion.yaml\x1b\\"
+
+	for _, enabled := range []bool{true, false} {
+		var buf bytes.Buffer
+		rep := NewTerminalReporter(WithOutput(&buf), WithColor(enabled))
+		rep.ReportRepoResult(result)
+
+		if got := strings.Contains(buf.String(), link); got != enabled {
+			t.Errorf("color %v: expected hyperlink %v, got output:\n%q", enabled, enabled, buf.String())
+		}
+	}
+}
+
+func TestNewTerminalReporter_ColorFollowsOutput(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      []ReporterOption
+		wantColor bool
+	}{
+		{"detected off for a non-terminal writer", nil, false},
+		{"forced on", []ReporterOption{WithColor(true)}, true},
+		{"forced off", []ReporterOption{WithColor(false)}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rep := NewTerminalReporter(append([]ReporterOption{WithOutput(&buf)}, tc.opts...)...)
+			rep.ReportError("%s failed", "test-muaddib")
+
+			if !strings.Contains(buf.String(), "❌ test-muaddib failed") {
+				t.Errorf("expected the emoji and text either way, got %q", buf.String())
+			}
+			if got := strings.Contains(buf.String(), "\x1b["); got != tc.wantColor {
+				t.Errorf("expected color codes %v, got %q", tc.wantColor, buf.String())
+			}
+		})
+	}
+}
+
+func TestCalculateOwnerStats_GroupsByOwner(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{
+			RepoName: "test-org-b/one",
+			Owner:    "test-org-b",
+			VulnerablePackages: []*scanner.VulnerablePackage{
+				vulnerablePackage("test-org-b/one", "package-lock.json", "test-muaddib-bad", "1.0.0"),
+				vulnerablePackage("test-org-b/one", "package-lock.json", "test-muaddib-bad", "1.0.1"),
+			},
+		},
+		{
+			RepoName:          "test-org-a/one",
+			Owner:             "test-org-a",
+			MaliciousBranches: []*scanner.MaliciousBranch{{RepoName: "test-org-a/one", BranchName: "shai-hulud"}},
+		},
+		{RepoName: "test-org-b/two"}, // owner derived from the repo name
+	}
+	orgResult := &scanner.OrgScanResult{
+		MaliciousRepos: []*scanner.MaliciousRepo{{RepoName: "test-org-a/x-migration"}},
+	}
+
+	owners := calculateOwnerStats(results, orgResult)
+
+	if len(owners) != 2 {
+		t.Fatalf("expected 2 owners, got %d", len(owners))
+	}
+	a, b := owners[0], owners[1]
+	if a.owner != "test-org-a" || a.repos != 1 || a.maliciousBranches != 1 || a.maliciousRepos != 1 {
+		t.Errorf("unexpected stats for test-org-a: %+v", a)
+	}
+	if b.owner != "test-org-b" || b.repos != 2 || b.vulnerable != 2 {
+		t.Errorf("unexpected stats for test-org-b: %+v", b)
+	}
+}
+
+func TestReportSummary_OwnerBreakdownOnlyForMultipleOwners(t *testing.T) {
+	single := []*scanner.RepoScanResult{{RepoName: "test-org-a/one"}, {RepoName: "test-org-a/two"}}
+	multi := append(single, &scanner.RepoScanResult{RepoName: "test-org-b/one"})
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf)).ReportSummary(single, nil, 0)
+	if strings.Contains(buf.String(), "Findings by owner") {
+		t.Error("expected no owner breakdown for a single owner")
+	}
+
+	buf.Reset()
+	NewTerminalReporter(WithOutput(&buf)).ReportSummary(multi, nil, 0)
+	if !strings.Contains(buf.String(), "Findings by owner") {
+		t.Errorf("expected owner breakdown for multiple owners, got:\n%s", buf.String())
+	}
+}
+
+func TestReportRepo_ConcurrentBlocksStayContiguous(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf))
+
+	const repos = 20
+	var wg sync.WaitGroup
+	for i := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("test-org/repo-%02d", i)
+			rep.ReportRepo(name, &scanner.RepoScanResult{
+				RepoName:     name,
+				FilesScanned: 1,
+				VulnerablePackages: []*scanner.VulnerablePackage{
+					vulnerablePackage(name, "package.json", "test-muaddib-bad", "1.0.0"),
+					vulnerablePackage(name, "package-lock.json", "test-muaddib-bad", "1.0.1"),
+				},
+			})
+			rep.ReportInfo("done %s", name)
+		}()
+	}
+	wg.Wait()
+
+	blocks := strings.Split(buf.String(), "📁 Repository: ")[1:]
+	if len(blocks) != repos {
+		t.Fatalf("expected %d repo blocks, got %d", repos, len(blocks))
+	}
+	for _, block := range blocks {
+		name := strings.SplitN(block, "\n", 2)[0]
+		// Everything up to the next block belongs to this repo, apart from
+		// progress lines written between blocks
+		body := strings.Split(block, "done ")[0]
+		if !strings.Contains(body, "test-muaddib-bad@1.0.0") || !strings.Contains(body, "test-muaddib-bad@1.0.1") {
+			t.Errorf("block for %s is not contiguous:\n%s", name, block)
+		}
+	}
+}
+
+func TestReportSummary_SeparatesNotScannableFromErrors(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewTerminalReporter(WithOutput(&buf))
+
+	results := []*scanner.RepoScanResult{
+		{RepoName: "test-org/disabled", NotScannable: "repository is disabled"},
+		{RepoName: "test-org/empty", NotScannable: "repository is empty"},
+		{RepoName: "test-org/also-empty", NotScannable: "repository is empty"},
+		{RepoName: "test-org/broken", Error: fmt.Errorf("boom")},
+	}
+
+	stats := calculateSummaryStats(results, nil)
+	if stats.errorCount != 1 {
+		t.Errorf("expected 1 error, got %d", stats.errorCount)
+	}
+	if stats.notScannableCount() != 3 {
+		t.Errorf("expected 3 not scannable, got %d", stats.notScannableCount())
+	}
+
+	rep.ReportSummary(results, &scanner.OrgScanResult{}, 10)
+
+	output := buf.String()
+	for _, want := range []string{
+		"Repositories not scannable: 3",
+		"repository is disabled: 1",
+		"repository is empty: 2",
+		"Repositories with errors: 1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in summary, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestCalculateSummaryStats_Coverage(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{RepoName: "test-org/app", FilesScanned: 2, TotalPackages: 40},
+		{RepoName: "test-org/lib", FilesScanned: 1, TotalPackages: 5},
+		{RepoName: "test-org/docs"},
+		{RepoName: "test-org/fixtures", FilesExcluded: 1},
+		{RepoName: "test-org/old", NotScannable: "repository is archived"},
+		{RepoName: "test-org/private", Error: fmt.Errorf("403 Forbidden")},
+		{RepoName: "test-org/site", FilesScanned: 1},
+		{RepoName: "test-org/empty", NotScannable: "repository is empty"},
+	}
+
+	stats := calculateSummaryStats(results, nil)
+
+	if stats.covered != 3 {
+		t.Errorf("expected 3 covered repositories, got %d", stats.covered)
+	}
+	if stats.skipped() != 3 {
+		t.Errorf("expected 3 skipped repositories, got %d", stats.skipped())
+	}
+	if got := stats.coveragePercent(); got != 37.5 {
+		t.Errorf("expected 37.5%% coverage, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf)).ReportSummary(results, nil, 10)
+	if want := "3 of 8 repositories had package files scanned (37.5%), 3 skipped"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in summary, got:\n%s", want, buf.String())
+	}
+
+	summary := NewJSONReport(results, nil, 10).Summary
+	if summary.RepositoriesCovered != 3 || summary.RepositoriesSkipped != 3 || summary.CoveragePercent != 37.5 {
+		t.Errorf("unexpected JSON coverage %+v", summary)
+	}
+}
+
+func TestCalculateSummaryStats_CoverageWithoutRepos(t *testing.T) {
+	if got := calculateSummaryStats(nil, nil).coveragePercent(); got != 0 {
+		t.Errorf("expected 0%% coverage without repositories, got %v", got)
+	}
+}
+
+func TestReportSummary_ListsReposWithoutPackageFiles(t *testing.T) {
+	results := []*scanner.RepoScanResult{
+		{RepoName: "test-org/web", Language: "TypeScript"},
+		{RepoName: "test-org/service", Language: "Go"},
+		{RepoName: "test-org/app", Language: "JavaScript", FilesScanned: 1, TotalPackages: 3},
+		{RepoName: "test-org/fixtures", Language: "JavaScript", FilesExcluded: 2},
+	}
+
+	var buf bytes.Buffer
+	NewTerminalReporter(WithOutput(&buf), WithListEmpty(true)).ReportSummary(results, &scanner.OrgScanResult{}, 10)
+
+	output := buf.String()
+	for _, want := range []string{
+		"Repositories without package files: 2",
+		"JavaScript projects among them: 1",
+		"test-org/web (TypeScript, discovery found nothing)",
+		"test-org/service (no JS project)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in summary, got:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"test-org/app", "test-org/fixtures"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %q in summary, got:\n%s", unwanted, output)
+		}
+	}
+
+	buf.Reset()
+	NewTerminalReporter(WithOutput(&buf)).ReportSummary(results, &scanner.OrgScanResult{}, 10)
+	if !strings.Contains(buf.String(), "Repositories without package files: 2") {
+		t.Errorf("expected the count without --list-empty, got:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "test-org/web") {
+		t.Errorf("expected repositories to be listed only with --list-empty, got:\n%s", buf.String())
+	}
+}
+
+func TestIsTerminal_NonTerminalWriters(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("expected a buffer not to be a terminal")
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "report.txt"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer f.Close()
+	if IsTerminal(f) {
+		t.Error("expected a regular file not to be a terminal")
+	}
+}
+
+func TestIsTerminal_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if IsTerminal(os.Stdout) {
+		t.Error("expected NO_COLOR to disable color")
+	}
+}
@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Changes written by WriteJSONChanges
+const (
+	ChangeNew      = "new"
+	ChangeResolved = "resolved"
+)
+
+// JSONChange is one line of the NDJSON stream written in --watch mode: a
+// finding that appeared or was resolved since the previous iteration
+type JSONChange struct {
+	Time      string       `json:"time"`
+	Iteration int          `json:"iteration"`
+	Change    string       `json:"change"`
+	Finding   *JSONFinding `json:"finding"`
+}
+
+// WriteJSONChanges writes the new and resolved findings of a diff as
+// newline-delimited JSON, one change per line. Unchanged findings are omitted.
+func WriteJSONChanges(w io.Writer, diff *ReportDiff, at time.Time, iteration int) error {
+	enc := json.NewEncoder(w)
+	write := func(change string, findings []*JSONFinding) error {
+		for _, f := range findings {
+			line := &JSONChange{
+				Time:      at.UTC().Format(time.RFC3339),
+				Iteration: iteration,
+				Change:    change,
+				Finding:   f,
+			}
+			if err := enc.Encode(line); err != nil {
+				return fmt.Errorf("failed to encode change: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := write(ChangeNew, diff.New); err != nil {
+		return err
+	}
+	return write(ChangeResolved, diff.Resolved)
+}
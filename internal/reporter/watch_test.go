@@ -0,0 +1,46 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONChanges(t *testing.T) {
+	diff := &ReportDiff{
+		New:       []*JSONFinding{{ID: "added", Category: "vulnerable-package", Repository: "test-org/web"}},
+		Resolved:  []*JSONFinding{{ID: "fixed", Category: "malicious-branch", Repository: "test-org/app"}},
+		Unchanged: []*JSONFinding{{ID: "kept", Category: "vulnerable-package", Repository: "test-org/app"}},
+	}
+	at := time.Date(2025, 9, 16, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteJSONChanges(&buf, diff, at, 3); err != nil {
+		t.Fatalf("WriteJSONChanges failed: %v", err)
+	}
+
+	var changes []JSONChange
+	lines := bufio.NewScanner(&buf)
+	for lines.Scan() {
+		var change JSONChange
+		if err := json.Unmarshal(lines.Bytes(), &change); err != nil {
+			t.Fatalf("line is not valid JSON: %v", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes (unchanged omitted), got %+v", changes)
+	}
+	if changes[0].Change != ChangeNew || changes[0].Finding.ID != "added" {
+		t.Errorf("expected the new finding first, got %+v", changes[0])
+	}
+	if changes[1].Change != ChangeResolved || changes[1].Finding.ID != "fixed" {
+		t.Errorf("expected the resolved finding second, got %+v", changes[1])
+	}
+	if changes[0].Time != "2025-09-16T12:00:00Z" || changes[0].Iteration != 3 {
+		t.Errorf("expected time and iteration to be recorded, got %+v", changes[0])
+	}
+}
@@ -0,0 +1,175 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// Advisory represents a heuristic finding that is not proof of compromise but
+// warrants review. Advisories are only produced when deep inspection is enabled.
+type Advisory struct {
+	Kind     string // e.g., "SuspiciousFilesField"
+	FilePath string
+	RepoName string
+	Detail   string
+	Known    bool // Present in the baseline
+}
+
+// AdvisorySuspiciousFilesField flags a package.json "files" field that may publish secrets
+const AdvisorySuspiciousFilesField = "SuspiciousFilesField"
+
+// broadFilesGlobs are "files" entries that publish the entire package directory
+var broadFilesGlobs = []string{"*", "**", "**/*", ".", "./", "/", "./*", "./**", "./**/*"}
+
+// sensitivePathNames are path segments that should never be published to npm
+var sensitivePathNames = []string{".env", ".git", ".npmrc", ".netrc", ".ssh", ".aws", "id_rsa", "id_ed25519"}
+
+// envTemplateSuffixes mark .env variants that are conventionally safe to publish
+var envTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// CheckFilesField flags package.json files whose "files" field is overly broad
+// or explicitly includes sensitive paths such as .env or .git
+func (s *Scanner) CheckFilesField(files []*github.PackageFile) []*Advisory {
+	var advisories []*Advisory
+
+	for _, file := range files {
+		if path.Base(file.Path) != "package.json" {
+			continue
+		}
+
+		var pkg struct {
+			Files []string `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(file.Content), &pkg); err != nil {
+			continue
+		}
+
+		for _, entry := range pkg.Files {
+			reason := classifyFilesEntry(entry)
+			if reason == "" {
+				continue
+			}
+			advisories = append(advisories, &Advisory{
+				Kind:     AdvisorySuspiciousFilesField,
+				FilePath: file.Path,
+				RepoName: file.RepoName,
+				Detail:   reason,
+			})
+		}
+	}
+
+	return advisories
+}
+
+// classifyFilesEntry returns why a "files" entry is suspicious, or "" if it is not
+func classifyFilesEntry(entry string) string {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || strings.HasPrefix(entry, "!") {
+		return ""
+	}
+
+	for _, glob := range broadFilesGlobs {
+		if entry == glob {
+			return fmt.Sprintf("files entry %q publishes the entire package directory", entry)
+		}
+	}
+
+	for _, segment := range strings.Split(entry, "/") {
+		if isSensitivePathSegment(segment) {
+			return fmt.Sprintf("files entry %q includes sensitive path %q", entry, segment)
+		}
+	}
+
+	return ""
+}
+
+// isSensitivePathSegment checks if a single path segment names a secret-bearing file or directory
+func isSensitivePathSegment(segment string) bool {
+	for _, name := range sensitivePathNames {
+		if segment == name {
+			return true
+		}
+	}
+
+	return isEnvVariant(segment)
+}
+
+// isEnvVariant checks for .env.local, .env.production, etc. but not .env.example
+func isEnvVariant(name string) bool {
+	if !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	for _, suffix := range envTemplateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// AdvisoryCommittedSecretFile flags a secret-bearing file committed to the repository
+const AdvisoryCommittedSecretFile = "CommittedSecretFile"
+
+// SmallSecretFileSize is the largest committed .npmrc downloaded to inspect its content
+const SmallSecretFileSize = 4096
+
+// committedSecretNames are file names that hold credentials when committed
+var committedSecretNames = []string{
+	".env", ".npmrc", ".netrc", ".pypirc", ".git-credentials",
+	"credentials.json", "id_rsa", "id_ed25519",
+}
+
+// IsCommittedSecretPath checks if a repository path is a secret-bearing file.
+// Template variants such as .env.example and vendored node_modules are ignored.
+func IsCommittedSecretPath(filePath string) bool {
+	if slices.Contains(strings.Split(filePath, "/"), "node_modules") {
+		return false
+	}
+	base := path.Base(filePath)
+	return slices.Contains(committedSecretNames, base) ||
+		isEnvVariant(base) ||
+		strings.HasSuffix(filePath, ".aws/credentials")
+}
+
+// IsInspectableNpmrc checks if a committed file is an .npmrc small enough to
+// download and inspect. Other secret files are flagged by their presence, so
+// their contents are never downloaded.
+func IsInspectableNpmrc(file *github.TreeFile) bool {
+	return IsNpmrcPath(file.Path) && file.Size <= SmallSecretFileSize
+}
+
+// CheckCommittedSecretFiles flags committed secret-bearing files so their
+// secrets can be rotated. An .npmrc is only flagged when it holds literal
+// credentials, not ones read from the environment, or when it was too large
+// to inspect.
+func (s *Scanner) CheckCommittedSecretFiles(files []*github.TreeFile) []*Advisory {
+	var advisories []*Advisory
+
+	for _, file := range files {
+		if !IsCommittedSecretPath(file.Path) {
+			continue
+		}
+
+		detail := fmt.Sprintf("committed %s may expose secrets; rotate any credentials it held", path.Base(file.Path))
+		if path.Base(file.Path) == ".npmrc" {
+			if file.Fetched && !hasCommittedNpmrcCredential(file.Content) {
+				continue
+			}
+			detail = "committed .npmrc may hold a registry token; revoke and rotate it"
+		}
+
+		advisories = append(advisories, &Advisory{
+			Kind:     AdvisoryCommittedSecretFile,
+			FilePath: file.Path,
+			RepoName: file.RepoName,
+			Detail:   detail,
+		})
+	}
+
+	return advisories
+}
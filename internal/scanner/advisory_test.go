@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestScanner_CheckFilesField(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    string
+		expected int
+	}{
+		{"publishes everything", `["**/*"]`, 1},
+		{"includes .env", `["dist", ".env"]`, 1},
+		{"includes nested .git", `["lib", "src/.git/**"]`, 1},
+		{"includes .env.production", `[".env.production"]`, 1},
+		{"normal dist", `["dist"]`, 0},
+		{"env template", `[".env.example"]`, 0},
+		{"negated pattern", `["dist", "!.env"]`, 0},
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithDeepInspect(true))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := []*github.PackageFile{
+				{
+					RepoName: "test-org/test-repo",
+					Path:     "package.json",
+					Content:  `{"name": "test-muaddib-pkg", "files": ` + tc.files + `}`,
+				},
+			}
+
+			advisories := scanner.CheckFilesField(files)
+
+			if len(advisories) != tc.expected {
+				t.Fatalf("expected %d advisories, got %d", tc.expected, len(advisories))
+			}
+			for _, a := range advisories {
+				if a.Kind != AdvisorySuspiciousFilesField {
+					t.Errorf("expected kind %s, got %s", AdvisorySuspiciousFilesField, a.Kind)
+				}
+				if a.FilePath != "package.json" || a.RepoName != "test-org/test-repo" {
+					t.Errorf("unexpected location %s in %s", a.FilePath, a.RepoName)
+				}
+			}
+		})
+	}
+}
+
+func TestScanner_CheckFilesField_IgnoresLockfiles(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithDeepInspect(true))
+
+	files := []*github.PackageFile{
+		{RepoName: "test-repo", Path: "package-lock.json", Content: `{"files": ["**/*"]}`},
+	}
+
+	if advisories := scanner.CheckFilesField(files); len(advisories) != 0 {
+		t.Errorf("expected 0 advisories for lockfile, got %d", len(advisories))
+	}
+}
+
+func TestScanner_ScanFiles_AdvisoriesRequireDeepInspect(t *testing.T) {
+	files := []*github.PackageFile{
+		{RepoName: "test-repo", Path: "package.json", Content: `{"name": "test-muaddib-pkg", "files": ["**/*"]}`},
+	}
+
+	if result := NewScanner(vuln.NewVulnDB(), true).ScanFiles(files); len(result.Advisories) != 0 {
+		t.Errorf("expected no advisories without deep inspection, got %d", len(result.Advisories))
+	}
+
+	if result := NewScanner(vuln.NewVulnDB(), true, WithDeepInspect(true)).ScanFiles(files); len(result.Advisories) != 1 {
+		t.Errorf("expected 1 advisory with deep inspection, got %d", len(result.Advisories))
+	}
+}
+
+func TestIsCommittedSecretPath(t *testing.T) {
+	testCases := []struct {
+		path string
+		want bool
+	}{
+		{".env", true},
+		{"services/api/.env", true},
+		{".env.production", true},
+		{".npmrc", true},
+		{"packages/web/.npmrc", true},
+		{"config/credentials.json", true},
+		{"deploy/.aws/credentials", true},
+		{".env.example", false},
+		{".env.sample", false},
+		{"docs/.env.template", false},
+		{"node_modules/some-pkg/.npmrc", false},
+		{"environment.ts", false},
+		{".envrc", false},
+		{"credentials.json.example", false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsCommittedSecretPath(tc.path); got != tc.want {
+			t.Errorf("IsCommittedSecretPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestScanner_CheckCommittedSecretFiles(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+
+	files := []*github.TreeFile{
+		{RepoName: "test-org/test-repo", Path: ".env", Size: 20000},
+		{RepoName: "test-org/test-repo", Path: ".npmrc", Fetched: true, Content: "//registry.npmjs.org/:_authToken=npm_secret\n"},
+		{RepoName: "test-org/test-repo", Path: "tools/.npmrc", Fetched: true, Content: "registry=https://registry.npmjs.org/\n"},
+		{RepoName: "test-org/test-repo", Path: "ci/.npmrc", Fetched: true, Content: "//registry.npmjs.org/:_authToken=${NPM_TOKEN}\n_auth = \"${NPM_AUTH}\"\n"},
+		{RepoName: "test-org/test-repo", Path: "large/.npmrc", Size: 20000},
+		{RepoName: "test-org/test-repo", Path: ".env.example", Fetched: true},
+	}
+
+	advisories := scanner.CheckCommittedSecretFiles(files)
+
+	want := []string{".env", ".npmrc", "large/.npmrc"}
+	if len(advisories) != len(want) {
+		t.Fatalf("expected %d advisories, got %d", len(want), len(advisories))
+	}
+	for i, a := range advisories {
+		if a.FilePath != want[i] {
+			t.Errorf("advisory %d: expected %s, got %s", i, want[i], a.FilePath)
+		}
+		if a.Kind != AdvisoryCommittedSecretFile {
+			t.Errorf("advisory %d: expected kind %s, got %s", i, AdvisoryCommittedSecretFile, a.Kind)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Baseline is a point-in-time snapshot of accepted findings taken from a
+// prior JSON report. Findings present in the baseline are treated as known.
+type Baseline struct {
+	ids map[string]bool
+}
+
+// baselineReport is the subset of the JSON report needed to build a baseline
+type baselineReport struct {
+	Findings []struct {
+		ID string `json:"id"`
+	} `json:"findings"`
+}
+
+// LoadBaseline loads a baseline from a prior JSON report on disk
+func LoadBaseline(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline file: %w", err)
+	}
+	defer f.Close()
+
+	return ParseBaseline(f)
+}
+
+// ParseBaseline parses a baseline from a JSON report
+func ParseBaseline(r io.Reader) (*Baseline, error) {
+	var report baselineReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline report: %w", err)
+	}
+
+	b := &Baseline{ids: make(map[string]bool)}
+	for _, f := range report.Findings {
+		if f.ID != "" {
+			b.ids[f.ID] = true
+		}
+	}
+	return b, nil
+}
+
+// Size returns the number of findings in the baseline
+func (b *Baseline) Size() int {
+	return len(b.ids)
+}
+
+// Contains checks if a finding ID is present in the baseline
+func (b *Baseline) Contains(id string) bool {
+	return b != nil && b.ids[id]
+}
+
+// Apply marks findings in the result that are present in the baseline as known.
+// Unless includeKnown is set, known findings are removed from the result and
+// only counted in KnownFindings so they no longer affect the scan outcome.
+func (b *Baseline) Apply(result *RepoScanResult, includeKnown bool) {
+	if b == nil || result == nil {
+		return
+	}
+
+	var known int
+	result.VulnerablePackages, known = partitionKnown(result.VulnerablePackages, includeKnown, b.markVulnerablePackage)
+	result.KnownFindings += known
+	result.MaliciousWorkflows, known = partitionKnown(result.MaliciousWorkflows, includeKnown, b.markWorkflow)
+	result.KnownFindings += known
+	result.MaliciousScripts, known = partitionKnown(result.MaliciousScripts, includeKnown, b.markScript)
+	result.KnownFindings += known
+	result.MaliciousBranches, known = partitionKnown(result.MaliciousBranches, includeKnown, b.markBranch)
+	result.KnownFindings += known
+	result.Advisories, known = partitionKnown(result.Advisories, includeKnown, b.markAdvisory)
+	result.KnownFindings += known
+	result.SuspiciousPackages, known = partitionKnown(result.SuspiciousPackages, includeKnown, b.markSuspiciousPackage)
+	result.KnownFindings += known
+	result.SuspiciousConfigs, known = partitionKnown(result.SuspiciousConfigs, includeKnown, b.markSuspiciousConfig)
+	result.KnownFindings += known
+}
+
+// ApplyOrg marks org-level findings present in the baseline as known
+func (b *Baseline) ApplyOrg(orgResult *OrgScanResult, includeKnown bool) {
+	if b == nil || orgResult == nil {
+		return
+	}
+
+	var known int
+	orgResult.MaliciousRepos, known = partitionKnown(orgResult.MaliciousRepos, includeKnown, b.markRepo)
+	orgResult.KnownFindings += known
+}
+
+func (b *Baseline) markVulnerablePackage(vp *VulnerablePackage) bool {
+	vp.Known = b.Contains(vp.ID())
+	return vp.Known
+}
+
+func (b *Baseline) markWorkflow(mw *MaliciousWorkflow) bool {
+	mw.Known = b.Contains(mw.ID())
+	return mw.Known
+}
+
+func (b *Baseline) markScript(ms *MaliciousScript) bool {
+	ms.Known = b.Contains(ms.ID())
+	return ms.Known
+}
+
+func (b *Baseline) markBranch(mb *MaliciousBranch) bool {
+	mb.Known = b.Contains(mb.ID())
+	return mb.Known
+}
+
+func (b *Baseline) markAdvisory(a *Advisory) bool {
+	a.Known = b.Contains(a.ID())
+	return a.Known
+}
+
+func (b *Baseline) markSuspiciousPackage(sp *SuspiciousPackage) bool {
+	sp.Known = b.Contains(sp.ID())
+	return sp.Known
+}
+
+func (b *Baseline) markSuspiciousConfig(sc *SuspiciousConfig) bool {
+	sc.Known = b.Contains(sc.ID())
+	return sc.Known
+}
+
+func (b *Baseline) markRepo(mr *MaliciousRepo) bool {
+	mr.Known = b.Contains(mr.ID())
+	return mr.Known
+}
+
+// partitionKnown marks each item and, unless keepKnown is set, drops known items
+// from the returned slice. It returns the number of known items found.
+func partitionKnown[T any](items []T, keepKnown bool, mark func(T) bool) ([]T, int) {
+	var kept []T
+	known := 0
+	for _, item := range items {
+		if mark(item) {
+			known++
+			if !keepKnown {
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	return kept, known
+}
@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// newBaselineTestResult creates a result with one known and one new finding of each kind
+func newBaselineTestResult() *RepoScanResult {
+	entry := &vuln.VulnEntry{PackageName: "test-muaddib-vulnerable", PackageVersion: "1.0.0"}
+	return &RepoScanResult{
+		RepoName: "test-org/test-repo",
+		VulnerablePackages: []*VulnerablePackage{
+			{Package: &Package{Name: "test-muaddib-vulnerable", Version: "1.0.0"}, VulnEntry: entry, FilePath: "package.json", RepoName: "test-org/test-repo"},
+			{Package: &Package{Name: "test-muaddib-vulnerable", Version: "1.0.0"}, VulnEntry: entry, FilePath: "package-lock.json", RepoName: "test-org/test-repo"},
+		},
+		MaliciousBranches: []*MaliciousBranch{
+			{RepoName: "test-org/test-repo", BranchName: "shai-hulud"},
+		},
+	}
+}
+
+// baselineJSON builds a JSON report containing the given finding IDs
+func baselineJSON(ids ...string) string {
+	var entries []string
+	for _, id := range ids {
+		entries = append(entries, fmt.Sprintf(`{"id": %q, "category": "ignored"}`, id))
+	}
+	return `{"repositories": [], "findings": [` + strings.Join(entries, ",") + `]}`
+}
+
+func TestParseBaseline(t *testing.T) {
+	b, err := ParseBaseline(strings.NewReader(baselineJSON("abc", "def", "")))
+	if err != nil {
+		t.Fatalf("ParseBaseline failed: %v", err)
+	}
+
+	if b.Size() != 2 {
+		t.Errorf("expected 2 findings in baseline, got %d", b.Size())
+	}
+	if !b.Contains("abc") || !b.Contains("def") {
+		t.Error("expected baseline to contain abc and def")
+	}
+	if b.Contains("") {
+		t.Error("expected empty IDs to be ignored")
+	}
+}
+
+func TestParseBaseline_InvalidJSON(t *testing.T) {
+	if _, err := ParseBaseline(strings.NewReader("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestLoadBaseline_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte(baselineJSON("abc")), 0o600); err != nil {
+		t.Fatalf("failed to write baseline: %v", err)
+	}
+
+	b, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+	if !b.Contains("abc") {
+		t.Error("expected baseline to contain abc")
+	}
+}
+
+func TestBaseline_Apply_RemovesKnownFindings(t *testing.T) {
+	result := newBaselineTestResult()
+	known := result.VulnerablePackages[0]
+	b, err := ParseBaseline(strings.NewReader(baselineJSON(known.ID(), result.MaliciousBranches[0].ID())))
+	if err != nil {
+		t.Fatalf("ParseBaseline failed: %v", err)
+	}
+
+	b.Apply(result, false)
+
+	if result.KnownFindings != 2 {
+		t.Errorf("expected 2 known findings, got %d", result.KnownFindings)
+	}
+	if len(result.VulnerablePackages) != 1 {
+		t.Fatalf("expected 1 new vulnerable package, got %d", len(result.VulnerablePackages))
+	}
+	if result.VulnerablePackages[0].FilePath != "package-lock.json" {
+		t.Errorf("expected the package-lock.json finding to be new, got %s", result.VulnerablePackages[0].FilePath)
+	}
+	if result.VulnerablePackages[0].Known {
+		t.Error("expected new finding not to be marked known")
+	}
+	if len(result.MaliciousBranches) != 0 {
+		t.Errorf("expected known branch to be removed, got %d", len(result.MaliciousBranches))
+	}
+}
+
+func TestBaseline_Apply_IncludeKnown(t *testing.T) {
+	result := newBaselineTestResult()
+	b, err := ParseBaseline(strings.NewReader(baselineJSON(result.VulnerablePackages[0].ID())))
+	if err != nil {
+		t.Fatalf("ParseBaseline failed: %v", err)
+	}
+
+	b.Apply(result, true)
+
+	if result.KnownFindings != 1 {
+		t.Errorf("expected 1 known finding, got %d", result.KnownFindings)
+	}
+	if len(result.VulnerablePackages) != 2 {
+		t.Fatalf("expected known finding to be kept, got %d packages", len(result.VulnerablePackages))
+	}
+	if !result.VulnerablePackages[0].Known || result.VulnerablePackages[1].Known {
+		t.Error("expected only the baseline finding to be marked known")
+	}
+}
+
+func TestBaseline_ApplyOrg(t *testing.T) {
+	orgResult := &OrgScanResult{
+		MaliciousRepos: []*MaliciousRepo{
+			{RepoName: "test-org/old-migration", Description: "Shai-Hulud Migration"},
+			{RepoName: "test-org/new-migration", Description: "Shai-Hulud Migration"},
+		},
+	}
+	b, err := ParseBaseline(strings.NewReader(baselineJSON(orgResult.MaliciousRepos[0].ID())))
+	if err != nil {
+		t.Fatalf("ParseBaseline failed: %v", err)
+	}
+
+	b.ApplyOrg(orgResult, false)
+
+	if orgResult.KnownFindings != 1 {
+		t.Errorf("expected 1 known finding, got %d", orgResult.KnownFindings)
+	}
+	if len(orgResult.MaliciousRepos) != 1 || orgResult.MaliciousRepos[0].RepoName != "test-org/new-migration" {
+		t.Errorf("expected only the new migration repo to remain, got %v", orgResult.MaliciousRepos)
+	}
+}
+
+func TestBaseline_NilIsNoop(t *testing.T) {
+	var b *Baseline
+	result := newBaselineTestResult()
+
+	b.Apply(result, false)
+
+	if len(result.VulnerablePackages) != 2 || result.KnownFindings != 0 {
+		t.Error("expected nil baseline to leave the result untouched")
+	}
+}
+
+func TestFindingID_StableAndDistinct(t *testing.T) {
+	result := newBaselineTestResult()
+	first := result.VulnerablePackages[0].ID()
+
+	if first != newBaselineTestResult().VulnerablePackages[0].ID() {
+		t.Error("expected finding IDs to be stable across scans")
+	}
+	if first == result.VulnerablePackages[1].ID() {
+		t.Error("expected findings in different files to have distinct IDs")
+	}
+
+	repo := &MaliciousRepo{RepoName: "test-org/migration", Description: "Shai-Hulud Migration"}
+	if repo.ID() != (&MaliciousRepo{RepoName: "test-org/migration", Description: "Reworded"}).ID() {
+		t.Error("expected malicious repository IDs not to depend on the description")
+	}
+}
+
+func TestRepoScanResult_Findings(t *testing.T) {
+	result := newBaselineTestResult()
+	result.VulnerablePackages[0].Known = true
+
+	findings := result.Findings()
+
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(findings))
+	}
+	if findings[0].Category != CategoryVulnerablePackage || !findings[0].Known {
+		t.Errorf("unexpected first finding: %+v", findings[0])
+	}
+	if findings[2].Category != CategoryMaliciousBranch || findings[2].Detail != "shai-hulud" {
+		t.Errorf("unexpected branch finding: %+v", findings[2])
+	}
+	if findings[0].ID != result.VulnerablePackages[0].ID() {
+		t.Error("expected flattened finding to carry the source ID")
+	}
+}
+
+func TestMaliciousWorkflow_SeverityFollowsActionsEnabled(t *testing.T) {
+	mw := &MaliciousWorkflow{RepoName: "test-org/test-repo", FilePath: ".github/workflows/discussion.yaml"}
+
+	if mw.Severity() != SeverityHigh {
+		t.Errorf("expected high severity when Actions status is unknown, got %s", mw.Severity())
+	}
+
+	SetActionsEnabled([]*MaliciousWorkflow{mw}, true)
+	if mw.Severity() != SeverityCritical {
+		t.Errorf("expected critical severity when Actions is enabled, got %s", mw.Severity())
+	}
+
+	SetActionsEnabled([]*MaliciousWorkflow{mw}, false)
+	if mw.Severity() != SeverityMedium {
+		t.Errorf("expected medium severity when Actions is disabled, got %s", mw.Severity())
+	}
+
+	findings := (&RepoScanResult{MaliciousWorkflows: []*MaliciousWorkflow{mw}}).Findings()
+	if len(findings) != 1 || findings[0].Severity != SeverityMedium {
+		t.Errorf("expected flattened finding to carry the workflow severity, got %+v", findings)
+	}
+}
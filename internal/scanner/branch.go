@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"path"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// MaliciousPayloadFiles are file names of payloads dropped by the Shai-Hulud worm
+var MaliciousPayloadFiles = []string{
+	"bundle.js",
+	"setup_bun.js",
+	"bun_environment.js",
+}
+
+// InspectBranch records what a malicious branch changed relative to the default
+// branch and scans the changed package files and workflows for worm payloads
+func (s *Scanner) InspectBranch(branch *MaliciousBranch, diff *github.BranchDiff) {
+	if branch == nil || diff == nil {
+		return
+	}
+
+	branch.ChangedFiles = diff.ChangedFiles
+	branch.Authors = diff.Authors
+	branch.PayloadFiles = nil
+	for _, filePath := range diff.ChangedFiles {
+		if isMaliciousPayloadFile(filePath) {
+			branch.PayloadFiles = append(branch.PayloadFiles, filePath)
+		}
+	}
+
+	inspection := s.ScanFiles(diff.PackageFiles)
+	inspection.RepoName = branch.RepoName
+	inspection.Ref = branch.BranchName
+	inspection.MaliciousWorkflows = append(s.CheckWorkflows(diff.WorkflowFiles), s.CheckPersistenceWorkflows(diff.WorkflowFiles)...)
+	branch.Inspection = inspection
+}
+
+// PushedBy checks if the given login authored or committed any of the branch's
+// commits. Logins are compared case-insensitively, as GitHub treats them.
+// Only inspected branches record their authors.
+func (mb *MaliciousBranch) PushedBy(login string) bool {
+	for _, author := range mb.Authors {
+		if strings.EqualFold(author, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchesPushedBy returns the branches the given login pushed commits to
+func BranchesPushedBy(branches []*MaliciousBranch, login string) []*MaliciousBranch {
+	var matched []*MaliciousBranch
+	for _, mb := range branches {
+		if mb.PushedBy(login) {
+			matched = append(matched, mb)
+		}
+	}
+	return matched
+}
+
+// isMaliciousPayloadFile checks if a path names a known worm payload file
+func isMaliciousPayloadFile(filePath string) bool {
+	base := path.Base(filePath)
+	for _, name := range MaliciousPayloadFiles {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
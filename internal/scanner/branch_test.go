@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestScanner_InspectBranch(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	scanner := NewScanner(db, true)
+
+	branch := &MaliciousBranch{RepoName: "test-org/test-repo", BranchName: "shai-hulud"}
+	diff := &github.BranchDiff{
+		Branch:       "shai-hulud",
+		ChangedFiles: []string{"package.json", "bundle.js", "README.md", ".github/workflows/discussion.yaml"},
+		PackageFiles: []*github.PackageFile{
+			{
+				RepoName: "test-org/test-repo",
+				Path:     "package.json",
+				Content: `{
+					"dependencies": {"test-muaddib-vulnerable": "1.0.0"},
+					"scripts": {"postinstall": "node bundle.js"}
+				}`,
+			},
+		},
+		WorkflowFiles: []*github.WorkflowFile{
+			{
+				RepoName: "test-org/test-repo",
+				Path:     ".github/workflows/discussion.yaml",
+				Content:  "run: echo ${{ github.event.discussion.body }}",
+			},
+		},
+	}
+
+	scanner.InspectBranch(branch, diff)
+
+	if len(branch.ChangedFiles) != 4 {
+		t.Errorf("expected 4 changed files, got %d", len(branch.ChangedFiles))
+	}
+	if len(branch.PayloadFiles) != 1 || branch.PayloadFiles[0] != "bundle.js" {
+		t.Errorf("expected bundle.js payload, got %v", branch.PayloadFiles)
+	}
+	if branch.Inspection == nil {
+		t.Fatal("expected inspection result")
+	}
+	if len(branch.Inspection.VulnerablePackages) != 1 {
+		t.Errorf("expected 1 vulnerable package on branch, got %d", len(branch.Inspection.VulnerablePackages))
+	}
+	if len(branch.Inspection.MaliciousScripts) != 1 {
+		t.Errorf("expected 1 malicious script on branch, got %d", len(branch.Inspection.MaliciousScripts))
+	}
+	if len(branch.Inspection.MaliciousWorkflows) != 1 {
+		t.Errorf("expected 1 malicious workflow on branch, got %d", len(branch.Inspection.MaliciousWorkflows))
+	}
+}
+
+func TestScanner_InspectBranch_NoChanges(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+	branch := &MaliciousBranch{RepoName: "test-org/test-repo", BranchName: "shai-hulud"}
+
+	scanner.InspectBranch(branch, &github.BranchDiff{Branch: "shai-hulud"})
+
+	if len(branch.PayloadFiles) != 0 {
+		t.Errorf("expected no payload files, got %v", branch.PayloadFiles)
+	}
+	if branch.Inspection == nil || len(branch.Inspection.VulnerablePackages) != 0 {
+		t.Error("expected an empty inspection result")
+	}
+}
+
+func TestBranchesPushedBy(t *testing.T) {
+	scanner := NewScanner(nil, true)
+	inspected := &MaliciousBranch{RepoName: "test-org/test-repo", BranchName: "shai-hulud"}
+	scanner.InspectBranch(inspected, &github.BranchDiff{
+		Branch:  "shai-hulud",
+		Authors: []string{"test-muaddib-compromised", "web-flow"},
+	})
+	other := &MaliciousBranch{RepoName: "test-org/other-repo", BranchName: "shai-hulud", Authors: []string{"test-muaddib-someone"}}
+	uninspected := &MaliciousBranch{RepoName: "test-org/third-repo", BranchName: "shai-hulud"}
+	branches := []*MaliciousBranch{inspected, other, uninspected}
+
+	tests := []struct {
+		name  string
+		login string
+		want  []*MaliciousBranch
+	}{
+		{name: "exact login", login: "test-muaddib-compromised", want: []*MaliciousBranch{inspected}},
+		{name: "case-insensitive login", login: "Test-Muaddib-Compromised", want: []*MaliciousBranch{inspected}},
+		{name: "committer login", login: "web-flow", want: []*MaliciousBranch{inspected}},
+		{name: "unknown login", login: "test-muaddib-nobody", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BranchesPushedBy(branches, tt.login)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d branches, got %d", len(tt.want), len(got))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("branch %d: expected %s, got %s", i, tt.want[i].RepoName, got[i].RepoName)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bunLockbHeader starts every binary bun.lockb, whatever its format version
+const bunLockbHeader = "#!/usr/bin/env bun\nbun-lockfile-format-v0\n"
+
+// ErrBinaryLockfile is returned for lockfiles in a binary format muaddib
+// cannot decode. The packages they pin are not checked.
+var ErrBinaryLockfile = errors.New("binary lockfile cannot be decoded")
+
+// ParseBunLockb identifies a binary bun.lockb. Its layout mirrors Bun's
+// in-memory structures and changes between Bun releases, and the versions
+// are stored as packed structs rather than strings, so it is not decoded:
+// the error wraps ErrBinaryLockfile and names the format version so the file
+// can be reported. Bun 1.2 writes the text bun.lock by default, and
+// `bun install --save-text-lockfile` converts older projects.
+func ParseBunLockb(content string) ([]*Package, error) {
+	if !strings.HasPrefix(content, bunLockbHeader) || len(content) < len(bunLockbHeader)+4 {
+		return nil, fmt.Errorf("failed to parse bun.lockb: missing Bun lockfile header")
+	}
+	version := binary.LittleEndian.Uint32([]byte(content[len(bunLockbHeader):]))
+	return nil, fmt.Errorf("bun.lockb format version %d: %w", version, ErrBinaryLockfile)
+}
+
+// BunLock represents the structure of a text bun.lock file
+type BunLock struct {
+	LockfileVersion int                          `json:"lockfileVersion"`
+	Workspaces      map[string]BunWorkspace      `json:"workspaces"`
+	Packages        map[string][]json.RawMessage `json:"packages"`
+}
+
+// BunWorkspace represents a workspace entry in bun.lock; the root package is
+// the workspace with the empty key
+type BunWorkspace struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// BunPackageInfo is the metadata object in a bun.lock package entry
+type BunPackageInfo struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// bunPackage is a decoded bun.lock package entry. Entries are arrays of
+// ["name@version", registry, info, integrity]; workspace, git, and file
+// entries are shorter and have non-semver versions.
+type bunPackage struct {
+	name      string
+	version   string
+	info      BunPackageInfo
+	integrity string
+}
+
+// ParseBunLock parses a text bun.lock file and returns the list of packages.
+// bun.lock is JSONC, so comments and trailing commas are tolerated. Bun does
+// not mark dev packages, so packages only reachable from the workspaces'
+// devDependencies are treated as dev.
+func ParseBunLock(content string, includeDev bool) ([]*Package, error) {
+	var lockFile BunLock
+	if err := json.Unmarshal([]byte(stripJSONC(content)), &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse bun.lock: %w", err)
+	}
+
+	entries := make(map[string]*bunPackage, len(lockFile.Packages))
+	for key, fields := range lockFile.Packages {
+		if entry := decodeBunPackage(fields); entry != nil {
+			entries[key] = entry
+		}
+	}
+
+	direct, prodRoots := bunWorkspaceDependencies(lockFile.Workspaces)
+	prod := reachableBunPackages(entries, prodRoots)
+
+	var packages []*Package
+	merged := make(map[string]*Package)
+	for key, entry := range entries {
+		isDev := !prod[key]
+		if isDev && !includeDev {
+			continue
+		}
+
+		source := "transitive"
+		if direct[key] {
+			source = "direct"
+		}
+
+		// Copies of the same version at different keys are reported once,
+		// as production if any copy is and as direct if any copy is
+		pkgKey := entry.name + "@" + entry.version
+		if pkg, ok := merged[pkgKey]; ok {
+			pkg.IsDev = pkg.IsDev && isDev
+			if source == "direct" {
+				pkg.Source = source
+			}
+			if pkg.Integrity == "" {
+				pkg.Integrity = entry.integrity
+			}
+			continue
+		}
+
+		pkg := &Package{
+			Name:      entry.name,
+			Version:   entry.version,
+			IsDev:     isDev,
+			Source:    source,
+			Integrity: entry.integrity,
+		}
+		merged[pkgKey] = pkg
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// bunWorkspaceDependencies returns the names of the workspaces' direct
+// dependencies, and those of them that are not devDependencies
+func bunWorkspaceDependencies(workspaces map[string]BunWorkspace) (map[string]bool, []string) {
+	direct := make(map[string]bool)
+	var prodRoots []string
+	for _, ws := range workspaces {
+		for _, deps := range []map[string]string{ws.Dependencies, ws.OptionalDependencies, ws.PeerDependencies} {
+			for name := range deps {
+				direct[name] = true
+				prodRoots = append(prodRoots, name)
+			}
+		}
+		for name := range ws.DevDependencies {
+			direct[name] = true
+		}
+	}
+	return direct, prodRoots
+}
+
+// decodeBunPackage decodes a bun.lock package entry, returning nil for
+// entries that do not resolve to a registry version
+func decodeBunPackage(fields []json.RawMessage) *bunPackage {
+	if len(fields) == 0 {
+		return nil
+	}
+	var ident string
+	if err := json.Unmarshal(fields[0], &ident); err != nil {
+		return nil
+	}
+
+	at := strings.LastIndex(ident, "@")
+	if at <= 0 {
+		return nil
+	}
+	entry := &bunPackage{name: ident[:at], version: ident[at+1:]}
+	if entry.version == "" || strings.Contains(entry.version, ":") {
+		// workspace:, github:, file:, and link: entries
+		return nil
+	}
+
+	if len(fields) > 2 {
+		_ = json.Unmarshal(fields[2], &entry.info)
+	}
+	if len(fields) > 3 {
+		_ = json.Unmarshal(fields[3], &entry.integrity)
+	}
+	return entry
+}
+
+// reachableBunPackages returns the keys of the packages reachable from the
+// named root dependencies. A dependency of the package at key "a" resolves to
+// the nested key "a/dep" when present, as with node_modules, else to "dep".
+func reachableBunPackages(entries map[string]*bunPackage, roots []string) map[string]bool {
+	reached := make(map[string]bool)
+	queue := roots
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		entry, ok := entries[key]
+		if !ok || reached[key] {
+			continue
+		}
+		reached[key] = true
+
+		for _, deps := range []map[string]string{entry.info.Dependencies, entry.info.OptionalDependencies, entry.info.PeerDependencies} {
+			for name := range deps {
+				queue = append(queue, resolveBunKey(entries, key, name))
+			}
+		}
+	}
+	return reached
+}
+
+// resolveBunKey finds the package key a dependency of the package at parent
+// resolves to, searching from the most nested key outwards
+func resolveBunKey(entries map[string]*bunPackage, parent, name string) string {
+	for scope := parent; scope != ""; scope = trimBunKey(scope) {
+		if _, ok := entries[scope+"/"+name]; ok {
+			return scope + "/" + name
+		}
+	}
+	return name
+}
+
+// trimBunKey drops the last package name from a nested bun.lock key, keeping
+// scoped names whole: "a/@scope/b" becomes "a"
+func trimBunKey(key string) string {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return ""
+	}
+	if j := strings.LastIndex(key[:i], "/"); strings.HasPrefix(key[j+1:], "@") {
+		i = j
+	}
+	if i < 0 {
+		return ""
+	}
+	return key[:i]
+}
+
+// stripJSONC removes comments and trailing commas from JSONC so it can be
+// decoded as JSON. Strings are copied unchanged.
+func stripJSONC(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '"':
+			end := jsonStringEnd(content, i)
+			b.WriteString(content[i:end])
+			i = end - 1
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		case c == ',' && isTrailingComma(content[i+1:]):
+			// Dropped
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// jsonStringEnd returns the index just past the string starting at start
+func jsonStringEnd(content string, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(content)
+}
+
+// isTrailingComma checks if the text after a comma closes an object or array,
+// skipping whitespace and comments
+func isTrailingComma(rest string) bool {
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				return false
+			}
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return false
+			}
+			rest = rest[end+2:]
+		default:
+			return strings.HasPrefix(rest, "}") || strings.HasPrefix(rest, "]")
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package scanner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// testBunLock is a bun.lock in Bun's text format, trailing commas included
+const testBunLock = `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "test-muaddib-app",
+      "dependencies": {
+        "@test-muaddib/scoped": "^1.0.0",
+        "test-muaddib-vulnerable": "^1.0.0",
+      },
+      "devDependencies": {
+        "test-muaddib-dev": "^3.0.0",
+      },
+    },
+    "packages/lib": {
+      "name": "test-muaddib-lib",
+    },
+  },
+  "packages": {
+    "@test-muaddib/scoped": ["@test-muaddib/scoped@1.2.0", "", { "dependencies": { "test-muaddib-shared": "^1.0.0" } }, "sha512-scoped"],
+    "test-muaddib-vulnerable": ["test-muaddib-vulnerable@1.0.0", "", {}, "sha512-vulnerable"],
+    "test-muaddib-dev": ["test-muaddib-dev@3.0.0", "", { "dependencies": { "test-muaddib-shared": "^2.0.0" } }, "sha512-dev"],
+    "test-muaddib-shared": ["test-muaddib-shared@1.0.0", "", {}, "sha512-shared-1"],
+    "test-muaddib-dev/test-muaddib-shared": ["test-muaddib-shared@2.0.0", "", {}, "sha512-shared-2"],
+    "test-muaddib-lib": ["test-muaddib-lib@workspace:packages/lib"],
+    // Git dependencies have no registry version
+    "test-muaddib-git": ["test-muaddib-git@github:test-org/test-repo#abc123", {}, "test-org-test-repo-abc123"],
+  },
+}
+`
+
+func TestParseBunLock(t *testing.T) {
+	packages, err := ParseBunLock(testBunLock, false)
+	if err != nil {
+		t.Fatalf("ParseBunLock failed: %v", err)
+	}
+
+	got := make(map[string]*Package)
+	for _, pkg := range packages {
+		got[pkg.Name+"@"+pkg.Version] = pkg
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 production packages, got %d: %v", len(got), got)
+	}
+
+	scoped := got["@test-muaddib/scoped@1.2.0"]
+	if scoped == nil || scoped.Source != "direct" || scoped.Integrity != "sha512-scoped" {
+		t.Errorf("expected direct scoped package with integrity, got %+v", scoped)
+	}
+	shared := got["test-muaddib-shared@1.0.0"]
+	if shared == nil || shared.Source != "transitive" || shared.IsDev {
+		t.Errorf("expected transitive production package, got %+v", shared)
+	}
+	if got["test-muaddib-vulnerable@1.0.0"] == nil {
+		t.Error("expected test-muaddib-vulnerable@1.0.0")
+	}
+}
+
+func TestParseBunLock_IncludeDev(t *testing.T) {
+	packages, err := ParseBunLock(testBunLock, true)
+	if err != nil {
+		t.Fatalf("ParseBunLock failed: %v", err)
+	}
+
+	dev := make(map[string]bool)
+	for _, pkg := range packages {
+		dev[pkg.Name+"@"+pkg.Version] = pkg.IsDev
+	}
+	if len(dev) != 5 {
+		t.Fatalf("expected 5 packages with dev dependencies, got %d", len(dev))
+	}
+	// The nested copy is only reachable from a devDependency
+	for _, key := range []string{"test-muaddib-dev@3.0.0", "test-muaddib-shared@2.0.0"} {
+		if !dev[key] {
+			t.Errorf("expected %s to be dev", key)
+		}
+	}
+	if dev["test-muaddib-shared@1.0.0"] {
+		t.Error("expected test-muaddib-shared@1.0.0 to be production")
+	}
+}
+
+func TestParseBunLock_MergesDuplicateVersions(t *testing.T) {
+	// The same version is installed as a direct devDependency and nested
+	// beneath a production dependency
+	content := `{
+		"lockfileVersion": 1,
+		"workspaces": {
+			"": {
+				"name": "test-muaddib-app",
+				"dependencies": {"test-muaddib-parent": "^1.0.0"},
+				"devDependencies": {"test-muaddib-shared": "^2.0.0"},
+			},
+		},
+		"packages": {
+			"test-muaddib-parent": ["test-muaddib-parent@1.0.0", "", {"dependencies": {"test-muaddib-shared": "^2.0.0"}}, "sha512-parent"],
+			"test-muaddib-shared": ["test-muaddib-shared@2.0.0", "", {}, "sha512-shared"],
+			"test-muaddib-parent/test-muaddib-shared": ["test-muaddib-shared@2.0.0", "", {}, "sha512-shared"],
+		},
+	}`
+
+	// Map order varies between runs, so parse several times
+	for range 20 {
+		packages, err := ParseBunLock(content, true)
+		if err != nil {
+			t.Fatalf("ParseBunLock failed: %v", err)
+		}
+		if len(packages) != 2 {
+			t.Fatalf("expected 2 packages, got %d", len(packages))
+		}
+		for _, pkg := range packages {
+			if pkg.Name == "test-muaddib-shared" && (pkg.IsDev || pkg.Source != "direct") {
+				t.Fatalf("expected test-muaddib-shared to be a direct production package, got dev=%v source=%s",
+					pkg.IsDev, pkg.Source)
+			}
+		}
+	}
+}
+
+func TestParseBunLock_InvalidJSON(t *testing.T) {
+	if _, err := ParseBunLock(`{"packages": [`, true); err == nil {
+		t.Error("expected an error for invalid bun.lock")
+	}
+}
+
+func TestParseBunLockb(t *testing.T) {
+	content := bunLockbHeader + "\x02\x00\x00\x00" + strings.Repeat("\x00", 32)
+	_, err := ParseBunLockb(content)
+	if !errors.Is(err, ErrBinaryLockfile) {
+		t.Fatalf("expected ErrBinaryLockfile, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "format version 2") {
+		t.Errorf("expected the format version in the error, got %v", err)
+	}
+
+	if _, err := ParseBunLockb(`{"lockfileVersion": 1}`); err == nil || errors.Is(err, ErrBinaryLockfile) {
+		t.Errorf("expected a parse error for a file without the Bun header, got %v", err)
+	}
+}
+
+func TestScanner_ReportsBinaryBunLockb(t *testing.T) {
+	result := NewScanner(vuln.NewVulnDB(), true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"dependencies": {"test-muaddib-pkg": "^1.0.0"}}`},
+		{RepoName: "test-org/test-repo", Path: "bun.lockb", Content: bunLockbHeader + "\x02\x00\x00\x00"},
+		{RepoName: "test-org/test-repo", Path: "bun.lock", Content: testBunLock},
+	})
+
+	if len(result.BinaryLockfiles) != 1 || result.BinaryLockfiles[0] != "bun.lockb" {
+		t.Errorf("expected bun.lockb to be reported as unreadable, got %v", result.BinaryLockfiles)
+	}
+	if result.FilesScanned != 3 || result.TotalPackages == 0 {
+		t.Errorf("expected the other files to be scanned, got %d files and %d packages", result.FilesScanned, result.TotalPackages)
+	}
+}
+
+func TestScanner_DetectsVulnerableBunDependency(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	result := NewScanner(db, false).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "bun.lock", Content: testBunLock},
+	})
+
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].Package.Name != "test-muaddib-vulnerable" {
+		t.Fatalf("expected test-muaddib-vulnerable to be detected, got %+v", result.VulnerablePackages)
+	}
+	if result.VulnerablePackages[0].FilePath != "bun.lock" {
+		t.Errorf("expected finding in bun.lock, got %s", result.VulnerablePackages[0].FilePath)
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": [1, 2,], }`, `{"a": [1, 2] }`},
+		{"{\"a\": \"x,}\", /* c */ \"b\": 1, // trailing\n}", "{\"a\": \"x,}\",  \"b\": 1 \n}"},
+		{`{"url": "https://example.com/a"}`, `{"url": "https://example.com/a"}`},
+		{`["a\"," ,]`, `["a\"," ]`},
+	}
+
+	for _, tt := range tests {
+		if got := stripJSONC(tt.in); got != tt.want {
+			t.Errorf("stripJSONC(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package scanner
+
+// confidenceRank orders confidences so the strongest match of a package is kept
+var confidenceRank = map[Confidence]int{
+	ConfidenceLow:    0,
+	ConfidenceMedium: 1,
+	ConfidenceHigh:   2,
+}
+
+// dedupeVulnerablePackages collapses findings of the same name@version into
+// the first one found, recording every file it was found in. A copy in a test
+// directory must not hide one that is installed, so the highest confidence of
+// the duplicates is kept.
+func dedupeVulnerablePackages(packages []*VulnerablePackage) []*VulnerablePackage {
+	byKey := make(map[string]*VulnerablePackage, len(packages))
+	var deduped []*VulnerablePackage
+	for _, vp := range packages {
+		key := vp.Package.Name + "@" + vp.Package.Version
+		first, seen := byKey[key]
+		if !seen {
+			vp.FilePaths = []string{vp.FilePath}
+			byKey[key] = vp
+			deduped = append(deduped, vp)
+			continue
+		}
+
+		first.FilePaths = append(first.FilePaths, vp.FilePath)
+		if confidenceRank[vp.Confidence] > confidenceRank[first.Confidence] {
+			first.Confidence, first.ConfidenceNote = vp.Confidence, vp.ConfidenceNote
+		}
+		first.Package.IsDev = first.Package.IsDev && vp.Package.IsDev
+	}
+	return deduped
+}
@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestScanFiles_DedupesAcrossFiles(t *testing.T) {
+	db, err := vuln.ParseCSVForTest(strings.NewReader(`package_name,package_versions,sources
+test-muaddib-dup,1.0.0,"test"`))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	lock := `{"lockfileVersion": 3, "packages": {"node_modules/test-muaddib-dup": {"version": "1.0.0"}}}`
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "test/fixtures/package-lock.json", Content: lock},
+		{RepoName: "test-org/test-repo", Path: "apps/web/package-lock.json", Content: lock},
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: lock},
+	}
+
+	if result := NewScanner(db, true).ScanFiles(files); len(result.VulnerablePackages) != 3 {
+		t.Fatalf("expected one finding per file by default, got %d", len(result.VulnerablePackages))
+	}
+
+	result := NewScanner(db, true, WithDedupe(true)).ScanFiles(files)
+	if len(result.VulnerablePackages) != 1 {
+		t.Fatalf("expected one deduplicated finding, got %d", len(result.VulnerablePackages))
+	}
+	vp := result.VulnerablePackages[0]
+	if vp.FilePath != "test/fixtures/package-lock.json" {
+		t.Errorf("expected the first file to be kept as FilePath, got %s", vp.FilePath)
+	}
+	want := []string{"test/fixtures/package-lock.json", "apps/web/package-lock.json", "package-lock.json"}
+	if !slices.Equal(vp.FilePaths, want) {
+		t.Errorf("expected FilePaths %v, got %v", want, vp.FilePaths)
+	}
+	if vp.Confidence != ConfidenceHigh {
+		t.Errorf("expected the installed copies to outrank the test fixture, got %s confidence", vp.Confidence)
+	}
+}
@@ -0,0 +1,23 @@
+package scanner
+
+// jsLanguages are GitHub primary languages of projects expected to have npm manifests
+var jsLanguages = map[string]bool{
+	"JavaScript":   true,
+	"TypeScript":   true,
+	"CoffeeScript": true,
+	"Vue":          true,
+	"Svelte":       true,
+}
+
+// NoPackageFiles checks if a repository was scanned but yielded no package
+// files. Repositories whose files were all excluded by path are not counted.
+func (r *RepoScanResult) NoPackageFiles() bool {
+	return r.Error == nil && r.NotScannable == "" && r.FilesScanned == 0 && r.FilesExcluded == 0
+}
+
+// DiscoveryMissed checks if a repository without package files is a JavaScript
+// project by its primary language, suggesting discovery missed its manifests
+// rather than the repository having no npm dependencies
+func (r *RepoScanResult) DiscoveryMissed() bool {
+	return r.NoPackageFiles() && jsLanguages[r.Language]
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRepoScanResult_NoPackageFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *RepoScanResult
+		wantEmpty  bool
+		wantMissed bool
+	}{
+		{name: "javascript repo without files", result: &RepoScanResult{Language: "TypeScript"}, wantEmpty: true, wantMissed: true},
+		{name: "non-js repo without files", result: &RepoScanResult{Language: "Go"}, wantEmpty: true},
+		{name: "repo without a language", result: &RepoScanResult{}, wantEmpty: true},
+		{name: "repo with files", result: &RepoScanResult{Language: "JavaScript", FilesScanned: 2}},
+		{name: "all files excluded", result: &RepoScanResult{Language: "JavaScript", FilesExcluded: 1}},
+		{name: "not scannable", result: &RepoScanResult{Language: "JavaScript", NotScannable: "empty repository"}},
+		{name: "errored", result: &RepoScanResult{Language: "JavaScript", Error: errors.New("boom")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.NoPackageFiles(); got != tt.wantEmpty {
+				t.Errorf("NoPackageFiles() = %v, want %v", got, tt.wantEmpty)
+			}
+			if got := tt.result.DiscoveryMissed(); got != tt.wantMissed {
+				t.Errorf("DiscoveryMissed() = %v, want %v", got, tt.wantMissed)
+			}
+		})
+	}
+}
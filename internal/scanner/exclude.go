@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// DefaultTestPaths are the globs for test, fixture, and example directories
+// whose vulnerable packages are reported with low confidence
+var DefaultTestPaths = []string{
+	"**/test/**", "**/tests/**", "**/__tests__/**", "**/__mocks__/**", "**/testdata/**",
+	"**/fixtures/**", "**/__fixtures__/**", "**/example/**", "**/examples/**",
+}
+
+// ValidateExcludePatterns checks that each --exclude-paths glob is well formed
+func ValidateExcludePatterns(patterns []string) error {
+	return validatePathGlobs("exclude", patterns)
+}
+
+// ValidateTestPathPatterns checks that each --test-paths glob is well formed
+func ValidateTestPathPatterns(patterns []string) error {
+	return validatePathGlobs("test path", patterns)
+}
+
+// validatePathGlobs checks that each glob's segments are well formed
+func validatePathGlobs(kind string, patterns []string) error {
+	for _, pattern := range patterns {
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", kind, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchPathGlob checks if a slash-separated repository path matches a glob.
+// Each path segment is matched with path.Match, and a "**" segment matches
+// any number of segments, including none, so "**/fixtures/**" matches both
+// "fixtures/package.json" and "test/fixtures/app/yarn.lock".
+func MatchPathGlob(pattern, filePath string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(segments); skip++ {
+				if matchSegments(pattern[1:], segments[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// isExcluded checks if a file path matches any of the scanner's exclude patterns
+func (s *Scanner) isExcluded(filePath string) bool {
+	for _, pattern := range s.excludePaths {
+		if MatchPathGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestPath checks if a file path is under a test, fixture, or example
+// directory matching the scanner's test path patterns
+func (s *Scanner) isTestPath(filePath string) bool {
+	for _, pattern := range s.testPaths {
+		if pattern != "" && MatchPathGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeFiles drops files matching the exclude patterns, returning the kept
+// files and how many were excluded
+func (s *Scanner) excludeFiles(files []*github.PackageFile) ([]*github.PackageFile, int) {
+	if len(s.excludePaths) == 0 {
+		return files, 0
+	}
+
+	kept := make([]*github.PackageFile, 0, len(files))
+	for _, file := range files {
+		if !s.isExcluded(file.Path) {
+			kept = append(kept, file)
+		}
+	}
+	return kept, len(files) - len(kept)
+}
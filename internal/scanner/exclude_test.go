@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**/fixtures/**", "test/fixtures/package-lock.json", true},
+		{"**/fixtures/**", "fixtures/package.json", true},
+		{"**/fixtures/**", "packages/app/test/fixtures/nested/yarn.lock", true},
+		{"**/fixtures/**", "package-lock.json", false},
+		{"**/fixtures/**", "src/fixturesque/package.json", false},
+		{"examples/*/package.json", "examples/basic/package.json", true},
+		{"examples/*/package.json", "examples/basic/nested/package.json", false},
+		{"**/package-lock.json", "package-lock.json", true},
+		{"**/package-lock.json", "a/b/package-lock.json", true},
+		{"package.json", "sub/package.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPathGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPathGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateExcludePatterns(t *testing.T) {
+	if err := ValidateExcludePatterns([]string{"**/fixtures/**", "examples/*"}); err != nil {
+		t.Errorf("expected valid patterns, got %v", err)
+	}
+	if err := ValidateExcludePatterns([]string{"test/[fixtures/**"}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestScanner_ExcludePaths(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	scanner := NewScanner(db, true, WithExcludePaths([]string{"**/fixtures/**"}))
+
+	manifest := `{"dependencies": {"test-muaddib-vulnerable": "1.0.0"}}`
+	result := scanner.ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "test/fixtures/package.json", Content: manifest},
+		{RepoName: "test-org/test-repo", Path: "fixtures/app/package.json", Content: manifest},
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: manifest},
+	})
+
+	if result.FilesExcluded != 2 {
+		t.Errorf("expected 2 excluded files, got %d", result.FilesExcluded)
+	}
+	if result.FilesScanned != 1 {
+		t.Errorf("expected 1 scanned file, got %d", result.FilesScanned)
+	}
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].FilePath != "package.json" {
+		t.Errorf("expected one finding in package.json, got %+v", result.VulnerablePackages)
+	}
+}
+
+func TestScanner_ExcludePathsAllFiles(t *testing.T) {
+	scanner := NewScanner(nil, true, WithExcludePaths([]string{"examples/**"}))
+
+	result := scanner.ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "examples/demo/package.json", Content: `{}`},
+	})
+
+	if result.FilesExcluded != 1 || result.FilesScanned != 0 {
+		t.Errorf("expected 1 excluded and 0 scanned, got %d excluded and %d scanned", result.FilesExcluded, result.FilesScanned)
+	}
+}
+
+func TestScanner_TestPathsLowerConfidence(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	scanner := NewScanner(db, true, WithTestPaths(DefaultTestPaths))
+
+	manifest := `{"dependencies": {"test-muaddib-vulnerable": "1.0.0"}}`
+	result := scanner.ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "examples/demo/package.json", Content: manifest},
+		{RepoName: "test-org/test-repo", Path: "src/app/package.json", Content: manifest},
+	})
+
+	if len(result.VulnerablePackages) != 2 {
+		t.Fatalf("expected both findings to be reported, got %d", len(result.VulnerablePackages))
+	}
+	confidence := make(map[string]Confidence)
+	for _, vp := range result.VulnerablePackages {
+		confidence[vp.FilePath] = vp.Confidence
+	}
+	if confidence["examples/demo/package.json"] != ConfidenceLow {
+		t.Errorf("expected low confidence under examples/, got %s", confidence["examples/demo/package.json"])
+	}
+	if confidence["src/app/package.json"] != ConfidenceHigh {
+		t.Errorf("expected high confidence under src/, got %s", confidence["src/app/package.json"])
+	}
+
+	policy := FailPolicy{FailOn: FailOnAny}
+	if count := policy.Count([]*RepoScanResult{result}, nil); count != 1 {
+		t.Errorf("expected only the src/ finding to qualify, got %d", count)
+	}
+}
+
+func TestScanner_TestPathsDisabled(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	scanner := NewScanner(db, true, WithTestPaths([]string{""}))
+
+	result := scanner.ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "examples/demo/package.json", Content: `{"dependencies": {"test-muaddib-vulnerable": "1.0.0"}}`},
+	})
+
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].Confidence != ConfidenceHigh {
+		t.Errorf("expected a high confidence finding with test paths disabled, got %+v", result.VulnerablePackages)
+	}
+}
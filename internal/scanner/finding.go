@@ -0,0 +1,332 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FindingCategory identifies the kind of issue a finding represents
+type FindingCategory string
+
+const (
+	// CategoryVulnerablePackage is a dependency matching an IOC entry
+	CategoryVulnerablePackage FindingCategory = "vulnerable-package"
+	// CategoryMaliciousWorkflow is a GitHub Actions workflow matching a worm pattern
+	CategoryMaliciousWorkflow FindingCategory = "malicious-workflow"
+	// CategoryMaliciousScript is an npm lifecycle script matching a worm pattern
+	CategoryMaliciousScript FindingCategory = "malicious-script"
+	// CategoryMaliciousBranch is a branch created by the worm
+	CategoryMaliciousBranch FindingCategory = "malicious-branch"
+	// CategoryMaliciousRepo is a migration repository created by the worm
+	CategoryMaliciousRepo FindingCategory = "malicious-repo"
+	// CategoryAdvisory is a heuristic finding that warrants review
+	CategoryAdvisory FindingCategory = "advisory"
+)
+
+// Severity ranks how urgently a finding needs attention
+type Severity string
+
+const (
+	// SeverityCritical findings indicate active compromise
+	SeverityCritical Severity = "critical"
+	// SeverityHigh findings are likely exploitable
+	SeverityHigh Severity = "high"
+	// SeverityMedium findings are real but currently inert
+	SeverityMedium Severity = "medium"
+	// SeverityLow findings warrant review
+	SeverityLow Severity = "low"
+)
+
+// Finding is a flattened, format-agnostic view of a single detected issue
+type Finding struct {
+	ID          string
+	Category    FindingCategory
+	RepoName    string
+	FilePath    string
+	FilePaths   []string // Every file a deduplicated vulnerable package was found in
+	PackageName string
+	Version     string
+	IOCVersion  string
+	IsDev       bool
+	Source      string
+	Detail      string // Branch name, script name, workflow kind and pattern, or how a package matched
+	Known       bool   // Present in the baseline
+	Confidence  Confidence
+	Severity    Severity
+	Evidence    string   // Upstream IOC row that matched, for vulnerable packages
+	Workspaces  []string // Directories of the workspace members that depend on a vulnerable package
+	Line        int      // 1-based line in FilePath, or 0 if unknown
+	URL         string   // Where to view the finding on GitHub; empty for local scans
+	Campaign    string   // Campaign label of the IOC source a vulnerable package matched
+	AdvisoryID  string   // Advisory a vulnerable package matched, for OSV sources
+	IOCSources  []string // Feeds that reported the IOC a vulnerable package matched
+}
+
+// gitHubURL is the base of the links to repositories on GitHub
+const gitHubURL = "https://github.com/"
+
+// FileURL returns the GitHub URL of a file in a repository at ref, anchored to
+// line when it is known
+func FileURL(repoName, ref, filePath string, line int) string {
+	link := gitHubURL + repoName + "/blob/" + escapeURLPath(ref) + "/" + escapeURLPath(filePath)
+	if line > 0 {
+		link += fmt.Sprintf("#L%d", line)
+	}
+	return link
+}
+
+// BranchURL returns the GitHub URL of a branch in a repository
+func BranchURL(repoName, branch string) string {
+	return gitHubURL + repoName + "/tree/" + escapeURLPath(branch)
+}
+
+// RepoURL returns the GitHub URL of a repository
+func RepoURL(repoName string) string {
+	return gitHubURL + repoName
+}
+
+// escapeURLPath escapes each segment of a slash-separated path for a URL
+func escapeURLPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// FindingID returns a stable fingerprint for a finding so it can be matched
+// across runs. It deliberately excludes anything that varies between scans.
+func FindingID(category FindingCategory, repoName, filePath, subject string) string {
+	h := sha256.Sum256([]byte(strings.Join([]string{string(category), repoName, filePath, subject}, "\x00")))
+	return hex.EncodeToString(h[:8])
+}
+
+// ID returns the fingerprint of the vulnerable package finding
+func (vp *VulnerablePackage) ID() string {
+	return FindingID(CategoryVulnerablePackage, vp.RepoName, vp.FilePath, vp.Package.Name+"@"+vp.Package.Version)
+}
+
+// ID returns the fingerprint of the malicious workflow finding
+func (mw *MaliciousWorkflow) ID() string {
+	return FindingID(CategoryMaliciousWorkflow, mw.RepoName, mw.FilePath, mw.Pattern)
+}
+
+// ID returns the fingerprint of the malicious script finding
+func (ms *MaliciousScript) ID() string {
+	return FindingID(CategoryMaliciousScript, ms.RepoName, ms.FilePath, ms.ScriptName+":"+ms.Pattern)
+}
+
+// ID returns the fingerprint of the malicious branch finding
+func (mb *MaliciousBranch) ID() string {
+	return FindingID(CategoryMaliciousBranch, mb.RepoName, "", mb.BranchName)
+}
+
+// ID returns the fingerprint of the advisory
+func (a *Advisory) ID() string {
+	return FindingID(CategoryAdvisory, a.RepoName, a.FilePath, a.Kind+":"+a.Detail)
+}
+
+// ID returns the fingerprint of the possible typosquat
+func (sp *SuspiciousPackage) ID() string {
+	return FindingID(CategoryAdvisory, sp.RepoName, sp.FilePath, AdvisoryTyposquatDependency+":"+sp.Package.Name)
+}
+
+// ID returns the fingerprint of the malicious repository finding. The
+// description is left out so rewording it does not change the fingerprint.
+func (mr *MaliciousRepo) ID() string {
+	return FindingID(CategoryMaliciousRepo, mr.RepoName, "", "")
+}
+
+// Severity ranks the workflow by whether Actions can run it. Workflows in
+// repositories with Actions disabled cannot execute and are downgraded.
+func (mw *MaliciousWorkflow) Severity() Severity {
+	if mw.Kind == WorkflowKindRemoteCodeExecution {
+		return SeverityLow
+	}
+	if mw.ActionsEnabled == nil {
+		return SeverityHigh
+	}
+	if *mw.ActionsEnabled {
+		return SeverityCritical
+	}
+	return SeverityMedium
+}
+
+// Confidence reports how likely the workflow is malicious: generic patterns
+// that legitimate workflows also use are low confidence
+func (mw *MaliciousWorkflow) Confidence() Confidence {
+	if mw.Kind == WorkflowKindRemoteCodeExecution {
+		return ConfidenceLow
+	}
+	return ConfidenceHigh
+}
+
+// SetActionsEnabled records whether Actions is enabled on each workflow's repository
+func SetActionsEnabled(workflows []*MaliciousWorkflow, enabled bool) {
+	for _, mw := range workflows {
+		mw.ActionsEnabled = &enabled
+	}
+}
+
+// vulnerablePackageSeverity ranks a vulnerable package by match confidence
+func vulnerablePackageSeverity(vp *VulnerablePackage) Severity {
+	if vp.Confidence == ConfidenceLow {
+		return SeverityLow
+	}
+	return SeverityHigh
+}
+
+// Findings flattens all issues in the result into a single list
+func (r *RepoScanResult) Findings() []*Finding {
+	var findings []*Finding
+
+	for _, vp := range r.VulnerablePackages {
+		findings = append(findings, &Finding{
+			ID:          vp.ID(),
+			Category:    CategoryVulnerablePackage,
+			RepoName:    vp.RepoName,
+			FilePath:    vp.FilePath,
+			FilePaths:   vp.FilePaths,
+			PackageName: vp.Package.Name,
+			Version:     vp.Package.Version,
+			IOCVersion:  vp.VulnEntry.PackageVersion,
+			IsDev:       vp.Package.IsDev,
+			Source:      vp.Package.Source,
+			Detail:      vp.MatchedBy,
+			Known:       vp.Known,
+			Confidence:  vp.Confidence,
+			Severity:    vulnerablePackageSeverity(vp),
+			Evidence:    vp.VulnEntry.Evidence(),
+			Workspaces:  workspacePaths(vp.Workspaces),
+			Campaign:    vp.VulnEntry.Campaign,
+			AdvisoryID:  vp.VulnEntry.AdvisoryID,
+			IOCSources:  vp.VulnEntry.Sources,
+		})
+	}
+	for _, mw := range r.MaliciousWorkflows {
+		findings = append(findings, &Finding{
+			ID:         mw.ID(),
+			Category:   CategoryMaliciousWorkflow,
+			RepoName:   mw.RepoName,
+			FilePath:   mw.FilePath,
+			Detail:     mw.Kind + ": " + mw.Pattern,
+			Known:      mw.Known,
+			Confidence: mw.Confidence(),
+			Severity:   mw.Severity(),
+		})
+	}
+	for _, ms := range r.MaliciousScripts {
+		findings = append(findings, &Finding{
+			ID:         ms.ID(),
+			Category:   CategoryMaliciousScript,
+			RepoName:   ms.RepoName,
+			FilePath:   ms.FilePath,
+			Detail:     ms.ScriptName + ": " + ms.Command,
+			Known:      ms.Known,
+			Confidence: ConfidenceHigh,
+			Severity:   SeverityCritical,
+		})
+	}
+	for _, mb := range r.MaliciousBranches {
+		findings = append(findings, &Finding{
+			ID:         mb.ID(),
+			Category:   CategoryMaliciousBranch,
+			RepoName:   mb.RepoName,
+			Detail:     mb.BranchName,
+			Known:      mb.Known,
+			Confidence: ConfidenceHigh,
+			Severity:   SeverityHigh,
+		})
+	}
+	for _, a := range r.Advisories {
+		findings = append(findings, &Finding{
+			ID:         a.ID(),
+			Category:   CategoryAdvisory,
+			RepoName:   a.RepoName,
+			FilePath:   a.FilePath,
+			Detail:     a.Kind + ": " + a.Detail,
+			Known:      a.Known,
+			Confidence: ConfidenceLow,
+			Severity:   SeverityLow,
+		})
+	}
+	for _, sp := range r.SuspiciousPackages {
+		findings = append(findings, &Finding{
+			ID:          sp.ID(),
+			Category:    CategoryAdvisory,
+			RepoName:    sp.RepoName,
+			FilePath:    sp.FilePath,
+			PackageName: sp.Package.Name,
+			Version:     sp.Package.Version,
+			IsDev:       sp.Package.IsDev,
+			Source:      sp.Package.Source,
+			Detail:      AdvisoryTyposquatDependency + ": " + sp.Detail(),
+			Known:       sp.Known,
+			Confidence:  ConfidenceLow,
+			Severity:    SeverityLow,
+		})
+	}
+
+	for _, sc := range r.SuspiciousConfigs {
+		findings = append(findings, &Finding{
+			ID:         sc.ID(),
+			Category:   CategoryAdvisory,
+			RepoName:   sc.RepoName,
+			FilePath:   sc.FilePath,
+			Detail:     AdvisorySuspiciousNpmrc + ": " + sc.Reason + ": " + sc.Content,
+			Known:      sc.Known,
+			Confidence: ConfidenceMedium,
+			Severity:   SeverityMedium,
+			Line:       sc.Line,
+		})
+	}
+
+	r.linkFindings(findings)
+	return findings
+}
+
+// linkFindings sets the GitHub URL of each finding. Results scanned from a
+// local directory have no Ref and are not linked.
+func (r *RepoScanResult) linkFindings(findings []*Finding) {
+	if r.Ref == "" {
+		return
+	}
+	for _, f := range findings {
+		switch {
+		case f.Category == CategoryMaliciousBranch:
+			f.URL = BranchURL(f.RepoName, f.Detail)
+		case f.FilePath != "":
+			f.URL = FileURL(f.RepoName, r.Ref, f.FilePath, f.Line)
+		}
+	}
+}
+
+// workspacePaths returns the directories of workspace members
+func workspacePaths(members []WorkspaceMember) []string {
+	var paths []string
+	for _, member := range members {
+		paths = append(paths, member.Path)
+	}
+	return paths
+}
+
+// Findings flattens the org-level issues into a single list
+func (o *OrgScanResult) Findings() []*Finding {
+	var findings []*Finding
+	for _, mr := range o.MaliciousRepos {
+		findings = append(findings, &Finding{
+			ID:         mr.ID(),
+			Category:   CategoryMaliciousRepo,
+			RepoName:   mr.RepoName,
+			Detail:     mr.Description,
+			Known:      mr.Known,
+			Confidence: ConfidenceHigh,
+			Severity:   SeverityCritical,
+			URL:        RepoURL(mr.RepoName),
+		})
+	}
+	return findings
+}
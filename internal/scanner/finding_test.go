@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestFileURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		filePath string
+		line     int
+		expected string
+	}{
+		{"with line", "main", "packages/web/package-lock.json", 42, "https://github.com/test-org/test-repo/blob/main/packages/web/package-lock.json#L42"},
+		{"without line", "main", "package.json", 0, "https://github.com/test-org/test-repo/blob/main/package.json"},
+		{"escaped path", "feature/x", "apps/my app/package.json", 3, "https://github.com/test-org/test-repo/blob/feature/x/apps/my%20app/package.json#L3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileURL("test-org/test-repo", tt.ref, tt.filePath, tt.line); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRepoScanResult_FindingsLinkToGitHub(t *testing.T) {
+	result := &RepoScanResult{
+		RepoName: "test-org/test-repo",
+		Ref:      "main",
+		MaliciousScripts: []*MaliciousScript{
+			{RepoName: "test-org/test-repo", FilePath: "package.json", ScriptName: "postinstall", Command: "node bundle.js"},
+		},
+		MaliciousBranches: []*MaliciousBranch{{RepoName: "test-org/test-repo", BranchName: "shai-hulud"}},
+	}
+
+	urls := make(map[FindingCategory]string)
+	for _, f := range result.Findings() {
+		urls[f.Category] = f.URL
+	}
+	if urls[CategoryMaliciousScript] != "https://github.com/test-org/test-repo/blob/main/package.json" {
+		t.Errorf("unexpected script URL %q", urls[CategoryMaliciousScript])
+	}
+	if urls[CategoryMaliciousBranch] != "https://github.com/test-org/test-repo/tree/shai-hulud" {
+		t.Errorf("unexpected branch URL %q", urls[CategoryMaliciousBranch])
+	}
+
+	// Local scans have no ref to link to
+	result.Ref = ""
+	for _, f := range result.Findings() {
+		if f.URL != "" {
+			t.Errorf("expected no URL for a local finding, got %q", f.URL)
+		}
+	}
+}
+
+func TestRepoScanResult_WorkflowFindingsCarryKind(t *testing.T) {
+	result := &RepoScanResult{
+		RepoName: "test-org/test-repo",
+		MaliciousWorkflows: []*MaliciousWorkflow{
+			{RepoName: "test-org/test-repo", FilePath: ".github/workflows/sync.yml", Pattern: "schedule + payload: toJSON(secrets)", Kind: WorkflowKindPersistence},
+		},
+	}
+
+	findings := result.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if want := "PersistenceWorkflow: schedule + payload: toJSON(secrets)"; findings[0].Detail != want {
+		t.Errorf("expected detail %q, got %q", want, findings[0].Detail)
+	}
+}
+
+func TestScanFiles_FindingsCarryIOCCampaign(t *testing.T) {
+	db := vuln.NewVulnDB()
+	db.Add(&vuln.VulnEntry{PackageName: "test-muaddib-pkg", PackageVersion: "1.0.0", Campaign: "test-campaign"})
+
+	result := NewScanner(db, true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-repo", Path: "package.json", Content: `{"dependencies": {"test-muaddib-pkg": "1.0.0"}}`},
+	})
+
+	findings := result.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Campaign != "test-campaign" {
+		t.Errorf("expected the finding to carry campaign test-campaign, got %q", findings[0].Campaign)
+	}
+}
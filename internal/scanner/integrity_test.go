@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestScanner_MatchesKnownMaliciousIntegrity(t *testing.T) {
+	csvData := `package_name,package_versions,integrity
+test-muaddib-vulnerable,1.0.0,sha512-known-bad`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	scanner := NewScanner(db, true)
+
+	tests := []struct {
+		name    string
+		path    string
+		content func(integrity string) string
+	}{
+		{
+			name: "npm v3 lockfile",
+			path: "package-lock.json",
+			content: func(integrity string) string {
+				return `{"lockfileVersion": 3, "packages": {
+					"": {"name": "app"},
+					"node_modules/test-muaddib-vulnerable": {"version": "1.0.1", "integrity": "` + integrity + `"}
+				}}`
+			},
+		},
+		{
+			name: "npm v1 lockfile",
+			path: "package-lock.json",
+			content: func(integrity string) string {
+				return `{"lockfileVersion": 1, "dependencies": {
+					"test-muaddib-vulnerable": {"version": "1.0.1", "integrity": "` + integrity + `"}
+				}}`
+			},
+		},
+		{
+			name: "pnpm lockfile",
+			path: "pnpm-lock.yaml",
+			content: func(integrity string) string {
+				return "lockfileVersion: '6.0'\n\npackages:\n  /test-muaddib-vulnerable@1.0.1:\n    resolution: {integrity: " + integrity + "}\n"
+			},
+		},
+		{
+			name: "yarn lockfile",
+			path: "yarn.lock",
+			content: func(integrity string) string {
+				return "test-muaddib-vulnerable@^1.0.0:\n  version \"1.0.1\"\n  integrity " + integrity + "\n"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" match", func(t *testing.T) {
+			result := scanner.ScanFiles([]*github.PackageFile{
+				{RepoName: "test-org/test-repo", Path: tt.path, Content: tt.content("sha512-known-bad")},
+			})
+
+			if len(result.VulnerablePackages) != 1 {
+				t.Fatalf("expected 1 vulnerable package, got %d", len(result.VulnerablePackages))
+			}
+			vp := result.VulnerablePackages[0]
+			if vp.MatchedBy != KnownMaliciousIntegrity {
+				t.Errorf("expected match by %s, got %q", KnownMaliciousIntegrity, vp.MatchedBy)
+			}
+			if vp.Package.Version != "1.0.1" || vp.VulnEntry.PackageVersion != "1.0.0" {
+				t.Errorf("expected altered 1.0.1 to match IOC 1.0.0, got %s and %s", vp.Package.Version, vp.VulnEntry.PackageVersion)
+			}
+			if vp.Confidence != ConfidenceHigh {
+				t.Errorf("expected high confidence, got %s", vp.Confidence)
+			}
+		})
+
+		t.Run(tt.name+" mismatch", func(t *testing.T) {
+			result := scanner.ScanFiles([]*github.PackageFile{
+				{RepoName: "test-org/test-repo", Path: tt.path, Content: tt.content("sha512-something-else")},
+			})
+
+			if len(result.VulnerablePackages) != 0 {
+				t.Errorf("expected no vulnerable packages, got %d", len(result.VulnerablePackages))
+			}
+		})
+	}
+}
+
+func TestScanner_VersionMatchTakesPrecedenceOverIntegrity(t *testing.T) {
+	csvData := `package_name,package_versions,integrity
+test-muaddib-vulnerable,1.0.0,sha512-known-bad`
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	result := NewScanner(db, true).ScanFiles([]*github.PackageFile{{
+		RepoName: "test-org/test-repo",
+		Path:     "package-lock.json",
+		Content: `{"lockfileVersion": 3, "packages": {
+			"node_modules/test-muaddib-vulnerable": {"version": "1.0.0", "integrity": "sha512-known-bad"}
+		}}`,
+	}})
+
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].MatchedBy != "" {
+		t.Errorf("expected a single name and version match, got %+v", result.VulnerablePackages)
+	}
+}
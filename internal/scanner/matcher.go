@@ -1,27 +1,59 @@
 package scanner
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"path"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/rslater/muaddib/internal/github"
 	"github.com/rslater/muaddib/internal/vuln"
 )
 
+// Confidence describes how likely a finding reflects an installed package
+type Confidence string
+
+const (
+	// ConfidenceHigh is the default for exact IOC matches on installed packages
+	ConfidenceHigh Confidence = "high"
+	// ConfidenceMedium marks matches against an IOC that flags a whole scope
+	ConfidenceMedium Confidence = "medium"
+	// ConfidenceLow marks matches that may not reflect an installed package
+	ConfidenceLow Confidence = "low"
+)
+
 // VulnerablePackage represents a package found to be vulnerable
 type VulnerablePackage struct {
-	Package   *Package
-	VulnEntry *vuln.VulnEntry
-	FilePath  string
-	RepoName  string
+	Package        *Package
+	VulnEntry      *vuln.VulnEntry
+	FilePath       string
+	RepoName       string
+	Known          bool              // Present in the baseline
+	Confidence     Confidence        // Low-confidence findings do not fail the scan by default
+	ConfidenceNote string            // Why confidence was lowered
+	MatchedBy      string            // KnownMaliciousIntegrity for tarball hash matches; empty for name and version
+	Workspaces     []WorkspaceMember // Workspace members that depend on the package directly
+	FilePaths      []string          // Every file the package was found in, with WithDedupe; FilePath is the first
 }
 
+// KnownMaliciousIntegrity marks a package matched by its lockfile integrity
+// hash rather than its name and version, which the attacker may have altered
+const KnownMaliciousIntegrity = "KnownMaliciousIntegrity"
+
 // MaliciousWorkflow represents a detected malicious GitHub Actions workflow
 type MaliciousWorkflow struct {
-	FilePath string
-	RepoName string
-	Pattern  string // The malicious pattern detected
+	FilePath       string
+	RepoName       string
+	Pattern        string   // The malicious pattern detected
+	Kind           string   // WorkflowKindWormPattern, WorkflowKindRemoteCodeExecution, or WorkflowKindPersistence
+	Schedules      []string // Cron expressions, for persistence workflows
+	Known          bool     // Present in the baseline
+	ActionsEnabled *bool    // Whether Actions can run the workflow; nil if unknown
 }
 
 // MaliciousScript represents a detected malicious script in package.json
@@ -31,71 +63,209 @@ type MaliciousScript struct {
 	ScriptName string // e.g., "postinstall"
 	Command    string // The actual command
 	Pattern    string // The pattern that matched
+	Kind       string // ScriptKindWormPattern or ScriptKindRemoteCodeExecution
+	Known      bool   // Present in the baseline
 }
 
+const (
+	// ScriptKindWormPattern is a lifecycle script matching a known worm pattern
+	ScriptKindWormPattern = "WormPattern"
+	// ScriptKindRemoteCodeExecution is a lifecycle script that downloads and executes remote code
+	ScriptKindRemoteCodeExecution = "RemoteCodeExecutionScript"
+)
+
 // MaliciousRepo represents a detected malicious repository (migration repo)
 type MaliciousRepo struct {
 	RepoName    string
 	Description string
+	Known       bool // Present in the baseline
 }
 
 // MaliciousBranch represents a detected malicious branch
 type MaliciousBranch struct {
 	RepoName   string
 	BranchName string
+	Known      bool // Present in the baseline
+
+	// Populated when the branch is inspected against the default branch
+	ChangedFiles []string
+	PayloadFiles []string
+	Authors      []string // Logins that authored or committed the branch's commits
+	Inspection   *RepoScanResult
 }
 
 // RepoScanResult represents the scan results for a single repository
 type RepoScanResult struct {
 	RepoName           string
+	Owner              string // Organization or user that owns the repository
+	Language           string // Primary language detected by GitHub, if any
+	Ref                string // Branch the files were read from on GitHub; empty for local scans
 	TotalPackages      int
 	VulnerablePackages []*VulnerablePackage
 	MaliciousWorkflows []*MaliciousWorkflow
 	MaliciousScripts   []*MaliciousScript
 	MaliciousBranches  []*MaliciousBranch
+	Advisories         []*Advisory
+	SuspiciousPackages []*SuspiciousPackage // Possible typosquats, only with WithTyposquatDetection
+	SuspiciousConfigs  []*SuspiciousConfig  // Suspicious .npmrc lines, from CheckNpmrcFiles
+	VersionSprawl      []*VersionSprawl     // Informational, only with WithVersionSprawl
 	FilesScanned       int
+	FilesExcluded      int                       // Package files skipped by WithExcludePaths
+	WorkspaceMembers   []string                  // package.json files of npm or Yarn workspace members
+	BinaryLockfiles    []string                  // Lockfiles that were found but not decoded, such as bun.lockb
+	UnverifiableDeps   []*UnverifiableDependency // package.json git and URL dependencies, not checked against the IOC list
+	KnownFindings      int                       // Findings matched by the baseline
+	NotScannable       string                    // Why the repository was skipped (e.g., disabled or empty); not an error
 	Error              error
 }
 
 // OrgScanResult represents additional scan results at the org/user level
 type OrgScanResult struct {
 	MaliciousRepos []*MaliciousRepo
+	KnownFindings  int // Findings matched by the baseline
 }
 
 // Scanner scans repositories for vulnerable packages
 type Scanner struct {
-	db         *vuln.VulnDB
-	includeDev bool
+	db                *vuln.VulnDB
+	includeDev        bool
+	skipOptional      bool
+	deepInspect       bool
+	detectTyposquat   bool
+	dedupe            bool
+	sprawlThreshold   int
+	excludePaths      []string
+	testPaths         []string
+	scriptPatterns    []string
+	scriptRegexps     []ScriptPattern
+	workflowPatterns  []WorkflowPattern
+	npmrcAllowedHosts []string
+}
+
+// ScannerOption configures the Scanner
+type ScannerOption func(*Scanner)
+
+// WithDeepInspect enables heuristic checks that produce advisories
+func WithDeepInspect(enabled bool) ScannerOption {
+	return func(s *Scanner) {
+		s.deepInspect = enabled
+	}
+}
+
+// WithTyposquatDetection reports dependencies named like popular packages
+func WithTyposquatDetection(enabled bool) ScannerOption {
+	return func(s *Scanner) {
+		s.detectTyposquat = enabled
+	}
+}
+
+// WithDedupe collapses findings of the same package version in several files
+// of a repository into one, listing every file in FilePaths
+func WithDedupe(enabled bool) ScannerOption {
+	return func(s *Scanner) {
+		s.dedupe = enabled
+	}
+}
+
+// WithSkipOptional skips optional dependencies, which may not be installed
+func WithSkipOptional(enabled bool) ScannerOption {
+	return func(s *Scanner) {
+		s.skipOptional = enabled
+	}
+}
+
+// WithVersionSprawl reports packages resolving to more than threshold distinct
+// versions. A threshold of zero disables the check.
+func WithVersionSprawl(threshold int) ScannerOption {
+	return func(s *Scanner) {
+		s.sprawlThreshold = threshold
+	}
+}
+
+// WithExcludePaths skips package files whose repository path matches any of
+// the globs (see MatchPathGlob), such as vendored or fixture lockfiles
+func WithExcludePaths(patterns []string) ScannerOption {
+	return func(s *Scanner) {
+		s.excludePaths = patterns
+	}
+}
+
+// WithTestPaths reports vulnerable packages in files whose repository path
+// matches any of the globs, such as test fixtures and examples, with low
+// confidence, so they are still listed but do not fail the scan by default
+func WithTestPaths(patterns []string) ScannerOption {
+	return func(s *Scanner) {
+		s.testPaths = patterns
+	}
+}
+
+// WithScriptPatterns adds patterns to look for in lifecycle scripts, such as
+// the payload filenames of new worm variants. The built-in
+// MaliciousScriptPatterns are always checked.
+func WithScriptPatterns(patterns []string) ScannerOption {
+	return func(s *Scanner) {
+		for _, pattern := range patterns {
+			if pattern != "" && !slices.Contains(s.scriptPatterns, pattern) {
+				s.scriptPatterns = append(s.scriptPatterns, pattern)
+			}
+		}
+	}
+}
+
+// WithScriptRegexps adds named regular expressions to match against lifecycle
+// scripts, reported as remote code execution. The DefaultScriptPatterns are
+// always checked.
+func WithScriptRegexps(patterns []ScriptPattern) ScannerOption {
+	return func(s *Scanner) {
+		s.scriptRegexps = append(s.scriptRegexps, patterns...)
+	}
+}
+
+// WithWorkflowPatterns adds named regular expressions to match against
+// workflow content. The DefaultWorkflowPatterns are always checked.
+func WithWorkflowPatterns(patterns []WorkflowPattern) ScannerOption {
+	return func(s *Scanner) {
+		s.workflowPatterns = append(s.workflowPatterns, patterns...)
+	}
 }
 
 // NewScanner creates a new scanner with the given vulnerability database
-func NewScanner(db *vuln.VulnDB, includeDev bool) *Scanner {
-	return &Scanner{
-		db:         db,
-		includeDev: includeDev,
+func NewScanner(db *vuln.VulnDB, includeDev bool, opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		db:               db,
+		includeDev:       includeDev,
+		scriptPatterns:   slices.Clone(MaliciousScriptPatterns),
+		scriptRegexps:    slices.Clone(DefaultScriptPatterns),
+		workflowPatterns: slices.Clone(DefaultWorkflowPatterns),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // ScanFiles scans a list of package files for vulnerable packages
 func (s *Scanner) ScanFiles(files []*github.PackageFile) *RepoScanResult {
+	files, excluded := s.excludeFiles(files)
 	if len(files) == 0 {
-		return &RepoScanResult{}
+		return &RepoScanResult{FilesExcluded: excluded}
 	}
 
 	result := &RepoScanResult{
-		RepoName:     files[0].RepoName,
-		FilesScanned: len(files),
+		RepoName:      files[0].RepoName,
+		FilesScanned:  len(files),
+		FilesExcluded: excluded,
 	}
 
 	seen := make(map[string]bool)
+	squatted := make(map[string]bool)
 
-	for _, file := range files {
-		packages, err := s.parseFile(file)
-		if err != nil {
-			// Continue scanning other files even if one fails
-			continue
-		}
-
+	parsed, binaryLockfiles := s.parseFiles(files)
+	result.BinaryLockfiles = binaryLockfiles
+	for i, packages := range parsed {
+		file := files[i]
 		for _, pkg := range packages {
 			// Track unique packages
 			key := pkg.Name + "@" + pkg.Version
@@ -104,14 +274,20 @@ func (s *Scanner) ScanFiles(files []*github.PackageFile) *RepoScanResult {
 				result.TotalPackages++
 			}
 
-			// Check for vulnerability
-			if vulnEntry := s.db.Check(pkg.Name, pkg.Version); vulnEntry != nil {
-				result.VulnerablePackages = append(result.VulnerablePackages, &VulnerablePackage{
-					Package:   pkg,
-					VulnEntry: vulnEntry,
-					FilePath:  file.Path,
-					RepoName:  file.RepoName,
-				})
+			if !pkg.FromRegistry() {
+				// Only registry versions can match the IOC list
+				if pkg.Unverifiable() {
+					result.UnverifiableDeps = append(result.UnverifiableDeps, &UnverifiableDependency{
+						Name: pkg.Name, Spec: pkg.Version, SpecType: pkg.SpecType, FilePath: file.Path,
+					})
+				}
+				continue
+			}
+			if vp := s.checkPackage(pkg, file); vp != nil {
+				result.VulnerablePackages = append(result.VulnerablePackages, vp)
+			}
+			if sp := s.checkTyposquat(pkg, file.Path, file.RepoName, squatted); sp != nil {
+				result.SuspiciousPackages = append(result.SuspiciousPackages, sp)
 			}
 		}
 	}
@@ -119,11 +295,110 @@ func (s *Scanner) ScanFiles(files []*github.PackageFile) *RepoScanResult {
 	// Check for malicious scripts in package.json files
 	result.MaliciousScripts = s.CheckPackageScripts(files)
 
+	if s.deepInspect {
+		result.Advisories = append(result.Advisories, s.CheckFilesField(files)...)
+		result.Advisories = append(result.Advisories, s.CheckPackageNameSquat(files)...)
+	}
+
+	result.VersionSprawl = s.checkVersionSprawl(files, parsed)
+	result.WorkspaceMembers = FindWorkspaceMembers(files)
+	attributeWorkspaces(result.VulnerablePackages, files, result.WorkspaceMembers)
+	if s.dedupe {
+		result.VulnerablePackages = dedupeVulnerablePackages(result.VulnerablePackages)
+	}
+
 	return result
 }
 
-// parseFile parses a package file and returns the list of packages
-func (s *Scanner) parseFile(file *github.PackageFile) ([]*Package, error) {
+// parseFiles parses each file, skipping files that fail to parse, and returns
+// the paths of binary lockfiles that could not be decoded. A lockfile records
+// what is installed, so package.json ranges are dropped for packages a
+// lockfile in the same directory resolves, and their resolved versions are
+// checked instead.
+func (s *Scanner) parseFiles(files []*github.PackageFile) ([][]*Package, []string) {
+	parsed := make([][]*Package, len(files))
+	resolved := make(map[string]map[string]bool) // directory -> resolved package names
+	var binaryLockfiles []string
+	for i, file := range files {
+		packages, err := s.ParseFile(file)
+		if errors.Is(err, ErrBinaryLockfile) {
+			binaryLockfiles = append(binaryLockfiles, file.Path)
+		}
+		if err != nil {
+			// Continue scanning other files even if one fails
+			continue
+		}
+		parsed[i] = packages
+
+		// Override pins say what should be installed, not what is
+		dir := path.Dir(file.Path)
+		for _, pkg := range packages {
+			if pkg.Declared || pkg.Source == "override" {
+				continue
+			}
+			if resolved[dir] == nil {
+				resolved[dir] = make(map[string]bool)
+			}
+			resolved[dir][pkg.Name] = true
+		}
+	}
+
+	for i, packages := range parsed {
+		dirResolved := resolved[path.Dir(files[i].Path)]
+		parsed[i] = slices.DeleteFunc(packages, func(pkg *Package) bool { return pkg.Declared && dirResolved[pkg.Name] })
+	}
+	return parsed, binaryLockfiles
+}
+
+// checkPackage checks a package against the IOC database by name and version,
+// then by the integrity hash of its tarball
+func (s *Scanner) checkPackage(pkg *Package, file *github.PackageFile) *VulnerablePackage {
+	vp := &VulnerablePackage{
+		Package:    pkg,
+		FilePath:   file.Path,
+		RepoName:   file.RepoName,
+		Confidence: ConfidenceHigh,
+	}
+
+	vp.VulnEntry = s.db.Check(pkg.Name, pkg.Version)
+	if vp.VulnEntry == nil && pkg.Integrity != "" {
+		vp.VulnEntry = s.db.CheckIntegrity(pkg.Integrity)
+		vp.MatchedBy = KnownMaliciousIntegrity
+	}
+	if vp.VulnEntry == nil {
+		return nil
+	}
+
+	switch {
+	case vp.MatchedBy == KnownMaliciousIntegrity:
+		// The tarball is known-bad whatever the lockfile claims it is
+	case pkg.OptionalPeer:
+		vp.Confidence = ConfidenceLow
+		vp.ConfidenceNote = "optional peer dependency, may not be installed"
+	case vp.VulnEntry.ScopeWide:
+		vp.Confidence = ConfidenceMedium
+		vp.ConfidenceNote = "scope-level IOC " + vp.VulnEntry.PackageName + ", version not confirmed"
+	}
+	if s.isTestPath(file.Path) {
+		vp.Confidence = ConfidenceLow
+		vp.ConfidenceNote = "in a test or example directory"
+	}
+	return vp
+}
+
+// ParseFile parses a package file and returns the list of packages, without
+// optional dependencies when they are skipped. ScanFiles skips files that
+// fail to parse; callers checking a single file use this to report them.
+func (s *Scanner) ParseFile(file *github.PackageFile) ([]*Package, error) {
+	packages, err := s.parsePackages(file)
+	if err != nil || !s.skipOptional {
+		return packages, err
+	}
+	return slices.DeleteFunc(packages, func(pkg *Package) bool { return pkg.IsOptional }), nil
+}
+
+// parsePackages parses a package file with the parser for its filename
+func (s *Scanner) parsePackages(file *github.PackageFile) ([]*Package, error) {
 	filename := path.Base(file.Path)
 
 	switch filename {
@@ -135,22 +410,79 @@ func (s *Scanner) parseFile(file *github.PackageFile) ([]*Package, error) {
 		return ParseYarnLock(file.Content, s.includeDev)
 	case "pnpm-lock.yaml":
 		return ParsePnpmLock(file.Content, s.includeDev)
+	case "bun.lock":
+		return ParseBunLock(file.Content, s.includeDev)
+	case "bun.lockb":
+		return ParseBunLockb(file.Content)
 	default:
-		return nil, nil
+		return nil, fmt.Errorf("unsupported package file %q", filename)
 	}
 }
 
 // MaliciousWorkflowPattern is the pattern that indicates the Shai-Hulud worm in workflow files
 const MaliciousWorkflowPattern = `echo ${{ github.event.discussion.body }}`
 
+// WorkflowPattern is a named regular expression that indicates a malicious step
+// in a workflow file. The name is reported as the pattern that matched.
+type WorkflowPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	Kind   string // Kind of the findings it reports; WorkflowKindWormPattern if empty
+}
+
+// DefaultWorkflowPatterns are the workflow patterns every scanner checks. The
+// discussion body echo keeps MaliciousWorkflowPattern as its name, so findings
+// keep their IDs, but also matches it with different spacing and quoting.
+var DefaultWorkflowPatterns = []WorkflowPattern{
+	{
+		Name:   MaliciousWorkflowPattern,
+		Regexp: regexp.MustCompile(`echo\s+["']?\$\{\{\s*github\.event\.discussion\.body\s*\}\}`),
+	},
+	{
+		Name:   "remote script piped to a shell",
+		Regexp: regexp.MustCompile(`(?i)\b(curl|wget)\b[^\n|]*\|\s*(sudo\s+)?(ba|z)?sh\b`),
+		Kind:   WorkflowKindRemoteCodeExecution,
+	},
+	{
+		Name:   "base64-decoded payload piped to a shell",
+		Regexp: regexp.MustCompile(`(?i)\bbase64\s+(-d|--decode)\b[^\n|]*\|\s*(sudo\s+)?(ba|z)?sh\b`),
+		Kind:   WorkflowKindRemoteCodeExecution,
+	},
+}
+
 // MaliciousScriptPatterns are patterns that indicate the Shai-Hulud worm in package.json scripts
 // These are checked against lifecycle scripts like postinstall, preinstall, etc.
+// WithScriptPatterns adds to them.
 var MaliciousScriptPatterns = []string{
 	"node bundle.js",
 	"setup_bun.js",
 	"bun_environment.js",
 }
 
+// ReadScriptPatterns reads lifecycle script patterns from a file, one per
+// line. Blank lines and lines starting with "#" are skipped.
+func ReadScriptPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script patterns file: %w", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script patterns file: %w", err)
+	}
+	return patterns, nil
+}
+
 // LifecycleScripts are npm scripts that run automatically and are commonly abused
 var LifecycleScripts = []string{
 	"preinstall",
@@ -170,11 +502,19 @@ func (s *Scanner) CheckWorkflows(workflows []*github.WorkflowFile) []*MaliciousW
 	var malicious []*MaliciousWorkflow
 
 	for _, wf := range workflows {
-		if strings.Contains(wf.Content, MaliciousWorkflowPattern) {
+		for _, pattern := range s.workflowPatterns {
+			if !pattern.Regexp.MatchString(wf.Content) {
+				continue
+			}
+			kind := pattern.Kind
+			if kind == "" {
+				kind = WorkflowKindWormPattern
+			}
 			malicious = append(malicious, &MaliciousWorkflow{
 				FilePath: wf.Path,
 				RepoName: wf.RepoName,
-				Pattern:  MaliciousWorkflowPattern,
+				Pattern:  pattern.Name,
+				Kind:     kind,
 			})
 		}
 	}
@@ -203,24 +543,91 @@ func (s *Scanner) CheckPackageScripts(files []*github.PackageFile) []*MaliciousS
 			if !exists {
 				continue
 			}
+			malicious = append(malicious, s.checkLifecycleScript(file, scriptName, command)...)
+		}
+	}
 
-			for _, pattern := range MaliciousScriptPatterns {
-				if strings.Contains(command, pattern) {
-					malicious = append(malicious, &MaliciousScript{
-						FilePath:   file.Path,
-						RepoName:   file.RepoName,
-						ScriptName: scriptName,
-						Command:    command,
-						Pattern:    pattern,
-					})
-				}
-			}
+	return malicious
+}
+
+// checkLifecycleScript checks a single lifecycle script for worm patterns and
+// for download-and-execute droppers
+func (s *Scanner) checkLifecycleScript(file *github.PackageFile, scriptName, command string) []*MaliciousScript {
+	var malicious []*MaliciousScript
+	newScript := func(pattern, kind string) *MaliciousScript {
+		return &MaliciousScript{
+			FilePath:   file.Path,
+			RepoName:   file.RepoName,
+			ScriptName: scriptName,
+			Command:    command,
+			Pattern:    pattern,
+			Kind:       kind,
+		}
+	}
+
+	for _, pattern := range s.scriptPatterns {
+		if strings.Contains(command, pattern) {
+			malicious = append(malicious, newScript(pattern, ScriptKindWormPattern))
 		}
 	}
 
+	if pattern, ok := matchScriptPattern(s.scriptRegexps, command); ok {
+		malicious = append(malicious, newScript(pattern, ScriptKindRemoteCodeExecution))
+	}
+
 	return malicious
 }
 
+// matchScriptPattern returns the name of the first pattern matching a command.
+// A dropper often matches several patterns; only the first is reported.
+func matchScriptPattern(patterns []ScriptPattern, command string) (string, bool) {
+	for _, pattern := range patterns {
+		if pattern.Regexp.MatchString(command) {
+			return pattern.Name, true
+		}
+	}
+	return "", false
+}
+
+// ScriptPattern is a named regular expression that indicates a lifecycle
+// script downloads or decodes code and runs it. The name is reported as the
+// pattern that matched.
+type ScriptPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultScriptPatterns are the download-and-execute patterns every scanner
+// checks in lifecycle scripts. Each needs both the fetch or decode and the
+// execution, so scripts that only download a file or only run local code
+// are not flagged.
+var DefaultScriptPatterns = []ScriptPattern{
+	{
+		Name:   "remote script piped to an interpreter",
+		Regexp: regexp.MustCompile(`(?i)\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?((ba|z)?sh|node|python3?|perl|ruby)\b`),
+	},
+	{
+		Name:   "remote script run by command substitution",
+		Regexp: regexp.MustCompile(`(?i)(\beval\s+["']?\$\(|\b(ba|z)?sh\s+(-c\s+["']?\$\(|<\())\s*(curl|wget)\b`),
+	},
+	{
+		Name:   "base64-decoded payload evaluated",
+		Regexp: regexp.MustCompile(`(?i)\beval\s*\(\s*(atob\s*\(|Buffer\.from\s*\([^)]*base64)`),
+	},
+	{
+		Name:   "base64-decoded payload piped to a shell",
+		Regexp: regexp.MustCompile(`(?i)\bbase64\s+(-d|--decode)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`),
+	},
+	{
+		Name:   "inline node code fetching remote content",
+		Regexp: regexp.MustCompile(`(?i)\bnode\s+(-e|--eval|-p|--print)\b.*(\bfetch\s*\(|\bhttps?\.(get|request)\s*\(|require\(\s*["'](node:)?https?["']\s*\))`),
+	},
+	{
+		Name:   "PowerShell download and invoke",
+		Regexp: regexp.MustCompile(`(?i)\b(invoke-webrequest|iwr|downloadstring)\b.*\b(iex|invoke-expression)\b`),
+	},
+}
+
 // extractScripts extracts the scripts section from package.json
 func extractScripts(content string) map[string]string {
 	var pkg struct {
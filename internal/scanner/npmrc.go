@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"bufio"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// AdvisorySuspiciousNpmrc flags an .npmrc line that sends packages or
+// credentials to an unknown registry, or commits a registry token
+const AdvisorySuspiciousNpmrc = "SuspiciousNpmrc"
+
+// DefaultNpmrcAllowedHosts are the public registries an .npmrc may point at
+// without being flagged. WithNpmrcAllowedHosts adds private registries.
+var DefaultNpmrcAllowedHosts = []string{"registry.npmjs.org", "registry.yarnpkg.com", "npm.pkg.github.com"}
+
+// npmrcCredentialKeys are the .npmrc settings that hold registry credentials
+var npmrcCredentialKeys = []string{"_authToken", "_auth", "_password"}
+
+// SuspiciousConfig is a line of a committed .npmrc that may exfiltrate
+// packages or credentials. It is heuristic and reported as an advisory.
+type SuspiciousConfig struct {
+	FilePath string
+	RepoName string
+	Line     int    // 1-based line in FilePath
+	Content  string // The offending line, with any committed credential redacted
+	Reason   string
+	Known    bool // Present in the baseline
+}
+
+// WithNpmrcAllowedHosts adds registry hosts, such as a private registry,
+// that .npmrc files may point at and authenticate to. Subdomains of a host
+// are allowed too. The DefaultNpmrcAllowedHosts are always allowed.
+func WithNpmrcAllowedHosts(hosts []string) ScannerOption {
+	return func(s *Scanner) {
+		s.npmrcAllowedHosts = append(s.npmrcAllowedHosts, hosts...)
+	}
+}
+
+// IsNpmrcPath checks if a repository path is an .npmrc outside node_modules
+func IsNpmrcPath(filePath string) bool {
+	return path.Base(filePath) == ".npmrc" && !slices.Contains(strings.Split(filePath, "/"), "node_modules")
+}
+
+// CheckNpmrcFiles flags .npmrc lines that point a registry at an unknown
+// host, send credentials to one, or commit a literal token. Files too large
+// to have been fetched are skipped; CheckCommittedSecretFiles covers them.
+func (s *Scanner) CheckNpmrcFiles(files []*github.TreeFile) []*SuspiciousConfig {
+	var configs []*SuspiciousConfig
+	for _, file := range files {
+		if !file.Fetched || !IsNpmrcPath(file.Path) {
+			continue
+		}
+		for _, sc := range s.checkNpmrc(file.Content) {
+			sc.FilePath = file.Path
+			sc.RepoName = file.RepoName
+			configs = append(configs, sc)
+		}
+	}
+	return configs
+}
+
+// npmrcLine is a key=value setting of an .npmrc file
+type npmrcLine struct {
+	number int
+	key    string
+	value  string
+}
+
+// checkNpmrc checks the settings of an .npmrc file. always-auth is only
+// flagged when the file also points a registry at an unknown host.
+func (s *Scanner) checkNpmrc(content string) []*SuspiciousConfig {
+	var configs []*SuspiciousConfig
+	var alwaysAuth []npmrcLine
+	unknownRegistry := false
+	for _, line := range parseNpmrc(content) {
+		switch {
+		case line.key == "always-auth" && line.value == "true":
+			alwaysAuth = append(alwaysAuth, line)
+		case line.key == "registry" || strings.HasSuffix(line.key, ":registry"):
+			if host := registryHost(line.value); host != "" && !s.npmrcHostAllowed(host) {
+				unknownRegistry = true
+				configs = append(configs, line.suspicious("registry points at unknown host "+host))
+			}
+		default:
+			if sc := s.checkNpmrcCredential(line); sc != nil {
+				configs = append(configs, sc)
+			}
+		}
+	}
+	if unknownRegistry {
+		for _, line := range alwaysAuth {
+			configs = append(configs, line.suspicious("always-auth sends credentials to the unknown registry"))
+		}
+	}
+	return configs
+}
+
+// checkNpmrcCredential flags a credential sent to an unknown host, or a
+// literal one committed for any host. Credentials read from the environment,
+// such as ${NPM_TOKEN}, are expected for allowed hosts.
+func (s *Scanner) checkNpmrcCredential(line npmrcLine) *SuspiciousConfig {
+	host, ok := line.credentialRegistry()
+	if !ok {
+		return nil
+	}
+	committed := !isEnvReference(line.value)
+	if committed {
+		line.value = "<redacted>"
+	}
+	if host = registryHost("https:" + host); host != "" && !s.npmrcHostAllowed(host) {
+		return line.suspicious("sends a registry credential to unknown host " + host)
+	}
+	if committed {
+		return line.suspicious("commits a registry credential; revoke and rotate it")
+	}
+	return nil
+}
+
+// credentialRegistry reports whether the line sets a registry credential, and
+// the registry it is for. Credentials are keyed by registry, e.g.
+// //registry.example.com/:_authToken, or unscoped for the default registry.
+func (l npmrcLine) credentialRegistry() (string, bool) {
+	host, setting := "", l.key
+	if i := strings.LastIndex(l.key, ":"); i >= 0 {
+		host, setting = l.key[:i], l.key[i+1:]
+	}
+	return host, slices.Contains(npmrcCredentialKeys, setting)
+}
+
+// hasCommittedNpmrcCredential checks if an .npmrc holds a literal registry
+// credential rather than one read from the environment
+func hasCommittedNpmrcCredential(content string) bool {
+	return slices.ContainsFunc(parseNpmrc(content), func(line npmrcLine) bool {
+		_, ok := line.credentialRegistry()
+		return ok && line.value != "" && !isEnvReference(line.value)
+	})
+}
+
+// suspicious creates a finding for the line
+func (l npmrcLine) suspicious(reason string) *SuspiciousConfig {
+	return &SuspiciousConfig{Line: l.number, Content: l.key + "=" + l.value, Reason: reason}
+}
+
+// parseNpmrc reads the key=value settings of an .npmrc file, skipping blank
+// lines and # or ; comments
+func parseNpmrc(content string) []npmrcLine {
+	var lines []npmrcLine
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			continue
+		}
+		lines = append(lines, npmrcLine{number: n, key: strings.TrimSpace(key), value: strings.Trim(strings.TrimSpace(value), `"'`)})
+	}
+	return lines
+}
+
+// registryHost returns the lowercased host of a registry URL, or "" if it is
+// not a URL, such as a ${REGISTRY} reference to the environment
+func registryHost(raw string) string {
+	if strings.Contains(raw, "${") {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// isEnvReference checks if a value is read from an environment variable
+func isEnvReference(value string) bool {
+	return strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}")
+}
+
+// npmrcHostAllowed checks if a host, or a domain it is under, is allowed
+func (s *Scanner) npmrcHostAllowed(host string) bool {
+	for _, allowed := range slices.Concat(DefaultNpmrcAllowedHosts, s.npmrcAllowedHosts) {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ID returns the fingerprint of the suspicious .npmrc line
+func (sc *SuspiciousConfig) ID() string {
+	return FindingID(CategoryAdvisory, sc.RepoName, sc.FilePath, AdvisorySuspiciousNpmrc+":"+sc.Content)
+}
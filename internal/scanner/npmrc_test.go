@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+func TestCheckNpmrcFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // Reasons, in line order
+	}{
+		{"default registry", "registry=https://registry.npmjs.org/\n", nil},
+		{"token from the environment", "//registry.npmjs.org/:_authToken=${NPM_TOKEN}\n", nil},
+		{"GitHub Packages scope", "@test-org:registry=https://npm.pkg.github.com\n//npm.pkg.github.com/:_authToken=${GITHUB_TOKEN}\n", nil},
+		{"comments", "# registry=https://evil.example.com/\n; _authToken=npm_secret\n", nil},
+		{"unknown registry", "registry=https://evil.example.com/\n", []string{"registry points at unknown host evil.example.com"}},
+		{"unknown scoped registry", "@test-org:registry=http://evil.example.com:8080/\n", []string{"registry points at unknown host evil.example.com"}},
+		{"token sent to unknown host", "//evil.example.com:8080/:_authToken=${NPM_TOKEN}\n", []string{"sends a registry credential to unknown host evil.example.com"}},
+		{"committed token sent to unknown host", "//evil.example.com/:_authToken=npm_secret\n", []string{"sends a registry credential to unknown host evil.example.com"}},
+		{"committed token", "//registry.npmjs.org/:_authToken=npm_secret\n", []string{"commits a registry credential; revoke and rotate it"}},
+		{"committed token without a host", "_auth=c2VjcmV0\n", []string{"commits a registry credential; revoke and rotate it"}},
+		{"always-auth with a known registry", "always-auth=true\nregistry=https://registry.npmjs.org/\n", nil},
+		{"always-auth with an unknown registry", "always-auth=true\nregistry=https://evil.example.com/\n", []string{
+			"registry points at unknown host evil.example.com",
+			"always-auth sends credentials to the unknown registry",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := NewScanner(nil, true).CheckNpmrcFiles([]*github.TreeFile{
+				{RepoName: "test-org/test-repo", Path: "packages/web/.npmrc", Fetched: true, Content: tt.content},
+			})
+			if len(configs) != len(tt.want) {
+				t.Fatalf("expected %d findings, got %d: %+v", len(tt.want), len(configs), configs)
+			}
+			for i, sc := range configs {
+				if sc.Reason != tt.want[i] {
+					t.Errorf("expected reason %q, got %q", tt.want[i], sc.Reason)
+				}
+				if strings.Contains(sc.Content, "npm_secret") {
+					t.Errorf("expected the committed token to be redacted, got %q", sc.Content)
+				}
+				if sc.FilePath != "packages/web/.npmrc" || sc.RepoName != "test-org/test-repo" {
+					t.Errorf("expected the finding in test-org/test-repo packages/web/.npmrc, got %s %s", sc.RepoName, sc.FilePath)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckNpmrcFiles_RedactsAndLocatesLine(t *testing.T) {
+	configs := NewScanner(nil, true).CheckNpmrcFiles([]*github.TreeFile{{
+		RepoName: "test-org/test-repo",
+		Path:     ".npmrc",
+		Fetched:  true,
+		Content:  "# test registry\nsave-exact=true\n//registry.npmjs.org/:_authToken=npm_secret\n",
+	}})
+
+	if len(configs) != 1 {
+		t.Fatalf("expected the committed token to be flagged, got %+v", configs)
+	}
+	if configs[0].Line != 3 {
+		t.Errorf("expected line 3, got %d", configs[0].Line)
+	}
+	if strings.Contains(configs[0].Content, "npm_secret") || configs[0].Content != "//registry.npmjs.org/:_authToken=<redacted>" {
+		t.Errorf("expected the token to be redacted, got %q", configs[0].Content)
+	}
+}
+
+func TestCheckNpmrcFiles_AllowedHosts(t *testing.T) {
+	files := []*github.TreeFile{{
+		RepoName: "test-org/test-repo",
+		Path:     ".npmrc",
+		Fetched:  true,
+		Content:  "registry=https://npm.test-org.example.com/\n//npm.test-org.example.com/:_authToken=${NPM_TOKEN}\n",
+	}}
+
+	if configs := NewScanner(nil, true).CheckNpmrcFiles(files); len(configs) != 2 {
+		t.Fatalf("expected the private registry to be flagged by default, got %+v", configs)
+	}
+	if configs := NewScanner(nil, true, WithNpmrcAllowedHosts([]string{"test-org.example.com"})).CheckNpmrcFiles(files); len(configs) != 0 {
+		t.Errorf("expected subdomains of an allowed host to pass, got %+v", configs)
+	}
+}
+
+func TestCheckNpmrcFiles_SkipsVendoredAndUnfetchedFiles(t *testing.T) {
+	configs := NewScanner(nil, true).CheckNpmrcFiles([]*github.TreeFile{
+		{RepoName: "test-org/test-repo", Path: "node_modules/test-muaddib-pkg/.npmrc", Fetched: true, Content: "registry=https://evil.example.com/\n"},
+		{RepoName: "test-org/test-repo", Path: "large/.npmrc", Size: 20000},
+	})
+	if len(configs) != 0 {
+		t.Errorf("expected vendored and unfetched files to be skipped, got %+v", configs)
+	}
+}
+
+func TestRepoScanResult_FindingsIncludeSuspiciousConfigs(t *testing.T) {
+	result := &RepoScanResult{
+		RepoName: "test-org/test-repo",
+		Ref:      "main",
+		SuspiciousConfigs: []*SuspiciousConfig{{
+			RepoName: "test-org/test-repo",
+			FilePath: ".npmrc",
+			Line:     2,
+			Content:  "registry=https://evil.example.com/",
+			Reason:   "registry points at unknown host evil.example.com",
+		}},
+	}
+
+	findings := result.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected one finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Category != CategoryAdvisory || f.Severity != SeverityMedium || !strings.HasPrefix(f.Detail, AdvisorySuspiciousNpmrc+": ") {
+		t.Errorf("expected a medium severity %s advisory, got %+v", AdvisorySuspiciousNpmrc, f)
+	}
+	if f.URL != "https://github.com/test-org/test-repo/blob/main/.npmrc#L2" {
+		t.Errorf("expected a link to the line, got %s", f.URL)
+	}
+}
@@ -4,25 +4,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"gopkg.in/yaml.v3"
+	"regexp"
+	"slices"
 	"strings"
 )
 
 // Package represents a package with name and version
 type Package struct {
-	Name    string
-	Version string
-	IsDev   bool
-	Source  string // "direct" or "transitive"
+	Name         string
+	Version      string
+	IsDev        bool
+	Source       string   // "direct", "transitive", or "override"
+	OptionalPeer bool     // Peer marked optional in peerDependenciesMeta; may not be installed
+	IsOptional   bool     // Optional dependency, skipped by WithSkipOptional
+	Integrity    string   // Lockfile integrity hash of the resolved tarball, if recorded
+	Declared     bool     // Version is cleaned from a package.json range, not resolved
+	SpecType     SpecType // Kind of package.json specifier; empty for lockfile entries
 }
 
 // PackageJSON represents the structure of a package.json file
 type PackageJSON struct {
-	Name                 string            `json:"name"`
-	Version              string            `json:"version"`
-	Dependencies         map[string]string `json:"dependencies"`
-	DevDependencies      map[string]string `json:"devDependencies"`
-	OptionalDependencies map[string]string `json:"optionalDependencies"`
-	PeerDependencies     map[string]string `json:"peerDependencies"`
+	Name                 string                        `json:"name"`
+	Version              string                        `json:"version"`
+	Dependencies         map[string]string             `json:"dependencies"`
+	DevDependencies      map[string]string             `json:"devDependencies"`
+	OptionalDependencies map[string]string             `json:"optionalDependencies"`
+	PeerDependencies     map[string]string             `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]PeerDependencyMeta `json:"peerDependenciesMeta"`
+	Workspaces           Workspaces                    `json:"workspaces"`
+	Overrides            map[string]json.RawMessage    `json:"overrides"`   // npm
+	Resolutions          map[string]json.RawMessage    `json:"resolutions"` // yarn
+}
+
+// PeerDependencyMeta represents an entry in the peerDependenciesMeta map
+type PeerDependencyMeta struct {
+	Optional bool `json:"optional"`
 }
 
 // PackageLockJSON represents the structure of a package-lock.json file (v2/v3)
@@ -36,74 +52,146 @@ type PackageLockJSON struct {
 
 // PackageLockEntry represents an entry in the packages map (v2/v3)
 type PackageLockEntry struct {
-	Version      string            `json:"version"`
-	Resolved     string            `json:"resolved"`
-	Dev          bool              `json:"dev"`
-	Optional     bool              `json:"optional"`
-	Dependencies map[string]string `json:"dependencies"`
+	Name         string                     `json:"name"` // Workspace members only
+	Version      string                     `json:"version"`
+	Resolved     string                     `json:"resolved"`
+	Link         bool                       `json:"link"` // Symlink to a workspace member
+	Integrity    string                     `json:"integrity"`
+	Dev          bool                       `json:"dev"`
+	DevOptional  bool                       `json:"devOptional"` // Only needed by both dev and optional dependencies
+	Optional     bool                       `json:"optional"`
+	Dependencies map[string]string          `json:"dependencies"`
+	Overrides    map[string]json.RawMessage `json:"overrides"` // Root entry only
 }
 
 // LegacyLockEntry represents an entry in the v1 dependencies map
 type LegacyLockEntry struct {
 	Version      string                     `json:"version"`
+	Integrity    string                     `json:"integrity"`
 	Dev          bool                       `json:"dev"`
 	Optional     bool                       `json:"optional"`
 	Requires     map[string]string          `json:"requires"`
 	Dependencies map[string]LegacyLockEntry `json:"dependencies"`
 }
 
-// ParsePackageJSON parses a package.json file and extracts all dependencies
+// ParsePackageJSON parses a package.json file and extracts all dependencies.
+// Each is tagged with its SpecType; git, path, URL, and workspace specifiers
+// are kept as written in Version rather than cleaned as a range.
+// Exact versions pinned by npm overrides or yarn resolutions are recorded as
+// override packages. Top-level pins replace the declared ranges they
+// override; pins nested under another package only apply beneath it, so the
+// declared range is kept. Overrides to a range pin no one version, so they
+// are not recorded and leave the declared range in place.
 func ParsePackageJSON(content string, includeDev bool) ([]*Package, error) {
 	var pkg PackageJSON
 	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
 		return nil, fmt.Errorf("failed to parse package.json: %w", err)
 	}
 
-	var packages []*Package
+	packages := pkg.overridePackages()
+	pinned := pkg.topLevelPins()
 
 	// Production dependencies
-	for name, version := range pkg.Dependencies {
-		packages = append(packages, &Package{
-			Name:    name,
-			Version: cleanVersion(version),
-			IsDev:   false,
-			Source:  "direct",
-		})
+	for name, spec := range pkg.Dependencies {
+		packages = append(packages, declaredPackage(name, spec))
 	}
 
 	// Dev dependencies
 	if includeDev {
-		for name, version := range pkg.DevDependencies {
-			packages = append(packages, &Package{
-				Name:    name,
-				Version: cleanVersion(version),
-				IsDev:   true,
-				Source:  "direct",
-			})
+		for name, spec := range pkg.DevDependencies {
+			dep := declaredPackage(name, spec)
+			dep.IsDev = true
+			packages = append(packages, dep)
 		}
 	}
 
 	// Optional dependencies
-	for name, version := range pkg.OptionalDependencies {
-		packages = append(packages, &Package{
-			Name:    name,
-			Version: cleanVersion(version),
-			IsDev:   false,
-			Source:  "direct",
-		})
+	for name, spec := range pkg.OptionalDependencies {
+		dep := declaredPackage(name, spec)
+		dep.IsOptional = true
+		packages = append(packages, dep)
 	}
 
-	// Peer dependencies
-	for name, version := range pkg.PeerDependencies {
-		packages = append(packages, &Package{
-			Name:    name,
-			Version: cleanVersion(version),
-			IsDev:   false,
-			Source:  "direct",
-		})
+	// Peer dependencies (optional peers may not be installed at all)
+	for name, spec := range pkg.PeerDependencies {
+		dep := declaredPackage(name, spec)
+		dep.OptionalPeer = pkg.PeerDependenciesMeta[name].Optional
+		packages = append(packages, dep)
 	}
 
-	return packages, nil
+	// A declared range is not what is installed once an override pins it
+	return slices.DeleteFunc(packages, func(p *Package) bool { return p.Declared && pinned[p.Name] }), nil
+}
+
+// overridePackages returns the versions pinned by npm overrides and yarn
+// resolutions
+func (pkg *PackageJSON) overridePackages() []*Package {
+	var packages []*Package
+	seen := make(map[string]bool)
+	parseLockOverrides(pkg.Overrides, seen, &packages)
+	for key, raw := range pkg.Resolutions {
+		var version string
+		if err := json.Unmarshal(raw, &version); err == nil {
+			addOverridePackage(resolutionName(key), version, seen, &packages)
+		}
+	}
+	return packages
+}
+
+// topLevelPins returns the packages whose overrides or resolutions pin an
+// exact version for the project's own dependencies rather than only beneath
+// another package
+func (pkg *PackageJSON) topLevelPins() map[string]bool {
+	pinned := make(map[string]bool)
+	for key, raw := range pkg.Overrides {
+		if overrideVersion(selfOverride(raw)) != "" {
+			pinned[overrideName(key)] = true
+		}
+	}
+	for key, raw := range pkg.Resolutions {
+		var version string
+		if err := json.Unmarshal(raw, &version); err != nil || overrideVersion(version) == "" {
+			continue
+		}
+		// "**/foo" pins foo everywhere, "parent/foo" only beneath parent
+		name := resolutionName(key)
+		if path := strings.TrimPrefix(key, "**/"); path == name || strings.HasPrefix(path, name+"@") {
+			pinned[name] = true
+		}
+	}
+	return pinned
+}
+
+// selfOverride returns the version an overrides value sets for the package
+// itself: the value when it is a string, or its "." key when it is an object
+func selfOverride(raw json.RawMessage) string {
+	var version string
+	if err := json.Unmarshal(raw, &version); err == nil {
+		return version
+	}
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return ""
+	}
+	if self, ok := nested["."]; ok {
+		json.Unmarshal(self, &version)
+	}
+	return version
+}
+
+// resolutionName returns the package a yarn resolutions key pins. Keys are
+// paths through the dependency tree that may carry a version selector, e.g.
+// "**/foo", "parent/@scope/foo", or "foo@^1.0.0".
+func resolutionName(key string) string {
+	segments := strings.Split(key, "/")
+	name := segments[len(segments)-1]
+	if len(segments) > 1 && strings.HasPrefix(segments[len(segments)-2], "@") {
+		name = segments[len(segments)-2] + "/" + name
+	}
+	if idx := strings.LastIndex(name, "@"); idx > 0 {
+		name = name[:idx]
+	}
+	return name
 }
 
 // ParsePackageLock parses a package-lock.json file and extracts all dependencies including transitive
@@ -115,17 +203,29 @@ func ParsePackageLock(content string, includeDev bool) ([]*Package, error) {
 
 	seen := make(map[string]bool)
 	var packages []*Package
+	var rootOverrides map[string]json.RawMessage
 
 	// v2/v3 format uses "packages" field
 	if len(lock.Packages) > 0 {
 		for pkgPath, entry := range lock.Packages {
-			// Skip the root package (empty path or ".")
+			// The root package (empty path or ".") is not a dependency, but it
+			// records override pins that must be checked wherever they apply
 			if pkgPath == "" || pkgPath == "." {
+				rootOverrides = entry.Overrides
+				continue
+			}
+
+			// Workspace members and the links to them are local code, not
+			// registry packages; their resolved dependencies have their own
+			// node_modules entries
+			if isWorkspaceMember(pkgPath, entry) || entry.Link {
 				continue
 			}
 
-			// Skip dev dependencies if not included
-			if entry.Dev && !includeDev {
+			// Skip dev dependencies if not included; npm marks packages
+			// needed only by dev and optional dependencies as devOptional
+			isDev := entry.Dev || entry.DevOptional
+			if isDev && !includeDev {
 				continue
 			}
 
@@ -141,48 +241,189 @@ func ParsePackageLock(content string, includeDev bool) ([]*Package, error) {
 			seen[key] = true
 
 			packages = append(packages, &Package{
-				Name:    name,
-				Version: entry.Version,
-				IsDev:   entry.Dev,
-				Source:  "transitive",
+				Name:       name,
+				Version:    entry.Version,
+				IsDev:      isDev,
+				Source:     "transitive",
+				Integrity:  entry.Integrity,
+				IsOptional: entry.Optional,
 			})
 		}
 	}
 
 	// v1 format uses "dependencies" field
 	if len(lock.Dependencies) > 0 {
-		parseLegacyDeps(lock.Dependencies, "", includeDev, seen, &packages)
+		parseLegacyDeps(lock.Dependencies, nil, includeDev, seen, &packages)
 	}
 
-	return packages, nil
+	return appendUninstalledOverrides(packages, rootOverrides, seen), nil
+}
+
+// appendUninstalledOverrides adds the override pins of the root lockfile entry
+// for versions the lockfile does not install, so the installed entry, with its
+// integrity hash and dev flag, is always the one checked
+func appendUninstalledOverrides(packages []*Package, overrides map[string]json.RawMessage, installed map[string]bool) []*Package {
+	var pins []*Package
+	parseLockOverrides(overrides, make(map[string]bool), &pins)
+	for _, pkg := range pins {
+		if !installed[pkg.Name+"@"+pkg.Version] {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// parseLockOverrides extracts override-pinned versions from npm overrides, as
+// found in package.json and the root lockfile entry.
+// Overrides map a package name to a version, or to a nested object where "."
+// pins the package itself and other keys pin its dependencies:
+//
+//	"overrides": {"foo": "1.0.0", "bar": {".": "2.0.0", "baz": "3.0.0"}}
+//
+// Ranges and references to other dependencies ("$foo") are skipped as they
+// pin no one version.
+func parseLockOverrides(overrides map[string]json.RawMessage, seen map[string]bool, packages *[]*Package) {
+	for key, raw := range overrides {
+		name := overrideName(key)
+
+		var version string
+		if err := json.Unmarshal(raw, &version); err == nil {
+			addOverridePackage(name, version, seen, packages)
+			continue
+		}
+
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &nested); err != nil {
+			continue
+		}
+		if self, ok := nested["."]; ok {
+			if err := json.Unmarshal(self, &version); err == nil {
+				addOverridePackage(name, version, seen, packages)
+			}
+			delete(nested, ".")
+		}
+		parseLockOverrides(nested, seen, packages)
+	}
+}
+
+// overrideName returns the package an overrides key pins. Keys may carry a
+// version selector, e.g. "foo@1.x".
+func overrideName(key string) string {
+	if idx := strings.LastIndex(key, "@"); idx > 0 {
+		return key[:idx]
+	}
+	return key
 }
 
-// parseLegacyDeps recursively parses v1 format dependencies
-func parseLegacyDeps(deps map[string]LegacyLockEntry, prefix string, includeDev bool, seen map[string]bool, packages *[]*Package) {
+// exactVersion matches a single semver version such as 1.2.3 or 1.2.3-beta.1
+var exactVersion = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// overrideVersion returns the exact version an override pins, without any
+// "=" or "v" prefix, or "" for a range or a reference to another dependency
+// ("$foo"), which pin no one version
+func overrideVersion(spec string) string {
+	exact := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(spec), "="), "v")
+	if !exactVersion.MatchString(exact) {
+		return ""
+	}
+	return exact
+}
+
+// addOverridePackage records a single override pin, unless it pins no exact
+// version
+func addOverridePackage(name, version string, seen map[string]bool, packages *[]*Package) {
+	version = overrideVersion(version)
+	if name == "" || version == "" {
+		return
+	}
+
+	key := name + "@" + version
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+
+	*packages = append(*packages, &Package{
+		Name:    name,
+		Version: version,
+		Source:  "override",
+	})
+}
+
+// parseLegacyDeps recursively parses v1 format dependencies. scopes holds the
+// dependencies maps enclosing deps, outermost first, which its entries'
+// requires may resolve to when the required package is hoisted.
+func parseLegacyDeps(deps map[string]LegacyLockEntry, scopes []map[string]LegacyLockEntry, includeDev bool, seen map[string]bool, packages *[]*Package) {
+	scopes = append(slices.Clip(scopes), deps)
 	for name, entry := range deps {
 		// Skip dev dependencies if not included
 		if entry.Dev && !includeDev {
 			continue
 		}
 
-		key := name + "@" + entry.Version
-		if seen[key] {
-			continue
+		addLegacyPackage(&Package{
+			Name:       name,
+			Version:    entry.Version,
+			IsDev:      entry.Dev,
+			Source:     "transitive",
+			Integrity:  entry.Integrity,
+			IsOptional: entry.Optional,
+		}, seen, packages)
+		addUnresolvedRequires(entry, scopes, seen, packages)
+
+		// Recurse into nested dependencies, even for a version already seen
+		// elsewhere in the tree, as its nested copies may differ
+		if len(entry.Dependencies) > 0 {
+			parseLegacyDeps(entry.Dependencies, scopes, includeDev, seen, packages)
 		}
-		seen[key] = true
+	}
+}
 
-		*packages = append(*packages, &Package{
-			Name:    name,
-			Version: entry.Version,
-			IsDev:   entry.Dev,
-			Source:  "transitive",
-		})
+// addUnresolvedRequires records the packages an entry requires that resolve
+// to no entry in its own or an enclosing dependencies map, checking the base
+// version of the required range as for package.json. Requires that do
+// resolve, including to a package hoisted to the top level, are recorded
+// where the package is declared.
+func addUnresolvedRequires(entry LegacyLockEntry, scopes []map[string]LegacyLockEntry, seen map[string]bool, packages *[]*Package) {
+	for name, spec := range entry.Requires {
+		if legacyDepResolves(name, entry.Dependencies, scopes) || strings.Contains(spec, ":") {
+			continue
+		}
+		if version := cleanVersion(spec); version != "" {
+			addLegacyPackage(&Package{Name: name, Version: version, IsDev: entry.Dev, Source: "transitive", IsOptional: entry.Optional}, seen, packages)
+		}
+	}
+}
 
-		// Recurse into nested dependencies
-		if len(entry.Dependencies) > 0 {
-			parseLegacyDeps(entry.Dependencies, name+"/", includeDev, seen, packages)
+// legacyDepResolves checks if a required package resolves, as node does, to
+// the requiring entry's nested dependencies or an enclosing dependencies map
+func legacyDepResolves(name string, nested map[string]LegacyLockEntry, scopes []map[string]LegacyLockEntry) bool {
+	if _, ok := nested[name]; ok {
+		return true
+	}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if _, ok := scopes[i][name]; ok {
+			return true
 		}
 	}
+	return false
+}
+
+// addLegacyPackage records a v1 package version once
+func addLegacyPackage(pkg *Package, seen map[string]bool, packages *[]*Package) {
+	key := pkg.Name + "@" + pkg.Version
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	*packages = append(*packages, pkg)
+}
+
+// isWorkspaceMember checks if a v2/v3 packages entry is an npm workspace
+// member, keyed by its directory (e.g. "apps/web") rather than a
+// node_modules path
+func isWorkspaceMember(pkgPath string, entry PackageLockEntry) bool {
+	return entry.Name != "" && !strings.Contains("/"+pkgPath+"/", "/node_modules/")
 }
 
 // extractPackageName extracts the package name from a package path
@@ -231,66 +472,205 @@ func cleanVersion(version string) string {
 	return version
 }
 
-// PnpmLockYAML represents the structure of a pnpm-lock.yaml file (v6+)
+// PnpmLockYAML represents the structure of a pnpm-lock.yaml file (v6+).
+// From v9, packages holds each package's metadata and snapshots its resolved
+// dependency graph, keyed with peer dependency suffixes.
 type PnpmLockYAML struct {
 	LockfileVersion string                   `yaml:"lockfileVersion"`
+	Importers       map[string]PnpmImporter  `yaml:"importers"`
 	Packages        map[string]PnpmLockEntry `yaml:"packages"`
+	Snapshots       map[string]PnpmLockEntry `yaml:"snapshots"`
+}
+
+// PnpmImporter lists the direct dependencies of a workspace project
+type PnpmImporter struct {
+	Dependencies         map[string]PnpmImporterDependency `yaml:"dependencies"`
+	DevDependencies      map[string]PnpmImporterDependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]PnpmImporterDependency `yaml:"optionalDependencies"`
+}
+
+// PnpmImporterDependency is the resolved version of a direct dependency. It is
+// a mapping with specifier and version from v6, and a plain version before.
+type PnpmImporterDependency struct {
+	Version string `yaml:"version"`
+}
+
+// UnmarshalYAML accepts both the mapping and the plain version forms
+func (d *PnpmImporterDependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+	type plain PnpmImporterDependency
+	return node.Decode((*plain)(d))
 }
 
 // PnpmLockEntry represents an entry in the pnpm packages map
 type PnpmLockEntry struct {
-	Version      string            `yaml:"version"`
-	Resolution   map[string]string `yaml:"resolution"`
-	Dev          bool              `yaml:"dev"`
-	Optional     bool              `yaml:"optional"`
-	Dependencies map[string]string `yaml:"dependencies"`
+	Version              string            `yaml:"version"`
+	Resolution           map[string]string `yaml:"resolution"`
+	Dev                  bool              `yaml:"dev"`
+	Optional             bool              `yaml:"optional"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
 }
 
-// ParsePnpmLock parses a pnpm-lock.yaml file and returns the list of packages
+// ParsePnpmLock parses a pnpm-lock.yaml file and returns the list of packages.
+// YAML anchors and aliases, such as a resolution shared by several entries,
+// are resolved by the decoder. Snapshot entries (v9+) add any package missing
+// from the packages map.
+//
+// Lockfiles before v9 mark dev dependencies with "dev: true". From v9 the
+// marker is gone, so a package is dev when it is only reachable from the
+// importers' devDependencies through the snapshots graph.
 func ParsePnpmLock(content string, includeDev bool) ([]*Package, error) {
 	var lockFile PnpmLockYAML
 	if err := yaml.Unmarshal([]byte(content), &lockFile); err != nil {
 		return nil, fmt.Errorf("failed to parse pnpm-lock.yaml: %w", err)
 	}
 
+	devOnly := lockFile.devOnlyPackages()
 	var packages []*Package
 	seen := make(map[string]bool)
 
 	// Parse the packages map
 	// Keys are in format: /pkg/1.0.0 or /@scope/pkg@1.0.0 or /pkg@1.0.0
 	for key, entry := range lockFile.Packages {
-		// Skip root package (empty key)
-		if key == "" {
-			continue
-		}
+		entry.Dev = entry.Dev || devOnly[pnpmPackageID(key)]
+		addPnpmPackage(key, entry, includeDev, seen, &packages)
+	}
 
-		// Skip dev dependencies if requested
-		if entry.Dev && !includeDev {
-			continue
+	// Snapshot keys carry peer suffixes, which are stripped, so entries
+	// already listed under packages are deduplicated
+	for key, entry := range lockFile.Snapshots {
+		if name, version := parsePnpmPackageKey(key); entry.Resolution == nil {
+			entry.Resolution = lockFile.Packages[name+"@"+version].Resolution
 		}
+		entry.Dev = entry.Dev || devOnly[pnpmPackageID(key)]
+		addPnpmPackage(key, entry, includeDev, seen, &packages)
+	}
+
+	return packages, nil
+}
+
+// devOnlyPackages returns the name@version of each package reachable from the
+// importers' devDependencies but not from their dependencies or
+// optionalDependencies, following the snapshots graph. It is empty for
+// lockfiles without snapshots, which mark dev packages themselves.
+func (l *PnpmLockYAML) devOnlyPackages() map[string]bool {
+	if len(l.Snapshots) == 0 {
+		return nil
+	}
+
+	var prodRoots, devRoots []string
+	for _, importer := range l.Importers {
+		prodRoots = appendPnpmImporterKeys(prodRoots, importer.Dependencies)
+		prodRoots = appendPnpmImporterKeys(prodRoots, importer.OptionalDependencies)
+		devRoots = appendPnpmImporterKeys(devRoots, importer.DevDependencies)
+	}
+
+	prod := l.reachableSnapshots(prodRoots)
+	devOnly := make(map[string]bool)
+	for key := range l.reachableSnapshots(devRoots) {
+		devOnly[pnpmPackageID(key)] = true
+	}
+	// A package with several peer variants is prod if any variant is
+	for key := range prod {
+		delete(devOnly, pnpmPackageID(key))
+	}
+	return devOnly
+}
 
-		// Extract package name and version from key
-		name, version := parsePnpmPackageKey(key)
-		if name == "" || version == "" {
+// reachableSnapshots returns the snapshot keys reachable from roots
+func (l *PnpmLockYAML) reachableSnapshots(roots []string) map[string]bool {
+	reached := make(map[string]bool)
+	queue := roots
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if reached[key] {
 			continue
 		}
+		reached[key] = true
+
+		snapshot := l.Snapshots[key]
+		for _, deps := range []map[string]string{snapshot.Dependencies, snapshot.OptionalDependencies} {
+			for name, version := range deps {
+				if depKey := pnpmSnapshotKey(name, version); depKey != "" {
+					queue = append(queue, depKey)
+				}
+			}
+		}
+	}
+	return reached
+}
 
-		// Deduplicate
-		pkgKey := name + "@" + version
-		if seen[pkgKey] {
-			continue
+// appendPnpmImporterKeys appends the snapshot keys of an importer's dependencies
+func appendPnpmImporterKeys(keys []string, deps map[string]PnpmImporterDependency) []string {
+	for name, dep := range deps {
+		if key := pnpmSnapshotKey(name, dep.Version); key != "" {
+			keys = append(keys, key)
 		}
-		seen[pkgKey] = true
+	}
+	return keys
+}
 
-		packages = append(packages, &Package{
-			Name:    name,
-			Version: version,
-			IsDev:   entry.Dev,
-			Source:  "transitive",
-		})
+// pnpmSnapshotKey returns the snapshots key a dependency resolves to. The
+// version is usually a bare version with any peer suffix, but an npm alias
+// gives the full "pkg@version" key. Workspace links and local paths have no
+// snapshot and return "".
+func pnpmSnapshotKey(name, version string) string {
+	if strings.HasPrefix(version, "link:") || strings.HasPrefix(version, "file:") {
+		return ""
 	}
+	base, _, _ := strings.Cut(version, "(")
+	if strings.LastIndex(base, "@") > 0 {
+		return version
+	}
+	return name + "@" + version
+}
 
-	return packages, nil
+// pnpmPackageID returns the name@version of a pnpm package key, without its
+// leading slash or peer dependency suffix
+func pnpmPackageID(key string) string {
+	name, version := parsePnpmPackageKey(key)
+	return name + "@" + version
+}
+
+// addPnpmPackage adds the package for a pnpm lockfile entry unless it is a
+// skipped dev dependency or has already been added
+func addPnpmPackage(key string, entry PnpmLockEntry, includeDev bool, seen map[string]bool, packages *[]*Package) {
+	// Skip root package (empty key)
+	if key == "" {
+		return
+	}
+
+	// Skip dev dependencies if requested
+	if entry.Dev && !includeDev {
+		return
+	}
+
+	// Extract package name and version from key
+	name, version := parsePnpmPackageKey(key)
+	if name == "" || version == "" {
+		return
+	}
+
+	// Deduplicate
+	pkgKey := name + "@" + version
+	if seen[pkgKey] {
+		return
+	}
+	seen[pkgKey] = true
+
+	*packages = append(*packages, &Package{
+		Name:       name,
+		Version:    version,
+		IsDev:      entry.Dev,
+		Source:     "transitive",
+		Integrity:  entry.Resolution["integrity"],
+		IsOptional: entry.Optional,
+	})
 }
 
 // parsePnpmPackageKey extracts package name and version from a pnpm package key
@@ -302,50 +682,32 @@ func ParsePnpmLock(content string, includeDev bool) ([]*Package, error) {
 //	/@scope/pkg/1.0.0 -> (@scope/pkg, 1.0.0)
 //	/pkg@1.0.0(peer@2.0.0) -> (pkg, 1.0.0)  // peer dep suffix stripped
 //	/pkg@1.0.0_peer@2.0.0 -> (pkg, 1.0.0)   // peer dep suffix stripped
+//	/pkg/1.0.0_peer@2.0.0 -> (pkg, 1.0.0)   // peer dep suffix stripped
+//
+// The name ends at the first "/" or "@" after the scope, so an "@" in a peer
+// dependency suffix is never mistaken for the version separator.
 func parsePnpmPackageKey(key string) (name, version string) {
 	// Remove leading slash
 	key = strings.TrimPrefix(key, "/")
 
-	// Handle scoped packages
+	// Skip the scope of scoped packages
+	nameStart := 0
 	if strings.HasPrefix(key, "@") {
-		// Find the @ that separates name from version
-		// For @scope/pkg@version, this produces ["", "scope/pkg", "version"]
-		// For @scope/pkg/version, this produces ["", "scope/pkg/version"]
-		parts := strings.SplitN(key, "@", 3)
-		if len(parts) < 2 {
+		slash := strings.Index(key, "/")
+		if slash < 0 {
 			return "", ""
 		}
-
-		scopedName := "@" + parts[1]
-
-		// Check if version is after @ or /
-		if len(parts) == 3 {
-			// Format: @scope/pkg@version
-			return scopedName, stripPnpmPeerDepSuffix(parts[2])
-		}
-
-		// Format: @scope/pkg/version - split the original key on last / to get version
-		if idx := strings.LastIndex(key, "/"); idx > 0 {
-			return key[:idx], stripPnpmPeerDepSuffix(key[idx+1:])
-		}
-
-		return "", ""
+		nameStart = slash + 1
 	}
 
-	// Regular package: pkg@version or pkg/version
-	if strings.Contains(key, "@") {
-		parts := strings.SplitN(key, "@", 2)
-		if len(parts) == 2 {
-			return parts[0], stripPnpmPeerDepSuffix(parts[1])
-		}
-	}
-
-	// Format: pkg/version
-	if idx := strings.LastIndex(key, "/"); idx > 0 {
-		return key[:idx], stripPnpmPeerDepSuffix(key[idx+1:])
+	// The separator is "@" (pkg@1.0.0) or, in older lockfiles, "/" (pkg/1.0.0)
+	sep := strings.IndexAny(key[nameStart:], "/@")
+	if sep <= 0 {
+		return "", ""
 	}
+	sep += nameStart
 
-	return "", ""
+	return key[:sep], stripPnpmPeerDepSuffix(key[sep+1:])
 }
 
 // stripPnpmPeerDepSuffix removes peer dependency suffixes from pnpm versions.
@@ -383,11 +745,12 @@ func stripPnpmPeerDepSuffix(version string) string {
 // a descriptive message. Berry format can be detected by the __metadata: header.
 // yarnLockParser holds state for parsing a yarn.lock file
 type yarnLockParser struct {
-	packages     []*Package
-	seen         map[string]bool
-	currentNames []string
-	currentVer   string
-	inEntry      bool
+	packages         []*Package
+	seen             map[string]bool
+	currentNames     []string
+	currentVer       string
+	currentIntegrity string
+	inEntry          bool
 }
 
 // newYarnLockParser creates a new yarn.lock parser
@@ -409,10 +772,11 @@ func (p *yarnLockParser) saveCurrentEntry() {
 		}
 		p.seen[pkgKey] = true
 		p.packages = append(p.packages, &Package{
-			Name:    name,
-			Version: p.currentVer,
-			IsDev:   false, // yarn.lock v1 doesn't track dev vs prod
-			Source:  "transitive",
+			Name:      name,
+			Version:   p.currentVer,
+			IsDev:     false, // yarn.lock v1 doesn't track dev vs prod
+			Source:    "transitive",
+			Integrity: p.currentIntegrity,
 		})
 	}
 }
@@ -460,7 +824,8 @@ func isYarnDeclarationLine(line, trimmed string) bool {
 		strings.HasSuffix(trimmed, ":")
 }
 
-// parseYarnVersionLine extracts version from a "version X" line
+// parseYarnVersionLine extracts the value from a "version X" line. Other
+// fields of the same form, such as "integrity X", are parsed with it too.
 func parseYarnVersionLine(trimmed string) string {
 	// Format: version "1.0.0"
 	parts := strings.SplitN(trimmed, " ", 2)
@@ -499,13 +864,17 @@ func ParseYarnLock(content string, includeDev bool) ([]*Package, error) {
 			// e.g., "pkg@^1.0.0, pkg@~1.0.5:" - both resolve to the same version
 			p.currentNames = parseYarnDeclarationLine(trimmed)
 			p.currentVer = ""
+			p.currentIntegrity = ""
 			p.inEntry = true
 			continue
 		}
 
-		// Parse version field
-		if p.inEntry && strings.HasPrefix(trimmed, "version") {
+		// Parse version and integrity fields, which share the "key value" form
+		switch {
+		case p.inEntry && strings.HasPrefix(trimmed, "version"):
 			p.currentVer = parseYarnVersionLine(trimmed)
+		case p.inEntry && strings.HasPrefix(trimmed, "integrity "):
+			p.currentIntegrity = parseYarnVersionLine(trimmed)
 		}
 	}
 
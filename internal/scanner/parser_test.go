@@ -123,6 +123,42 @@ func TestParsePackageJSON_ScopedPackages(t *testing.T) {
 	}
 }
 
+func TestParsePackageJSON_OverridesAndResolutions(t *testing.T) {
+	content := `{
+		"dependencies": {"test-muaddib-a": "^1.0.0", "test-muaddib-b": "^2.0.0", "test-muaddib-c": "^3.1.0", "test-muaddib-e": "^5.1.0", "test-muaddib-f": "^6.0.0"},
+		"overrides": {"test-muaddib-a": "1.0.1", "test-muaddib-parent": {"test-muaddib-c": "3.0.0"}, "test-muaddib-ref": "$test-muaddib-a", "test-muaddib-f": "~6.1.0"},
+		"resolutions": {"**/@test-muaddib/d": "4.0.0", "test-muaddib-parent/test-muaddib-e@^5.0.0": "5.0.1"}
+	}`
+
+	packages, err := ParsePackageJSON(content, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, pkg := range packages {
+		found[pkg.Name+"@"+pkg.Version] = pkg.Source
+	}
+	want := map[string]string{
+		"test-muaddib-a@1.0.1":  "override",
+		"test-muaddib-b@2.0.0":  "direct",
+		"test-muaddib-c@3.0.0":  "override",
+		"test-muaddib-c@3.1.0":  "direct", // nested pins keep the declared range
+		"@test-muaddib/d@4.0.0": "override",
+		"test-muaddib-e@5.0.1":  "override",
+		"test-muaddib-e@5.1.0":  "direct",
+		"test-muaddib-f@6.0.0":  "direct", // range overrides keep the declared range
+	}
+	if len(found) != len(want) {
+		t.Errorf("expected %d packages, got %v", len(want), found)
+	}
+	for key, source := range want {
+		if found[key] != source {
+			t.Errorf("expected %s from %s, got %q", key, source, found[key])
+		}
+	}
+}
+
 func TestParsePackageJSON_InvalidJSON(t *testing.T) {
 	content := `{ invalid json }`
 
@@ -323,6 +359,46 @@ func TestParsePackageLock_DevDependencies(t *testing.T) {
 	}
 }
 
+func TestParsePackageLock_DevOptionalAndOptional(t *testing.T) {
+	content := `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/test-muaddib-prod": {"version": "1.0.0"},
+			"node_modules/test-muaddib-dev-optional": {"version": "2.0.0", "devOptional": true},
+			"node_modules/test-muaddib-optional": {"version": "3.0.0", "optional": true}
+		}
+	}`
+
+	withoutDev, err := ParsePackageLock(content, false)
+	if err != nil {
+		t.Fatalf("ParsePackageLock failed: %v", err)
+	}
+	found := make(map[string]*Package)
+	for _, pkg := range withoutDev {
+		found[pkg.Name] = pkg
+	}
+	if _, ok := found["test-muaddib-dev-optional"]; ok {
+		t.Error("expected the devOptional package to be skipped with dev dependencies")
+	}
+	if pkg := found["test-muaddib-optional"]; pkg == nil || !pkg.IsOptional || pkg.IsDev {
+		t.Errorf("expected the optional package as a prod optional dependency, got %+v", pkg)
+	}
+
+	withDev, err := ParsePackageLock(content, true)
+	if err != nil {
+		t.Fatalf("ParsePackageLock failed: %v", err)
+	}
+	for _, pkg := range withDev {
+		if pkg.Name == "test-muaddib-dev-optional" && !pkg.IsDev {
+			t.Error("expected the devOptional package to be marked dev")
+		}
+	}
+	if len(withDev) != 3 {
+		t.Errorf("expected 3 packages with dev dependencies, got %d", len(withDev))
+	}
+}
+
 func TestParsePackageLock_V1Format(t *testing.T) {
 	content := `{
 		"name": "test-project",
@@ -373,6 +449,73 @@ func TestParsePackageLock_V1Format(t *testing.T) {
 	}
 }
 
+func TestParsePackageLock_V1RequiresResolution(t *testing.T) {
+	content := `{
+		"lockfileVersion": 1,
+		"dependencies": {
+			"test-muaddib-hoisted": {
+				"version": "1.2.3",
+				"integrity": "sha512-hoisted"
+			},
+			"test-muaddib-parent": {
+				"version": "2.0.0",
+				"requires": {
+					"test-muaddib-hoisted": "^1.0.0",
+					"test-muaddib-nested": "^4.0.0",
+					"test-muaddib-missing": "~5.1.0",
+					"test-muaddib-git": "github:test-org/test-muaddib-git"
+				},
+				"dependencies": {
+					"test-muaddib-nested": {
+						"version": "4.2.0",
+						"requires": {"test-muaddib-hoisted": "^1.0.0"}
+					}
+				}
+			},
+			"test-muaddib-other": {
+				"version": "1.0.0",
+				"dependencies": {
+					"test-muaddib-nested": {
+						"version": "4.2.0",
+						"dependencies": {"test-muaddib-deep": {"version": "6.0.0"}}
+					}
+				}
+			}
+		}
+	}`
+
+	packages, err := ParsePackageLock(content, false)
+	if err != nil {
+		t.Fatalf("ParsePackageLock failed: %v", err)
+	}
+
+	found := make(map[string]*Package)
+	for _, pkg := range packages {
+		found[pkg.Name+"@"+pkg.Version] = pkg
+	}
+
+	// The hoisted package resolves for both requirers and is recorded once,
+	// where it is declared
+	hoisted := found["test-muaddib-hoisted@1.2.3"]
+	if hoisted == nil || hoisted.Integrity != "sha512-hoisted" {
+		t.Errorf("expected the hoisted package with its integrity, got %+v", hoisted)
+	}
+	if _, ok := found["test-muaddib-hoisted@1.0.0"]; ok {
+		t.Error("expected the resolved require not to be recorded by its range")
+	}
+	// A require with no resolved entry is checked by its base version
+	if _, ok := found["test-muaddib-missing@5.1.0"]; !ok {
+		t.Errorf("expected the unresolved require to be recorded, got %v", found)
+	}
+	// Nested dependencies of an already seen version are still walked
+	if _, ok := found["test-muaddib-deep@6.0.0"]; !ok {
+		t.Errorf("expected the second copy's nested dependency, got %v", found)
+	}
+	if len(packages) != 6 {
+		t.Errorf("expected 6 packages, got %d", len(packages))
+	}
+}
+
 func TestParsePackageLock_InvalidJSON(t *testing.T) {
 	content := `{ invalid json }`
 
@@ -589,6 +732,174 @@ packages:
 		t.Errorf("expected @test-muaddib/scoped@2.0.0, got %s", found["@test-muaddib/scoped"])
 	}
 }
+func TestParsePnpmLock_AnchoredResolution(t *testing.T) {
+	// A resolution block anchored once and aliased by other entries, and a
+	// whole entry merged into another with <<
+	content := `lockfileVersion: '9.0'
+
+packages:
+  test-muaddib-pkg-a@1.0.0:
+    resolution: &shared {integrity: sha512-shared}
+
+  test-muaddib-pkg-b@2.0.0:
+    resolution: *shared
+
+  '@test-muaddib/scoped@3.0.0':
+    resolution: *shared
+
+  test-muaddib-pkg-c@4.0.0: &meta
+    resolution: {integrity: sha512-meta}
+    engines: {node: '>=18'}
+
+  test-muaddib-pkg-d@5.0.0:
+    <<: *meta
+    hasBin: true
+`
+
+	packages, err := ParsePnpmLock(content, false)
+	if err != nil {
+		t.Fatalf("ParsePnpmLock failed: %v", err)
+	}
+
+	integrity := make(map[string]string)
+	for _, pkg := range packages {
+		integrity[pkg.Name+"@"+pkg.Version] = pkg.Integrity
+	}
+	want := map[string]string{
+		"test-muaddib-pkg-a@1.0.0":   "sha512-shared",
+		"test-muaddib-pkg-b@2.0.0":   "sha512-shared",
+		"@test-muaddib/scoped@3.0.0": "sha512-shared",
+		"test-muaddib-pkg-c@4.0.0":   "sha512-meta",
+		"test-muaddib-pkg-d@5.0.0":   "sha512-meta",
+	}
+	if len(integrity) != len(want) {
+		t.Fatalf("expected %d packages, got %v", len(want), integrity)
+	}
+	for key, hash := range want {
+		if integrity[key] != hash {
+			t.Errorf("expected %s with integrity %q, got %q", key, hash, integrity[key])
+		}
+	}
+}
+
+func TestParsePnpmLock_V9Snapshots(t *testing.T) {
+	content := `lockfileVersion: '9.0'
+
+packages:
+  test-muaddib-pkg-a@1.0.0:
+    resolution: {integrity: sha512-a}
+
+  test-muaddib-peer@2.0.0:
+    resolution: {integrity: sha512-peer}
+
+snapshots:
+  test-muaddib-pkg-a@1.0.0(test-muaddib-peer@2.0.0):
+    dependencies:
+      test-muaddib-peer: 2.0.0
+
+  test-muaddib-peer@2.0.0: {}
+
+  '@test-muaddib/snapshot-only@3.0.0(test-muaddib-peer@2.0.0)':
+    dependencies:
+      test-muaddib-peer: 2.0.0
+`
+
+	packages, err := ParsePnpmLock(content, false)
+	if err != nil {
+		t.Fatalf("ParsePnpmLock failed: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, pkg := range packages {
+		found[pkg.Name+"@"+pkg.Version] = true
+	}
+	if len(packages) != 3 {
+		t.Errorf("expected 3 deduplicated packages, got %d: %v", len(packages), found)
+	}
+	for _, key := range []string{"test-muaddib-pkg-a@1.0.0", "test-muaddib-peer@2.0.0", "@test-muaddib/snapshot-only@3.0.0"} {
+		if !found[key] {
+			t.Errorf("expected %s", key)
+		}
+	}
+}
+
+func TestParsePnpmLock_V9DevClassification(t *testing.T) {
+	// The dev-only transitive is reached only through the devDependency; the
+	// shared package is reached from both, so it stays prod
+	content := `lockfileVersion: '9.0'
+
+importers:
+  .:
+    dependencies:
+      test-muaddib-prod:
+        specifier: ^1.0.0
+        version: 1.0.0
+    devDependencies:
+      test-muaddib-devtool:
+        specifier: ^2.0.0
+        version: 2.0.0(test-muaddib-shared@3.0.0)
+  packages/app:
+    dependencies:
+      test-muaddib-workspace:
+        specifier: workspace:*
+        version: link:../lib
+
+packages:
+  test-muaddib-prod@1.0.0:
+    resolution: {integrity: sha512-prod}
+  test-muaddib-devtool@2.0.0:
+    resolution: {integrity: sha512-devtool}
+  test-muaddib-dev-transitive@4.0.0:
+    resolution: {integrity: sha512-dev-transitive}
+  test-muaddib-shared@3.0.0:
+    resolution: {integrity: sha512-shared}
+
+snapshots:
+  test-muaddib-prod@1.0.0:
+    dependencies:
+      test-muaddib-shared: 3.0.0
+  test-muaddib-devtool@2.0.0(test-muaddib-shared@3.0.0):
+    dependencies:
+      test-muaddib-dev-transitive: 4.0.0
+      test-muaddib-shared: 3.0.0
+  test-muaddib-dev-transitive@4.0.0: {}
+  test-muaddib-shared@3.0.0: {}
+`
+
+	packages, err := ParsePnpmLock(content, false)
+	if err != nil {
+		t.Fatalf("ParsePnpmLock failed: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, pkg := range packages {
+		found[pkg.Name] = true
+	}
+	for name, want := range map[string]bool{
+		"test-muaddib-prod":           true,
+		"test-muaddib-shared":         true,
+		"test-muaddib-devtool":        false,
+		"test-muaddib-dev-transitive": false,
+	} {
+		if found[name] != want {
+			t.Errorf("%s: expected included %v with dev skipped, got %v", name, want, found[name])
+		}
+	}
+
+	packages, err = ParsePnpmLock(content, true)
+	if err != nil {
+		t.Fatalf("ParsePnpmLock failed: %v", err)
+	}
+	if len(packages) != 4 {
+		t.Fatalf("expected all 4 packages with dev included, got %d", len(packages))
+	}
+	for _, pkg := range packages {
+		wantDev := pkg.Name == "test-muaddib-devtool" || pkg.Name == "test-muaddib-dev-transitive"
+		if pkg.IsDev != wantDev {
+			t.Errorf("%s: expected IsDev %v, got %v", pkg.Name, wantDev, pkg.IsDev)
+		}
+	}
+}
+
 func TestParseYarnLock_BasicPackages(t *testing.T) {
 	content := `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
 # yarn lockfile v1
@@ -680,6 +991,8 @@ func TestParsePnpmPackageKey_PeerDepSuffix(t *testing.T) {
 		{"/test-muaddib-pkg@1.0.0_peer@2.0.0", "test-muaddib-pkg", "1.0.0"},
 		{"/test-muaddib-pkg@1.0.0_@scope/peer@2.0.0", "test-muaddib-pkg", "1.0.0"},
 		{"/@test-muaddib/scoped@1.0.0_peer@2.0.0", "@test-muaddib/scoped", "1.0.0"},
+		{"/test-muaddib-pkg/1.0.0_peer@2.0.0", "test-muaddib-pkg", "1.0.0"},
+		{"/@test-muaddib/scoped/1.0.0_@scope/peer@2.0.0", "@test-muaddib/scoped", "1.0.0"},
 		// Complex peer deps
 		{"/test-muaddib-pkg@1.0.0(peer1@2.0.0)(peer2@3.0.0)", "test-muaddib-pkg", "1.0.0"},
 	}
@@ -842,3 +1155,154 @@ alias-pkg@npm:actual-pkg@^1.0.0:
 		})
 	}
 }
+
+func TestParsePackageJSON_OptionalPeerDependencies(t *testing.T) {
+	content := `{
+		"name": "test-project",
+		"peerDependencies": {
+			"test-muaddib-required-peer": "1.0.0",
+			"test-muaddib-optional-peer": "2.0.0"
+		},
+		"peerDependenciesMeta": {
+			"test-muaddib-optional-peer": {"optional": true},
+			"test-muaddib-required-peer": {"optional": false}
+		}
+	}`
+
+	packages, err := ParsePackageJSON(content, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(packages))
+	}
+
+	for _, pkg := range packages {
+		switch pkg.Name {
+		case "test-muaddib-required-peer":
+			if pkg.OptionalPeer {
+				t.Error("expected required peer not to be marked optional")
+			}
+		case "test-muaddib-optional-peer":
+			if !pkg.OptionalPeer {
+				t.Error("expected optional peer to be marked optional")
+			}
+		default:
+			t.Errorf("unexpected package %s", pkg.Name)
+		}
+	}
+}
+
+func TestParsePackageLock_V3OverridePins(t *testing.T) {
+	content := `{
+		"name": "test-project",
+		"lockfileVersion": 3,
+		"packages": {
+			"": {
+				"name": "test-project",
+				"dependencies": {"test-muaddib-parent": "^1.0.0"},
+				"overrides": {
+					"test-muaddib-pinned": "1.0.1",
+					"test-muaddib-parent@1.x": {
+						".": "1.2.0",
+						"test-muaddib-nested-pin": "~3.0.0"
+					},
+					"test-muaddib-ref": "$test-muaddib-parent"
+				}
+			},
+			"node_modules/test-muaddib-parent": {"version": "1.2.0"},
+			"node_modules/test-muaddib-parent/node_modules/test-muaddib-pinned": {"version": "1.0.1", "dev": true, "integrity": "sha512-test-muaddib"}
+		}
+	}`
+
+	packages, err := ParsePackageLock(content, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, pkg := range packages {
+		found[pkg.Name+"@"+pkg.Version] = pkg.Source
+		if pkg.Name == "test-muaddib-pinned" && (pkg.Integrity != "sha512-test-muaddib" || !pkg.IsDev) {
+			t.Errorf("expected the installed entry to be kept over the override pin, got %+v", pkg)
+		}
+	}
+
+	for _, key := range []string{"test-muaddib-pinned@1.0.1", "test-muaddib-parent@1.2.0"} {
+		if _, ok := found[key]; !ok {
+			t.Errorf("expected %s to be extracted, got %v", key, found)
+		}
+	}
+	for key := range found {
+		if strings.HasPrefix(key, "test-muaddib-ref@") || strings.HasPrefix(key, "test-muaddib-nested-pin@") || strings.HasPrefix(key, "test-project@") {
+			t.Errorf("unexpected package %s", key)
+		}
+	}
+	if len(packages) != 2 {
+		t.Errorf("expected 2 unique packages, got %d", len(packages))
+	}
+}
+
+func TestOverrideVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.0.1":           "1.0.1",
+		"=1.0.1":          "1.0.1",
+		"v2.0.0-beta.1":   "2.0.0-beta.1",
+		"~3.0.0":          "",
+		"^1.2.0":          "",
+		">=1.0.0 <2.0.0":  "",
+		"1.x":             "",
+		"$test-muaddib-a": "",
+	}
+	for spec, want := range tests {
+		if got := overrideVersion(spec); got != want {
+			t.Errorf("overrideVersion(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestParsePackageLock_V3Workspaces(t *testing.T) {
+	content := `{
+		"name": "test-monorepo",
+		"lockfileVersion": 3,
+		"packages": {
+			"": {
+				"name": "test-monorepo",
+				"workspaces": ["apps/*", "packages/*"]
+			},
+			"apps/web": {
+				"name": "@test-muaddib/web",
+				"version": "0.1.0",
+				"dependencies": {"test-muaddib-shared": "^1.0.0", "test-muaddib-ui": "*"}
+			},
+			"packages/ui": {
+				"name": "test-muaddib-ui",
+				"version": "2.0.0"
+			},
+			"node_modules/@test-muaddib/web": {"resolved": "apps/web", "link": true},
+			"node_modules/test-muaddib-ui": {"resolved": "packages/ui", "link": true},
+			"node_modules/test-muaddib-shared": {"version": "1.0.0"},
+			"apps/web/node_modules/test-muaddib-shared": {"version": "1.2.0"}
+		}
+	}`
+
+	packages, err := ParsePackageLock(content, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, pkg := range packages {
+		found[pkg.Name+"@"+pkg.Version] = true
+	}
+
+	for _, key := range []string{"test-muaddib-shared@1.0.0", "test-muaddib-shared@1.2.0"} {
+		if !found[key] {
+			t.Errorf("expected %s to be extracted, got %v", key, found)
+		}
+	}
+	if len(packages) != 2 {
+		t.Errorf("expected workspace members and links to be skipped, got %v", found)
+	}
+}
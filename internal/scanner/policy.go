@@ -0,0 +1,83 @@
+package scanner
+
+import "fmt"
+
+// FailOn selects which finding categories fail a scan
+type FailOn string
+
+const (
+	// FailOnNone never fails the scan because of findings
+	FailOnNone FailOn = "none"
+	// FailOnVuln fails on vulnerable packages
+	FailOnVuln FailOn = "vuln"
+	// FailOnMalicious fails on malicious workflows, scripts, branches, and migration repos
+	FailOnMalicious FailOn = "malicious"
+	// FailOnAny fails on any vulnerable or malicious finding
+	FailOnAny FailOn = "any"
+)
+
+// ParseFailOn validates a --fail-on value
+func ParseFailOn(name string) (FailOn, error) {
+	switch FailOn(name) {
+	case FailOnNone, FailOnVuln, FailOnMalicious, FailOnAny:
+		return FailOn(name), nil
+	default:
+		return "", fmt.Errorf("unknown --fail-on value %q (expected none, vuln, malicious, or any)", name)
+	}
+}
+
+// Matches reports whether findings of the category are selected
+func (f FailOn) Matches(category FindingCategory) bool {
+	switch category {
+	case CategoryVulnerablePackage:
+		return f == FailOnVuln || f == FailOnAny
+	case CategoryMaliciousWorkflow, CategoryMaliciousScript, CategoryMaliciousBranch, CategoryMaliciousRepo:
+		return f == FailOnMalicious || f == FailOnAny
+	default:
+		// Advisories are heuristics for review and never fail a scan
+		return false
+	}
+}
+
+// FailPolicy decides whether a scan's findings should fail it
+type FailPolicy struct {
+	FailOn       FailOn
+	Threshold    int  // The scan fails only when qualifying findings exceed this count
+	IncludeKnown bool // Count findings present in the baseline
+}
+
+// Qualifies reports whether a finding counts towards failing the scan.
+// Known baseline findings and low-confidence findings are excluded by default.
+func (p FailPolicy) Qualifies(f *Finding) bool {
+	if !p.FailOn.Matches(f.Category) {
+		return false
+	}
+	if f.Known && !p.IncludeKnown {
+		return false
+	}
+	return f.Confidence != ConfidenceLow
+}
+
+// Count returns the number of qualifying findings across all results
+func (p FailPolicy) Count(results []*RepoScanResult, orgResult *OrgScanResult) int {
+	var findings []*Finding
+	for _, result := range results {
+		findings = append(findings, result.Findings()...)
+	}
+	if orgResult != nil {
+		findings = append(findings, orgResult.Findings()...)
+	}
+
+	count := 0
+	for _, f := range findings {
+		if p.Qualifies(f) {
+			count++
+		}
+	}
+	return count
+}
+
+// Fails reports whether the qualifying count exceeds the threshold
+func (p FailPolicy) Fails(count int) bool {
+	return p.FailOn != FailOnNone && count > p.Threshold
+}
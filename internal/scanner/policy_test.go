@@ -0,0 +1,102 @@
+package scanner
+
+import "testing"
+
+// newPolicyTestResults creates one vulnerable package, one malicious script, and one advisory
+func newPolicyTestResults() []*RepoScanResult {
+	result := newBaselineTestResult()
+	result.VulnerablePackages = result.VulnerablePackages[:1]
+	result.VulnerablePackages[0].Confidence = ConfidenceHigh
+	result.MaliciousBranches = nil
+	result.MaliciousScripts = []*MaliciousScript{
+		{RepoName: "test-org/test-repo", FilePath: "package.json", ScriptName: "postinstall", Pattern: "node bundle.js"},
+	}
+	result.Advisories = []*Advisory{
+		{RepoName: "test-org/test-repo", FilePath: "package.json", Kind: AdvisorySuspiciousFilesField},
+	}
+	return []*RepoScanResult{result}
+}
+
+func TestFailPolicy_CountByCategory(t *testing.T) {
+	testCases := []struct {
+		failOn   FailOn
+		expected int
+	}{
+		{FailOnNone, 0},
+		{FailOnVuln, 1},
+		{FailOnMalicious, 1},
+		{FailOnAny, 2},
+	}
+
+	for _, tc := range testCases {
+		policy := FailPolicy{FailOn: tc.failOn}
+		if got := policy.Count(newPolicyTestResults(), nil); got != tc.expected {
+			t.Errorf("fail-on %s: expected %d qualifying findings, got %d", tc.failOn, tc.expected, got)
+		}
+	}
+}
+
+func TestFailPolicy_ExcludesKnownAndLowConfidence(t *testing.T) {
+	results := newPolicyTestResults()
+	results[0].VulnerablePackages[0].Confidence = ConfidenceLow
+	results[0].MaliciousScripts[0].Known = true
+
+	policy := FailPolicy{FailOn: FailOnAny}
+	if got := policy.Count(results, nil); got != 0 {
+		t.Errorf("expected known and low-confidence findings to be excluded, got %d", got)
+	}
+
+	policy.IncludeKnown = true
+	if got := policy.Count(results, nil); got != 1 {
+		t.Errorf("expected known finding to count with IncludeKnown, got %d", got)
+	}
+}
+
+func TestFailPolicy_ExcludesRemoteCodeExecutionWorkflows(t *testing.T) {
+	results := []*RepoScanResult{{
+		RepoName: "test-org/test-repo",
+		MaliciousWorkflows: []*MaliciousWorkflow{
+			{RepoName: "test-org/test-repo", FilePath: ".github/workflows/ci.yml", Pattern: "remote script piped to a shell", Kind: WorkflowKindRemoteCodeExecution},
+			{RepoName: "test-org/test-repo", FilePath: ".github/workflows/ci.yml", Pattern: MaliciousWorkflowPattern, Kind: WorkflowKindWormPattern},
+		},
+	}}
+
+	policy := FailPolicy{FailOn: FailOnMalicious}
+	if got := policy.Count(results, nil); got != 1 {
+		t.Errorf("expected only the worm pattern to count, got %d", got)
+	}
+}
+
+func TestFailPolicy_Threshold(t *testing.T) {
+	policy := FailPolicy{FailOn: FailOnAny, Threshold: 2}
+
+	testCases := []struct {
+		count int
+		fails bool
+	}{
+		{1, false}, // below
+		{2, false}, // at
+		{3, true},  // above
+	}
+
+	for _, tc := range testCases {
+		if got := policy.Fails(tc.count); got != tc.fails {
+			t.Errorf("count %d with threshold 2: expected fails=%v, got %v", tc.count, tc.fails, got)
+		}
+	}
+
+	if (FailPolicy{FailOn: FailOnNone}).Fails(100) {
+		t.Error("expected fail-on none never to fail")
+	}
+}
+
+func TestParseFailOn(t *testing.T) {
+	for _, name := range []string{"none", "vuln", "malicious", "any"} {
+		if _, err := ParseFailOn(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	if _, err := ParseFailOn("all"); err == nil {
+		t.Error("expected error for unknown value")
+	}
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"sort"
+	"sync"
+)
+
+// Results aggregates scan results from concurrent repository scans.
+// All methods are safe for concurrent use.
+type Results struct {
+	mu        sync.Mutex
+	repos     []*RepoScanResult
+	orgResult OrgScanResult
+}
+
+// NewResults creates an empty results aggregator
+func NewResults() *Results {
+	return &Results{}
+}
+
+// AddRepoResult records the result of scanning a single repository
+func (r *Results) AddRepoResult(result *RepoScanResult) {
+	if result == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.repos = append(r.repos, result)
+}
+
+// AddMaliciousRepo records a malicious migration repository
+func (r *Results) AddMaliciousRepo(repo *MaliciousRepo) {
+	if repo == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orgResult.MaliciousRepos = append(r.orgResult.MaliciousRepos, repo)
+}
+
+// AddOrgResult merges org-level results, including baseline counts
+func (r *Results) AddOrgResult(orgResult *OrgScanResult) {
+	if orgResult == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orgResult.MaliciousRepos = append(r.orgResult.MaliciousRepos, orgResult.MaliciousRepos...)
+	r.orgResult.KnownFindings += orgResult.KnownFindings
+}
+
+// Snapshot returns a consistent copy of the aggregated results for reporting.
+// Repository results are sorted by name, as concurrent scans finish in any
+// order. The returned slices are not affected by subsequent adds.
+func (r *Results) Snapshot() ([]*RepoScanResult, *OrgScanResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repos := make([]*RepoScanResult, len(r.repos))
+	copy(repos, r.repos)
+	sort.SliceStable(repos, func(i, j int) bool {
+		return repos[i].RepoName < repos[j].RepoName
+	})
+
+	orgResult := r.orgResult
+	orgResult.MaliciousRepos = make([]*MaliciousRepo, len(r.orgResult.MaliciousRepos))
+	copy(orgResult.MaliciousRepos, r.orgResult.MaliciousRepos)
+
+	return repos, &orgResult
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestResults_ConcurrentAdds(t *testing.T) {
+	results := NewResults()
+
+	const workers = 16
+	const perWorker = 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				results.AddRepoResult(&RepoScanResult{RepoName: fmt.Sprintf("test-org/repo-%d-%d", w, i)})
+				results.AddMaliciousRepo(&MaliciousRepo{RepoName: fmt.Sprintf("test-org/repo-%d-%d-migration", w, i)})
+				// Snapshot concurrently with writers to exercise the lock
+				results.Snapshot()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	repos, orgResult := results.Snapshot()
+	if len(repos) != workers*perWorker {
+		t.Errorf("expected %d repo results, got %d", workers*perWorker, len(repos))
+	}
+	if len(orgResult.MaliciousRepos) != workers*perWorker {
+		t.Errorf("expected %d malicious repos, got %d", workers*perWorker, len(orgResult.MaliciousRepos))
+	}
+}
+
+func TestResults_SnapshotIsIsolated(t *testing.T) {
+	results := NewResults()
+	results.AddRepoResult(&RepoScanResult{RepoName: "test-org/a"})
+	results.AddOrgResult(&OrgScanResult{
+		MaliciousRepos: []*MaliciousRepo{{RepoName: "test-org/a-migration"}},
+		KnownFindings:  2,
+	})
+
+	repos, orgResult := results.Snapshot()
+
+	results.AddRepoResult(&RepoScanResult{RepoName: "test-org/b"})
+	results.AddMaliciousRepo(&MaliciousRepo{RepoName: "test-org/b-migration"})
+
+	if len(repos) != 1 {
+		t.Errorf("expected snapshot to keep 1 repo result, got %d", len(repos))
+	}
+	if len(orgResult.MaliciousRepos) != 1 {
+		t.Errorf("expected snapshot to keep 1 malicious repo, got %d", len(orgResult.MaliciousRepos))
+	}
+	if orgResult.KnownFindings != 2 {
+		t.Errorf("expected 2 known findings, got %d", orgResult.KnownFindings)
+	}
+}
+
+func TestResults_IgnoresNil(t *testing.T) {
+	results := NewResults()
+	results.AddRepoResult(nil)
+	results.AddMaliciousRepo(nil)
+	results.AddOrgResult(nil)
+
+	repos, orgResult := results.Snapshot()
+	if len(repos) != 0 || len(orgResult.MaliciousRepos) != 0 {
+		t.Error("expected nil adds to be ignored")
+	}
+}
+
+func TestResults_SnapshotSortsByRepoName(t *testing.T) {
+	results := NewResults()
+	for _, name := range []string{"test-org/c", "test-org/a", "test-org/b"} {
+		results.AddRepoResult(&RepoScanResult{RepoName: name})
+	}
+
+	repos, _ := results.Snapshot()
+	for i, want := range []string{"test-org/a", "test-org/b", "test-org/c"} {
+		if repos[i].RepoName != want {
+			t.Errorf("expected %s at position %d, got %s", want, i, repos[i].RepoName)
+		}
+	}
+}
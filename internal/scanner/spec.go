@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SpecType is the kind of dependency specifier a package.json declares
+type SpecType string
+
+const (
+	// SpecRegistry is a version or range resolved from the npm registry
+	SpecRegistry SpecType = "registry"
+	// SpecGit is a git repository, e.g. "git+https://...", "github:org/repo#tag", or "org/repo"
+	SpecGit SpecType = "git"
+	// SpecFile is a local path, e.g. "file:../local" or "link:../local"
+	SpecFile SpecType = "file"
+	// SpecURL is a tarball URL, e.g. "https://example.com/pkg.tgz"
+	SpecURL SpecType = "url"
+	// SpecWorkspace is a workspace member, e.g. "workspace:*"
+	SpecWorkspace SpecType = "workspace"
+)
+
+// gitSpecPrefixes are the prefixes npm, Yarn, and pnpm read as git repositories
+var gitSpecPrefixes = []string{"git:", "git+", "github:", "gitlab:", "bitbucket:", "gist:"}
+
+// fileSpecPrefixes are the prefixes of local path specifiers
+var fileSpecPrefixes = []string{"file:", "link:", "portal:", "./", "../", "/", "~/"}
+
+// githubShorthand matches the "org/repo" and "org/repo#ref" shorthand for a
+// GitHub repository. A scoped package name starts with "@", so never matches.
+var githubShorthand = regexp.MustCompile(`^[\w.-]+/[\w.-]+(#\S*)?$`)
+
+// ParseSpecType classifies a package.json dependency specifier. Anything
+// not recognised as git, a path, a URL, or a workspace is a registry range.
+func ParseSpecType(spec string) SpecType {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(spec, "workspace:"):
+		return SpecWorkspace
+	case hasAnyPrefix(spec, gitSpecPrefixes):
+		return SpecGit
+	case hasAnyPrefix(spec, fileSpecPrefixes):
+		return SpecFile
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return SpecURL
+	case githubShorthand.MatchString(spec):
+		return SpecGit
+	default:
+		return SpecRegistry
+	}
+}
+
+// hasAnyPrefix checks if s starts with any of the prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRegistry checks if the package is installed from the npm registry, so
+// its version can be checked against the IOC list. Lockfile entries carry
+// no SpecType and are treated as registry packages.
+func (p *Package) FromRegistry() bool {
+	return p.SpecType == "" || p.SpecType == SpecRegistry
+}
+
+// Unverifiable checks if the package comes from a git repository or URL,
+// whose content can change without a new version and so cannot be checked
+// against the IOC list. Local paths and workspace members are part of the
+// repository being scanned.
+func (p *Package) Unverifiable() bool {
+	return p.SpecType == SpecGit || p.SpecType == SpecURL
+}
+
+// declaredPackage creates a package for a package.json dependency. Registry
+// ranges are cleaned to their base version; other specifiers are kept as
+// written, as they name no version.
+func declaredPackage(name, spec string) *Package {
+	pkg := &Package{Name: name, Source: "direct", Declared: true, SpecType: ParseSpecType(spec)}
+	if pkg.FromRegistry() {
+		pkg.Version = cleanVersion(spec)
+	} else {
+		pkg.Version = strings.TrimSpace(spec)
+	}
+	return pkg
+}
+
+// UnverifiableDependency is a package.json dependency installed from a git
+// repository or URL, which is not checked against the IOC list
+type UnverifiableDependency struct {
+	Name     string
+	Spec     string
+	SpecType SpecType
+	FilePath string
+}
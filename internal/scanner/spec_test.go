@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestParseSpecType(t *testing.T) {
+	tests := []struct {
+		spec string
+		want SpecType
+	}{
+		{"1.0.0", SpecRegistry},
+		{"^2.0.0", SpecRegistry},
+		{">=1.0.0 <2.0.0", SpecRegistry},
+		{"latest", SpecRegistry},
+		{"*", SpecRegistry},
+		{"npm:test-muaddib-pkg@1.0.0", SpecRegistry},
+		{"git+https://github.com/test-org/test-repo.git", SpecGit},
+		{"git+ssh://git@github.com/test-org/test-repo.git#v1.0.0", SpecGit},
+		{"git://github.com/test-org/test-repo.git", SpecGit},
+		{"github:test-org/test-repo#v1.0.0", SpecGit},
+		{"gitlab:test-org/test-repo", SpecGit},
+		{"bitbucket:test-org/test-repo", SpecGit},
+		{"test-org/test-repo", SpecGit},
+		{"test-org/test-repo#semver:^1.0.0", SpecGit},
+		{"file:../local", SpecFile},
+		{"link:../local", SpecFile},
+		{"portal:../local", SpecFile},
+		{"../local", SpecFile},
+		{"./vendor/test-muaddib-pkg.tgz", SpecFile},
+		{"https://example.com/test-muaddib-pkg-1.0.0.tgz", SpecURL},
+		{"http://example.com/test-muaddib-pkg-1.0.0.tgz", SpecURL},
+		{"workspace:*", SpecWorkspace},
+		{"workspace:^1.0.0", SpecWorkspace},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSpecType(tt.spec); got != tt.want {
+			t.Errorf("ParseSpecType(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePackageJSON_KeepsNonRegistrySpecifiers(t *testing.T) {
+	content := `{
+		"dependencies": {
+			"test-muaddib-registry": "^1.2.0",
+			"test-muaddib-git": "github:test-org/test-repo#v1.0.0",
+			"test-muaddib-file": "file:../local"
+		}
+	}`
+
+	packages, err := ParsePackageJSON(content, false)
+	if err != nil {
+		t.Fatalf("ParsePackageJSON failed: %v", err)
+	}
+
+	want := map[string]struct {
+		version  string
+		specType SpecType
+	}{
+		"test-muaddib-registry": {"1.2.0", SpecRegistry},
+		"test-muaddib-git":      {"github:test-org/test-repo#v1.0.0", SpecGit},
+		"test-muaddib-file":     {"file:../local", SpecFile},
+	}
+	if len(packages) != len(want) {
+		t.Fatalf("expected %d packages, got %d", len(want), len(packages))
+	}
+	for _, pkg := range packages {
+		if w := want[pkg.Name]; pkg.Version != w.version || pkg.SpecType != w.specType {
+			t.Errorf("%s: expected %s %q, got %s %q", pkg.Name, w.specType, w.version, pkg.SpecType, pkg.Version)
+		}
+	}
+}
+
+func TestScanFiles_SkipsNonRegistryDependencies(t *testing.T) {
+	// A scope-wide IOC matches any version, so only the specifier type keeps
+	// git, URL, and path dependencies from matching it
+	db, err := vuln.ParseCSVForTest(strings.NewReader(`package_name,package_versions,sources
+@test-muaddib/*,*,"test"`))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+
+	result := NewScanner(db, true).ScanFiles([]*github.PackageFile{{
+		RepoName: "test-org/test-repo",
+		Path:     "package.json",
+		Content: `{"dependencies": {
+			"@test-muaddib/registry": "1.0.0",
+			"@test-muaddib/git": "git+https://github.com/test-org/test-repo.git",
+			"@test-muaddib/url": "https://example.com/test-muaddib-url.tgz",
+			"@test-muaddib/file": "file:../local",
+			"@test-muaddib/workspace": "workspace:*"
+		}}`,
+	}})
+
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].Package.Name != "@test-muaddib/registry" {
+		t.Fatalf("expected only the registry dependency to be checked, got %+v", result.VulnerablePackages)
+	}
+	if len(result.UnverifiableDeps) != 2 {
+		t.Fatalf("expected the git and URL dependencies to be unverifiable, got %+v", result.UnverifiableDeps)
+	}
+	for _, dep := range result.UnverifiableDeps {
+		if dep.SpecType != SpecGit && dep.SpecType != SpecURL {
+			t.Errorf("expected only git and URL dependencies, got %s %s", dep.SpecType, dep.Name)
+		}
+		if dep.FilePath != "package.json" {
+			t.Errorf("expected %s to be found in package.json, got %s", dep.Name, dep.FilePath)
+		}
+	}
+}
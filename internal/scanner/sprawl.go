@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// VersionSprawl is a package that resolves to many distinct versions across a
+// repository's lockfiles. It is informational rather than a vulnerability, but
+// a single odd version among many can point to a targeted injection.
+type VersionSprawl struct {
+	RepoName    string
+	PackageName string
+	Versions    []string // Distinct resolved versions, in semver order
+}
+
+// checkVersionSprawl lists packages whose lockfile entries resolve to more than
+// the configured number of distinct versions, given the packages parseFiles
+// returned for files. Declared ranges and override pins are skipped because
+// they are not resolved versions.
+func (s *Scanner) checkVersionSprawl(files []*github.PackageFile, parsed [][]*Package) []*VersionSprawl {
+	if s.sprawlThreshold <= 0 {
+		return nil
+	}
+
+	versions := make(map[string]map[string]bool)
+	repoName := ""
+	for i, packages := range parsed {
+		if packages == nil {
+			continue
+		}
+		repoName = files[i].RepoName
+		for _, pkg := range packages {
+			if pkg.Declared || pkg.Source == "override" {
+				continue
+			}
+			if versions[pkg.Name] == nil {
+				versions[pkg.Name] = make(map[string]bool)
+			}
+			versions[pkg.Name][pkg.Version] = true
+		}
+	}
+
+	var sprawl []*VersionSprawl
+	for name, set := range versions {
+		if len(set) <= s.sprawlThreshold {
+			continue
+		}
+		vs := &VersionSprawl{RepoName: repoName, PackageName: name}
+		for version := range set {
+			vs.Versions = append(vs.Versions, version)
+		}
+		slices.SortFunc(vs.Versions, vuln.CompareVersions)
+		sprawl = append(sprawl, vs)
+	}
+
+	sort.Slice(sprawl, func(i, j int) bool { return sprawl[i].PackageName < sprawl[j].PackageName })
+	return sprawl
+}
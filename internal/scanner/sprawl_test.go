@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+// sprawlLockfile has test-muaddib-sprawl at four versions and test-muaddib-tidy at two
+const sprawlLockfile = `{
+	"lockfileVersion": 3,
+	"packages": {
+		"": {"name": "test-project"},
+		"node_modules/test-muaddib-sprawl": {"version": "1.0.0"},
+		"node_modules/a/node_modules/test-muaddib-sprawl": {"version": "1.1.0"},
+		"node_modules/b/node_modules/test-muaddib-sprawl": {"version": "2.0.0"},
+		"node_modules/c/node_modules/test-muaddib-sprawl": {"version": "2.0.1"},
+		"node_modules/d/node_modules/test-muaddib-sprawl": {"version": "2.0.1"},
+		"node_modules/test-muaddib-tidy": {"version": "1.0.0"},
+		"node_modules/a/node_modules/test-muaddib-tidy": {"version": "1.0.1"}
+	}
+}`
+
+func TestScanner_VersionSprawl(t *testing.T) {
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: sprawlLockfile},
+		// package.json ranges and override pins are not resolved versions and must not count
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"dependencies": {"test-muaddib-tidy": "^3.0.0"}, "overrides": {"test-muaddib-sprawl": "3.0.0"}}`},
+		{RepoName: "test-org/test-repo", Path: "web/package.json", Content: `{"dependencies": {"test-muaddib-tidy": "^4.0.0"}}`},
+	}
+
+	testCases := []struct {
+		threshold int
+		expected  []string
+	}{
+		{0, nil},
+		{1, []string{"test-muaddib-sprawl", "test-muaddib-tidy"}},
+		{2, []string{"test-muaddib-sprawl"}},
+		{3, []string{"test-muaddib-sprawl"}},
+		{4, nil},
+	}
+
+	for _, tc := range testCases {
+		scanner := NewScanner(vuln.NewVulnDB(), true, WithVersionSprawl(tc.threshold))
+
+		sprawl := scanner.ScanFiles(files).VersionSprawl
+
+		var names []string
+		for _, vs := range sprawl {
+			names = append(names, vs.PackageName)
+		}
+		if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("threshold %d: expected %v, got %v", tc.threshold, tc.expected, names)
+		}
+	}
+}
+
+func TestScanner_VersionSprawl_CountsDistinctVersions(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithVersionSprawl(3))
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: sprawlLockfile},
+	}
+
+	result := scanner.ScanFiles(files)
+
+	if len(result.VersionSprawl) != 1 {
+		t.Fatalf("expected 1 sprawling package, got %d", len(result.VersionSprawl))
+	}
+	vs := result.VersionSprawl[0]
+	if got := strings.Join(vs.Versions, ","); got != "1.0.0,1.1.0,2.0.0,2.0.1" {
+		t.Errorf("expected 4 distinct sorted versions, got %s", got)
+	}
+	if vs.RepoName != "test-org/test-repo" {
+		t.Errorf("expected repo name to be set, got %q", vs.RepoName)
+	}
+}
+
+func TestScanner_VersionSprawl_SortsVersionsBySemver(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithVersionSprawl(2))
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: `{
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/test-muaddib-sprawl": {"version": "1.10.0"},
+				"node_modules/a/node_modules/test-muaddib-sprawl": {"version": "1.9.0"},
+				"node_modules/b/node_modules/test-muaddib-sprawl": {"version": "1.2.0"}
+			}
+		}`},
+	}
+
+	sprawl := scanner.ScanFiles(files).VersionSprawl
+
+	if len(sprawl) != 1 {
+		t.Fatalf("expected 1 sprawling package, got %d", len(sprawl))
+	}
+	if got := strings.Join(sprawl[0].Versions, ","); got != "1.2.0,1.9.0,1.10.0" {
+		t.Errorf("expected versions in semver order, got %s", got)
+	}
+}
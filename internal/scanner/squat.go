@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// AdvisoryPackageNameSquat flags a repository whose root package.json
+// publishes a package named like a popular one
+const AdvisoryPackageNameSquat = "PackageNameSquat"
+
+// popularPackages are widely depended-upon npm packages that typosquats imitate
+var popularPackages = []string{
+	"angular", "axios", "babel-cli", "bluebird", "body-parser", "chalk",
+	"classnames", "commander", "cross-env", "dayjs", "debug", "dotenv",
+	"eslint", "express", "glob", "jest", "jquery", "js-yaml", "jsonwebtoken",
+	"lodash", "minimist", "mocha", "moment", "mongoose", "next", "node-fetch",
+	"nodemon", "preact", "prettier", "react", "react-dom", "redux", "request",
+	"rimraf", "rxjs", "semver", "socket.io", "styled-components", "tslib",
+	"typescript", "uuid", "vue", "vuex", "webpack", "yargs",
+}
+
+// minSquatNameLength keeps short popular names, where a single edit yields an
+// unrelated word such as "next" and "text", out of the edit distance check
+const minSquatNameLength = 5
+
+// CheckPackageNameSquat flags a root package.json whose name resembles a
+// popular package, such as "lodahs" or "crossenv", or reuses a popular
+// package's name in a repository named something else. Such repositories may
+// host a typosquat for publishing. Private packages cannot be published and
+// scoped names cannot collide with unscoped ones, so both are skipped.
+func (s *Scanner) CheckPackageNameSquat(files []*github.PackageFile) []*Advisory {
+	for _, file := range files {
+		if file.Path != "package.json" {
+			continue
+		}
+
+		var pkg struct {
+			Name    string `json:"name"`
+			Private bool   `json:"private"`
+		}
+		if err := json.Unmarshal([]byte(file.Content), &pkg); err != nil || pkg.Private {
+			return nil
+		}
+
+		detail := classifyPackageName(strings.ToLower(pkg.Name), path.Base(file.RepoName))
+		if detail == "" {
+			return nil
+		}
+		return []*Advisory{{
+			Kind:     AdvisoryPackageNameSquat,
+			FilePath: file.Path,
+			RepoName: file.RepoName,
+			Detail:   detail,
+		}}
+	}
+
+	return nil
+}
+
+// classifyPackageName returns why a package name in the named repository
+// looks like a squat, or "" if it does not
+func classifyPackageName(name, repoName string) string {
+	if name == "" || strings.HasPrefix(name, "@") {
+		return ""
+	}
+
+	if slices.Contains(popularPackages, name) {
+		if strings.EqualFold(repoName, name) {
+			return ""
+		}
+		return fmt.Sprintf("package name %q matches a popular package but the repository is named %q", name, repoName)
+	}
+
+	if popular := resemblesPopularPackage(name); popular != "" {
+		return fmt.Sprintf("package name %q resembles popular package %q (repository %q)", name, popular, repoName)
+	}
+
+	return ""
+}
+
+// resemblesPopularPackage returns the popular package a name imitates, by a
+// single edit or by different separators, or "" if none
+func resemblesPopularPackage(name string) string {
+	for _, popular := range popularPackages {
+		if stripSeparators(name) == stripSeparators(popular) {
+			return popular
+		}
+		if len(popular) >= minSquatNameLength && editDistance(name, popular) == 1 {
+			return popular
+		}
+	}
+	return ""
+}
+
+// stripSeparators removes the "-", "_", and "." separators from a package name
+func stripSeparators(name string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(name)
+}
+
+// editDistance returns the Damerau-Levenshtein distance between two names,
+// counting an adjacent transposition as a single edit
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
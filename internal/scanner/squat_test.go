@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestScanner_CheckPackageNameSquat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		repo     string
+		manifest string
+		want     string // Substring of the advisory detail, or "" for none
+	}{
+		{"transposed popular name", "test-org/test-utils", `{"name": "lodahs"}`, `resembles popular package "lodash"`},
+		{"extra character", "test-org/test-utils", `{"name": "expresss"}`, `resembles popular package "express"`},
+		{"missing separator", "test-org/crossenv", `{"name": "crossenv"}`, `resembles popular package "cross-env"`},
+		{"popular name in other repo", "test-org/test-utils", `{"name": "axios"}`, `matches a popular package`},
+		{"popular name in its own repo", "test-org/axios", `{"name": "axios"}`, ""},
+		{"unrelated name", "test-org/test-repo", `{"name": "test-muaddib-pkg"}`, ""},
+		{"scoped name", "test-org/test-repo", `{"name": "@test-org/lodash"}`, ""},
+		{"private package", "test-org/test-repo", `{"name": "lodahs", "private": true}`, ""},
+		{"short popular name", "test-org/test-repo", `{"name": "text"}`, ""},
+	}
+
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithDeepInspect(true))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			advisories := scanner.CheckPackageNameSquat([]*github.PackageFile{
+				{RepoName: tc.repo, Path: "package.json", Content: tc.manifest},
+			})
+
+			if tc.want == "" {
+				if len(advisories) != 0 {
+					t.Errorf("expected no advisories, got %q", advisories[0].Detail)
+				}
+				return
+			}
+			if len(advisories) != 1 {
+				t.Fatalf("expected 1 advisory, got %d", len(advisories))
+			}
+			if advisories[0].Kind != AdvisoryPackageNameSquat {
+				t.Errorf("expected kind %s, got %s", AdvisoryPackageNameSquat, advisories[0].Kind)
+			}
+			if !strings.Contains(advisories[0].Detail, tc.want) {
+				t.Errorf("expected detail containing %q, got %q", tc.want, advisories[0].Detail)
+			}
+		})
+	}
+}
+
+func TestScanner_CheckPackageNameSquat_RootOnly(t *testing.T) {
+	scanner := NewScanner(vuln.NewVulnDB(), true, WithDeepInspect(true))
+
+	advisories := scanner.CheckPackageNameSquat([]*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "packages/lodahs/package.json", Content: `{"name": "lodahs"}`},
+	})
+
+	if len(advisories) != 0 {
+		t.Errorf("expected workspace packages to be skipped, got %d advisories", len(advisories))
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"lodash", "lodash", 0},
+		{"lodash", "lodahs", 1},
+		{"lodash", "lodas", 1},
+		{"lodash", "llodash", 1},
+		{"react", "preact", 1},
+		{"react", "redux", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
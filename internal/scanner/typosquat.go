@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AdvisoryTyposquatDependency flags a dependency named like a popular package
+const AdvisoryTyposquatDependency = "TyposquatDependency"
+
+// knownGoodPackages are the most depended-upon npm packages. Dependencies
+// named within a couple of edits of one are reported as possible typosquats,
+// and dependencies named exactly like one are never reported. Popular names
+// that are themselves a single edit apart, such as "eslint" and "tslint", are
+// both listed so neither is flagged as the other.
+var knownGoodPackages = mergePackageLists(popularPackages, []string{
+	"ajv", "ansi-regex", "ansi-styles", "argparse", "async",
+	"autoprefixer", "aws-sdk", "babel-core", "babel-loader", "bcrypt",
+	"bcryptjs", "buffer", "camelcase", "cheerio", "chokidar", "color",
+	"colord", "colors", "compression", "concurrently", "cookie",
+	"cookie-parser", "core-js", "cors", "css-loader", "date-fns",
+	"deepmerge", "del", "ejs", "electron", "enquirer", "esbuild",
+	"eslint-plugin-react", "event-stream", "eventemitter3", "execa",
+	"fast-glob", "fs-extra", "graphql", "handlebars", "helmet",
+	"http-proxy", "immer", "immutable", "inquirer", "joi", "jsdom",
+	"json5", "karma", "koa", "less", "lodash.merge", "lru-cache",
+	"marked", "mime", "mime-types", "minimatch", "mkdirp",
+	"moment-timezone", "morgan", "ms", "mssql", "multer", "mysql",
+	"mysql2", "nanoid", "node-sass", "nodemailer", "object-assign", "ora",
+	"passport", "pg", "postcss", "postcss-loader", "prop-types", "q",
+	"qs", "ramda", "react-redux", "react-router", "react-router-dom",
+	"readable-stream", "redis", "regenerator-runtime", "sass",
+	"sass-loader", "shelljs", "sinon", "source-map", "source-map-support",
+	"style-loader", "superagent", "supports-color", "svelte",
+	"tailwindcss", "tape", "through2", "ts-loader", "ts-node", "tslint",
+	"underscore", "validator", "vite", "vue-router", "webpack-cli",
+	"webpack-dev-server", "winston", "ws", "xml2js", "yaml", "yarn",
+	"zod",
+})
+
+// minTyposquatDistanceLength is the length from which a popular name allows
+// two edits rather than one; shorter names are too close to ordinary words
+const minTyposquatDistanceLength = 10
+
+// SuspiciousPackage is a dependency whose name resembles a popular package,
+// as a typosquat would. It is heuristic and reported as an advisory.
+type SuspiciousPackage struct {
+	Package     *Package
+	FilePath    string
+	RepoName    string
+	LookalikeOf string // Popular package the name imitates
+	Known       bool   // Present in the baseline
+}
+
+// Detail describes why the package is suspicious
+func (sp *SuspiciousPackage) Detail() string {
+	return fmt.Sprintf("dependency %q resembles popular package %q", sp.Package.Name, sp.LookalikeOf)
+}
+
+// checkTyposquat flags a dependency named like a popular package. Each name
+// is reported once per repository, tracked in flagged.
+func (s *Scanner) checkTyposquat(pkg *Package, filePath, repoName string, flagged map[string]bool) *SuspiciousPackage {
+	if !s.detectTyposquat || flagged[pkg.Name] {
+		return nil
+	}
+	lookalike := typosquatTarget(pkg.Name)
+	if lookalike == "" {
+		return nil
+	}
+	flagged[pkg.Name] = true
+	return &SuspiciousPackage{Package: pkg, FilePath: filePath, RepoName: repoName, LookalikeOf: lookalike}
+}
+
+// typosquatTarget returns the known-good package a dependency name imitates,
+// by different separators or by one edit (two for long names), or "" if none.
+// Known-good names themselves and scoped names are never flagged.
+func typosquatTarget(name string) string {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "@") || slices.Contains(knownGoodPackages, name) {
+		return ""
+	}
+
+	for _, popular := range knownGoodPackages {
+		if strings.HasPrefix(popular, "@") {
+			continue
+		}
+		if stripSeparators(name) == stripSeparators(popular) {
+			return popular
+		}
+		if distance := editDistance(name, popular); distance <= maxTyposquatDistance(popular) {
+			return popular
+		}
+	}
+	return ""
+}
+
+// maxTyposquatDistance returns how many edits from a popular name are flagged
+func maxTyposquatDistance(popular string) int {
+	switch {
+	case len(popular) >= minTyposquatDistanceLength:
+		return 2
+	case len(popular) >= minSquatNameLength:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// mergePackageLists combines package lists, dropping duplicates
+func mergePackageLists(lists ...[]string) []string {
+	var merged []string
+	for _, list := range lists {
+		for _, name := range list {
+			if !slices.Contains(merged, name) {
+				merged = append(merged, name)
+			}
+		}
+	}
+	return merged
+}
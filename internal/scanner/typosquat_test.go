@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestTyposquatTarget(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"crossenv", "cross-env"},
+		{"lodahs", "lodash"},
+		{"expres", "express"},
+		{"axois", "axios"},
+		{"Lodahs", "lodash"},
+		{"webpack-dev-sever", "webpack-dev-server"},
+		// Known-good names are never flagged, even when close to another
+		{"lodash", ""},
+		{"tslint", ""},
+		{"ts-loader", ""},
+		{"mysql2", ""},
+		{"ts-jest", ""},
+		{"react-is", ""},
+		// Scoped and unrelated names
+		{"@test-org/lodahs", ""},
+		{"test-muaddib-pkg", ""},
+		// Short names allow no edits
+		{"ms2", ""},
+	}
+
+	for _, tt := range tests {
+		if got := typosquatTarget(tt.name); got != tt.want {
+			t.Errorf("typosquatTarget(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanFiles_DetectsTyposquats(t *testing.T) {
+	files := []*github.PackageFile{
+		{RepoName: "test-org/test-repo", Path: "package.json", Content: `{"dependencies": {"crossenv": "^1.0.0", "lodash": "^4.17.21"}}`},
+		{RepoName: "test-org/test-repo", Path: "package-lock.json", Content: `{
+			"lockfileVersion": 3,
+			"packages": {
+				"node_modules/crossenv": {"version": "1.0.0"},
+				"node_modules/lodash": {"version": "4.17.21"}
+			}
+		}`},
+	}
+
+	if result := NewScanner(vuln.NewVulnDB(), true).ScanFiles(files); len(result.SuspiciousPackages) != 0 {
+		t.Errorf("expected no typosquat checks by default, got %d", len(result.SuspiciousPackages))
+	}
+
+	result := NewScanner(vuln.NewVulnDB(), true, WithTyposquatDetection(true)).ScanFiles(files)
+	if len(result.SuspiciousPackages) != 1 {
+		t.Fatalf("expected crossenv to be flagged once, got %d", len(result.SuspiciousPackages))
+	}
+	sp := result.SuspiciousPackages[0]
+	if sp.Package.Name != "crossenv" || sp.LookalikeOf != "cross-env" {
+		t.Errorf("expected crossenv to resemble cross-env, got %s resembling %s", sp.Package.Name, sp.LookalikeOf)
+	}
+
+	findings := result.Findings()
+	if len(findings) != 1 || findings[0].Category != CategoryAdvisory || findings[0].Severity != SeverityLow {
+		t.Fatalf("expected one low severity advisory finding, got %+v", findings)
+	}
+	if !strings.HasPrefix(findings[0].Detail, AdvisoryTyposquatDependency+": ") || !strings.Contains(findings[0].Detail, `"cross-env"`) {
+		t.Errorf("expected the detail to name the popular package, got %q", findings[0].Detail)
+	}
+}
@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"encoding/json"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rslater/muaddib/internal/github"
+)
+
+// Workspaces is the "workspaces" field of a package.json. npm and Yarn accept
+// a list of member directories or globs:
+//
+//	"workspaces": ["packages/*", "apps/web"]
+//
+// Yarn also accepts an object holding the list under "packages", alongside
+// options such as "nohoist":
+//
+//	"workspaces": {"packages": ["packages/*"], "nohoist": ["**/react-native"]}
+type Workspaces struct {
+	Packages []string
+	NoHoist  []string
+}
+
+// UnmarshalJSON decodes either form. A malformed field leaves the workspaces
+// empty rather than failing, so the manifest's dependencies are still parsed.
+func (w *Workspaces) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		w.Packages = list
+		return nil
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+		NoHoist  []string `json:"nohoist"`
+	}
+	if err := json.Unmarshal(data, &object); err == nil {
+		w.Packages, w.NoHoist = object.Packages, object.NoHoist
+	}
+	return nil
+}
+
+// WorkspaceMember is a package in an npm or Yarn workspace
+type WorkspaceMember struct {
+	Path string // Directory of the member's package.json
+	Name string // Package name declared by the member, if any
+}
+
+// attributeWorkspaces records on each vulnerable package the workspace members
+// whose package.json depends on it directly, so a finding in a shared
+// lockfile can be traced to the sub-packages it affects. members are the
+// paths of the members' package.json files among files.
+func attributeWorkspaces(packages []*VulnerablePackage, files []*github.PackageFile, members []string) {
+	if len(packages) == 0 || len(members) == 0 {
+		return
+	}
+
+	for _, file := range files {
+		if _, found := slices.BinarySearch(members, file.Path); !found {
+			continue
+		}
+		var pkg PackageJSON
+		if err := json.Unmarshal([]byte(file.Content), &pkg); err != nil {
+			continue
+		}
+		member := WorkspaceMember{Path: path.Dir(file.Path), Name: pkg.Name}
+		for _, vp := range packages {
+			if pkg.declares(vp.Package.Name) {
+				vp.Workspaces = append(vp.Workspaces, member)
+			}
+		}
+	}
+}
+
+// declares checks if the manifest lists the named package in any of its
+// dependency fields
+func (pkg *PackageJSON) declares(name string) bool {
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+		if _, ok := deps[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FindWorkspaceMembers returns the paths of the package.json files among
+// files that belong to workspaces declared by package.json manifests in the
+// same set. Member patterns are relative to the declaring manifest and may be
+// literal directories or globs; patterns starting with "!" exclude members.
+func FindWorkspaceMembers(files []*github.PackageFile) []string {
+	var manifests []string
+	for _, file := range files {
+		if path.Base(file.Path) == "package.json" {
+			manifests = append(manifests, file.Path)
+		}
+	}
+
+	members := make(map[string]bool)
+	for _, file := range files {
+		if path.Base(file.Path) != "package.json" {
+			continue
+		}
+		var pkg PackageJSON
+		if err := json.Unmarshal([]byte(file.Content), &pkg); err != nil || len(pkg.Workspaces.Packages) == 0 {
+			continue
+		}
+		for _, member := range matchWorkspaceMembers(path.Dir(file.Path), pkg.Workspaces.Packages, manifests) {
+			members[member] = true
+		}
+	}
+
+	paths := make([]string, 0, len(members))
+	for member := range members {
+		paths = append(paths, member)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// matchWorkspaceMembers returns the manifests whose directory matches the
+// workspace patterns declared in root
+func matchWorkspaceMembers(root string, patterns, manifests []string) []string {
+	var members []string
+	for _, manifest := range manifests {
+		dir := path.Dir(manifest)
+		if dir == root {
+			continue
+		}
+		rel, ok := relativeTo(root, dir)
+		if !ok {
+			continue
+		}
+
+		included := false
+		for _, pattern := range patterns {
+			exclude := strings.HasPrefix(pattern, "!")
+			pattern = cleanWorkspacePattern(strings.TrimPrefix(pattern, "!"))
+			if MatchPathGlob(pattern, rel) {
+				included = !exclude
+			}
+		}
+		if included {
+			members = append(members, manifest)
+		}
+	}
+	return members
+}
+
+// cleanWorkspacePattern normalises a workspace pattern such as "./packages/*/"
+// to the slash-separated form MatchPathGlob expects
+func cleanWorkspacePattern(pattern string) string {
+	return strings.TrimPrefix(path.Clean(strings.TrimSpace(pattern)), "./")
+}
+
+// relativeTo returns dir relative to root if it is inside it
+func relativeTo(root, dir string) (string, bool) {
+	if root == "." {
+		return dir, true
+	}
+	rel, found := strings.CutPrefix(dir, root+"/")
+	return rel, found
+}
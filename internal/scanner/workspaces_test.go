@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rslater/muaddib/internal/github"
+	"github.com/rslater/muaddib/internal/vuln"
+)
+
+func TestWorkspaces_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantMembers []string
+		wantNoHoist []string
+	}{
+		{"array form", `{"workspaces": ["packages/*", "apps/web"]}`, []string{"packages/*", "apps/web"}, nil},
+		{
+			"object form with nohoist",
+			`{"workspaces": {"packages": ["packages/*"], "nohoist": ["**/react-native", "**/react-native/**"]}}`,
+			[]string{"packages/*"},
+			[]string{"**/react-native", "**/react-native/**"},
+		},
+		{"object form without packages", `{"workspaces": {"nohoist": ["**/jest"]}}`, nil, []string{"**/jest"}},
+		{"malformed", `{"workspaces": "packages/*"}`, nil, nil},
+		{"absent", `{}`, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pkg PackageJSON
+			if err := json.Unmarshal([]byte(tt.content), &pkg); err != nil {
+				t.Fatalf("expected the manifest to parse, got %v", err)
+			}
+			if !reflect.DeepEqual(pkg.Workspaces.Packages, tt.wantMembers) {
+				t.Errorf("expected packages %v, got %v", tt.wantMembers, pkg.Workspaces.Packages)
+			}
+			if !reflect.DeepEqual(pkg.Workspaces.NoHoist, tt.wantNoHoist) {
+				t.Errorf("expected nohoist %v, got %v", tt.wantNoHoist, pkg.Workspaces.NoHoist)
+			}
+		})
+	}
+}
+
+func TestFindWorkspaceMembers_ObjectFormWithNoHoist(t *testing.T) {
+	files := []*github.PackageFile{
+		{Path: "package.json", Content: `{
+			"name": "test-monorepo",
+			"workspaces": {
+				"packages": ["packages/*", "./apps/web/", "tools/**", "!packages/legacy"],
+				"nohoist": ["**/react-native"]
+			}
+		}`},
+		{Path: "packages/ui/package.json", Content: `{"name": "test-muaddib-ui"}`},
+		{Path: "packages/legacy/package.json", Content: `{"name": "test-muaddib-legacy"}`},
+		{Path: "packages/ui/fixtures/package.json", Content: `{}`},
+		{Path: "apps/web/package.json", Content: `{"name": "test-muaddib-web"}`},
+		{Path: "apps/docs/package.json", Content: `{"name": "test-muaddib-docs"}`},
+		{Path: "tools/lint/config/package.json", Content: `{}`},
+		{Path: "yarn.lock", Content: ``},
+	}
+
+	want := []string{"apps/web/package.json", "packages/ui/package.json", "tools/lint/config/package.json"}
+	if got := FindWorkspaceMembers(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected members %v, got %v", want, got)
+	}
+}
+
+func TestFindWorkspaceMembers_NestedRoot(t *testing.T) {
+	files := []*github.PackageFile{
+		{Path: "frontend/package.json", Content: `{"workspaces": ["packages/*"]}`},
+		{Path: "frontend/packages/app/package.json", Content: `{}`},
+		{Path: "packages/other/package.json", Content: `{}`},
+	}
+
+	want := []string{"frontend/packages/app/package.json"}
+	if got := FindWorkspaceMembers(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected members %v, got %v", want, got)
+	}
+}
+
+func TestScanFiles_RecordsWorkspaceMembersAndTheirDependencies(t *testing.T) {
+	db, err := vuln.ParseCSVForTest(strings.NewReader("package_name,package_versions,sources\ntest-muaddib-dep,1.0.0,test"))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	result := NewScanner(db, true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/monorepo", Path: "package.json", Content: `{"workspaces": {"packages": ["packages/*"], "nohoist": ["**/jest"]}}`},
+		{RepoName: "test-org/monorepo", Path: "packages/ui/package.json", Content: `{"dependencies": {"test-muaddib-dep": "1.0.0"}}`},
+	})
+
+	if !reflect.DeepEqual(result.WorkspaceMembers, []string{"packages/ui/package.json"}) {
+		t.Errorf("expected packages/ui to be a workspace member, got %v", result.WorkspaceMembers)
+	}
+	if len(result.VulnerablePackages) != 1 || result.VulnerablePackages[0].FilePath != "packages/ui/package.json" {
+		t.Errorf("expected the member's dependency to be scanned, got %+v", result.VulnerablePackages)
+	}
+}
+
+func TestScanFiles_AttributesLockfileFindingsToWorkspaces(t *testing.T) {
+	db, err := vuln.ParseCSVForTest(strings.NewReader("package_name,package_versions,sources\ntest-muaddib-dep,1.0.0,test"))
+	if err != nil {
+		t.Fatalf("failed to create test DB: %v", err)
+	}
+	result := NewScanner(db, true).ScanFiles([]*github.PackageFile{
+		{RepoName: "test-org/monorepo", Path: "package.json", Content: `{"workspaces": ["packages/*"]}`},
+		{RepoName: "test-org/monorepo", Path: "package-lock.json", Content: `{"lockfileVersion": 3, "packages": {
+			"node_modules/test-muaddib-dep": {"version": "1.0.0"}
+		}}`},
+		{RepoName: "test-org/monorepo", Path: "packages/ui/package.json", Content: `{"name": "test-muaddib-ui", "devDependencies": {"test-muaddib-dep": "^1.0.0"}}`},
+		{RepoName: "test-org/monorepo", Path: "packages/web/package.json", Content: `{"name": "test-muaddib-web"}`},
+	})
+
+	var fromLockfile *VulnerablePackage
+	for _, vp := range result.VulnerablePackages {
+		if vp.FilePath == "package-lock.json" {
+			fromLockfile = vp
+		}
+	}
+	if fromLockfile == nil {
+		t.Fatalf("expected a finding in the root lockfile, got %+v", result.VulnerablePackages)
+	}
+	expected := []WorkspaceMember{{Path: "packages/ui", Name: "test-muaddib-ui"}}
+	if !reflect.DeepEqual(fromLockfile.Workspaces, expected) {
+		t.Errorf("expected the finding attributed to %v, got %v", expected, fromLockfile.Workspaces)
+	}
+}
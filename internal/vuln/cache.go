@@ -0,0 +1,80 @@
+package vuln
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithCacheDir sets the directory each IOC feed fetched from a URL is cached
+// in. When a feed cannot be fetched, LoadFromMultipleURLs falls back to its
+// cached copy. An empty directory, the default, disables the cache.
+func WithCacheDir(dir string) LoadOption {
+	return func(o *loadOptions) {
+		o.cacheDir = dir
+	}
+}
+
+// DefaultCacheDir returns the IOC cache directory under the user's cache
+// directory, or "" if the platform has none
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "muaddib", "iocs")
+}
+
+// cachePath returns the cache file for a feed URL
+func (o loadOptions) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(o.cacheDir, hex.EncodeToString(sum[:])+".csv")
+}
+
+// writeCache stores the content of a feed fetched from url. Failing to cache
+// is not fatal to the load, so it is reported as a warning.
+func (o loadOptions) writeCache(url string, content []byte) {
+	if o.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(o.cacheDir, 0o700); err != nil {
+		warn("Failed to create IOC cache directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(o.cachePath(url), content, 0o600); err != nil {
+		warn("Failed to cache %s: %v", url, err)
+	}
+}
+
+// loadFromCache loads the cached copy of a feed, recording it as a cached
+// source fetched when the copy was written. A pinned checksum applies to the
+// cached copy as it would to a fresh fetch.
+func (o loadOptions) loadFromCache(url string) (*VulnDB, error) {
+	if o.cacheDir == "" {
+		return nil, fmt.Errorf("no IOC cache directory")
+	}
+	path := o.cachePath(url)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("no cached copy: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached copy: %w", err)
+	}
+	if err := o.verifyChecksum(url, content); err != nil {
+		return nil, err
+	}
+
+	db, err := parseSource(url, content, info.ModTime(), o.campaignFor(url))
+	if err != nil {
+		return nil, err
+	}
+	db.sources[0].Cached = true
+	warn("%s is unavailable; using the cached copy from %s (%s old)",
+		url, info.ModTime().UTC().Format(time.RFC3339), time.Since(info.ModTime()).Round(time.Minute))
+	return db, nil
+}
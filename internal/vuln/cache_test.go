@@ -0,0 +1,88 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// captureWarnings records warnings for the duration of a test
+func captureWarnings(t *testing.T) *[]string {
+	t.Helper()
+	var warnings []string
+	prev := SetWarningFunc(func(message string) { warnings = append(warnings, message) })
+	t.Cleanup(func() { SetWarningFunc(prev) })
+	return &warnings
+}
+
+func TestLoadFromMultipleURLs_FallsBackToCachePerSource(t *testing.T) {
+	cache := WithCacheDir(t.TempDir())
+	warnings := captureWarnings(t)
+
+	var down atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.csv", func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(checksumFeed))
+	})
+	mux.HandleFunc("/other.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package_name,package_versions,sources\n" + testPkgVulnerable2 + ",2.0.0,\"test\"\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	urls := []string{server.URL + "/feed.csv", server.URL + "/other.csv"}
+
+	// Prime the cache with both feeds
+	if _, err := LoadFromMultipleURLs(urls, cache); err != nil {
+		t.Fatalf("initial load failed: %v", err)
+	}
+
+	down.Store(true)
+	db, err := LoadFromMultipleURLs(urls, cache)
+	if err != nil {
+		t.Fatalf("expected the cached copy to be used, got %v", err)
+	}
+
+	if db.Check(testPkgVulnerable1, "1.0.0") == nil || db.Check(testPkgVulnerable2, "2.0.0") == nil {
+		t.Error("expected entries from both the cached and the fresh feed")
+	}
+	sources := db.Sources()
+	if len(sources) != 2 || !sources[0].Cached || sources[1].Cached {
+		t.Fatalf("expected only the first source to be cached, got %+v", sources)
+	}
+	if stats := db.Stats(); stats.CachedSources != 1 {
+		t.Errorf("expected 1 cached source in the stats, got %d", stats.CachedSources)
+	}
+	if len(*warnings) != 1 || !strings.Contains((*warnings)[0], "using the cached copy") {
+		t.Errorf("expected a staleness warning, got %v", *warnings)
+	}
+}
+
+func TestLoadFromMultipleURLs_NoCachedCopy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := LoadFromMultipleURLs([]string{server.URL + "/feed.csv"}, WithCacheDir(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Errorf("expected the fetch error without a cached copy, got %v", err)
+	}
+}
+
+func TestLoadFromMultipleURLs_CachedCopyMustMatchChecksum(t *testing.T) {
+	server := serveChecksumFeeds(t)
+	url := server.URL + "/feed.csv"
+	opts := []LoadOption{WithCacheDir(t.TempDir()), WithChecksums(Checksums{url: sha256Hex(checksumFeed)})}
+	newLoadOptions(opts).writeCache(url, []byte("package_name,package_versions,sources\ntampered,1.0.0,\"test\"\n"))
+	server.Close()
+
+	if _, err := LoadFromMultipleURLs([]string{url}, opts...); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a tampered cached copy to fail the load, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package vuln
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShaiHulud2Campaign labels indicators from the Shai-Hulud 2.0 campaign
+const ShaiHulud2Campaign = "shai-hulud-2.0"
+
+// Campaigns maps IOC source locations to the campaign their indicators belong to
+type Campaigns map[string]string
+
+// defaultCampaigns labels the default IOC sources
+var defaultCampaigns = Campaigns{
+	DataDogIOCURL: ShaiHulud2Campaign,
+	WizIOCURL:     ShaiHulud2Campaign,
+}
+
+// WithCampaigns labels IOC sources with the campaign their indicators belong
+// to. The label is recorded on the source and on every entry loaded from it.
+// The default sources are labelled unless c overrides them.
+func WithCampaigns(c Campaigns) LoadOption {
+	return func(o *loadOptions) {
+		o.campaigns = c
+	}
+}
+
+// withFallback returns a new map holding the labels in c and those in
+// fallback for sources c does not label
+func (c Campaigns) withFallback(fallback Campaigns) Campaigns {
+	merged := make(Campaigns, len(c)+len(fallback))
+	for location, campaign := range fallback {
+		merged[location] = campaign
+	}
+	for location, campaign := range c {
+		merged[location] = campaign
+	}
+	return merged
+}
+
+// ParseCampaigns parses "location=campaign" pairs. The label follows the last
+// "=", so URLs with query strings are accepted.
+func ParseCampaigns(pairs []string) (Campaigns, error) {
+	campaigns := make(Campaigns, len(pairs))
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 || strings.TrimSpace(pair[i+1:]) == "" {
+			return nil, fmt.Errorf("invalid IOC campaign %q (expected url=campaign)", pair)
+		}
+		campaigns[pair[:i]] = strings.TrimSpace(pair[i+1:])
+	}
+	return campaigns, nil
+}
+
+// campaignFor returns the campaign a source is labelled with, if any
+func (o loadOptions) campaignFor(location string) string {
+	if campaign, ok := o.campaigns[location]; ok {
+		return campaign
+	}
+	return defaultCampaigns[location]
+}
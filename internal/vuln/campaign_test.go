@@ -0,0 +1,119 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveCampaignManifest serves a manifest labelling /feed.csv and leaving
+// /other.csv unlabelled
+func serveCampaignManifest(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manifest.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sources:\n  - url: feed.csv\n    campaign: test-campaign\n  - other.csv\n"))
+	})
+	mux.HandleFunc("/feed.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksumFeed))
+	})
+	mux.HandleFunc("/other.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package_name,package_versions,sources\n" + testPkgVulnerable2 + ",2.0.0,\"test\"\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadFromSourceManifest_LabelsCampaigns(t *testing.T) {
+	server := serveCampaignManifest(t)
+
+	db, err := LoadFromSourceManifest(server.URL + "/manifest.yaml")
+	if err != nil {
+		t.Fatalf("LoadFromSourceManifest failed: %v", err)
+	}
+
+	if entry := db.Check(testPkgVulnerable1, "1.0.0"); entry == nil || entry.Campaign != "test-campaign" {
+		t.Errorf("expected entry labelled test-campaign, got %+v", entry)
+	}
+	if entry := db.Check(testPkgVulnerable2, "2.0.0"); entry == nil || entry.Campaign != "" {
+		t.Errorf("expected unlabelled entry from the unlabelled source, got %+v", entry)
+	}
+	for _, source := range db.Sources() {
+		if strings.HasSuffix(source.Location, "/feed.csv") && source.Campaign != "test-campaign" {
+			t.Errorf("expected source labelled test-campaign, got %q", source.Campaign)
+		}
+	}
+}
+
+func TestLoadFromSourceManifest_OptionLabelsOverrideManifest(t *testing.T) {
+	server := serveCampaignManifest(t)
+	labels := Campaigns{server.URL + "/feed.csv": "from-flag"}
+
+	db, err := LoadFromSourceManifest(server.URL+"/manifest.yaml", WithCampaigns(labels))
+	if err != nil {
+		t.Fatalf("LoadFromSourceManifest failed: %v", err)
+	}
+	if entry := db.Check(testPkgVulnerable1, "1.0.0"); entry == nil || entry.Campaign != "from-flag" {
+		t.Errorf("expected the option label to win over the manifest, got %+v", entry)
+	}
+	if len(labels) != 1 {
+		t.Errorf("expected the caller's labels to be left untouched, got %v", labels)
+	}
+
+	// Manifest labels must not carry over to a later load
+	db, err = LoadFromMultipleURLs([]string{server.URL + "/feed.csv"})
+	if err != nil {
+		t.Fatalf("LoadFromMultipleURLs failed: %v", err)
+	}
+	if entry := db.Check(testPkgVulnerable1, "1.0.0"); entry == nil || entry.Campaign != "" {
+		t.Errorf("expected no label without a manifest or option, got %+v", entry)
+	}
+}
+
+func TestCampaignFor_OverridesDefaults(t *testing.T) {
+	o := newLoadOptions([]LoadOption{WithCampaigns(Campaigns{"https://example.test/a.csv": "from-flag", WizIOCURL: "from-flag"})})
+
+	for location, want := range map[string]string{
+		"https://example.test/a.csv": "from-flag",
+		WizIOCURL:                    "from-flag",
+		DataDogIOCURL:                ShaiHulud2Campaign,
+		"https://example.test/c.csv": "",
+	} {
+		if got := o.campaignFor(location); got != want {
+			t.Errorf("campaignFor(%s) = %q, want %q", location, got, want)
+		}
+	}
+}
+
+func TestParseCampaigns(t *testing.T) {
+	campaigns, err := ParseCampaigns([]string{"https://example.test/a.csv?ref=main=test-campaign"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if campaigns["https://example.test/a.csv?ref=main"] != "test-campaign" {
+		t.Errorf("unexpected campaigns: %v", campaigns)
+	}
+
+	for _, pair := range []string{"no-label", "=test-campaign", "https://example.test/a.csv="} {
+		if _, err := ParseCampaigns([]string{pair}); err == nil {
+			t.Errorf("expected an error for %q", pair)
+		}
+	}
+}
+
+func TestFilterByCampaign(t *testing.T) {
+	db := NewVulnDB()
+	db.Add(&VulnEntry{PackageName: testPkgVulnerable1, PackageVersion: "1.0.0", Campaign: "test-campaign"})
+	db.Add(&VulnEntry{PackageName: testPkgVulnerable2, PackageVersion: "2.0.0", Campaign: "other-campaign"})
+	db.Add(&VulnEntry{PackageName: testPkgSafe, PackageVersion: "3.0.0"})
+
+	filtered := db.FilterByCampaign([]string{"test-campaign"})
+	if filtered.Check(testPkgVulnerable1, "1.0.0") == nil {
+		t.Error("expected the labelled entry to be kept")
+	}
+	if filtered.Check(testPkgVulnerable2, "2.0.0") != nil || filtered.Check(testPkgSafe, "3.0.0") != nil {
+		t.Error("expected entries from other or unlabelled campaigns to be dropped")
+	}
+}
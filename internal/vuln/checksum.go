@@ -0,0 +1,62 @@
+package vuln
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Checksums maps IOC source URLs to their expected SHA-256 digests
+type Checksums map[string]string
+
+// WithChecksums pins the expected SHA-256 digests of IOC sources. Sources
+// without a pinned digest are loaded unverified.
+func WithChecksums(c Checksums) LoadOption {
+	return func(o *loadOptions) {
+		o.checksums = c
+	}
+}
+
+// ParseChecksums parses "url=sha256" pairs. The digest follows the last "=",
+// so URLs with query strings are accepted.
+func ParseChecksums(pairs []string) (Checksums, error) {
+	checksums := make(Checksums, len(pairs))
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid IOC checksum %q (expected url=sha256)", pair)
+		}
+		url, digest := pair[:i], strings.ToLower(strings.TrimSpace(pair[i+1:]))
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 digest for %s: %q", url, digest)
+		}
+		checksums[url] = digest
+	}
+	return checksums, nil
+}
+
+// ChecksumMismatchError reports an IOC source whose content does not match its
+// pinned digest, which may indicate a tampered mirror or interception
+type ChecksumMismatchError struct {
+	URL      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.URL, e.Expected, e.Actual)
+}
+
+// verifyChecksum checks content against the digest pinned for url, if any
+func (o loadOptions) verifyChecksum(url string, content []byte) error {
+	expected, ok := o.checksums[url]
+	if !ok {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return &ChecksumMismatchError{URL: url, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package vuln
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const checksumFeed = "package_name,package_versions,sources\n" + testPkgVulnerable1 + ",1.0.0,\"test\"\n"
+
+// serveChecksumFeeds serves the test feed at /feed.csv and a second good feed at /other.csv
+func serveChecksumFeeds(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksumFeed))
+	})
+	mux.HandleFunc("/other.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package_name,package_versions,sources\n" + testPkgVulnerable2 + ",2.0.0,\"test\"\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoadFromURL_MatchingChecksum(t *testing.T) {
+	server := serveChecksumFeeds(t)
+	url := server.URL + "/feed.csv"
+	db, err := LoadFromURLWithOptions(url, WithChecksums(Checksums{url: sha256Hex(checksumFeed)}))
+	if err != nil {
+		t.Fatalf("expected matching checksum to load, got %v", err)
+	}
+	if db.Check(testPkgVulnerable1, "1.0.0") == nil {
+		t.Error("expected entry from verified feed")
+	}
+}
+
+func TestLoadFromURL_MismatchingChecksum(t *testing.T) {
+	server := serveChecksumFeeds(t)
+	url := server.URL + "/feed.csv"
+	_, err := LoadFromURLWithOptions(url, WithChecksums(Checksums{url: sha256Hex("tampered")}))
+
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected ChecksumMismatchError, got %v", err)
+	}
+	if mismatch.Actual != sha256Hex(checksumFeed) {
+		t.Errorf("expected actual digest of the served feed, got %s", mismatch.Actual)
+	}
+}
+
+func TestLoadFromMultipleURLs_MismatchFailsEvenWithOtherSources(t *testing.T) {
+	server := serveChecksumFeeds(t)
+	pinned := WithChecksums(Checksums{server.URL + "/feed.csv": sha256Hex("tampered")})
+
+	_, err := LoadFromMultipleURLs([]string{server.URL + "/other.csv", server.URL + "/feed.csv"}, pinned)
+
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected the mismatch to fail the load, got %v", err)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	digest := sha256Hex(checksumFeed)
+
+	checksums, err := ParseChecksums([]string{"https://example.invalid/feed.csv?ref=main=" + digest})
+	if err != nil {
+		t.Fatalf("ParseChecksums failed: %v", err)
+	}
+	if checksums["https://example.invalid/feed.csv?ref=main"] != digest {
+		t.Errorf("expected digest keyed by the full URL, got %v", checksums)
+	}
+
+	for _, invalid := range []string{"no-separator", "=" + digest, "https://example.invalid/feed.csv=abc123"} {
+		if _, err := ParseChecksums([]string{invalid}); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
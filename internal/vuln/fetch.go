@@ -0,0 +1,128 @@
+package vuln
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rslater/muaddib/internal/proxy"
+)
+
+const (
+	// DefaultLoadTimeout bounds a single IOC download, including reading the body
+	DefaultLoadTimeout = 30 * time.Second
+	// DefaultLoadRetries is how many times a transient download failure is retried
+	DefaultLoadRetries = 3
+)
+
+// defaultRetryDelay is the delay before the first retry; shortened in tests
+var defaultRetryDelay = time.Second
+
+// LoadOption configures how IOC feeds and manifests are downloaded, verified,
+// cached, and labelled
+type LoadOption func(*loadOptions)
+
+// loadOptions holds the download settings
+type loadOptions struct {
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	proxyURL   *url.URL
+	checksums  Checksums
+	campaigns  Campaigns
+	cacheDir   string
+}
+
+// WithTimeout sets the timeout for each download attempt
+func WithTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRetries sets how many times network errors, 5xx, and 429 responses are retried
+func WithRetries(n int) LoadOption {
+	return func(o *loadOptions) {
+		o.retries = n
+	}
+}
+
+// WithRetryDelay sets the delay before the first retry; each later retry
+// waits twice as long as the one before
+func WithRetryDelay(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.retryDelay = d
+	}
+}
+
+// WithProxy downloads through the proxy at proxyURL instead of the one named
+// by HTTPS_PROXY. A nil URL keeps the environment's proxy.
+func WithProxy(proxyURL *url.URL) LoadOption {
+	return func(o *loadOptions) {
+		o.proxyURL = proxyURL
+	}
+}
+
+// newLoadOptions applies opts over the defaults
+func newLoadOptions(opts []LoadOption) loadOptions {
+	o := loadOptions{
+		timeout:    DefaultLoadTimeout,
+		retries:    DefaultLoadRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// httpStatusError is a non-200 response to a download
+type httpStatusError struct {
+	StatusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// fetch downloads url, retrying transient failures with exponential backoff
+func fetch(url string, o loadOptions) ([]byte, error) {
+	client := &http.Client{Timeout: o.timeout, Transport: proxy.Transport(o.proxyURL)}
+	delay := o.retryDelay
+
+	for attempt := 0; ; attempt++ {
+		content, err := fetchOnce(client, url)
+		if err == nil || attempt >= o.retries || !isTransient(err) {
+			return content, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// fetchOnce makes a single download attempt
+func fetchOnce(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &httpStatusError{StatusCode: resp.StatusCode}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isTransient checks if a download failure may succeed when retried: network
+// errors and timeouts, server errors, and rate limiting
+func isTransient(err error) bool {
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= http.StatusInternalServerError || status.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
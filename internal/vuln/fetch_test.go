@@ -0,0 +1,135 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Stub feeds fail on purpose; don't wait seconds between retries
+	defaultRetryDelay = time.Millisecond
+	os.Exit(m.Run())
+}
+
+// serveFlakyFeed serves the test feed after failing the first failures
+// requests with status
+func serveFlakyFeed(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, "failed", status)
+			return
+		}
+		w.Write([]byte(checksumFeed))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestLoadFromURLWithOptions_RetriesTransientFailures(t *testing.T) {
+	for _, status := range []int{http.StatusBadGateway, http.StatusTooManyRequests} {
+		server, requests := serveFlakyFeed(t, 2, status)
+
+		db, err := LoadFromURLWithOptions(server.URL, WithRetries(2))
+		if err != nil {
+			t.Fatalf("expected HTTP %d to be retried, got %v", status, err)
+		}
+		if db.Check(testPkgVulnerable1, "1.0.0") == nil {
+			t.Error("expected entry from the feed")
+		}
+		if requests.Load() != 3 {
+			t.Errorf("expected 3 requests, got %d", requests.Load())
+		}
+	}
+}
+
+func TestLoadFromURLWithOptions_GivesUpAfterRetries(t *testing.T) {
+	server, requests := serveFlakyFeed(t, 10, http.StatusServiceUnavailable)
+
+	_, err := LoadFromURLWithOptions(server.URL, WithRetries(1))
+	if err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Errorf("expected HTTP 503 after retries, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestLoadFromURLWithOptions_DoesNotRetryClientErrors(t *testing.T) {
+	server, requests := serveFlakyFeed(t, 10, http.StatusNotFound)
+
+	if _, err := LoadFromURLWithOptions(server.URL, WithRetries(3)); err == nil {
+		t.Error("expected HTTP 404 to fail")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected 404 not to be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestLoadFromURLWithOptions_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	start := time.Now()
+	_, err := LoadFromURLWithOptions(server.URL, WithTimeout(50*time.Millisecond), WithRetries(0))
+	if err == nil {
+		t.Fatal("expected a hung feed to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the timeout to end the download, took %v", elapsed)
+	}
+}
+
+func TestLoadFromURLWithOptions_UsesProxy(t *testing.T) {
+	var proxied string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(checksumFeed))
+	}))
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+
+	if _, err := LoadFromURLWithOptions("http://iocs.test/feed.csv", WithProxy(proxyURL), WithRetries(0)); err != nil {
+		t.Fatalf("expected the feed to be loaded through the proxy: %v", err)
+	}
+	if proxied != "http://iocs.test/feed.csv" {
+		t.Errorf("expected the proxy to receive the feed request, got %q", proxied)
+	}
+}
+
+func TestLoadFromMultipleURLs_SlowSourceDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow.csv", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	mux.HandleFunc("/feed.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksumFeed))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	db, err := LoadFromMultipleURLs([]string{server.URL + "/slow.csv", server.URL + "/feed.csv"},
+		WithTimeout(100*time.Millisecond), WithRetries(0))
+	if err != nil {
+		t.Fatalf("expected the fast source to load, got %v", err)
+	}
+	if db.Check(testPkgVulnerable1, "1.0.0") == nil {
+		t.Error("expected entry from the fast source")
+	}
+	if len(db.Sources()) != 1 {
+		t.Errorf("expected only the fast source to load, got %+v", db.Sources())
+	}
+}
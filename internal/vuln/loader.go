@@ -2,11 +2,15 @@ package vuln
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 const (
@@ -41,16 +45,77 @@ func SetWarningFunc(fn WarningFunc) WarningFunc {
 	return prev
 }
 
+// warnMu serialises warnings from sources loaded concurrently
+var warnMu sync.Mutex
+
 // warn calls the current warning function
 func warn(format string, args ...interface{}) {
+	warnMu.Lock()
+	defer warnMu.Unlock()
 	currentWarningFunc(fmt.Sprintf(format, args...))
 }
 
 // VulnEntry represents a vulnerable package entry
 type VulnEntry struct {
 	PackageName     string
-	PackageVersion  string // Single version (after splitting comma-separated list)
-	OriginalVersion string // Original version string from CSV (may be comma-separated)
+	PackageVersion  string        // Single version (after splitting comma-separated list)
+	OriginalVersion string        // Original version string from CSV (may be comma-separated)
+	ScopeWide       bool          // Entry flags every package in a scope (e.g., "@ctrl/*") at any version
+	Added           time.Time     // When the indicator was added; zero if the feed has no date
+	Raw             string        // Original CSV row the entry was parsed from, kept as evidence
+	Range           *VersionRange // Set when the entry flags a range; PackageVersion holds its spec
+	Integrity       []string      // Known-bad tarball integrity hashes (e.g., "sha512-..."), if the feed lists any
+	Campaign        string        // Campaign label of the source the entry was loaded from, if any
+	AdvisoryID      string        // ID of the advisory the entry came from (e.g., "GHSA-..."), for OSV sources
+	Sources         []string      // Feeds that reported the entry, from the CSV sources column or the feed it was loaded from
+}
+
+// Evidence returns the upstream IOC row the entry was parsed from. Entries
+// added without a source row get a reconstructed "name,version" row instead.
+func (e *VulnEntry) Evidence() string {
+	if e.Raw != "" {
+		return e.Raw
+	}
+	version := e.OriginalVersion
+	if version == "" {
+		version = e.PackageVersion
+	}
+	return encodeRecord([]string{e.PackageName, version})
+}
+
+// encodeRecord re-encodes a CSV record as a single line, quoting as needed
+func encodeRecord(record []string) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	_ = w.Write(record) // Writes to a strings.Builder cannot fail
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// ScopeWildcardSuffix marks an IOC package name that flags a whole scope
+const ScopeWildcardSuffix = "/*"
+
+// scopeOf returns the scope of a scoped package name (e.g., "@ctrl"), or "" if unscoped
+func scopeOf(name string) string {
+	if !strings.HasPrefix(name, "@") {
+		return ""
+	}
+	if i := strings.Index(name, "/"); i > 1 {
+		return name[:i]
+	}
+	return ""
+}
+
+// parseScopeWildcard returns the scope flagged by an "@scope/*" package name
+func parseScopeWildcard(name string) (string, bool) {
+	if !strings.HasSuffix(name, ScopeWildcardSuffix) {
+		return "", false
+	}
+	scope := strings.TrimSuffix(name, ScopeWildcardSuffix)
+	if !strings.HasPrefix(scope, "@") || len(scope) < 2 || strings.Contains(scope, "/") {
+		return "", false
+	}
+	return scope, true
 }
 
 // VulnDB holds the vulnerability database as a lookup map
@@ -59,42 +124,80 @@ type VulnDB struct {
 	entries map[string]*VulnEntry
 	// Index by package name for listing
 	byName map[string][]*VulnEntry
+	// Key: "@scope" for entries flagging a whole scope
+	scopes map[string]*VulnEntry
+	// Key: package name for entries flagging a version range
+	ranges map[string][]*VulnEntry
+	// Key: tarball integrity hash for entries listing known-bad hashes
+	integrity map[string]*VulnEntry
 	// Total entries count (before dedup)
 	totalEntries int
+	// Feeds the database was loaded from
+	sources []Source
 }
 
 // NewVulnDB creates a new vulnerability database
 func NewVulnDB() *VulnDB {
 	return &VulnDB{
-		entries: make(map[string]*VulnEntry),
-		byName:  make(map[string][]*VulnEntry),
+		entries:   make(map[string]*VulnEntry),
+		byName:    make(map[string][]*VulnEntry),
+		scopes:    make(map[string]*VulnEntry),
+		ranges:    make(map[string][]*VulnEntry),
+		integrity: make(map[string]*VulnEntry),
 	}
 }
 
-// LoadFromURL fetches and parses a CSV vulnerability database from a URL
+// LoadFromURL fetches and parses a CSV or OSV JSON vulnerability database from a URL
+// with the default timeout and retries.
+// If a checksum is pinned for the URL, the content must match it.
 func LoadFromURL(url string) (*VulnDB, error) {
-	resp, err := http.Get(url)
+	return LoadFromURLWithOptions(url)
+}
+
+// LoadFromURLWithOptions fetches and parses a CSV or OSV JSON vulnerability
+// database from a URL, retrying transient failures as configured by opts.
+// If a checksum is pinned for the URL, the content must match it.
+func LoadFromURLWithOptions(url string, opts ...LoadOption) (*VulnDB, error) {
+	o := newLoadOptions(opts)
+	content, err := fetch(url, o)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch vulnerability database: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch vulnerability database: HTTP %d", resp.StatusCode)
+	// Verify before parsing so tampered content is never used
+	if err := o.verifyChecksum(url, content); err != nil {
+		return nil, err
 	}
 
-	return parseCSV(resp.Body)
+	db, err := parseSource(url, content, time.Now(), o.campaignFor(url))
+	if err != nil {
+		return nil, err
+	}
+	o.writeCache(url, content)
+	return db, nil
 }
 
-// LoadFromFile loads and parses a CSV vulnerability database from a local file
-func LoadFromFile(path string) (*VulnDB, error) {
-	f, err := os.Open(path)
+// LoadFromFile loads and parses a CSV or OSV JSON vulnerability database from
+// a local file. Only the campaign labels in opts apply to it.
+func LoadFromFile(path string, opts ...LoadOption) (*VulnDB, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open vulnerability file: %w", err)
 	}
-	defer f.Close()
 
-	return parseCSV(f)
+	return parseSource(path, content, time.Now(), newLoadOptions(opts).campaignFor(path))
+}
+
+// LoadFromReader loads and parses a CSV or OSV JSON vulnerability database
+// read from r, such as stdin. location names the source in reports. Only
+// the campaign labels in opts apply to it.
+func LoadFromReader(location string, r io.Reader, opts ...LoadOption) (*VulnDB, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vulnerability database: %w", err)
+	}
+
+	return parseSource(location, content, time.Now(), newLoadOptions(opts).campaignFor(location))
 }
 
 // ParseCSVForTest is a test helper that parses CSV from a reader
@@ -107,20 +210,58 @@ func ParseCSVForTest(r io.Reader) (*VulnDB, error) {
 type csvColumnIndices struct {
 	nameIdx      int
 	versionIdx   int
+	dateIdx      int // Optional; -1 when the feed has no date column
+	rangeIdx     int // Optional; -1 when the feed has no affected_version_ranges column
+	integrityIdx int // Optional; -1 when the feed has no integrity column
+	sourcesIdx   int // Optional; -1 when the feed has no sources column
 	usedFallback bool
 }
 
+// Headers recognised for each column, compared case-insensitively
+var (
+	nameColumnNames      = []string{"package_name", "packagename", "name", "package"}
+	versionColumnNames   = []string{"package_versions", "package_version", "packageversion", "version", "versions"}
+	rangeColumnNames     = []string{"affected_version_ranges", "version_ranges"}
+	integrityColumnNames = []string{"integrity", "dist_integrity"}
+	sourcesColumnNames   = []string{"sources", "source"}
+)
+
+// dateColumnNames are headers recognised as the date an indicator was added
+var dateColumnNames = []string{"date", "added", "date_added", "added_at", "first_seen", "published", "created_at", "timestamp"}
+
+// dateLayouts are the accepted formats for the optional date column
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// parseIOCDate parses a date from the optional date column
+func parseIOCDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q (expected YYYY-MM-DD or RFC 3339)", value)
+}
+
 // detectColumnIndices finds the column indices for package name and version
 func detectColumnIndices(header []string) csvColumnIndices {
-	indices := csvColumnIndices{nameIdx: -1, versionIdx: -1}
+	indices := csvColumnIndices{nameIdx: -1, versionIdx: -1, dateIdx: -1, rangeIdx: -1, integrityIdx: -1, sourcesIdx: -1}
 
 	for i, col := range header {
 		colLower := strings.ToLower(strings.TrimSpace(col))
-		if colLower == "package_name" || colLower == "packagename" || colLower == "name" || colLower == "package" {
+		switch {
+		case slices.Contains(nameColumnNames, colLower):
 			indices.nameIdx = i
-		}
-		if colLower == "package_versions" || colLower == "package_version" || colLower == "packageversion" || colLower == "version" || colLower == "versions" {
+		case slices.Contains(versionColumnNames, colLower):
 			indices.versionIdx = i
+		case slices.Contains(dateColumnNames, colLower):
+			indices.dateIdx = i
+		case slices.Contains(rangeColumnNames, colLower):
+			indices.rangeIdx = i
+		case slices.Contains(integrityColumnNames, colLower):
+			indices.integrityIdx = i
+		case slices.Contains(sourcesColumnNames, colLower):
+			indices.sourcesIdx = i
 		}
 	}
 
@@ -129,7 +270,7 @@ func detectColumnIndices(header []string) csvColumnIndices {
 		indices.nameIdx = 0
 		indices.usedFallback = true
 	}
-	if indices.versionIdx == -1 {
+	if indices.versionIdx == -1 && indices.rangeIdx == -1 && indices.integrityIdx == -1 {
 		indices.versionIdx = 1
 		indices.usedFallback = true
 	}
@@ -167,7 +308,7 @@ func warnFallbackParsing(header []string, records [][]string, indices csvColumnI
 	var samples []string
 	for i := 0; i < sampleCount; i++ {
 		rec := records[i]
-		if len(rec) > 1 {
+		if len(rec) > 1 && indices.versionIdx >= 0 && indices.versionIdx < len(rec) {
 			samples = append(samples, fmt.Sprintf("  %s @ %s", rec[indices.nameIdx], rec[indices.versionIdx]))
 		}
 	}
@@ -187,25 +328,122 @@ func processRecord(db *VulnDB, record []string, indices csvColumnIndices) {
 		return
 	}
 
-	versionField := ""
-	if indices.versionIdx >= 0 && indices.versionIdx < len(record) {
-		versionField = strings.TrimSpace(record[indices.versionIdx])
+	added := recordDate(record, indices)
+	raw := encodeRecord(record)
+	sources := recordSources(record, indices)
+
+	if _, ok := parseScopeWildcard(packageName); ok {
+		db.Add(&VulnEntry{PackageName: packageName, PackageVersion: "*", ScopeWide: true, Added: added, Raw: raw, Sources: sources})
+		return
 	}
 
+	integrity := recordIntegrity(record, indices)
+	versionField, versions, ranges, err := recordVersions(record, indices)
+	if err != nil {
+		warn("Skipping %s: %v", packageName, err)
+		return
+	}
 	if versionField == "" {
+		if len(integrity) > 0 {
+			// The hashes identify the malicious tarballs without a version
+			db.Add(&VulnEntry{PackageName: packageName, Added: added, Raw: raw, Integrity: integrity, Sources: sources})
+		}
 		return // Skip entries without version
 	}
 
-	versions := parseVersionList(versionField)
 	for _, version := range versions {
 		db.Add(&VulnEntry{
 			PackageName:     packageName,
 			PackageVersion:  version,
 			OriginalVersion: versionField,
+			Added:           added,
+			Raw:             raw,
+			Integrity:       integrity,
+			Sources:         sources,
+		})
+	}
+	for _, r := range ranges {
+		db.Add(&VulnEntry{
+			PackageName:     packageName,
+			PackageVersion:  r.Spec,
+			OriginalVersion: versionField,
+			Added:           added,
+			Raw:             raw,
+			Range:           r,
+			Integrity:       integrity,
+			Sources:         sources,
 		})
 	}
 }
 
+// recordSources parses the optional sources column of a record, a list of
+// the feeds that reported the indicator separated by commas or semicolons
+func recordSources(record []string, indices csvColumnIndices) []string {
+	if indices.sourcesIdx < 0 || indices.sourcesIdx >= len(record) {
+		return nil
+	}
+	var sources []string
+	for _, source := range strings.FieldsFunc(record[indices.sourcesIdx], func(r rune) bool { return r == ',' || r == ';' }) {
+		if source = strings.TrimSpace(source); source != "" && !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// recordIntegrity parses the optional integrity column of a record. It may
+// list several hashes separated by whitespace, commas, or semicolons.
+func recordIntegrity(record []string, indices csvColumnIndices) []string {
+	if indices.integrityIdx < 0 || indices.integrityIdx >= len(record) {
+		return nil
+	}
+	return splitIntegrity(record[indices.integrityIdx])
+}
+
+// splitIntegrity splits a list of integrity hashes. Lockfiles use the same
+// whitespace-separated form when they record more than one algorithm.
+func splitIntegrity(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+}
+
+// recordVersions parses the version column and the optional range column of a
+// record. It returns the combined original text with the exact versions and
+// ranges it lists.
+func recordVersions(record []string, indices csvColumnIndices) (string, []string, []*VersionRange, error) {
+	var fields, versions []string
+	var ranges []*VersionRange
+	for _, idx := range []int{indices.versionIdx, indices.rangeIdx} {
+		if idx < 0 || idx >= len(record) || strings.TrimSpace(record[idx]) == "" {
+			continue
+		}
+		field := strings.TrimSpace(record[idx])
+		v, r, err := parseVersionSpec(field)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		fields = append(fields, field)
+		versions = append(versions, v...)
+		ranges = append(ranges, r...)
+	}
+	return strings.Join(fields, " || "), versions, ranges, nil
+}
+
+// recordDate returns the date an indicator was added, or the zero time if the
+// feed has no date column or the value cannot be parsed
+func recordDate(record []string, indices csvColumnIndices) time.Time {
+	if indices.dateIdx < 0 || indices.dateIdx >= len(record) || strings.TrimSpace(record[indices.dateIdx]) == "" {
+		return time.Time{}
+	}
+	added, err := parseIOCDate(record[indices.dateIdx])
+	if err != nil {
+		warn("Ignoring date for %s: %v", strings.TrimSpace(record[indices.nameIdx]), err)
+		return time.Time{}
+	}
+	return added
+}
+
 // parseCSV parses a CSV file looking for package_name and package_version columns
 // Handles comma-separated version lists like "6.10.1, 6.8.2, 6.8.3"
 // If column headers are not recognized, falls back to positional parsing (first=name, second=version)
@@ -293,13 +531,59 @@ func parseNpmVersionSpec(versionSpec string) []string {
 func (db *VulnDB) Add(entry *VulnEntry) {
 	db.totalEntries++
 
-	// Create key with name@version
-	key := entry.PackageName + "@" + entry.PackageVersion
+	for _, hash := range entry.Integrity {
+		if db.integrity[hash] == nil {
+			db.integrity[hash] = entry
+		}
+	}
+	if entry.PackageVersion == "" && !entry.ScopeWide {
+		return // Integrity-only entries are matched by hash alone
+	}
+
+	if entry.ScopeWide {
+		scope, ok := parseScopeWildcard(entry.PackageName)
+		if !ok {
+			return
+		}
+		if existing := db.scopes[scope]; existing != nil {
+			existing.addSources(entry.Sources)
+		} else {
+			db.scopes[scope] = entry
+		}
+		return
+	}
+
+	key := versionKey(entry.PackageName, entry.PackageVersion)
 
-	// Only add if not already present (dedup)
-	if _, exists := db.entries[key]; !exists {
+	// Only add if not already present (dedup), keeping every feed that reported it
+	if existing, exists := db.entries[key]; exists {
+		existing.addSources(entry.Sources)
+	} else {
 		db.entries[key] = entry
 		db.byName[entry.PackageName] = append(db.byName[entry.PackageName], entry)
+		if entry.Range != nil {
+			db.ranges[entry.PackageName] = append(db.ranges[entry.PackageName], entry)
+		}
+	}
+}
+
+// versionKey returns the name@version key of an exact entry. A single leading
+// "v" or "V" before a digit is dropped, as lockfiles and IOC lists write
+// versions both with and without it.
+func versionKey(name, version string) string {
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+		version = version[1:]
+	}
+	return name + "@" + version
+}
+
+// addSources adds the feeds in sources that are not already listed. Entries
+// parsed from the same row share a slice, so it is copied before growing.
+func (e *VulnEntry) addSources(sources []string) {
+	for _, source := range sources {
+		if !slices.Contains(e.Sources, source) {
+			e.Sources = append(slices.Clip(e.Sources), source)
+		}
 	}
 }
 
@@ -312,11 +596,34 @@ func (db *VulnDB) Check(name, version string) *VulnEntry {
 	}
 
 	// Look for exact match of name@version
-	key := name + "@" + version
-	if entry, ok := db.entries[key]; ok {
+	if entry, ok := db.entries[versionKey(name, version)]; ok {
 		return entry
 	}
 
+	// Then any range flagged for the package
+	for _, entry := range db.ranges[name] {
+		if entry.Range.Contains(version) {
+			return entry
+		}
+	}
+
+	// Fall back to an entry flagging the package's whole scope
+	if scope := scopeOf(name); scope != "" {
+		return db.scopes[scope]
+	}
+
+	return nil
+}
+
+// CheckIntegrity checks a lockfile integrity value against known-bad tarball
+// hashes. The value may list several space-separated hashes, as SRI allows.
+// Returns the matching VulnEntry if any hash is known, nil otherwise.
+func (db *VulnDB) CheckIntegrity(integrity string) *VulnEntry {
+	for _, hash := range splitIntegrity(integrity) {
+		if entry, ok := db.integrity[hash]; ok {
+			return entry
+		}
+	}
 	return nil
 }
 
@@ -344,47 +651,144 @@ func (db *VulnDB) UniquePackages() int {
 	return len(db.byName)
 }
 
+// Scopes returns the number of scopes flagged in their entirety
+func (db *VulnDB) Scopes() int {
+	return len(db.scopes)
+}
+
 // TotalEntries returns the total number of entries processed (before dedup)
 func (db *VulnDB) TotalEntries() int {
 	return db.totalEntries
 }
 
+// FilterByDate returns a database holding only the entries added within the
+// window. A zero after or before leaves that side open. Entries without a date
+// are always kept.
+func (db *VulnDB) FilterByDate(after, before time.Time) *VulnDB {
+	return db.filter(func(entry *VulnEntry) bool {
+		if entry.Added.IsZero() {
+			return true
+		}
+		if !after.IsZero() && entry.Added.Before(after) {
+			return false
+		}
+		return before.IsZero() || entry.Added.Before(before)
+	})
+}
+
+// FilterByCampaign returns a database holding only the entries from sources
+// labelled with one of the campaigns
+func (db *VulnDB) FilterByCampaign(campaigns []string) *VulnDB {
+	return db.filter(func(entry *VulnEntry) bool {
+		return slices.Contains(campaigns, entry.Campaign)
+	})
+}
+
+// filter returns a database holding only the entries keep accepts
+func (db *VulnDB) filter(keep func(*VulnEntry) bool) *VulnDB {
+	filtered := NewVulnDB()
+	filtered.sources = db.sources
+	db.each(func(entry *VulnEntry) {
+		if keep(entry) {
+			filtered.Add(entry)
+		}
+	})
+	return filtered
+}
+
+// each calls fn for every entry once, whether keyed by version, by scope, or
+// by integrity hash alone
+func (db *VulnDB) each(fn func(*VulnEntry)) {
+	for _, entry := range db.entries {
+		fn(entry)
+	}
+	for _, entry := range db.scopes {
+		fn(entry)
+	}
+	for _, entry := range db.integrityOnly() {
+		fn(entry)
+	}
+}
+
 // Merge adds all entries from another VulnDB into this one
 // Duplicates (same package@version) are automatically deduplicated
 func (db *VulnDB) Merge(other *VulnDB) {
 	if other == nil {
 		return
 	}
+	db.sources = append(db.sources, other.sources...)
+	other.each(db.Add)
+}
 
-	for _, entry := range other.entries {
-		db.Add(entry)
+// integrityOnly returns the entries identified only by integrity hashes,
+// which are not held in the version-keyed maps
+func (db *VulnDB) integrityOnly() []*VulnEntry {
+	seen := make(map[*VulnEntry]bool)
+	var entries []*VulnEntry
+	for _, entry := range db.integrity {
+		if entry.PackageVersion == "" && !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
 	}
+	return entries
 }
 
 // LoadFromMultipleURLs fetches and merges CSV vulnerability databases from multiple URLs
+// Sources are fetched concurrently, so a slow source does not hold up the others,
+// and merged in the order given
+// A source that fails to fetch is loaded from its cached copy, if any (see WithCacheDir)
 // Errors from individual URLs are collected but don't stop the overall process
 // Returns an error only if ALL sources fail to load
-func LoadFromMultipleURLs(urls []string) (*VulnDB, error) {
+func LoadFromMultipleURLs(urls []string, opts ...LoadOption) (*VulnDB, error) {
 	if len(urls) == 0 {
 		return nil, fmt.Errorf("no URLs provided")
 	}
 
+	type loaded struct {
+		db  *VulnDB
+		err error
+	}
+	o := newLoadOptions(opts)
+	results := make([]loaded, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].db, results[i].err = LoadFromURLWithOptions(url, opts...)
+		}()
+	}
+	wg.Wait()
+
 	db := NewVulnDB()
-	var errors []string
+	var failures []string
 	successCount := 0
 
-	for _, url := range urls {
-		sourceDB, err := LoadFromURL(url)
+	for i, url := range urls {
+		sourceDB, err := results[i].db, results[i].err
+		var mismatch *ChecksumMismatchError
+		if errors.As(err, &mismatch) {
+			// A tampered source must fail the load, not be skipped
+			return nil, err
+		}
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", url, err))
-			continue
+			cachedDB, cacheErr := o.loadFromCache(url)
+			if errors.As(cacheErr, &mismatch) {
+				return nil, cacheErr
+			}
+			if cacheErr != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", url, err))
+				continue
+			}
+			sourceDB = cachedDB
 		}
 		db.Merge(sourceDB)
 		successCount++
 	}
 
 	if successCount == 0 {
-		return nil, fmt.Errorf("failed to load any IOC sources: %s", strings.Join(errors, "; "))
+		return nil, fmt.Errorf("failed to load any IOC sources: %s", strings.Join(failures, "; "))
 	}
 
 	return db, nil
@@ -1,8 +1,10 @@
 package vuln
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test package names that are clearly fake and won't match real packages
@@ -248,7 +250,10 @@ test-muaddib-vulnerable-pkg-1,1.0.0,"test"`
 		{"1.0.1", false},   // different patch
 		{"1.0", false},     // missing patch
 		{"1.0.0.0", false}, // extra component
-		{"v1.0.0", false},  // v prefix
+		{"v1.0.0", true},   // v prefix
+		{"V1.0.0", true},   // upper case V prefix
+		{"vv1.0.0", false}, // only one v is dropped
+		{"1.0.0v", false},  // trailing junk
 		{" 1.0.0", false},  // leading space
 		{"1.0.0 ", false},  // trailing space
 	}
@@ -266,6 +271,24 @@ test-muaddib-vulnerable-pkg-1,1.0.0,"test"`
 	}
 }
 
+func TestCheck_IgnoresVersionPrefix(t *testing.T) {
+	db := NewVulnDB()
+	db.Add(&VulnEntry{PackageName: testPkgVulnerable1, PackageVersion: "v2.0.0", Sources: []string{"test-a"}})
+	db.Add(&VulnEntry{PackageName: testPkgVulnerable1, PackageVersion: "2.0.0", Sources: []string{"test-b"}})
+	db.Add(&VulnEntry{PackageName: testPkgVulnerable1, PackageVersion: "3.0.0v"})
+
+	entry := db.Check(testPkgVulnerable1, "2.0.0")
+	if entry == nil {
+		t.Fatal("expected 2.0.0 to match the v2.0.0 indicator")
+	}
+	if db.Size() != 2 || len(entry.Sources) != 2 {
+		t.Errorf("expected v2.0.0 and 2.0.0 to be one entry from both sources, got %d entries and sources %v", db.Size(), entry.Sources)
+	}
+	if db.Check(testPkgVulnerable1, "3.0.0") != nil {
+		t.Error("expected a trailing v to be left alone")
+	}
+}
+
 func TestGetVulnerableVersions(t *testing.T) {
 	csv := `package_name,package_versions,sources
 test-muaddib-multi-version,"1.0.0, 2.0.0, 3.0.0","test"`
@@ -480,6 +503,28 @@ test-muaddib-merge-pkg-1,= 1.0.0`
 	}
 }
 
+func TestVulnDB_Merge_UnionsSources(t *testing.T) {
+	db, err := parseCSV(strings.NewReader(`package_name,package_versions,sources
+test-muaddib-merge-sources,"1.0.0, 1.0.1","datadog; koi"`))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+	other, err := parseCSV(strings.NewReader(`package_name,package_versions,sources
+test-muaddib-merge-sources,1.0.0,"wiz, datadog"`))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	db.Merge(other)
+
+	if got := db.Check("test-muaddib-merge-sources", "1.0.0").Sources; !slices.Equal(got, []string{"datadog", "koi", "wiz"}) {
+		t.Errorf("expected the sources of both feeds, got %v", got)
+	}
+	if got := db.Check("test-muaddib-merge-sources", "1.0.1").Sources; !slices.Equal(got, []string{"datadog", "koi"}) {
+		t.Errorf("expected the other version from the same row to keep its sources, got %v", got)
+	}
+}
+
 func TestVulnDB_MergeNil(t *testing.T) {
 	csv := `package_name,package_versions,sources
 test-muaddib-merge-nil,1.0.0,"test"`
@@ -525,3 +570,235 @@ func TestDefaultIOCURLs(t *testing.T) {
 		t.Error("Wiz IOC URL not found in default URLs")
 	}
 }
+
+func TestCheck_ScopeWildcard(t *testing.T) {
+	csv := `package_name,package_versions,sources
+@test-muaddib/*,,"test"
+test-muaddib-vulnerable-pkg-1,1.0.0,"test"`
+
+	db, err := parseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	if db.Scopes() != 1 {
+		t.Errorf("expected 1 scope-wide entry, got %d", db.Scopes())
+	}
+
+	testCases := []struct {
+		name      string
+		version   string
+		shouldHit bool
+	}{
+		{"@test-muaddib/one", "1.0.0", true},
+		{"@test-muaddib/two", "9.9.9", true},
+		{"@test-muaddib-other/one", "1.0.0", false},
+		{"test-muaddib", "1.0.0", false},
+		{"@test-muaddib/one", "", false},
+	}
+
+	for _, tc := range testCases {
+		entry := db.Check(tc.name, tc.version)
+		if (entry != nil) != tc.shouldHit {
+			t.Errorf("Check(%q, %q): expected hit=%v, got %v", tc.name, tc.version, tc.shouldHit, entry)
+			continue
+		}
+		if entry != nil && !entry.ScopeWide {
+			t.Errorf("Check(%q, %q): expected a scope-wide entry", tc.name, tc.version)
+		}
+	}
+
+	if entry := db.Check(testPkgVulnerable1, "1.0.0"); entry == nil || entry.ScopeWide {
+		t.Error("expected exact entries to keep matching")
+	}
+}
+
+func TestParseScopeWildcard(t *testing.T) {
+	testCases := []struct {
+		name  string
+		scope string
+		ok    bool
+	}{
+		{"@ctrl/*", "@ctrl", true},
+		{"@ctrl/tinycolor", "", false},
+		{"ctrl/*", "", false},
+		{"@/*", "", false},
+		{"@a/b/*", "", false},
+	}
+
+	for _, tc := range testCases {
+		scope, ok := parseScopeWildcard(tc.name)
+		if scope != tc.scope || ok != tc.ok {
+			t.Errorf("parseScopeWildcard(%q) = (%q, %v), want (%q, %v)", tc.name, scope, ok, tc.scope, tc.ok)
+		}
+	}
+}
+
+func TestVulnDB_MergeScopes(t *testing.T) {
+	other := NewVulnDB()
+	other.Add(&VulnEntry{PackageName: "@test-muaddib/*", PackageVersion: "*", ScopeWide: true})
+
+	db := NewVulnDB()
+	db.Merge(other)
+
+	if db.Check("@test-muaddib/any", "1.0.0") == nil {
+		t.Error("expected merged scope entry to match")
+	}
+}
+
+func TestParseCSV_DateColumn(t *testing.T) {
+	csv := `package_name,package_versions,date_added
+test-muaddib-vulnerable-pkg-1,1.0.0,2025-09-15
+test-muaddib-vulnerable-pkg-2,2.0.0,2025-11-24T08:00:00Z
+test-muaddib-safe-pkg,3.0.0,
+test-muaddib-multi-version,4.0.0,not-a-date`
+
+	var warnings []string
+	defer SetWarningFunc(SetWarningFunc(func(msg string) { warnings = append(warnings, msg) }))
+
+	db, err := parseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	testCases := []struct {
+		name, version, want string
+	}{
+		{testPkgVulnerable1, "1.0.0", "2025-09-15"},
+		{testPkgVulnerable2, "2.0.0", "2025-11-24"},
+		{testPkgSafe, "3.0.0", ""},
+		{testPkgMultiVersion, "4.0.0", ""},
+	}
+	for _, tc := range testCases {
+		entry := db.Check(tc.name, tc.version)
+		if entry == nil {
+			t.Fatalf("expected entry for %s", tc.name)
+		}
+		got := ""
+		if !entry.Added.IsZero() {
+			got = entry.Added.Format("2006-01-02")
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected date %q, got %q", tc.name, tc.want, got)
+		}
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "not-a-date") {
+		t.Errorf("expected a single warning for the unparseable date, got %v", warnings)
+	}
+}
+
+func TestVulnDB_FilterByDate(t *testing.T) {
+	csv := `package_name,package_versions,date
+test-muaddib-vulnerable-pkg-1,1.0.0,2025-08-01
+test-muaddib-vulnerable-pkg-2,2.0.0,2025-09-15
+test-muaddib-multi-version,3.0.0,2025-11-24
+test-muaddib-safe-pkg,4.0.0,
+@test-muaddib/*,,2025-11-25`
+
+	db, err := parseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatalf("bad test date %q: %v", s, err)
+		}
+		return d
+	}
+
+	testCases := []struct {
+		name          string
+		after, before time.Time
+		included      []string
+	}{
+		{"no window", time.Time{}, time.Time{}, []string{testPkgVulnerable1, testPkgVulnerable2, testPkgMultiVersion, testPkgSafe, "@test-muaddib/x"}},
+		{"after only", day("2025-09-15"), time.Time{}, []string{testPkgVulnerable2, testPkgMultiVersion, testPkgSafe, "@test-muaddib/x"}},
+		{"before only", time.Time{}, day("2025-09-15"), []string{testPkgVulnerable1, testPkgSafe}},
+		{"campaign window", day("2025-09-01"), day("2025-10-01"), []string{testPkgVulnerable2, testPkgSafe}},
+	}
+
+	versions := map[string]string{
+		testPkgVulnerable1:  "1.0.0",
+		testPkgVulnerable2:  "2.0.0",
+		testPkgMultiVersion: "3.0.0",
+		testPkgSafe:         "4.0.0",
+		"@test-muaddib/x":   "9.9.9",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered := db.FilterByDate(tc.after, tc.before)
+			for name, version := range versions {
+				want := slices.Contains(tc.included, name)
+				if got := filtered.Check(name, version) != nil; got != want {
+					t.Errorf("%s: expected included=%v, got %v", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSV_KeepsRawRowAsEvidence(t *testing.T) {
+	csvData := `package_name,package_version,source
+` + testPkgMultiVersion + `,"1.0.0, 1.0.1",test-feed`
+
+	db, err := parseCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	want := testPkgMultiVersion + `,"1.0.0, 1.0.1",test-feed`
+	for _, version := range []string{"1.0.0", "1.0.1"} {
+		entry := db.Check(testPkgMultiVersion, version)
+		if entry == nil {
+			t.Fatalf("expected %s@%s to be found", testPkgMultiVersion, version)
+		}
+		if got := entry.Evidence(); got != want {
+			t.Errorf("expected evidence %q, got %q", want, got)
+		}
+	}
+}
+
+func TestVulnEntryEvidence_ReconstructsMissingRow(t *testing.T) {
+	entry := &VulnEntry{PackageName: testPkgVulnerable1, PackageVersion: "1.0.0"}
+
+	if got, want := entry.Evidence(), testPkgVulnerable1+",1.0.0"; got != want {
+		t.Errorf("expected reconstructed evidence %q, got %q", want, got)
+	}
+}
+
+func TestParseCSV_IntegrityColumn(t *testing.T) {
+	csvData := `package_name,package_versions,integrity
+` + testPkgVulnerable1 + `,1.0.0,sha512-bad1
+` + testPkgVulnerable2 + `,,"sha512-bad2 sha1-bad2"`
+
+	db, err := parseCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	if entry := db.Check(testPkgVulnerable1, "1.0.0"); entry == nil || len(entry.Integrity) != 1 {
+		t.Errorf("expected %s@1.0.0 with one integrity hash, got %+v", testPkgVulnerable1, entry)
+	}
+	if entry := db.CheckIntegrity("sha512-bad1"); entry == nil || entry.PackageName != testPkgVulnerable1 {
+		t.Errorf("expected sha512-bad1 to match %s, got %+v", testPkgVulnerable1, entry)
+	}
+	if entry := db.CheckIntegrity("sha512-other sha1-bad2"); entry == nil || entry.PackageName != testPkgVulnerable2 {
+		t.Errorf("expected any listed hash to match %s, got %+v", testPkgVulnerable2, entry)
+	}
+	if entry := db.CheckIntegrity("sha512-good"); entry != nil {
+		t.Errorf("expected no match for an unknown hash, got %+v", entry)
+	}
+	if db.Size() != 1 {
+		t.Errorf("expected the integrity-only row to add no versions, got size %d", db.Size())
+	}
+
+	merged := NewVulnDB()
+	merged.Merge(db)
+	if merged.CheckIntegrity("sha512-bad2") == nil {
+		t.Error("expected Merge to keep integrity-only entries")
+	}
+}
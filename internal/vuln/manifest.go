@@ -0,0 +1,121 @@
+package vuln
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestSource is a single feed entry in a source manifest.
+// It may be written as a plain URL string or as a mapping with a url key and
+// an optional campaign label.
+type manifestSource struct {
+	URL      string `yaml:"url"`
+	Campaign string `yaml:"campaign"`
+}
+
+// UnmarshalYAML accepts either a scalar URL or a mapping
+func (m *manifestSource) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		m.URL = node.Value
+		return nil
+	}
+	type plain manifestSource
+	return node.Decode((*plain)(m))
+}
+
+// sourceManifest is the mapping form of a source manifest
+type sourceManifest struct {
+	Sources []manifestSource `yaml:"sources"`
+}
+
+// ParseSourceManifest parses a JSON or YAML manifest listing IOC feed URLs,
+// returning the URLs and the campaign labels of the feeds that have one.
+// Both a top-level list and a mapping with a "sources" key are accepted:
+//
+//	sources:
+//	  - https://example.com/iocs.csv
+//	  - url: https://example.com/more-iocs.csv
+//	    campaign: shai-hulud-2.0
+//
+// Relative URLs are resolved against base, if provided.
+func ParseSourceManifest(r io.Reader, base *url.URL) ([]string, Campaigns, error) {
+	var root yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse source manifest: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, fmt.Errorf("source manifest is empty")
+	}
+
+	var sources []manifestSource
+	doc := root.Content[0]
+	if doc.Kind == yaml.SequenceNode {
+		if err := doc.Decode(&sources); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse source manifest: %w", err)
+		}
+	} else {
+		var m sourceManifest
+		if err := doc.Decode(&m); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse source manifest: %w", err)
+		}
+		sources = m.Sources
+	}
+
+	var urls []string
+	campaigns := make(Campaigns)
+	for _, src := range sources {
+		if src.URL == "" {
+			continue
+		}
+		u, err := url.Parse(src.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid source URL %q: %w", src.URL, err)
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		urls = append(urls, u.String())
+		if src.Campaign != "" {
+			campaigns[u.String()] = src.Campaign
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, nil, fmt.Errorf("source manifest does not list any sources")
+	}
+
+	return urls, campaigns, nil
+}
+
+// FetchSourceManifest fetches a source manifest and returns the feed URLs it
+// lists and the campaign labels it gives them
+func FetchSourceManifest(manifestURL string, opts ...LoadOption) ([]string, Campaigns, error) {
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid source manifest URL: %w", err)
+	}
+
+	content, err := fetch(manifestURL, newLoadOptions(opts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch source manifest: %w", err)
+	}
+
+	return ParseSourceManifest(bytes.NewReader(content), base)
+}
+
+// LoadFromSourceManifest fetches a source manifest and loads and merges every
+// feed it lists. Labels set with WithCampaigns take precedence over those in
+// the manifest.
+func LoadFromSourceManifest(manifestURL string, opts ...LoadOption) (*VulnDB, error) {
+	urls, campaigns, err := FetchSourceManifest(manifestURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	campaigns = newLoadOptions(opts).campaigns.withFallback(campaigns)
+	return LoadFromMultipleURLs(urls, append(slices.Clip(opts), WithCampaigns(campaigns))...)
+}
@@ -0,0 +1,77 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseSourceManifest_Forms(t *testing.T) {
+	testCases := []struct {
+		name     string
+		manifest string
+	}{
+		{"json list", `["https://example.test/a.csv", "https://example.test/b.csv"]`},
+		{"json object", `{"sources": [{"url": "https://example.test/a.csv"}, "https://example.test/b.csv"]}`},
+		{"yaml", "sources:\n  - https://example.test/a.csv\n  - url: https://example.test/b.csv\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			urls, _, err := ParseSourceManifest(strings.NewReader(tc.manifest), nil)
+			if err != nil {
+				t.Fatalf("ParseSourceManifest failed: %v", err)
+			}
+			if len(urls) != 2 || urls[0] != "https://example.test/a.csv" || urls[1] != "https://example.test/b.csv" {
+				t.Errorf("unexpected URLs: %v", urls)
+			}
+		})
+	}
+}
+
+func TestParseSourceManifest_ResolvesRelativeURLs(t *testing.T) {
+	base, _ := url.Parse("https://example.test/feeds/manifest.yaml")
+
+	urls, _, err := ParseSourceManifest(strings.NewReader("sources:\n  - iocs.csv\n"), base)
+	if err != nil {
+		t.Fatalf("ParseSourceManifest failed: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://example.test/feeds/iocs.csv" {
+		t.Errorf("expected resolved URL, got %v", urls)
+	}
+}
+
+func TestParseSourceManifest_Empty(t *testing.T) {
+	if _, _, err := ParseSourceManifest(strings.NewReader(`{"sources": []}`), nil); err == nil {
+		t.Error("expected error for manifest without sources")
+	}
+}
+
+func TestLoadFromSourceManifest_MergesFeeds(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sources": ["/feed-a.csv", "/feed-b.csv"]}`))
+	})
+	mux.HandleFunc("/feed-a.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package_name,package_versions,sources\n" + testPkgVulnerable1 + ",1.0.0,\"a\"\n"))
+	})
+	mux.HandleFunc("/feed-b.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Package,Version\n" + testPkgVulnerable2 + ",= 2.0.0\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	db, err := LoadFromSourceManifest(server.URL + "/manifest.json")
+	if err != nil {
+		t.Fatalf("LoadFromSourceManifest failed: %v", err)
+	}
+
+	if db.Check(testPkgVulnerable1, "1.0.0") == nil {
+		t.Error("expected entry from feed A")
+	}
+	if db.Check(testPkgVulnerable2, "2.0.0") == nil {
+		t.Error("expected entry from feed B")
+	}
+}
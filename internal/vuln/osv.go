@@ -0,0 +1,178 @@
+package vuln
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// osvEcosystem is the OSV ecosystem of the packages muaddib checks
+const osvEcosystem = "npm"
+
+// osvAdvisory is the part of an OSV advisory muaddib reads. See
+// https://ossf.github.io/osv-schema/ for the full schema.
+type osvAdvisory struct {
+	ID        string        `json:"id"`
+	Published string        `json:"published"`
+	Affected  []osvAffected `json:"affected"`
+}
+
+// osvAffected lists the affected versions of one package
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Versions []string   `json:"versions"`
+	Ranges   []osvRange `json:"ranges"`
+}
+
+// osvRange is a sequence of introduced, fixed, and last_affected events
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+// osvEvent is a single version event of a range
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// LoadFromOSV parses vulnerability entries from OSV JSON. The input may be a
+// single advisory, an array of advisories, or an object with a "vulns" array
+// as returned by the OSV API. Only npm packages are read; each affected
+// version and version range becomes an entry carrying the advisory ID.
+func LoadFromOSV(r io.Reader) (*VulnDB, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OSV JSON: %w", err)
+	}
+	advisories, err := decodeOSV(content)
+	if err != nil {
+		return nil, err
+	}
+
+	db := NewVulnDB()
+	for _, advisory := range advisories {
+		addOSVAdvisory(db, advisory)
+	}
+	return db, nil
+}
+
+// decodeOSV decodes the advisories in any of the accepted OSV layouts
+func decodeOSV(content []byte) ([]osvAdvisory, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) > 0 && content[0] == '[' {
+		var advisories []osvAdvisory
+		if err := json.Unmarshal(content, &advisories); err != nil {
+			return nil, fmt.Errorf("failed to parse OSV JSON: %w", err)
+		}
+		return advisories, nil
+	}
+
+	var doc struct {
+		osvAdvisory
+		Vulns []osvAdvisory `json:"vulns"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OSV JSON: %w", err)
+	}
+	if doc.Vulns != nil {
+		return doc.Vulns, nil
+	}
+	return []osvAdvisory{doc.osvAdvisory}, nil
+}
+
+// addOSVAdvisory adds an entry for each npm version and range an advisory affects
+func addOSVAdvisory(db *VulnDB, advisory osvAdvisory) {
+	var added time.Time
+	if advisory.Published != "" {
+		if published, err := time.Parse(time.RFC3339, advisory.Published); err == nil {
+			added = published
+		}
+	}
+
+	for _, affected := range advisory.Affected {
+		name := strings.TrimSpace(affected.Package.Name)
+		if name == "" || !strings.EqualFold(affected.Package.Ecosystem, osvEcosystem) {
+			continue
+		}
+		for _, version := range affected.Versions {
+			db.Add(&VulnEntry{PackageName: name, PackageVersion: version, OriginalVersion: version, Added: added, AdvisoryID: advisory.ID})
+		}
+		for _, spec := range osvRangeSpecs(affected.Ranges) {
+			r, err := parseVersionRange(spec)
+			if err != nil {
+				warn("Skipping %s range %q from %s: %v", name, spec, advisory.ID, err)
+				continue
+			}
+			db.Add(&VulnEntry{PackageName: name, PackageVersion: spec, OriginalVersion: spec, Added: added, Range: r, AdvisoryID: advisory.ID})
+		}
+	}
+}
+
+// osvRangeSpecs converts the SEMVER and ECOSYSTEM ranges of an affected
+// package to npm range specs. Each introduced event opens a range that the
+// next fixed or last_affected event closes; "0" means every earlier version.
+func osvRangeSpecs(ranges []osvRange) []string {
+	var specs []string
+	for _, r := range ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue // GIT ranges name commits, not package versions
+		}
+		lower := ""
+		open := false
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				lower, open = osvLowerBound(event.Introduced), true
+			case event.Fixed != "" && open:
+				specs = append(specs, strings.TrimSpace(lower+" <"+event.Fixed))
+				open = false
+			case event.LastAffected != "" && open:
+				specs = append(specs, strings.TrimSpace(lower+" <="+event.LastAffected))
+				open = false
+			}
+		}
+		if open {
+			specs = append(specs, osvOpenRange(lower))
+		}
+	}
+	return specs
+}
+
+// osvLowerBound returns the comparator for an introduced version, or "" when
+// the range starts at the first version
+func osvLowerBound(introduced string) string {
+	if introduced == "0" {
+		return ""
+	}
+	return ">=" + introduced
+}
+
+// osvOpenRange returns the spec of a range with no fixed version
+func osvOpenRange(lower string) string {
+	if lower == "" {
+		return ">=0.0.0"
+	}
+	return lower
+}
+
+// isOSVSource checks if an IOC source is OSV JSON rather than CSV, by its
+// extension or, failing that, by its content starting like a JSON document
+func isOSVSource(location string, content []byte) bool {
+	switch strings.ToLower(path.Ext(strings.SplitN(location, "?", 2)[0])) {
+	case ".json":
+		return true
+	case ".csv":
+		return false
+	}
+	trimmed := bytes.TrimLeft(content, " \t\r\n\ufeff")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
@@ -0,0 +1,144 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// osvAdvisories is an OSV export with exact versions, ranges, and a
+// package from another ecosystem
+const osvAdvisories = `[
+  {
+    "id": "GHSA-test-0001",
+    "published": "2025-11-24T12:00:00Z",
+    "affected": [
+      {"package": {"ecosystem": "npm", "name": "test-muaddib-exact"}, "versions": ["1.0.1", "1.0.2"]},
+      {"package": {"ecosystem": "PyPI", "name": "test-muaddib-python"}, "versions": ["1.0.0"]}
+    ]
+  },
+  {
+    "id": "MAL-test-0002",
+    "affected": [
+      {
+        "package": {"ecosystem": "npm", "name": "@test-muaddib/ranged"},
+        "ranges": [
+          {"type": "SEMVER", "events": [{"introduced": "2.0.0"}, {"fixed": "2.1.0"}, {"introduced": "3.0.0"}, {"last_affected": "3.0.5"}]},
+          {"type": "GIT", "events": [{"introduced": "abc123"}]}
+        ]
+      },
+      {
+        "package": {"ecosystem": "npm", "name": "test-muaddib-all"},
+        "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]
+      }
+    ]
+  }
+]`
+
+func TestLoadFromOSV(t *testing.T) {
+	db, err := LoadFromOSV(strings.NewReader(osvAdvisories))
+	if err != nil {
+		t.Fatalf("LoadFromOSV failed: %v", err)
+	}
+
+	tests := []struct {
+		name, version string
+		wantAdvisory  string
+	}{
+		{"test-muaddib-exact", "1.0.1", "GHSA-test-0001"},
+		{"test-muaddib-exact", "1.0.3", ""},
+		{"test-muaddib-python", "1.0.0", ""},
+		{"@test-muaddib/ranged", "2.0.5", "MAL-test-0002"},
+		{"@test-muaddib/ranged", "2.1.0", ""},
+		{"@test-muaddib/ranged", "3.0.5", "MAL-test-0002"},
+		{"@test-muaddib/ranged", "3.0.6", ""},
+		{"test-muaddib-all", "0.0.1", "MAL-test-0002"},
+	}
+	for _, tt := range tests {
+		entry := db.Check(tt.name, tt.version)
+		got := ""
+		if entry != nil {
+			got = entry.AdvisoryID
+		}
+		if got != tt.wantAdvisory {
+			t.Errorf("%s@%s: expected advisory %q, got %q", tt.name, tt.version, tt.wantAdvisory, got)
+		}
+	}
+
+	if entry := db.Check("test-muaddib-exact", "1.0.2"); entry == nil || entry.Added.IsZero() {
+		t.Errorf("expected the published date to be kept, got %+v", entry)
+	}
+}
+
+func TestLoadFromOSV_AcceptsSingleAdvisoryAndAPIResponse(t *testing.T) {
+	single := `{"id": "GHSA-test-0003", "affected": [{"package": {"ecosystem": "npm", "name": "test-muaddib-single"}, "versions": ["1.0.0"]}]}`
+	response := `{"vulns": [` + single + `]}`
+
+	for _, input := range []string{single, response} {
+		db, err := LoadFromOSV(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("LoadFromOSV failed: %v", err)
+		}
+		if entry := db.Check("test-muaddib-single", "1.0.0"); entry == nil || entry.AdvisoryID != "GHSA-test-0003" {
+			t.Errorf("expected the advisory to be loaded from %s, got %+v", input, entry)
+		}
+	}
+
+	if _, err := LoadFromOSV(strings.NewReader("{not json")); err == nil {
+		t.Error("expected invalid JSON to fail")
+	}
+}
+
+func TestLoadFromFile_DetectsOSV(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"advisories.json", "advisories.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(osvAdvisories), 0o600); err != nil {
+			t.Fatalf("failed to write advisories: %v", err)
+		}
+
+		db, err := LoadFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadFromFile(%s) failed: %v", name, err)
+		}
+		if db.Check("test-muaddib-exact", "1.0.1") == nil {
+			t.Errorf("expected %s to be loaded as OSV JSON", name)
+		}
+	}
+}
+
+func TestLoadFromReader_DetectsFormat(t *testing.T) {
+	for _, content := range []string{osvAdvisories, "package_name,package_versions\ntest-muaddib-exact,1.0.1\n"} {
+		db, err := LoadFromReader("stdin", strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("LoadFromReader failed: %v", err)
+		}
+		if db.Check("test-muaddib-exact", "1.0.1") == nil {
+			t.Errorf("expected the IOC to be loaded from %q", content[:20])
+		}
+		if sources := db.Sources(); len(sources) != 1 || sources[0].Location != "stdin" {
+			t.Errorf("expected stdin to be recorded as the source, got %+v", sources)
+		}
+	}
+}
+
+func TestLoadFromURL_DetectsOSV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(osvAdvisories))
+	}))
+	defer server.Close()
+
+	db, err := LoadFromURL(server.URL + "/osv.json?ref=main")
+	if err != nil {
+		t.Fatalf("LoadFromURL failed: %v", err)
+	}
+	if entry := db.Check("@test-muaddib/ranged", "2.0.0"); entry == nil || entry.AdvisoryID != "MAL-test-0002" {
+		t.Errorf("expected the OSV range to be loaded, got %+v", entry)
+	}
+	if sources := db.Sources(); len(sources) != 1 || sources[0].Entries != 5 {
+		t.Errorf("expected the source recorded with 5 entries, got %+v", sources)
+	}
+}
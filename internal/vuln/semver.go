@@ -0,0 +1,269 @@
+package vuln
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version. Build metadata is ignored.
+type semver struct {
+	core       [3]int // major, minor, patch
+	prerelease []string
+}
+
+// parseSemver parses a version such as "1.2.3", "v1.2.3" or "1.2.3-beta.1".
+// Missing minor or patch parts default to zero.
+func parseSemver(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v semver
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// compareSemver returns -1, 0 or 1. A prerelease sorts before its release.
+func compareSemver(a, b semver) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			return compareInts(a.core[i], b.core[i])
+		}
+	}
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		if c := comparePrereleaseIdentifier(a.prerelease[i], b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(a.prerelease), len(b.prerelease))
+}
+
+// comparePrereleaseIdentifier compares numeric identifiers numerically, and
+// sorts them before alphanumeric ones, which compare lexically
+func comparePrereleaseIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// CompareVersions orders two concrete versions, returning -1, 0 or 1.
+// Versions that are not semver sort after those that are, and lexically
+// among themselves.
+func CompareVersions(a, b string) int {
+	av, aOK := parseSemver(a)
+	bv, bOK := parseSemver(b)
+	switch {
+	case aOK && bOK:
+		if c := compareSemver(av, bv); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aOK:
+		return -1
+	case bOK:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparator is a single range bound such as ">=1.0.0"
+type comparator struct {
+	op      string // One of "=", ">", ">=", "<", "<="
+	version semver
+}
+
+func (c comparator) matches(v semver) bool {
+	cmp := compareSemver(v, c.version)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// VersionRange is an npm-style version range from an IOC feed, such as
+// ">=1.0.0 <1.2.0" or "1.0.0 - 1.1.5". All comparators must match.
+type VersionRange struct {
+	Spec        string
+	comparators []comparator
+}
+
+// parseVersionRange parses a single range without "||" alternatives
+func parseVersionRange(spec string) (*VersionRange, error) {
+	spec = strings.TrimSpace(spec)
+	r := &VersionRange{Spec: spec}
+
+	// Hyphen ranges are inclusive at both ends
+	if lower, upper, ok := strings.Cut(spec, " - "); ok {
+		lo, loOK := parseSemver(lower)
+		hi, hiOK := parseSemver(upper)
+		if !loOK || !hiOK {
+			return nil, fmt.Errorf("invalid hyphen range %q", spec)
+		}
+		r.comparators = []comparator{{">=", lo}, {"<=", hi}}
+		return r, nil
+	}
+
+	// Allow a space between an operator and its version, e.g. ">= 1.0.0"
+	fields := strings.Fields(spec)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if isRangeOperator(field) && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+		c, err := parseComparator(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", spec, err)
+		}
+		r.comparators = append(r.comparators, c)
+	}
+	if len(r.comparators) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+	return r, nil
+}
+
+// rangeOperators are the supported comparator operators, longest first
+var rangeOperators = []string{">=", "<=", ">", "<", "="}
+
+func isRangeOperator(s string) bool {
+	for _, op := range rangeOperators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparator parses an operator and version such as ">=1.0.0"
+func parseComparator(s string) (comparator, error) {
+	op := "="
+	for _, candidate := range rangeOperators {
+		if strings.HasPrefix(s, candidate) {
+			op = candidate
+			s = s[len(candidate):]
+			break
+		}
+	}
+	v, ok := parseSemver(s)
+	if !ok {
+		return comparator{}, fmt.Errorf("invalid version %q", s)
+	}
+	return comparator{op: op, version: v}, nil
+}
+
+// Contains reports whether a concrete version falls within the range.
+// As in npm, prerelease versions only match a range that names a
+// prerelease of the same major.minor.patch.
+func (r *VersionRange) Contains(version string) bool {
+	v, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+	if len(v.prerelease) > 0 && !r.allowsPrereleaseOf(v) {
+		return false
+	}
+	for _, c := range r.comparators {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrereleaseOf checks if a comparator names a prerelease of v's release
+func (r *VersionRange) allowsPrereleaseOf(v semver) bool {
+	for _, c := range r.comparators {
+		if len(c.version.prerelease) > 0 && c.version.core == v.core {
+			return true
+		}
+	}
+	return false
+}
+
+// isRangeSpec checks if an IOC version field uses range operators rather than
+// listing exact versions
+func isRangeSpec(versionField string) bool {
+	return strings.ContainsAny(versionField, "<>") || strings.Contains(versionField, " - ")
+}
+
+// parseVersionSpec splits an IOC version field into exact versions and ranges.
+// Alternatives are separated by "||" or commas; each is either an exact
+// version (optionally prefixed with "=") or a range.
+func parseVersionSpec(versionField string) (versions []string, ranges []*VersionRange, err error) {
+	if !isRangeSpec(versionField) {
+		return parseVersionList(versionField), nil, nil
+	}
+
+	alternatives := strings.FieldsFunc(strings.ReplaceAll(versionField, "||", ","), func(r rune) bool { return r == ',' })
+	for _, part := range alternatives {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if exact := strings.TrimSpace(strings.TrimPrefix(part, "=")); !isRangeSpec(exact) && !strings.ContainsAny(exact, " ") {
+			versions = append(versions, exact)
+			continue
+		}
+		r, err := parseVersionRange(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return versions, ranges, nil
+}
@@ -0,0 +1,117 @@
+package vuln
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVersionRange_Contains(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		included []string
+		excluded []string
+	}{
+		{
+			spec:     ">=1.0.0 <1.2.0",
+			included: []string{"1.0.0", "1.1.0", "1.1.9"},
+			excluded: []string{"0.9.9", "1.2.0", "2.0.0", "1.1.0-beta.1", "not-a-version"},
+		},
+		{
+			spec:     "1.0.0 - 1.1.5",
+			included: []string{"1.0.0", "1.0.7", "1.1.5"},
+			excluded: []string{"0.9.0", "1.1.6", "1.2.0"},
+		},
+		{
+			spec:     ">= 2.0.0-rc.1 <= 2.0.0",
+			included: []string{"2.0.0-rc.1", "2.0.0-rc.2", "2.0.0"},
+			excluded: []string{"2.0.0-beta.9", "2.0.1"},
+		},
+		{
+			spec:     ">3.0.0",
+			included: []string{"3.0.1", "v4.0.0"},
+			excluded: []string{"3.0.0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			r, err := parseVersionRange(tc.spec)
+			if err != nil {
+				t.Fatalf("parseVersionRange failed: %v", err)
+			}
+			for _, v := range tc.included {
+				if !r.Contains(v) {
+					t.Errorf("expected %s to be in %q", v, tc.spec)
+				}
+			}
+			for _, v := range tc.excluded {
+				if r.Contains(v) {
+					t.Errorf("expected %s not to be in %q", v, tc.spec)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	versions := []string{"1.10.0", "not-a-version", "1.2.0", "1.2.0-beta.2", "1.2.0-beta.10", "0.9.1", "git-sha"}
+
+	slices.SortFunc(versions, CompareVersions)
+
+	want := []string{"0.9.1", "1.2.0-beta.2", "1.2.0-beta.10", "1.2.0", "1.10.0", "git-sha", "not-a-version"}
+	if !slices.Equal(versions, want) {
+		t.Errorf("expected %v, got %v", want, versions)
+	}
+}
+
+func TestParseVersionSpec_MixedExactAndRanges(t *testing.T) {
+	versions, ranges, err := parseVersionSpec("= 0.5.0 || >=1.0.0 <1.2.0 || 2.0.0 - 2.0.3")
+	if err != nil {
+		t.Fatalf("parseVersionSpec failed: %v", err)
+	}
+
+	if len(versions) != 1 || versions[0] != "0.5.0" {
+		t.Errorf("expected exact version 0.5.0, got %v", versions)
+	}
+	if len(ranges) != 2 || ranges[0].Spec != ">=1.0.0 <1.2.0" || ranges[1].Spec != "2.0.0 - 2.0.3" {
+		t.Errorf("expected two ranges, got %v", ranges)
+	}
+
+	if _, _, err := parseVersionSpec(">=banana"); err == nil {
+		t.Error("expected an invalid range to be rejected")
+	}
+}
+
+func TestParseCSV_RangeSpecsMatchConcreteVersions(t *testing.T) {
+	csvData := `package_name,package_versions,affected_version_ranges
+` + testPkgVulnerable1 + `,,">=1.0.0 <1.2.0"
+` + testPkgVulnerable2 + `,3.0.0,1.0.0 - 1.1.5`
+
+	db, err := parseCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseCSV failed: %v", err)
+	}
+
+	testCases := []struct {
+		name, version string
+		want          bool
+	}{
+		{testPkgVulnerable1, "1.0.0", true},
+		{testPkgVulnerable1, "1.1.3", true},
+		{testPkgVulnerable1, "1.2.0", false},
+		{testPkgVulnerable2, "1.1.5", true},
+		{testPkgVulnerable2, "1.1.6", false},
+		{testPkgVulnerable2, "3.0.0", true},
+	}
+	for _, tc := range testCases {
+		entry := db.Check(tc.name, tc.version)
+		if got := entry != nil; got != tc.want {
+			t.Errorf("%s@%s: expected match=%v, got %v", tc.name, tc.version, tc.want, got)
+		}
+	}
+
+	if entry := db.Check(testPkgVulnerable1, "1.1.3"); entry != nil && entry.PackageVersion != ">=1.0.0 <1.2.0" {
+		t.Errorf("expected the range spec as the IOC version, got %q", entry.PackageVersion)
+	}
+}
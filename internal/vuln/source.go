@@ -0,0 +1,72 @@
+package vuln
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Source records an IOC feed the database was loaded from, so reports can
+// state the basis of their detections
+type Source struct {
+	Location  string    // URL or file path
+	FetchedAt time.Time // When the content was read
+	SHA256    string    // Digest of the content as read
+	Entries   int       // Entries parsed from the source, before deduplication
+	Cached    bool      // Loaded from the cache because the fetch failed
+	Campaign  string    // Campaign the source is labelled with, if any
+}
+
+// Sources returns the IOC feeds the database was loaded from, in load order.
+// Databases built by ParseCSVForTest or NewVulnDB have none.
+func (db *VulnDB) Sources() []Source {
+	return db.sources
+}
+
+// parseSource parses the content of an IOC feed, as OSV JSON or CSV, and
+// records it as a source labelled with campaign
+func parseSource(location string, content []byte, fetchedAt time.Time, campaign string) (*VulnDB, error) {
+	parse := parseCSV
+	if isOSVSource(location, content) {
+		parse = LoadFromOSV
+	}
+	db, err := parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	label := sourceLabel(location)
+	db.each(func(entry *VulnEntry) {
+		entry.Campaign = campaign
+		if len(entry.Sources) == 0 {
+			entry.Sources = []string{label}
+		}
+	})
+
+	sum := sha256.Sum256(content)
+	db.sources = []Source{{
+		Location:  location,
+		FetchedAt: fetchedAt,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Entries:   db.TotalEntries(),
+		Campaign:  campaign,
+	}}
+	return db, nil
+}
+
+// sourceLabels names the default IOC sources
+var sourceLabels = map[string]string{
+	DataDogIOCURL: "DataDog",
+	WizIOCURL:     "Wiz",
+}
+
+// sourceLabel names the feed at location for entries that do not list the
+// feeds that reported them: the vendor for the default sources, otherwise
+// the location itself
+func sourceLabel(location string) string {
+	if label, ok := sourceLabels[location]; ok {
+		return label
+	}
+	return location
+}
@@ -0,0 +1,75 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFromMultipleURLs_RecordsSources(t *testing.T) {
+	server := serveChecksumFeeds(t)
+	feedURL, otherURL := server.URL+"/feed.csv", server.URL+"/other.csv"
+
+	before := time.Now()
+	db, err := LoadFromMultipleURLs([]string{feedURL, otherURL, server.URL + "/missing.csv"})
+	if err != nil {
+		t.Fatalf("LoadFromMultipleURLs failed: %v", err)
+	}
+
+	sources := db.Sources()
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 loaded sources, got %+v", sources)
+	}
+	if sources[0].Location != feedURL || sources[1].Location != otherURL {
+		t.Errorf("expected sources in load order, got %s and %s", sources[0].Location, sources[1].Location)
+	}
+	for _, source := range sources {
+		if source.Entries != 1 {
+			t.Errorf("%s: expected 1 entry, got %d", source.Location, source.Entries)
+		}
+		if source.FetchedAt.Before(before) {
+			t.Errorf("%s: expected a fetch time after the load started, got %v", source.Location, source.FetchedAt)
+		}
+	}
+	if sources[0].SHA256 != sha256Hex(checksumFeed) {
+		t.Errorf("expected the digest of the feed content, got %s", sources[0].SHA256)
+	}
+
+	if filtered := db.FilterByDate(time.Now(), time.Time{}); len(filtered.Sources()) != 2 {
+		t.Errorf("expected FilterByDate to keep the sources, got %+v", filtered.Sources())
+	}
+}
+
+func TestLoadFromFile_RecordsSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iocs.csv")
+	if err := os.WriteFile(path, []byte(checksumFeed), 0o600); err != nil {
+		t.Fatalf("failed to write feed: %v", err)
+	}
+
+	db, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	sources := db.Sources()
+	if len(sources) != 1 || sources[0].Location != path || sources[0].Entries != 1 {
+		t.Errorf("expected the file recorded as a source with 1 entry, got %+v", sources)
+	}
+}
+
+func TestParseSource_LabelsEntriesWithoutSources(t *testing.T) {
+	content := []byte("package_name,package_versions\ntest-muaddib-labelled,1.0.0\n")
+	for _, tt := range []struct{ location, want string }{
+		{WizIOCURL, "Wiz"},
+		{"https://example.com/iocs.csv", "https://example.com/iocs.csv"},
+	} {
+		db, err := parseSource(tt.location, content, time.Now(), "")
+		if err != nil {
+			t.Fatalf("parseSource failed: %v", err)
+		}
+		if got := db.Check("test-muaddib-labelled", "1.0.0").Sources; len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: expected sources [%s], got %v", tt.location, tt.want, got)
+		}
+	}
+}
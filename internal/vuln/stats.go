@@ -0,0 +1,38 @@
+package vuln
+
+import "slices"
+
+// Stats summarises the contents of a database for load reporting
+type Stats struct {
+	TotalEntries         int      // Entries parsed across all sources, before deduplication
+	UniquePackages       int      // Distinct package names with vulnerable versions
+	VulnerableVersions   int      // Distinct name@version pairs, as returned by Size
+	MultiVersionPackages int      // Packages with more than one vulnerable version
+	Scopes               int      // Scopes flagged in their entirety
+	IntegrityHashes      int      // Known-bad tarball integrity hashes
+	Sources              []Source // Per-source contributions, in load order
+	CachedSources        int      // Sources loaded from the cache because the fetch failed
+}
+
+// Stats returns the database's counts in a single call
+func (db *VulnDB) Stats() Stats {
+	stats := Stats{
+		TotalEntries:       db.totalEntries,
+		UniquePackages:     len(db.byName),
+		VulnerableVersions: len(db.entries),
+		Scopes:             len(db.scopes),
+		IntegrityHashes:    len(db.integrity),
+		Sources:            slices.Clone(db.sources),
+	}
+	for _, source := range db.sources {
+		if source.Cached {
+			stats.CachedSources++
+		}
+	}
+	for _, entries := range db.byName {
+		if len(entries) > 1 {
+			stats.MultiVersionPackages++
+		}
+	}
+	return stats
+}
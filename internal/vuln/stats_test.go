@@ -0,0 +1,51 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats_MatchesLoadedFeed(t *testing.T) {
+	feed := "package_name,package_versions,sources,integrity\n" +
+		testPkgVulnerable1 + ",\"1.0.0, 1.0.1\",test,\n" +
+		testPkgVulnerable2 + ",2.0.0,test,sha512-dGVzdC1tdWFkZGli\n" +
+		testPkgVulnerable2 + ",2.0.0,duplicate,\n" +
+		"@test-muaddib-scope/*,,test,\n"
+	path := filepath.Join(t.TempDir(), "iocs.csv")
+	if err := os.WriteFile(path, []byte(feed), 0o600); err != nil {
+		t.Fatalf("failed to write feed: %v", err)
+	}
+
+	db, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+
+	stats := db.Stats()
+	want := Stats{
+		TotalEntries:         5, // One per version listed, before deduplication
+		UniquePackages:       2,
+		VulnerableVersions:   3,
+		MultiVersionPackages: 1,
+		Scopes:               1,
+		IntegrityHashes:      1,
+		Sources:              db.Sources(),
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+	if stats.VulnerableVersions != db.Size() || stats.UniquePackages != db.UniquePackages() {
+		t.Errorf("expected stats to agree with Size and UniquePackages, got %+v", stats)
+	}
+	if len(stats.Sources) != 1 || stats.Sources[0].Location != path || stats.Sources[0].Entries != stats.TotalEntries {
+		t.Errorf("expected the feed to contribute every entry, got %+v", stats.Sources)
+	}
+
+	// The stats are a copy the caller may change
+	stats.Sources[0].Entries = 0
+	if db.Stats().Sources[0].Entries != 5 {
+		t.Error("expected changing the stats not to change the database")
+	}
+}